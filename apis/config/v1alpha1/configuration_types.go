@@ -17,8 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+	configv1alpha1 "k8s.io/component-base/config/v1alpha1"
 )
 
 //+kubebuilder:object:root=true
@@ -27,8 +28,24 @@ import (
 type Configuration struct {
 	metav1.TypeMeta `json:",inline"`
 
-	// ControllerManagerConfigurationSpec returns the configurations for controllers
-	cfg.ControllerManagerConfigurationSpec `json:",inline"`
+	// Namespace is the namespace in which kueue is deployed. It is used as part
+	// of the DNSName of the webhook Service and to store the webhook
+	// certificates.
+	// Defaults to kueue-system.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// ControllerManager returns the configurations for controllers
+	ControllerManager `json:",inline"`
+
+	// InternalCertManagement is configuration for internalCertManagement
+	// +optional
+	InternalCertManagement *InternalCertManagement `json:"internalCertManagement,omitempty"`
+
+	// ClientConnection provides additional configuration options for the
+	// Kubernetes API server client.
+	// +optional
+	ClientConnection *ClientConnection `json:"clientConnection,omitempty"`
 
 	// ManageJobsWithoutQueueName controls whether or not Kueue reconciles
 	// batch/v1.Jobs that don't set the annotation kueue.x-k8s.io/queue-name.
@@ -38,6 +55,429 @@ type Configuration struct {
 	// Defaults to false; therefore, those jobs are not managed and if they are created
 	// unsuspended, they will start immediately.
 	ManageJobsWithoutQueueName bool `json:"manageJobsWithoutQueueName"`
+
+	// WaitForPodsReady is configuration to provide a time-based all-or-nothing
+	// scheduling semantics for Jobs, by ensuring all pods are ready (running
+	// and passing the readiness probe) within the specified time. If the
+	// timeout is exceeded, then the workload is evicted.
+	// +optional
+	WaitForPodsReady *WaitForPodsReady `json:"waitForPodsReady,omitempty"`
+
+	// StuckWorkloads is configuration to evict the admitted workloads whose
+	// pods are never created, for example, because the controller of their
+	// job is down. Such workloads hold their quota without running.
+	// If not set, the workloads are not evicted.
+	// +optional
+	StuckWorkloads *StuckWorkloads `json:"stuckWorkloads,omitempty"`
+
+	// Integrations provides configuration options for the integrations with
+	// job frameworks.
+	// +optional
+	Integrations *Integrations `json:"integrations,omitempty"`
+
+	// Resources provides configuration options for the handling of the
+	// resources requested by the workloads.
+	// +optional
+	Resources *Resources `json:"resources,omitempty"`
+
+	// ObjectRetentionPolicies provides configuration options for the garbage
+	// collection of the objects created by Kueue.
+	// +optional
+	ObjectRetentionPolicies *ObjectRetentionPolicies `json:"objectRetentionPolicies,omitempty"`
+
+	// Audit configures a log of the admission decisions, such as admissions
+	// and evictions, with the quota and usage at decision time.
+	// If not set, the decisions are not audited.
+	// +optional
+	Audit *Audit `json:"audit,omitempty"`
+
+	// Tracing configures the export of OpenTelemetry spans of the scheduling
+	// cycles and of the reconciliation of the jobs and workloads.
+	// If not set, no spans are exported.
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+
+	// FeatureGates is a map of feature names to bools that enable or disable
+	// alpha or beta features. The features that are not listed take their
+	// default values.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// WaitForPodsReady holds the configuration of the all-or-nothing scheduling
+// semantics based on the PodsReady condition of the workloads.
+type WaitForPodsReady struct {
+	// Enable indicates whether to enable the wait for pods ready feature.
+	// Defaults to false.
+	Enable bool `json:"enable,omitempty"`
+
+	// BlockAdmission indicates whether to block the admission of new
+	// workloads while there are admitted workloads whose pods are not ready
+	// yet. This prevents the pods of several workloads from competing for
+	// the same nodes in a deadlock.
+	// Defaults to true when enable is true.
+	// +optional
+	BlockAdmission *bool `json:"blockAdmission,omitempty"`
+
+	// Timeout defines the time for an admitted workload to reach the
+	// PodsReady=true condition. When the timeout is exceeded, the workload
+	// is evicted and requeued in the same cluster queue.
+	// Defaults to 5min.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// RecoveryTimeout defines the time for a workload that lost the
+	// PodsReady=true condition, for example, because its pods failed, to
+	// reach it again. When the timeout is exceeded, the workload is evicted
+	// and requeued in the same cluster queue.
+	// If not set, the workloads are not evicted when they lose the condition.
+	// +optional
+	RecoveryTimeout *metav1.Duration `json:"recoveryTimeout,omitempty"`
+}
+
+// StuckWorkloads holds the configuration of the eviction of the admitted
+// workloads whose pods are never created. Only the workloads whose
+// integration reports the PodsCreated condition, like batch/job, are evicted.
+type StuckWorkloads struct {
+	// GracePeriod is the time that the pods of an admitted workload have to
+	// be created since the workload was started. When exceeded, the workload
+	// is evicted and requeued in the same cluster queue.
+	// Defaults to 10min.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// Audit holds the configuration of the sink of the audit records. Exactly one
+// sink must be set.
+type Audit struct {
+	// File is the path of a file where the records are appended, one JSON
+	// object per line.
+	// +optional
+	File string `json:"file,omitempty"`
+
+	// WebhookURL is an http or https URL where each record is sent as a JSON
+	// object in a POST request.
+	// +optional
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+// TracingExporter is the destination of the spans.
+type TracingExporter string
+
+const (
+	// StdoutTracingExporter writes the spans as JSON objects to a file or to
+	// the standard output.
+	StdoutTracingExporter TracingExporter = "stdout"
+)
+
+// Tracing holds the configuration of the OpenTelemetry spans.
+type Tracing struct {
+	// Exporter is the destination of the spans. The only supported exporter
+	// is "stdout".
+	Exporter TracingExporter `json:"exporter"`
+
+	// File is the path of a file where the stdout exporter appends the
+	// spans. If not set, the spans are written to the standard output.
+	// +optional
+	File string `json:"file,omitempty"`
+
+	// SamplingRatePerMillion is the number of traces sampled per million.
+	// The spans of a workload follow the sampling decision taken when its
+	// trace started, so that its traces are complete.
+	// Defaults to 1000000, that is, all the traces are sampled.
+	// +optional
+	SamplingRatePerMillion *int32 `json:"samplingRatePerMillion,omitempty"`
+}
+
+// ObjectRetentionPolicies holds the configuration of how long the objects
+// created by Kueue are kept.
+type ObjectRetentionPolicies struct {
+	// FinishedWorkloads is the retention policy of the finished Workloads.
+	// If not set, the finished Workloads are kept until their owners are
+	// deleted.
+	// +optional
+	FinishedWorkloads *FinishedWorkloadRetention `json:"finishedWorkloads,omitempty"`
+}
+
+// FinishedWorkloadRetention holds the conditions under which the workload
+// controller deletes the finished Workloads.
+type FinishedWorkloadRetention struct {
+	// TTL is the time that a Workload is kept after it finishes.
+	// If not set, the finished Workloads are not deleted based on their age.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// MaxCount is the maximum number of finished Workloads kept in each
+	// namespace. When exceeded, the Workloads that finished the earliest are
+	// deleted first.
+	// If not set, the finished Workloads are not deleted based on their count.
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// Integrations holds the configuration of the job frameworks that Kueue
+// manages.
+type Integrations struct {
+	// Frameworks is the list of job frameworks that Kueue reconciles. The
+	// frameworks that are not listed are ignored, so Kueue doesn't need the
+	// RBAC permissions or the CRDs of their jobs.
+	// Possible options:
+	//  - "batch/job"
+	// Defaults to ["batch/job"].
+	// +optional
+	Frameworks []string `json:"frameworks,omitempty"`
+}
+
+// Resources holds the configuration of how the requests of the workloads are
+// accounted against the quotas.
+type Resources struct {
+	// ExcludeResourcePrefixes lists the prefixes of the resource names that
+	// are ignored when computing the requests of the podsets. Use it for
+	// resources injected by webhooks or sidecars, such as
+	// "networking.example.com/", so that they don't have to be declared in
+	// every ClusterQueue.
+	// +optional
+	ExcludeResourcePrefixes []string `json:"excludeResourcePrefixes,omitempty"`
+
+	// Transformations defines how the resources requested by the pods are
+	// mapped into the resources accounted against the quotas. This allows,
+	// for example, different models of accelerators to share a single quota.
+	// The transformations are applied before excluding the resources in
+	// excludeResourcePrefixes.
+	// +optional
+	Transformations []ResourceTransformation `json:"transformations,omitempty"`
+}
+
+// ResourceTransformationStrategy tells what happens to the input resource of
+// a transformation.
+type ResourceTransformationStrategy string
+
+const (
+	// Retain keeps the input resource in the requests, besides the outputs.
+	Retain ResourceTransformationStrategy = "Retain"
+	// Replace removes the input resource from the requests.
+	Replace ResourceTransformationStrategy = "Replace"
+)
+
+// ResourceTransformation maps a resource requested by the pods into one or
+// more resources accounted against the quotas.
+type ResourceTransformation struct {
+	// Input is the name of the resource requested by the pods.
+	Input corev1.ResourceName `json:"input"`
+
+	// Strategy indicates whether the input resource is retained in the
+	// requests or replaced by the outputs.
+	// Possible values are Retain and Replace. Defaults to Retain.
+	// +optional
+	Strategy *ResourceTransformationStrategy `json:"strategy,omitempty"`
+
+	// Outputs is the quantity of each resource added to the requests per unit
+	// of the input resource. For example, an output of 500m
+	// example.com/gpu-credits adds half a credit for each unit of input.
+	Outputs corev1.ResourceList `json:"outputs,omitempty"`
+}
+
+// InternalCertManagement holds the configuration of the built-in generation
+// and rotation of the webhook certificates.
+type InternalCertManagement struct {
+	// Enable controls whether Kueue generates and rotates a self-signed
+	// certificate for the webhooks and injects its CA bundle into the webhook
+	// configurations.
+	// Defaults to true. Set it to false to manage the certificates with a
+	// third-party tool, like cert-manager.
+	// +optional
+	Enable *bool `json:"enable,omitempty"`
+
+	// WebhookServiceName is the name of the Service of the webhooks. It is
+	// used as part of the DNSName of the certificate.
+	// Defaults to kueue-webhook-service.
+	// +optional
+	WebhookServiceName *string `json:"webhookServiceName,omitempty"`
+
+	// WebhookSecretName is the name of the Secret where the CA and the
+	// server certificates are stored.
+	// Defaults to kueue-webhook-server-cert.
+	// +optional
+	WebhookSecretName *string `json:"webhookSecretName,omitempty"`
+}
+
+// ControllerManager holds the configuration of the manager and the
+// controllers that it runs.
+type ControllerManager struct {
+	// Webhook contains the controllers webhook configuration
+	// +optional
+	Webhook ControllerWebhook `json:"webhook,omitempty"`
+
+	// LeaderElection is the LeaderElection config to be used when configuring
+	// the manager.Manager leader election
+	// +optional
+	LeaderElection *configv1alpha1.LeaderElectionConfiguration `json:"leaderElection,omitempty"`
+
+	// Metrics contains the controller metrics configuration
+	// +optional
+	Metrics ControllerMetrics `json:"metrics,omitempty"`
+
+	// Health contains the controller health configuration
+	// +optional
+	Health ControllerHealth `json:"health,omitempty"`
+
+	// PprofBindAddress is the TCP address that the manager should bind to
+	// for serving the runtime profiling data under /debug/pprof/.
+	// It can be set to "0" or "" to disable the endpoint, which is the
+	// default.
+	// +optional
+	PprofBindAddress string `json:"pprofBindAddress,omitempty"`
+
+	// VisibilityBindAddress is the TCP address that the manager should bind
+	// to for serving the visibility endpoints, like /dry-run. The endpoints
+	// are not authenticated, so the address should only be reachable by
+	// trusted clients.
+	// It can be set to "0" or "" to disable the endpoints, which is the
+	// default.
+	// +optional
+	VisibilityBindAddress string `json:"visibilityBindAddress,omitempty"`
+
+	// Controller contains global configuration options for controllers
+	// registered within this manager.
+	// +optional
+	Controller *ControllerConfigurationSpec `json:"controller,omitempty"`
+}
+
+// ControllerWebhook defines the webhook server for the controller.
+type ControllerWebhook struct {
+	// Port is the port that the webhook server serves at.
+	// It is used to set webhook.Server.Port.
+	// Defaults to 9443.
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Host is the hostname that the webhook server binds to.
+	// It is used to set webhook.Server.Host.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// CertDir is the directory that contains the server key and certificate.
+	// The server key and certificate must be named tls.key and tls.crt,
+	// respectively.
+	// Defaults to /tmp/k8s-webhook-server/serving-certs.
+	// +optional
+	CertDir string `json:"certDir,omitempty"`
+}
+
+// ControllerMetrics defines the metrics configs.
+type ControllerMetrics struct {
+	// BindAddress is the TCP address that the controller should bind to
+	// for serving prometheus metrics.
+	// It can be set to "0" to disable the metrics serving.
+	// Defaults to :8080.
+	// +optional
+	BindAddress string `json:"bindAddress,omitempty"`
+
+	// EnableLocalQueueMetrics controls whether or not Kueue reports the
+	// number of pending and admitted workloads and the resource usage of
+	// each Queue, labeled by Queue and namespace.
+	// Defaults to false, because the number of series grows with the number
+	// of Queues in the cluster.
+	// +optional
+	EnableLocalQueueMetrics bool `json:"enableLocalQueueMetrics,omitempty"`
+}
+
+// ControllerHealth defines the health configs.
+type ControllerHealth struct {
+	// HealthProbeBindAddress is the TCP address that the controller should bind to
+	// for serving health probes.
+	// It can be set to "0" or "" to disable serving the health probe.
+	// Defaults to :8081.
+	// +optional
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+
+	// ReadinessEndpointName, defaults to "readyz"
+	// +optional
+	ReadinessEndpointName string `json:"readinessEndpointName,omitempty"`
+
+	// LivenessEndpointName, defaults to "healthz"
+	// +optional
+	LivenessEndpointName string `json:"livenessEndpointName,omitempty"`
+}
+
+// ControllerConfigurationSpec defines the global configuration for
+// controllers registered with the manager.
+type ControllerConfigurationSpec struct {
+	// GroupKindConcurrency is a map from a Kind to the number of concurrent reconciliation
+	// allowed for that controller.
+	//
+	// When a controller is registered within this manager using the builder utilities,
+	// users have to specify the type the controller reconciles in the For(...) call.
+	// If the object's kind passed matches one of the keys in this map, the concurrency
+	// for that controller is set to the number specified.
+	//
+	// The key is expected to be consistent in form with GroupKind.String(),
+	// e.g. ReplicaSet in apps group (regardless of version) would be `ReplicaSet.apps`.
+	//
+	// +optional
+	GroupKindConcurrency map[string]int `json:"groupKindConcurrency,omitempty"`
+
+	// CacheSyncTimeout refers to the time limit set to wait for syncing caches.
+	// Defaults to 2 minutes if not set.
+	// +optional
+	CacheSyncTimeout *metav1.Duration `json:"cacheSyncTimeout,omitempty"`
+
+	// RateLimiter configures how the controllers delay the retries of the
+	// objects whose reconciliation failed. Each controller has its own rate
+	// limiter.
+	// If not set, the controllers use the default rate limiter of
+	// controller-runtime.
+	// +optional
+	RateLimiter *ControllerRateLimiter `json:"rateLimiter,omitempty"`
+
+	// UpdatesBatchPeriod is the time that the Queue and ClusterQueue
+	// controllers hold the updates of the workloads before updating the
+	// status of the Queues and ClusterQueues. Longer periods reduce the
+	// requests to the API server, at the cost of staler statuses.
+	// Defaults to 1s.
+	// +optional
+	UpdatesBatchPeriod *metav1.Duration `json:"updatesBatchPeriod,omitempty"`
+}
+
+// ControllerRateLimiter holds the configuration of the rate limiter of the
+// retries of a controller. The delay of an object is the largest of its
+// exponential backoff and of the delay imposed by the overall rate.
+type ControllerRateLimiter struct {
+	// BaseDelay is the delay before retrying an object for the first time.
+	// It doubles with each failure of the object.
+	// Defaults to 5ms.
+	// +optional
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+
+	// MaxDelay is the maximum delay before retrying an object.
+	// Defaults to 1000s.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// QPS is the overall number of retries per second of the controller.
+	// Defaults to 10.
+	// +optional
+	QPS *float32 `json:"qps,omitempty"`
+
+	// Burst is the number of retries that can exceed the QPS at once.
+	// Defaults to 100.
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// ClientConnection configures the client of the manager to the Kubernetes
+// API server.
+type ClientConnection struct {
+	// QPS controls the number of queries per second allowed for K8S api server
+	// connection.
+	// Defaults to 20.
+	// +optional
+	QPS *float32 `json:"qps,omitempty"`
+
+	// Burst allows extra queries to accumulate when a client is exceeding its rate.
+	// Defaults to 30.
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
 }
 
 func init() {
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	DefaultNamespace              = "kueue-system"
+	DefaultWebhookPort            = 9443
+	DefaultWebhookCertDir         = "/tmp/k8s-webhook-server/serving-certs"
+	DefaultHealthProbeBindAddress = ":8081"
+	DefaultMetricsBindAddress     = ":8080"
+	DefaultReadinessEndpoint      = "readyz"
+	DefaultLivenessEndpoint       = "healthz"
+	DefaultLeaderElectionID       = "c1f6bfd2.kueue.x-k8s.io"
+	DefaultClientConnectionQPS    = 20.0
+	DefaultClientConnectionBurst  = 30
+	DefaultWebhookServiceName     = "kueue-webhook-service"
+	DefaultWebhookSecretName      = "kueue-webhook-server-cert"
+	DefaultJobFrameworkName       = "batch/job"
+	DefaultPodsReadyTimeout       = 5 * time.Minute
+	DefaultStuckWorkloadsGrace    = 10 * time.Minute
+	DefaultRateLimiterBaseDelay   = 5 * time.Millisecond
+	DefaultRateLimiterMaxDelay    = 1000 * time.Second
+	DefaultRateLimiterQPS         = 10.0
+	DefaultRateLimiterBurst       = 100
+)
+
+func init() {
+	SchemeBuilder.SchemeBuilder.Register(addDefaultingFuncs)
+}
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&Configuration{}, func(obj interface{}) {
+		SetDefaults_Configuration(obj.(*Configuration))
+	})
+	return nil
+}
+
+// SetDefaults_Configuration sets default values for ComponentConfig.
+func SetDefaults_Configuration(cfg *Configuration) {
+	if cfg.Namespace == nil {
+		cfg.Namespace = pointer.String(DefaultNamespace)
+	}
+	if cfg.Webhook.Port == nil {
+		cfg.Webhook.Port = pointer.Int(DefaultWebhookPort)
+	}
+	if cfg.Webhook.CertDir == "" {
+		cfg.Webhook.CertDir = DefaultWebhookCertDir
+	}
+	if len(cfg.Metrics.BindAddress) == 0 {
+		cfg.Metrics.BindAddress = DefaultMetricsBindAddress
+	}
+	if len(cfg.Health.HealthProbeBindAddress) == 0 {
+		cfg.Health.HealthProbeBindAddress = DefaultHealthProbeBindAddress
+	}
+	if len(cfg.Health.ReadinessEndpointName) == 0 {
+		cfg.Health.ReadinessEndpointName = DefaultReadinessEndpoint
+	}
+	if len(cfg.Health.LivenessEndpointName) == 0 {
+		cfg.Health.LivenessEndpointName = DefaultLivenessEndpoint
+	}
+	if cfg.LeaderElection != nil && cfg.LeaderElection.LeaderElect != nil &&
+		*cfg.LeaderElection.LeaderElect && len(cfg.LeaderElection.ResourceName) == 0 {
+		cfg.LeaderElection.ResourceName = DefaultLeaderElectionID
+	}
+	if cfg.InternalCertManagement == nil {
+		cfg.InternalCertManagement = &InternalCertManagement{}
+	}
+	if cfg.InternalCertManagement.Enable == nil {
+		cfg.InternalCertManagement.Enable = pointer.Bool(true)
+	}
+	if *cfg.InternalCertManagement.Enable {
+		if cfg.InternalCertManagement.WebhookServiceName == nil {
+			cfg.InternalCertManagement.WebhookServiceName = pointer.String(DefaultWebhookServiceName)
+		}
+		if cfg.InternalCertManagement.WebhookSecretName == nil {
+			cfg.InternalCertManagement.WebhookSecretName = pointer.String(DefaultWebhookSecretName)
+		}
+	}
+	if cfg.WaitForPodsReady != nil && cfg.WaitForPodsReady.Enable {
+		if cfg.WaitForPodsReady.BlockAdmission == nil {
+			cfg.WaitForPodsReady.BlockAdmission = pointer.Bool(true)
+		}
+		if cfg.WaitForPodsReady.Timeout == nil {
+			cfg.WaitForPodsReady.Timeout = &metav1.Duration{Duration: DefaultPodsReadyTimeout}
+		}
+	}
+	if cfg.StuckWorkloads != nil && cfg.StuckWorkloads.GracePeriod == nil {
+		cfg.StuckWorkloads.GracePeriod = &metav1.Duration{Duration: DefaultStuckWorkloadsGrace}
+	}
+	if cfg.Integrations == nil {
+		cfg.Integrations = &Integrations{}
+	}
+	if cfg.Integrations.Frameworks == nil {
+		cfg.Integrations.Frameworks = []string{DefaultJobFrameworkName}
+	}
+	if cfg.Resources != nil {
+		for i := range cfg.Resources.Transformations {
+			if cfg.Resources.Transformations[i].Strategy == nil {
+				strategy := Retain
+				cfg.Resources.Transformations[i].Strategy = &strategy
+			}
+		}
+	}
+	if cfg.Controller != nil && cfg.Controller.RateLimiter != nil {
+		rl := cfg.Controller.RateLimiter
+		if rl.BaseDelay == nil {
+			rl.BaseDelay = &metav1.Duration{Duration: DefaultRateLimiterBaseDelay}
+		}
+		if rl.MaxDelay == nil {
+			rl.MaxDelay = &metav1.Duration{Duration: DefaultRateLimiterMaxDelay}
+		}
+		if rl.QPS == nil {
+			rl.QPS = pointer.Float32(DefaultRateLimiterQPS)
+		}
+		if rl.Burst == nil {
+			rl.Burst = pointer.Int32(DefaultRateLimiterBurst)
+		}
+	}
+	if cfg.ClientConnection == nil {
+		cfg.ClientConnection = &ClientConnection{}
+	}
+	if cfg.ClientConnection.QPS == nil {
+		cfg.ClientConnection.QPS = pointer.Float32(DefaultClientConnectionQPS)
+	}
+	if cfg.ClientConnection.Burst == nil {
+		cfg.ClientConnection.Burst = pointer.Int32(DefaultClientConnectionBurst)
+	}
+}
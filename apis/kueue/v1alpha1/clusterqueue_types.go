@@ -135,8 +135,93 @@ type ClusterQueueSpec struct {
 	// Defaults to null which is a nothing selector (no namespaces eligible).
 	// If set to an empty selector `{}`, then all namespaces are eligible.
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// stopPolicy - if set to a value different from None, the ClusterQueue is
+	// considered inactive and no new workloads are admitted.
+	//
+	// Depending on its value, its associated workloads will:
+	//
+	// - None: workloads are admitted.
+	// - HoldAndDrain: admitted workloads are evicted and pending workloads are held.
+	// - Hold: admitted workloads run to completion and pending workloads are held.
+	//
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;Hold;HoldAndDrain
+	StopPolicy *StopPolicy `json:"stopPolicy,omitempty"`
+
+	// admissionLimits caps the number of workloads admitted by this
+	// ClusterQueue that are not finished, and the total number of their
+	// pods, regardless of the quota left for the resources. This prevents a
+	// large number of small workloads from overloading the cluster.
+	// If not set, only the quota of the resources is enforced.
+	// +optional
+	AdmissionLimits *AdmissionLimits `json:"admissionLimits,omitempty"`
+
+	// priorityAging boosts the priority used to order the pending workloads
+	// of this ClusterQueue the longer they wait since they were created, so
+	// that low priority workloads are eventually admitted even when higher
+	// priority workloads keep being submitted. The priority of the Workload
+	// objects is not modified. If not set, the pending workloads are ordered
+	// by their priority.
+	// +optional
+	PriorityAging *PriorityAging `json:"priorityAging,omitempty"`
+}
+
+// PriorityAging defines how the priority of the pending workloads grows while
+// they wait.
+type PriorityAging struct {
+	// rate is the priority that a pending workload gains for every full
+	// minute it waits.
+	// +kubebuilder:validation:Minimum=1
+	Rate int32 `json:"rate"`
+
+	// maxBoost is the maximum priority that a pending workload can gain.
+	// +kubebuilder:validation:Minimum=0
+	MaxBoost int32 `json:"maxBoost"`
+}
+
+// AdmissionLimits caps the objects admitted by a ClusterQueue.
+type AdmissionLimits struct {
+	// maxWorkloads is the maximum number of admitted workloads that are not
+	// finished. If not set, the number of workloads is not limited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxWorkloads *int32 `json:"maxWorkloads,omitempty"`
+
+	// maxPods is the maximum total count of the pods of the admitted
+	// workloads that are not finished, that is, the sum of the counts of
+	// their podSets. If not set, the number of pods is not limited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPods *int32 `json:"maxPods,omitempty"`
+
+	// maxNamespaceQuotaPercent is the maximum percentage of the min quota of
+	// each resource flavor that the admitted workloads from a single
+	// namespace can use, so that a namespace sharing the ClusterQueue with
+	// others can't take all its quota. The usage borrowed from the cohort
+	// counts towards the limit. If not set, the usage of each namespace is
+	// only limited by the quota of the ClusterQueue.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxNamespaceQuotaPercent *int32 `json:"maxNamespaceQuotaPercent,omitempty"`
 }
 
+type StopPolicy string
+
+const (
+	// None means that the queue is active.
+	None StopPolicy = "None"
+
+	// HoldAndDrain means that the admitted workloads are evicted and the
+	// pending workloads are held.
+	HoldAndDrain StopPolicy = "HoldAndDrain"
+
+	// Hold means that the admitted workloads run to completion and the
+	// pending workloads are held.
+	Hold StopPolicy = "Hold"
+)
+
 type QueueingStrategy string
 
 const (
@@ -231,8 +316,24 @@ type ClusterQueueStatus struct {
 	// clusterQueue and haven't finished yet.
 	// +optional
 	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// conditions hold the latest available observations of the ClusterQueue
+	// current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+const (
+	// ClusterQueueActive indicates that the ClusterQueue can admit new
+	// workloads. It's false when the ClusterQueue is stopped or references
+	// ResourceFlavors that don't exist.
+	ClusterQueueActive = "Active"
+)
+
 type UsedResources map[corev1.ResourceName]map[string]Usage
 
 type Usage struct {
@@ -245,6 +346,7 @@ type Usage struct {
 }
 
 //+kubebuilder:object:root=true
+//+kubebuilder:storageversion
 //+kubebuilder:resource:scope=Cluster
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Cohort",JSONPath=".spec.cohort",type=string,description="Cohort that this ClusterQueue belongs to"
@@ -24,6 +24,19 @@ import (
 type QueueSpec struct {
 	// clusterQueue is a reference to a clusterQueue that backs this queue.
 	ClusterQueue ClusterQueueReference `json:"clusterQueue,omitempty"`
+
+	// stopPolicy - if set to a value different from None, the workloads
+	// submitted to this queue are not admitted.
+	//
+	// Depending on its value, its associated workloads will:
+	//
+	// - None: workloads are admitted.
+	// - HoldAndDrain: admitted workloads are evicted and pending workloads are held.
+	// - Hold: admitted workloads run to completion and pending workloads are held.
+	//
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;Hold;HoldAndDrain
+	StopPolicy *StopPolicy `json:"stopPolicy,omitempty"`
 }
 
 // ClusterQueueReference is the name of the ClusterQueue.
@@ -35,12 +48,40 @@ type QueueStatus struct {
 	// queue not yet admitted to a ClusterQueue.
 	// +optional
 	PendingWorkloads int32 `json:"pendingWorkloads"`
+
+	// admittedWorkloads is the number of workloads submitted to this queue
+	// that are admitted by the ClusterQueue and haven't finished yet.
+	// +optional
+	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// usedResources are the resources used by the admitted workloads
+	// submitted to this queue, by resource and flavor. The borrowed
+	// quantity is not reported.
+	// +optional
+	UsedResources UsedResources `json:"usedResources,omitempty"`
+
+	// conditions hold the latest available observations of the Queue
+	// current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+const (
+	// QueueActive indicates that the workloads submitted to the Queue can be
+	// admitted. It's false when the Queue is stopped.
+	QueueActive = "Active"
+)
+
 //+kubebuilder:object:root=true
+//+kubebuilder:storageversion
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="ClusterQueue",JSONPath=".spec.clusterQueue",type=string,description="Backing ClusterQueue"
 //+kubebuilder:printcolumn:name="Pending Workloads",JSONPath=".status.pendingWorkloads",type=integer,description="Number of pending workloads"
+//+kubebuilder:printcolumn:name="Admitted Workloads",JSONPath=".status.admittedWorkloads",type=integer,description="Number of admitted workloads that haven't finished yet"
 
 // Queue is the Schema for the queues API
 type Queue struct {
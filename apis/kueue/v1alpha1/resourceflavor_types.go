@@ -22,6 +22,7 @@ import (
 )
 
 //+kubebuilder:object:root=true
+//+kubebuilder:storageversion
 //+kubebuilder:resource:scope=Cluster
 
 // ResourceFlavor is the Schema for the resourceflavors API
@@ -38,6 +39,12 @@ type ResourceFlavor struct {
 	// “tolerate” to be able to use this flavor.
 	// For example, cloud.provider.com/preemptible="true":NoSchedule
 	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// tolerations are added to the pods of the workloads admitted with this
+	// flavor, so that they can land on the nodes that carry the taints of
+	// this flavor.
+	// For example, cloud.provider.com/preemptible="true":NoSchedule
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -49,6 +49,13 @@ type WorkloadSpec struct {
 	// The priority value is populated from PriorityClassName.
 	// The higher the value, the higher the priority.
 	Priority *int32 `json:"priority,omitempty"`
+
+	// active determines if the workload can be admitted by a ClusterQueue.
+	// Changing active from true to false evicts the workload if it was
+	// already admitted.
+	// Defaults to true.
+	// +kubebuilder:default=true
+	Active *bool `json:"active,omitempty"`
 }
 
 type Admission struct {
@@ -68,6 +75,15 @@ type PodSetFlavors struct {
 
 	// Flavors are the flavors assigned to the workload for each resource.
 	Flavors map[corev1.ResourceName]string `json:"flavors,omitempty"`
+
+	// count is the number of pods of the podSet that were admitted. It can
+	// be lower than the count of the podSet when the workload is partially
+	// admitted. If not set, all the pods were admitted.
+	// The Kueue scheduler always admits all the pods and leaves it unset;
+	// it's set by the controllers that write the admission of a workload
+	// themselves.
+	// +optional
+	Count *int32 `json:"count,omitempty"`
 }
 
 type PodSet struct {
@@ -80,6 +96,20 @@ type PodSet struct {
 
 	// count is the number of pods for the spec.
 	Count int32 `json:"count"`
+
+	// requiredFlavors are the names of the ResourceFlavors that can be
+	// assigned to the resources of this podSet. If empty, any flavor of the
+	// ClusterQueue can be assigned.
+	// +optional
+	// +listType=set
+	RequiredFlavors []string `json:"requiredFlavors,omitempty"`
+
+	// flavorSelector selects, by their labels, the ResourceFlavors that can
+	// be assigned to the resources of this podSet. The labels of the
+	// ResourceFlavor object are matched, not its node labels. If both
+	// requiredFlavors and flavorSelector are set, a flavor must satisfy both.
+	// +optional
+	FlavorSelector *metav1.LabelSelector `json:"flavorSelector,omitempty"`
 }
 
 // WorkloadStatus defines the observed state of Workload
@@ -97,6 +127,8 @@ type WorkloadCondition struct {
 	//
 	// Admitted: the Workload was admitted through a ClusterQueue.
 	//
+	// PodsReady: all the pods of the admitted Workload are ready or succeeded.
+	//
 	// Finished: the associated workload finished running (failed or succeeded).
 	Type WorkloadConditionType `json:"type"`
 
@@ -128,12 +160,22 @@ const (
 	// WorkloadAdmitted means that the Workload was admitted by a ClusterQueue.
 	WorkloadAdmitted WorkloadConditionType = "Admitted"
 
+	// WorkloadPodsReady means that at least as many pods as required to run
+	// the workload are ready or have succeeded.
+	WorkloadPodsReady WorkloadConditionType = "PodsReady"
+
+	// WorkloadPodsCreated means that the integration observed pods of the
+	// workload since it was last started. The integrations that support it
+	// set it to false when they start the workload.
+	WorkloadPodsCreated WorkloadConditionType = "PodsCreated"
+
 	// WorkloadFinished means that the workload associated to the
 	// ResourceClaim finished running (failed or succeeded).
 	WorkloadFinished WorkloadConditionType = "Finished"
 )
 
 // +kubebuilder:object:root=true
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Queue",JSONPath=".spec.queueName",type=string,description="Name of the queue this workload was submitted to"
 // +kubebuilder:printcolumn:name="Admitted by",JSONPath=".spec.admission.clusterQueue",type=string,description="Name of the ClusterQueue that admitted this workload"
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -89,6 +90,31 @@ func ValidateWorkload(obj *Workload) field.ErrorList {
 				"count must be greater than 0"),
 			)
 		}
+		for j, name := range podSet.RequiredFlavors {
+			for _, msg := range validation.IsDNS1123Subdomain(name) {
+				allErrs = append(allErrs, field.Invalid(podSetsField.Index(i).Child("requiredFlavors").Index(j), name, msg))
+			}
+		}
+		if podSet.FlavorSelector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(podSet.FlavorSelector, podSetsField.Index(i).Child("flavorSelector"))...)
+		}
+	}
+
+	if obj.Spec.Admission != nil {
+		podSetCounts := make(map[string]int32, len(obj.Spec.PodSets))
+		for _, podSet := range obj.Spec.PodSets {
+			podSetCounts[podSet.Name] = podSet.Count
+		}
+		podSetFlavorsField := specField.Child("admission", "podSetFlavors")
+		for i, psFlavors := range obj.Spec.Admission.PodSetFlavors {
+			if psFlavors.Count != nil && (*psFlavors.Count <= 0 || *psFlavors.Count > podSetCounts[psFlavors.Name]) {
+				allErrs = append(allErrs, field.Invalid(
+					podSetFlavorsField.Index(i).Child("count"),
+					*psFlavors.Count,
+					"count must be greater than 0 and not greater than the count of the podSet"),
+				)
+			}
+		}
 	}
 
 	if len(obj.Spec.PriorityClassName) > 0 {
@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	. "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
@@ -69,6 +70,31 @@ func TestValidateWorkload(t *testing.T) {
 				field.Invalid(podSetsField.Index(0).Child("count"), int32(-1), ""),
 			},
 		},
+		"should have valid requiredFlavors": {
+			workload: testingutil.MakeWorkload(objName, objNs).RequiredFlavors("on-demand", "Spot").Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(podSetsField.Index(0).Child("requiredFlavors").Index(1), "Spot", ""),
+			},
+		},
+		"should have valid flavorSelector": {
+			workload: testingutil.MakeWorkload(objName, objNs).FlavorSelector(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "gpu",
+					Operator: metav1.LabelSelectorOpIn,
+				}},
+			}).Obj(),
+			wantErr: field.ErrorList{
+				field.Required(podSetsField.Index(0).Child("flavorSelector", "matchExpressions").Index(0).Child("values"), ""),
+			},
+		},
+		"admitted count should not be greater than the podSet count": {
+			workload: testingutil.MakeWorkload(objName, objNs).Admit(
+				testingutil.MakeAdmission("cq").Count(2).Obj(),
+			).Obj(),
+			wantErr: field.ErrorList{
+				field.Invalid(specField.Child("admission", "podSetFlavors").Index(0).Child("count"), int32(2), ""),
+			},
+		},
 		"should have valid priorityClassName": {
 			workload: testingutil.MakeWorkload(objName, objNs).PriorityClass("invalid_class").Obj(),
 			wantErr: field.ErrorList{
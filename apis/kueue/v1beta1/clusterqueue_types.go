@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterQueueSpec defines the desired state of ClusterQueue
+type ClusterQueueSpec struct {
+	// resources represent the total pod requests of workloads dispatched
+	// via this clusterQueue. This doesn’t guarantee the actual availability of
+	// resources, although an integration with a resource provisioner like Cluster
+	// Autoscaler is possible to achieve that. Example:
+	//
+	// - name: cpu
+	//   flavors:
+	//   - name: default
+	//     nominalQuota: 100
+	// - name: memory
+	//   flavors:
+	//   - name: default
+	//     nominalQuota: 100Gi
+	//
+	// +listType=map
+	// +listMapKey=name
+	Resources []Resource `json:"resources,omitempty"`
+
+	// cohort that this ClusterQueue belongs to. CQs that belong to the
+	// same cohort can borrow unused resources from each other.
+	//
+	// A CQ can be a member of a single borrowing cohort. A workload submitted
+	// to a queue referencing this CQ can borrow quota from any CQ in the
+	// cohort. Only quota for the [resource, flavor] pairs listed in the CQ can
+	// be borrowed.
+	// If empty, this ClusterQueue cannot borrow from any other ClusterQueue and
+	// vice versa.
+	//
+	// The name style is similar to label keys. These are just names to link CQs
+	// together, and they are meaningless otherwise.
+	Cohort string `json:"cohort,omitempty"`
+
+	// QueueingStrategy indicates the queueing strategy of the workloads
+	// across the queues in this ClusterQueue. This field is immutable.
+	// Current Supported Strategies:
+	//
+	// - StrictFIFO: workloads are ordered strictly by creation time.
+	// Older workloads that can't be admitted will block admitting newer
+	// workloads even if they fit available quota.
+	// - BestEffortFIFO：workloads are ordered by creation time,
+	// however older workloads that can't be admitted will not block
+	// admitting newer workloads that fit existing quota.
+	//
+	// +kubebuilder:default=BestEffortFIFO
+	// +kubebuilder:validation:Enum=StrictFIFO;BestEffortFIFO
+	QueueingStrategy QueueingStrategy `json:"queueingStrategy,omitempty"`
+
+	// namespaceSelector defines which namespaces are allowed to submit workloads to
+	// this clusterQueue. Beyond this basic support for policy, an policy agent like
+	// Gatekeeper should be used to enforce more advanced policies.
+	// Defaults to null which is a nothing selector (no namespaces eligible).
+	// If set to an empty selector `{}`, then all namespaces are eligible.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// stopPolicy - if set to a value different from None, the ClusterQueue is
+	// considered inactive and no new workloads are admitted.
+	//
+	// Depending on its value, its associated workloads will:
+	//
+	// - None: workloads are admitted.
+	// - HoldAndDrain: admitted workloads are evicted and pending workloads are held.
+	// - Hold: admitted workloads run to completion and pending workloads are held.
+	//
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;Hold;HoldAndDrain
+	StopPolicy *StopPolicy `json:"stopPolicy,omitempty"`
+
+	// admissionLimits caps the number of workloads admitted by this
+	// ClusterQueue that are not finished, and the total number of their
+	// pods, regardless of the quota left for the resources. This prevents a
+	// large number of small workloads from overloading the cluster.
+	// If not set, only the quota of the resources is enforced.
+	// +optional
+	AdmissionLimits *AdmissionLimits `json:"admissionLimits,omitempty"`
+
+	// priorityAging boosts the priority used to order the pending workloads
+	// of this ClusterQueue the longer they wait since they were created, so
+	// that low priority workloads are eventually admitted even when higher
+	// priority workloads keep being submitted. The priority of the Workload
+	// objects is not modified. If not set, the pending workloads are ordered
+	// by their priority.
+	// +optional
+	PriorityAging *PriorityAging `json:"priorityAging,omitempty"`
+}
+
+// PriorityAging defines how the priority of the pending workloads grows while
+// they wait.
+type PriorityAging struct {
+	// rate is the priority that a pending workload gains for every full
+	// minute it waits.
+	// +kubebuilder:validation:Minimum=1
+	Rate int32 `json:"rate"`
+
+	// maxBoost is the maximum priority that a pending workload can gain.
+	// +kubebuilder:validation:Minimum=0
+	MaxBoost int32 `json:"maxBoost"`
+}
+
+// AdmissionLimits caps the objects admitted by a ClusterQueue.
+type AdmissionLimits struct {
+	// maxWorkloads is the maximum number of admitted workloads that are not
+	// finished. If not set, the number of workloads is not limited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxWorkloads *int32 `json:"maxWorkloads,omitempty"`
+
+	// maxPods is the maximum total count of the pods of the admitted
+	// workloads that are not finished, that is, the sum of the counts of
+	// their podSets. If not set, the number of pods is not limited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPods *int32 `json:"maxPods,omitempty"`
+
+	// maxNamespaceQuotaPercent is the maximum percentage of the nominal quota of
+	// each resource flavor that the admitted workloads from a single
+	// namespace can use, so that a namespace sharing the ClusterQueue with
+	// others can't take all its quota. The usage borrowed from the cohort
+	// counts towards the limit. If not set, the usage of each namespace is
+	// only limited by the quota of the ClusterQueue.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxNamespaceQuotaPercent *int32 `json:"maxNamespaceQuotaPercent,omitempty"`
+}
+
+type StopPolicy string
+
+const (
+	// None means that the queue is active.
+	None StopPolicy = "None"
+
+	// HoldAndDrain means that the admitted workloads are evicted and the
+	// pending workloads are held.
+	HoldAndDrain StopPolicy = "HoldAndDrain"
+
+	// Hold means that the admitted workloads run to completion and the
+	// pending workloads are held.
+	Hold StopPolicy = "Hold"
+)
+
+type QueueingStrategy string
+
+const (
+	// StrictFIFO means that workloads are ordered strictly by creation time.
+	// Older workloads that can't be admitted will block admitting newer
+	// workloads even if they fit available quota.
+	StrictFIFO QueueingStrategy = "StrictFIFO"
+
+	// BestEffortFIFO means that workloads are ordered by creation time,
+	// however older workloads that can't be admitted will not block
+	// admitting newer workloads that fit existing quota.
+	BestEffortFIFO QueueingStrategy = "BestEffortFIFO"
+)
+
+type Resource struct {
+	// name of the resource. For example, cpu, memory or nvidia.com/gpu.
+	Name corev1.ResourceName `json:"name"`
+
+	// flavors is the list of different flavors of this resource and their
+	// quotas. Typically two different “flavors” of the same resource
+	// represent different hardware models (e.g., gpu models, cpu
+	// architectures) or pricing (on-demand vs spot cpus).
+	//
+	// The flavors are evaluated in order, selecting the first to satisfy a
+	// workload’s requirements. The quantities are additive: in the example
+	// below the GPU quota in total is 20 (10 k80 + 10 p100).
+	//
+	// - name: nvidia.com/gpu
+	//   flavors:
+	//   - name: k80
+	//     nominalQuota: 10
+	//   - name: p100
+	//     nominalQuota: 10
+	//
+	// +listType=map
+	// +listMapKey=name
+	Flavors []Flavor `json:"flavors,omitempty"`
+}
+
+type Flavor struct {
+	// name is a reference to the resourceFlavor that defines this flavor.
+	// +kubebuilder:default=default
+	Name ResourceFlavorReference `json:"name"`
+
+	// nominalQuota is the quantity of this resource that is available for
+	// workloads admitted by this ClusterQueue at a point in time.
+	// The sum of the nominal quotas for a flavor in a cohort is the maximum
+	// amount of the resource that the ClusterQueues in the cohort can use.
+	NominalQuota resource.Quantity `json:"nominalQuota,omitempty"`
+
+	// borrowingLimit is the maximum amount of quota for this [resource,
+	// flavor] that this ClusterQueue is allowed to borrow from the unused
+	// nominal quota of other ClusterQueues in the same cohort.
+	// If null, there is no limit for borrowing.
+	BorrowingLimit *resource.Quantity `json:"borrowingLimit,omitempty"`
+}
+
+// ResourceFlavorReference is the name of the ResourceFlavor.
+type ResourceFlavorReference string
+
+// ClusterQueueStatus defines the observed state of ClusterQueue
+type ClusterQueueStatus struct {
+	// flavorsUsage are the used quotas, by flavor, currently in use by the
+	// workloads assigned to this ClusterQueue.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	FlavorsUsage []FlavorUsage `json:"flavorsUsage,omitempty"`
+
+	// pendingWorkloads is the number of workloads currently waiting to be
+	// admitted to this clusterQueue.
+	// +optional
+	PendingWorkloads int32 `json:"pendingWorkloads"`
+
+	// admittedWorkloads is the number of workloads currently admitted to this
+	// clusterQueue and haven't finished yet.
+	// +optional
+	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// conditions hold the latest available observations of the ClusterQueue
+	// current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+const (
+	// ClusterQueueActive indicates that the ClusterQueue can admit new
+	// workloads. It's false when the ClusterQueue is stopped or references
+	// ResourceFlavors that don't exist.
+	ClusterQueueActive = "Active"
+)
+
+type FlavorUsage struct {
+	// name of the flavor.
+	Name ResourceFlavorReference `json:"name"`
+
+	// resources lists the quota usage for the resources in this flavor.
+	// +listType=map
+	// +listMapKey=name
+	Resources []ResourceUsage `json:"resources"`
+}
+
+type ResourceUsage struct {
+	// name of the resource.
+	Name corev1.ResourceName `json:"name"`
+
+	// total is the total quantity of the resource used, including resources
+	// borrowed from the cohort.
+	Total resource.Quantity `json:"total,omitempty"`
+
+	// borrowed is the used quantity past the nominalQuota, borrowed from the
+	// cohort.
+	Borrowed resource.Quantity `json:"borrowed,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cohort",JSONPath=".spec.cohort",type=string,description="Cohort that this ClusterQueue belongs to"
+//+kubebuilder:printcolumn:name="Strategy",JSONPath=".spec.queueingStrategy",type=string,description="The queueing strategy used to prioritize workloads",priority=1
+//+kubebuilder:printcolumn:name="Pending Workloads",JSONPath=".status.pendingWorkloads",type=integer,description="Number of pending workloads"
+//+kubebuilder:printcolumn:name="Admitted Workloads",JSONPath=".status.admittedWorkloads",type=integer,description="Number of admitted workloads that haven't finished yet",priority=1
+
+// ClusterQueue is the Schema for the clusterQueue API.
+type ClusterQueue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterQueueSpec   `json:"spec,omitempty"`
+	Status ClusterQueueStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterQueueList contains a list of ClusterQueue
+type ClusterQueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterQueue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterQueue{}, &ClusterQueueList{})
+}
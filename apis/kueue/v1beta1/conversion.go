@@ -0,0 +1,351 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+var (
+	_ conversion.Convertible = &ClusterQueue{}
+	_ conversion.Convertible = &Queue{}
+	_ conversion.Convertible = &ResourceFlavor{}
+	_ conversion.Convertible = &Workload{}
+)
+
+// ConvertTo converts this ClusterQueue to the hub version (v1alpha1).
+// The borrowingLimit is converted to the max quota, which is the nominal quota
+// plus the borrowing limit.
+func (src *ClusterQueue) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ClusterQueue)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = v1alpha1.ClusterQueueSpec{
+		Cohort:            src.Spec.Cohort,
+		QueueingStrategy:  v1alpha1.QueueingStrategy(src.Spec.QueueingStrategy),
+		NamespaceSelector: src.Spec.NamespaceSelector.DeepCopy(),
+		StopPolicy:        (*v1alpha1.StopPolicy)(src.Spec.StopPolicy),
+		PriorityAging:     (*v1alpha1.PriorityAging)(src.Spec.PriorityAging.DeepCopy()),
+	}
+	if l := src.Spec.AdmissionLimits; l != nil {
+		dst.Spec.AdmissionLimits = &v1alpha1.AdmissionLimits{
+			MaxWorkloads:             l.MaxWorkloads,
+			MaxPods:                  l.MaxPods,
+			MaxNamespaceQuotaPercent: l.MaxNamespaceQuotaPercent,
+		}
+	}
+	if src.Spec.Resources != nil {
+		dst.Spec.Resources = make([]v1alpha1.Resource, len(src.Spec.Resources))
+		for i, r := range src.Spec.Resources {
+			dst.Spec.Resources[i].Name = r.Name
+			if r.Flavors == nil {
+				continue
+			}
+			dst.Spec.Resources[i].Flavors = make([]v1alpha1.Flavor, len(r.Flavors))
+			for j, f := range r.Flavors {
+				dstFlavor := &dst.Spec.Resources[i].Flavors[j]
+				dstFlavor.Name = v1alpha1.ResourceFlavorReference(f.Name)
+				dstFlavor.Quota.Min = f.NominalQuota.DeepCopy()
+				if f.BorrowingLimit != nil {
+					max := f.NominalQuota.DeepCopy()
+					max.Add(*f.BorrowingLimit)
+					dstFlavor.Quota.Max = &max
+				}
+			}
+		}
+	}
+
+	dst.Status = v1alpha1.ClusterQueueStatus{
+		PendingWorkloads:  src.Status.PendingWorkloads,
+		AdmittedWorkloads: src.Status.AdmittedWorkloads,
+		Conditions:        copyConditions(src.Status.Conditions),
+	}
+	dst.Status.UsedResources = usedResourcesToHub(src.Status.FlavorsUsage)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+// The used resources are listed by flavor, both sorted by name.
+func (dst *ClusterQueue) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ClusterQueue)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = ClusterQueueSpec{
+		Cohort:            src.Spec.Cohort,
+		QueueingStrategy:  QueueingStrategy(src.Spec.QueueingStrategy),
+		NamespaceSelector: src.Spec.NamespaceSelector.DeepCopy(),
+		StopPolicy:        (*StopPolicy)(src.Spec.StopPolicy),
+		PriorityAging:     (*PriorityAging)(src.Spec.PriorityAging.DeepCopy()),
+	}
+	if l := src.Spec.AdmissionLimits; l != nil {
+		dst.Spec.AdmissionLimits = &AdmissionLimits{
+			MaxWorkloads:             l.MaxWorkloads,
+			MaxPods:                  l.MaxPods,
+			MaxNamespaceQuotaPercent: l.MaxNamespaceQuotaPercent,
+		}
+	}
+	if src.Spec.Resources != nil {
+		dst.Spec.Resources = make([]Resource, len(src.Spec.Resources))
+		for i, r := range src.Spec.Resources {
+			dst.Spec.Resources[i].Name = r.Name
+			if r.Flavors == nil {
+				continue
+			}
+			dst.Spec.Resources[i].Flavors = make([]Flavor, len(r.Flavors))
+			for j, f := range r.Flavors {
+				dstFlavor := &dst.Spec.Resources[i].Flavors[j]
+				dstFlavor.Name = ResourceFlavorReference(f.Name)
+				dstFlavor.NominalQuota = f.Quota.Min.DeepCopy()
+				if f.Quota.Max != nil {
+					limit := f.Quota.Max.DeepCopy()
+					limit.Sub(f.Quota.Min)
+					dstFlavor.BorrowingLimit = &limit
+				}
+			}
+		}
+	}
+
+	dst.Status = ClusterQueueStatus{
+		PendingWorkloads:  src.Status.PendingWorkloads,
+		AdmittedWorkloads: src.Status.AdmittedWorkloads,
+		Conditions:        copyConditions(src.Status.Conditions),
+	}
+	dst.Status.FlavorsUsage = flavorsUsageFromHub(src.Status.UsedResources)
+	return nil
+}
+
+// ConvertTo converts this Queue to the hub version (v1alpha1).
+func (src *Queue) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Queue)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = v1alpha1.QueueSpec{
+		ClusterQueue: v1alpha1.ClusterQueueReference(src.Spec.ClusterQueue),
+		StopPolicy:   (*v1alpha1.StopPolicy)(src.Spec.StopPolicy),
+	}
+	dst.Status = v1alpha1.QueueStatus{
+		PendingWorkloads:  src.Status.PendingWorkloads,
+		AdmittedWorkloads: src.Status.AdmittedWorkloads,
+		UsedResources:     usedResourcesToHub(src.Status.FlavorsUsage),
+		Conditions:        copyConditions(src.Status.Conditions),
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *Queue) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Queue)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = QueueSpec{
+		ClusterQueue: ClusterQueueReference(src.Spec.ClusterQueue),
+		StopPolicy:   (*StopPolicy)(src.Spec.StopPolicy),
+	}
+	dst.Status = QueueStatus{
+		PendingWorkloads:  src.Status.PendingWorkloads,
+		AdmittedWorkloads: src.Status.AdmittedWorkloads,
+		FlavorsUsage:      flavorsUsageFromHub(src.Status.UsedResources),
+		Conditions:        copyConditions(src.Status.Conditions),
+	}
+	return nil
+}
+
+// ConvertTo converts this ResourceFlavor to the hub version (v1alpha1).
+func (src *ResourceFlavor) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ResourceFlavor)
+	spec := src.Spec.DeepCopy()
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Labels = spec.NodeLabels
+	dst.Taints = spec.NodeTaints
+	dst.Tolerations = spec.Tolerations
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *ResourceFlavor) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ResourceFlavor).DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = ResourceFlavorSpec{
+		NodeLabels:  src.Labels,
+		NodeTaints:  src.Taints,
+		Tolerations: src.Tolerations,
+	}
+	return nil
+}
+
+// ConvertTo converts this Workload to the hub version (v1alpha1). The
+// admission is part of the spec in v1alpha1.
+func (src *Workload) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Workload)
+	in := src.DeepCopy()
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Spec = v1alpha1.WorkloadSpec{
+		QueueName:         in.Spec.QueueName,
+		PriorityClassName: in.Spec.PriorityClassName,
+		Priority:          in.Spec.Priority,
+		Active:            in.Spec.Active,
+	}
+	if in.Spec.PodSets != nil {
+		dst.Spec.PodSets = make([]v1alpha1.PodSet, len(in.Spec.PodSets))
+		for i, ps := range in.Spec.PodSets {
+			dst.Spec.PodSets[i] = v1alpha1.PodSet(ps)
+		}
+	}
+	if in.Status.Admission != nil {
+		dst.Spec.Admission = &v1alpha1.Admission{
+			ClusterQueue: v1alpha1.ClusterQueueReference(in.Status.Admission.ClusterQueue),
+		}
+		if in.Status.Admission.PodSetFlavors != nil {
+			dst.Spec.Admission.PodSetFlavors = make([]v1alpha1.PodSetFlavors, len(in.Status.Admission.PodSetFlavors))
+			for i, psf := range in.Status.Admission.PodSetFlavors {
+				dst.Spec.Admission.PodSetFlavors[i] = v1alpha1.PodSetFlavors(psf)
+			}
+		}
+	}
+	dst.Status = v1alpha1.WorkloadStatus{}
+	if in.Status.Conditions != nil {
+		dst.Status.Conditions = make([]v1alpha1.WorkloadCondition, len(in.Status.Conditions))
+		for i, c := range in.Status.Conditions {
+			dst.Status.Conditions[i] = v1alpha1.WorkloadCondition{
+				Type:               v1alpha1.WorkloadConditionType(c.Type),
+				Status:             c.Status,
+				LastProbeTime:      c.LastProbeTime,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *Workload) ConvertFrom(srcRaw conversion.Hub) error {
+	in := srcRaw.(*v1alpha1.Workload).DeepCopy()
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Spec = WorkloadSpec{
+		QueueName:         in.Spec.QueueName,
+		PriorityClassName: in.Spec.PriorityClassName,
+		Priority:          in.Spec.Priority,
+		Active:            in.Spec.Active,
+	}
+	if in.Spec.PodSets != nil {
+		dst.Spec.PodSets = make([]PodSet, len(in.Spec.PodSets))
+		for i, ps := range in.Spec.PodSets {
+			dst.Spec.PodSets[i] = PodSet(ps)
+		}
+	}
+	dst.Status = WorkloadStatus{}
+	if in.Spec.Admission != nil {
+		dst.Status.Admission = &Admission{
+			ClusterQueue: ClusterQueueReference(in.Spec.Admission.ClusterQueue),
+		}
+		if in.Spec.Admission.PodSetFlavors != nil {
+			dst.Status.Admission.PodSetFlavors = make([]PodSetFlavors, len(in.Spec.Admission.PodSetFlavors))
+			for i, psf := range in.Spec.Admission.PodSetFlavors {
+				dst.Status.Admission.PodSetFlavors[i] = PodSetFlavors(psf)
+			}
+		}
+	}
+	if in.Status.Conditions != nil {
+		dst.Status.Conditions = make([]WorkloadCondition, len(in.Status.Conditions))
+		for i, c := range in.Status.Conditions {
+			dst.Status.Conditions[i] = WorkloadCondition{
+				Type:               WorkloadConditionType(c.Type),
+				Status:             c.Status,
+				LastProbeTime:      c.LastProbeTime,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	return nil
+}
+
+// usedResourcesToHub converts the usage listed by flavor to the usage by
+// resource and flavor of v1alpha1.
+func usedResourcesToHub(flavorsUsage []FlavorUsage) v1alpha1.UsedResources {
+	if flavorsUsage == nil {
+		return nil
+	}
+	out := make(v1alpha1.UsedResources)
+	for _, fUsage := range flavorsUsage {
+		for _, rUsage := range fUsage.Resources {
+			if out[rUsage.Name] == nil {
+				out[rUsage.Name] = make(map[string]v1alpha1.Usage)
+			}
+			total := rUsage.Total.DeepCopy()
+			usage := v1alpha1.Usage{Total: &total}
+			if !rUsage.Borrowed.IsZero() {
+				borrowed := rUsage.Borrowed.DeepCopy()
+				usage.Borrowed = &borrowed
+			}
+			out[rUsage.Name][string(fUsage.Name)] = usage
+		}
+	}
+	return out
+}
+
+// flavorsUsageFromHub lists the usage of v1alpha1 by flavor, both the
+// flavors and their resources sorted by name.
+func flavorsUsageFromHub(usedResources v1alpha1.UsedResources) []FlavorUsage {
+	if usedResources == nil {
+		return nil
+	}
+	usageByFlavor := make(map[string][]ResourceUsage)
+	for rName, flavors := range usedResources {
+		for fName, usage := range flavors {
+			rUsage := ResourceUsage{Name: rName}
+			if usage.Total != nil {
+				rUsage.Total = usage.Total.DeepCopy()
+			}
+			if usage.Borrowed != nil {
+				rUsage.Borrowed = usage.Borrowed.DeepCopy()
+			}
+			usageByFlavor[fName] = append(usageByFlavor[fName], rUsage)
+		}
+	}
+	out := make([]FlavorUsage, 0, len(usageByFlavor))
+	for fName, resources := range usageByFlavor {
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].Name < resources[j].Name
+		})
+		out = append(out, FlavorUsage{
+			Name:      ResourceFlavorReference(fName),
+			Resources: resources,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(conditions))
+	for i := range conditions {
+		conditions[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
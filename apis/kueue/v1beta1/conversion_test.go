@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	fuzz "github.com/google/gofuzz"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+const fuzzIterations = 200
+
+// kueueFuzzerFuncs generate objects that can be represented in both versions.
+// The used resources can't hold resources without flavors in v1beta1, and
+// v1beta1 lists them by flavor, sorted by name.
+func kueueFuzzerFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+		},
+		func(u *v1alpha1.UsedResources, c fuzz.Continue) {
+			c.FuzzNoCustom(u)
+			for rName, flavors := range *u {
+				if len(flavors) == 0 {
+					delete(*u, rName)
+					continue
+				}
+				for fName, usage := range flavors {
+					if usage.Total == nil {
+						usage.Total = resource.NewQuantity(0, resource.DecimalSI)
+					}
+					if usage.Borrowed != nil && usage.Borrowed.IsZero() {
+						usage.Borrowed = nil
+					}
+					flavors[fName] = usage
+				}
+			}
+		},
+		func(s *ClusterQueueStatus, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			s.FlavorsUsage = normalizeFlavorsUsage(s.FlavorsUsage)
+		},
+		func(s *QueueStatus, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			s.FlavorsUsage = normalizeFlavorsUsage(s.FlavorsUsage)
+		},
+	}
+}
+
+// normalizeFlavorsUsage removes the duplicated and empty entries, and sorts
+// the flavors and resources by name.
+func normalizeFlavorsUsage(in []FlavorUsage) []FlavorUsage {
+	if in == nil {
+		return nil
+	}
+	flavorsUsage := make([]FlavorUsage, 0, len(in))
+	seenFlavors := make(map[ResourceFlavorReference]bool)
+	for _, fUsage := range in {
+		if seenFlavors[fUsage.Name] {
+			continue
+		}
+		seenFlavors[fUsage.Name] = true
+		var resources []ResourceUsage
+		seenResources := make(map[corev1.ResourceName]bool)
+		for _, rUsage := range fUsage.Resources {
+			if !seenResources[rUsage.Name] {
+				seenResources[rUsage.Name] = true
+				resources = append(resources, rUsage)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].Name < resources[j].Name
+		})
+		fUsage.Resources = resources
+		flavorsUsage = append(flavorsUsage, fUsage)
+	}
+	sort.Slice(flavorsUsage, func(i, j int) bool {
+		return flavorsUsage[i].Name < flavorsUsage[j].Name
+	})
+	return flavorsUsage
+}
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding v1beta1 scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding v1alpha1 scheme: %v", err)
+	}
+	funcs := fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, kueueFuzzerFuncs)
+	f := fuzzer.FuzzerFor(funcs, rand.NewSource(rand.Int63()), runtimeserializer.NewCodecFactory(scheme)).
+		NumElements(0, 3)
+
+	cases := map[string]struct {
+		hub   func() conversion.Hub
+		spoke func() conversion.Convertible
+	}{
+		"ClusterQueue": {
+			hub:   func() conversion.Hub { return &v1alpha1.ClusterQueue{} },
+			spoke: func() conversion.Convertible { return &ClusterQueue{} },
+		},
+		"Queue": {
+			hub:   func() conversion.Hub { return &v1alpha1.Queue{} },
+			spoke: func() conversion.Convertible { return &Queue{} },
+		},
+		"ResourceFlavor": {
+			hub:   func() conversion.Hub { return &v1alpha1.ResourceFlavor{} },
+			spoke: func() conversion.Convertible { return &ResourceFlavor{} },
+		},
+		"Workload": {
+			hub:   func() conversion.Hub { return &v1alpha1.Workload{} },
+			spoke: func() conversion.Convertible { return &Workload{} },
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name+" hub-spoke-hub", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				hub := tc.hub()
+				f.Fuzz(hub)
+				spoke := tc.spoke()
+				if err := spoke.ConvertFrom(hub); err != nil {
+					t.Fatalf("Converting from hub: %v", err)
+				}
+				gotHub := tc.hub()
+				if err := spoke.ConvertTo(gotHub); err != nil {
+					t.Fatalf("Converting to hub: %v", err)
+				}
+				if !apiequality.Semantic.DeepEqual(hub, gotHub) {
+					t.Fatalf("Round trip changed the object (-want,+got):\n%s", cmp.Diff(hub, gotHub))
+				}
+			}
+		})
+		t.Run(name+" spoke-hub-spoke", func(t *testing.T) {
+			for i := 0; i < fuzzIterations; i++ {
+				spoke := tc.spoke()
+				f.Fuzz(spoke)
+				hub := tc.hub()
+				if err := spoke.ConvertTo(hub); err != nil {
+					t.Fatalf("Converting to hub: %v", err)
+				}
+				gotSpoke := tc.spoke()
+				if err := gotSpoke.ConvertFrom(hub); err != nil {
+					t.Fatalf("Converting from hub: %v", err)
+				}
+				if !apiequality.Semantic.DeepEqual(spoke, gotSpoke) {
+					t.Fatalf("Round trip changed the object (-want,+got):\n%s", cmp.Diff(spoke, gotSpoke))
+				}
+			}
+		})
+	}
+}
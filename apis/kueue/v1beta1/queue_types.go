@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QueueSpec defines the desired state of Queue
+type QueueSpec struct {
+	// clusterQueue is a reference to a clusterQueue that backs this queue.
+	ClusterQueue ClusterQueueReference `json:"clusterQueue,omitempty"`
+
+	// stopPolicy - if set to a value different from None, the workloads
+	// submitted to this queue are not admitted.
+	//
+	// Depending on its value, its associated workloads will:
+	//
+	// - None: workloads are admitted.
+	// - HoldAndDrain: admitted workloads are evicted and pending workloads are held.
+	// - Hold: admitted workloads run to completion and pending workloads are held.
+	//
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;Hold;HoldAndDrain
+	StopPolicy *StopPolicy `json:"stopPolicy,omitempty"`
+}
+
+// ClusterQueueReference is the name of the ClusterQueue.
+type ClusterQueueReference string
+
+// QueueStatus defines the observed state of Queue
+type QueueStatus struct {
+	// PendingWorkloads is the number of workloads currently admitted to this
+	// queue not yet admitted to a ClusterQueue.
+	// +optional
+	PendingWorkloads int32 `json:"pendingWorkloads"`
+
+	// admittedWorkloads is the number of workloads submitted to this queue
+	// that are admitted by the ClusterQueue and haven't finished yet.
+	// +optional
+	AdmittedWorkloads int32 `json:"admittedWorkloads"`
+
+	// flavorsUsage are the resources used by the admitted workloads
+	// submitted to this queue, by flavor. The borrowed quantity is not
+	// reported.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	FlavorsUsage []FlavorUsage `json:"flavorsUsage,omitempty"`
+
+	// conditions hold the latest available observations of the Queue
+	// current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+const (
+	// QueueActive indicates that the workloads submitted to the Queue can be
+	// admitted. It's false when the Queue is stopped.
+	QueueActive = "Active"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ClusterQueue",JSONPath=".spec.clusterQueue",type=string,description="Backing ClusterQueue"
+//+kubebuilder:printcolumn:name="Pending Workloads",JSONPath=".status.pendingWorkloads",type=integer,description="Number of pending workloads"
+//+kubebuilder:printcolumn:name="Admitted Workloads",JSONPath=".status.admittedWorkloads",type=integer,description="Number of admitted workloads that haven't finished yet"
+
+// Queue is the Schema for the queues API
+type Queue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QueueSpec   `json:"spec,omitempty"`
+	Status QueueStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// QueueList contains a list of Queue
+type QueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Queue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Queue{}, &QueueList{})
+}
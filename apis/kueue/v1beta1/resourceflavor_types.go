@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceFlavorSpec defines the desired state of the ResourceFlavor
+type ResourceFlavorSpec struct {
+	// nodeLabels are labels that associate the ResourceFlavor with Nodes that
+	// have the same labels. They are matched against or converted to node
+	// affinity constraints on the workload’s pods.
+	// For example, cloud.provider.com/accelerator: nvidia-tesla-k80.
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// nodeTaints are taints that the nodes associated with this ResourceFlavor
+	// have. Workloads must explicitly “tolerate” them to be able to use this
+	// flavor.
+	// For example, cloud.provider.com/preemptible="true":NoSchedule
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+
+	// tolerations are added to the pods of the workloads admitted with this
+	// flavor, so that they can land on the nodes that carry the taints of
+	// this flavor.
+	// For example, cloud.provider.com/preemptible="true":NoSchedule
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// ResourceFlavor is the Schema for the resourceflavors API
+type ResourceFlavor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ResourceFlavorSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceFlavorList contains a list of ResourceFlavor
+type ResourceFlavorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceFlavor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceFlavor{}, &ResourceFlavorList{})
+}
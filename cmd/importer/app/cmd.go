@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kueue.AddToScheme(scheme))
+}
+
+// NewImporterCmd creates the importer command. It connects to the cluster
+// with the standard kubeconfig flags.
+func NewImporterCmd() *cobra.Command {
+	configFlags := genericclioptions.NewConfigFlags(false)
+	var (
+		namespaces []string
+		selector   string
+		queueName  string
+		flavors    string
+		dryRun     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "importer",
+		Short: "Create admitted Workloads for the running Jobs and Pods",
+		Long: `importer creates admitted Workloads for the Jobs and Pods that are already
+running in the given namespaces, so that Kueue accounts for the quota they use.
+
+Jobs are imported into the queue of their queue annotation or, if they don't
+have one, into --queue, which is then set as their queue annotation. Pods that
+don't have a controller are imported into --queue. Suspended and finished
+objects are ignored, as well as objects that already have a Workload.
+
+Kueue doesn't track the Pods that don't have a controller, so the Workloads of
+the imported Pods are not marked as finished when the Pods finish. Running the
+importer again marks the Workloads of the finished Pods as finished.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(namespaces) == 0 {
+				return errors.New("at least one --namespace is required")
+			}
+			opts := Options{
+				Namespaces: namespaces,
+				QueueName:  queueName,
+				DryRun:     dryRun,
+			}
+			var err error
+			if opts.Selector, err = labels.Parse(selector); err != nil {
+				return fmt.Errorf("parsing selector: %w", err)
+			}
+			if opts.Flavors, err = parseFlavors(flavors); err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			c, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return err
+			}
+			res, err := New(c, scheme, opts, cmd.OutOrStdout()).Import(cmd.Context())
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d, skipped %d, finished %d\n", res.Imported, res.Skipped, res.Finished)
+			return err
+		},
+	}
+	cmd.SetOut(os.Stdout)
+	configFlags.AddFlags(cmd.Flags())
+	cmd.Flags().StringSliceVar(&namespaces, "namespaces", nil, "The namespaces to import Jobs and Pods from.")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Only import the Jobs and Pods matching this label selector.")
+	cmd.Flags().StringVar(&queueName, "queue", "", "The Queue for the Jobs without queue annotation and for the Pods.")
+	cmd.Flags().StringVar(&flavors, "flavors", "",
+		`The flavor to assign for each resource, in the form "cpu=on-demand,memory=on-demand". `+
+			"Resources not listed get the first flavor of the ClusterQueue.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print what would be imported.")
+	return cmd
+}
+
+func parseFlavors(s string) (map[corev1.ResourceName]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	flavors := make(map[corev1.ResourceName]string)
+	for _, pair := range strings.Split(s, ",") {
+		rName, flavor, ok := strings.Cut(pair, "=")
+		if !ok || rName == "" || flavor == "" {
+			return nil, fmt.Errorf("%q is not in the form resource=flavor", pair)
+		}
+		flavors[corev1.ResourceName(rName)] = flavor
+	}
+	return flavors, nil
+}
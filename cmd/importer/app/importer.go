@@ -0,0 +1,401 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/controller/workload/job"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// Options configures which Jobs and Pods are imported and how.
+type Options struct {
+	// Namespaces to scan.
+	Namespaces []string
+	// Selector restricts the Jobs and Pods that are imported.
+	Selector labels.Selector
+	// QueueName is the Queue used for the Jobs that don't have the queue
+	// annotation and for the Pods.
+	QueueName string
+	// Flavors maps resources to the flavor that the imported workloads are
+	// assigned. When a resource is not in the map, the first flavor of the
+	// ClusterQueue for the resource is used.
+	Flavors map[corev1.ResourceName]string
+	// DryRun only reports what would be imported.
+	DryRun bool
+}
+
+// Result counts the imported and skipped objects, and the Workloads of
+// finished Pods that were marked as finished.
+type Result struct {
+	Imported int
+	Skipped  int
+	Finished int
+}
+
+// Importer creates admitted Workloads for the Jobs and Pods that were
+// already running before Kueue managed them, so that the quota they use is
+// accounted for.
+type Importer struct {
+	client client.Client
+	scheme *runtime.Scheme
+	opts   Options
+	out    io.Writer
+
+	queues        map[types.NamespacedName]*kueue.Queue
+	clusterQueues map[string]*kueue.ClusterQueue
+}
+
+// New creates an Importer.
+func New(c client.Client, scheme *runtime.Scheme, opts Options, out io.Writer) *Importer {
+	if opts.Selector == nil {
+		opts.Selector = labels.Everything()
+	}
+	return &Importer{
+		client:        c,
+		scheme:        scheme,
+		opts:          opts,
+		out:           out,
+		queues:        make(map[types.NamespacedName]*kueue.Queue),
+		clusterQueues: make(map[string]*kueue.ClusterQueue),
+	}
+}
+
+// Import imports the running Jobs and Pods of all the namespaces. Objects
+// that can't be imported are reported and skipped. An error is returned if
+// the objects can't be listed or a Workload can't be created.
+func (i *Importer) Import(ctx context.Context) (Result, error) {
+	var res Result
+	for _, ns := range i.opts.Namespaces {
+		owners, err := i.workloadOwners(ctx, ns)
+		if err != nil {
+			return res, err
+		}
+		if err := i.importJobs(ctx, ns, owners, &res); err != nil {
+			return res, err
+		}
+		if err := i.importPods(ctx, ns, owners, &res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// workloadOwners returns the Workloads of the namespace by the UID of the
+// object that owns them.
+func (i *Importer) workloadOwners(ctx context.Context, ns string) (map[types.UID]*kueue.Workload, error) {
+	var workloads kueue.WorkloadList
+	if err := i.client.List(ctx, &workloads, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("listing workloads in namespace %s: %w", ns, err)
+	}
+	owners := make(map[types.UID]*kueue.Workload, len(workloads.Items))
+	for idx := range workloads.Items {
+		wl := &workloads.Items[idx]
+		if owner := metav1.GetControllerOf(wl); owner != nil {
+			owners[owner.UID] = wl
+		}
+	}
+	return owners, nil
+}
+
+func (i *Importer) importJobs(ctx context.Context, ns string, owners map[types.UID]*kueue.Workload, res *Result) error {
+	var jobs batchv1.JobList
+	if err := i.client.List(ctx, &jobs, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: i.opts.Selector}); err != nil {
+		return fmt.Errorf("listing jobs in namespace %s: %w", ns, err)
+	}
+	for idx := range jobs.Items {
+		j := &jobs.Items[idx]
+		if _, ok := owners[j.UID]; ok || jobInactive(j) {
+			continue
+		}
+		queueName := j.Annotations[constants.QueueAnnotation]
+		if queueName == "" {
+			queueName = i.opts.QueueName
+		}
+		wl, err := job.ConstructWorkloadFor(ctx, i.client, j, i.scheme)
+		if err != nil {
+			return fmt.Errorf("constructing workload for job %s/%s: %w", ns, j.Name, err)
+		}
+		wl.Spec.QueueName = queueName
+		imported, err := i.importWorkload(ctx, "Job", j, wl)
+		if err != nil {
+			return err
+		}
+		if !imported {
+			res.Skipped++
+			continue
+		}
+		res.Imported++
+		if i.opts.DryRun || j.Annotations[constants.QueueAnnotation] == queueName {
+			continue
+		}
+		// Set the queue so that the job controller manages the job, which
+		// marks the workload as finished when the job finishes.
+		patch := client.MergeFrom(j.DeepCopy())
+		if j.Annotations == nil {
+			j.Annotations = make(map[string]string, 1)
+		}
+		j.Annotations[constants.QueueAnnotation] = queueName
+		if err := i.client.Patch(ctx, j, patch); err != nil {
+			return fmt.Errorf("setting the queue of job %s/%s: %w", ns, j.Name, err)
+		}
+	}
+	return nil
+}
+
+func (i *Importer) importPods(ctx context.Context, ns string, owners map[types.UID]*kueue.Workload, res *Result) error {
+	var pods corev1.PodList
+	if err := i.client.List(ctx, &pods, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: i.opts.Selector}); err != nil {
+		return fmt.Errorf("listing pods in namespace %s: %w", ns, err)
+	}
+	for idx := range pods.Items {
+		p := &pods.Items[idx]
+		// Pods with a controller are imported through their owner, if it's
+		// a Job, or left out otherwise.
+		if metav1.GetControllerOf(p) != nil {
+			continue
+		}
+		if wl, ok := owners[p.UID]; ok {
+			// No controller marks the Workloads of the Pods as finished, so
+			// it's done when the importer runs again.
+			finished, err := i.finishPodWorkload(ctx, p, wl)
+			if err != nil {
+				return err
+			}
+			if finished {
+				res.Finished++
+			}
+			continue
+		}
+		if podInactive(p) {
+			continue
+		}
+		wl, err := i.constructWorkloadForPod(ctx, p)
+		if err != nil {
+			return fmt.Errorf("constructing workload for pod %s/%s: %w", ns, p.Name, err)
+		}
+		imported, err := i.importWorkload(ctx, "Pod", p, wl)
+		if err != nil {
+			return err
+		}
+		if imported {
+			res.Imported++
+		} else {
+			res.Skipped++
+		}
+	}
+	return nil
+}
+
+// importWorkload admits the workload in the ClusterQueue of its queue and
+// creates it. It returns false if the workload can't be admitted.
+func (i *Importer) importWorkload(ctx context.Context, kind string, owner client.Object, wl *kueue.Workload) (bool, error) {
+	ownerKey := fmt.Sprintf("%s %s/%s", kind, owner.GetNamespace(), owner.GetName())
+	if wl.Spec.QueueName == "" {
+		fmt.Fprintf(i.out, "Skipping %s: no queue annotation and no default queue\n", ownerKey)
+		return false, nil
+	}
+	cq, err := i.clusterQueueFor(ctx, wl.Namespace, wl.Spec.QueueName)
+	if err != nil {
+		fmt.Fprintf(i.out, "Skipping %s: %v\n", ownerKey, err)
+		return false, nil
+	}
+	admission, err := i.admission(cq, wl)
+	if err != nil {
+		fmt.Fprintf(i.out, "Skipping %s: %v\n", ownerKey, err)
+		return false, nil
+	}
+	wl.Spec.Admission = admission
+	if !i.opts.DryRun {
+		if err := i.client.Create(ctx, wl); err != nil {
+			return false, fmt.Errorf("creating workload for %s: %w", ownerKey, err)
+		}
+	}
+	fmt.Fprintf(i.out, "Imported %s into ClusterQueue %s\n", ownerKey, cq.Name)
+	return true, nil
+}
+
+// finishPodWorkload marks the workload of the pod as finished if the pod
+// finished, so that the workload releases its quota. It returns false if the
+// pod or the workload didn't finish.
+func (i *Importer) finishPodWorkload(ctx context.Context, p *corev1.Pod, wl *kueue.Workload) (bool, error) {
+	if !podFinished(p) || workload.InCondition(wl, kueue.WorkloadFinished) {
+		return false, nil
+	}
+	if !i.opts.DryRun {
+		if err := workload.UpdateStatus(ctx, i.client, wl, kueue.WorkloadFinished, corev1.ConditionTrue,
+			"PodFinished", fmt.Sprintf("Pod finished in phase %s", p.Status.Phase)); err != nil {
+			return false, fmt.Errorf("finishing workload %s: %w", workload.Key(wl), err)
+		}
+	}
+	fmt.Fprintf(i.out, "Finished the workload of Pod %s/%s\n", p.Namespace, p.Name)
+	return true, nil
+}
+
+func (i *Importer) clusterQueueFor(ctx context.Context, ns, queueName string) (*kueue.ClusterQueue, error) {
+	key := types.NamespacedName{Namespace: ns, Name: queueName}
+	q, ok := i.queues[key]
+	if !ok {
+		q = &kueue.Queue{}
+		if err := i.client.Get(ctx, key, q); err != nil {
+			return nil, fmt.Errorf("getting queue %s: %w", key, err)
+		}
+		i.queues[key] = q
+	}
+	cqName := string(q.Spec.ClusterQueue)
+	cq, ok := i.clusterQueues[cqName]
+	if !ok {
+		cq = &kueue.ClusterQueue{}
+		if err := i.client.Get(ctx, types.NamespacedName{Name: cqName}, cq); err != nil {
+			return nil, fmt.Errorf("getting clusterQueue %s: %w", cqName, err)
+		}
+		i.clusterQueues[cqName] = cq
+	}
+	return cq, nil
+}
+
+// admission assigns a flavor to each resource requested by the podSets.
+func (i *Importer) admission(cq *kueue.ClusterQueue, wl *kueue.Workload) (*kueue.Admission, error) {
+	admission := &kueue.Admission{
+		ClusterQueue:  kueue.ClusterQueueReference(cq.Name),
+		PodSetFlavors: make([]kueue.PodSetFlavors, len(wl.Spec.PodSets)),
+	}
+	for psIdx, ps := range wl.Spec.PodSets {
+		flavors := make(map[corev1.ResourceName]string)
+		for _, rName := range requestedResources(&ps.Spec) {
+			flavor, err := i.flavorFor(cq, rName)
+			if err != nil {
+				return nil, err
+			}
+			flavors[rName] = flavor
+		}
+		admission.PodSetFlavors[psIdx] = kueue.PodSetFlavors{
+			Name:    ps.Name,
+			Flavors: flavors,
+		}
+	}
+	return admission, nil
+}
+
+func (i *Importer) flavorFor(cq *kueue.ClusterQueue, rName corev1.ResourceName) (string, error) {
+	for _, res := range cq.Spec.Resources {
+		if res.Name != rName {
+			continue
+		}
+		want, ok := i.opts.Flavors[rName]
+		if !ok && len(res.Flavors) > 0 {
+			return string(res.Flavors[0].Name), nil
+		}
+		for _, flv := range res.Flavors {
+			if string(flv.Name) == want {
+				return want, nil
+			}
+		}
+		return "", fmt.Errorf("flavor %q not defined for resource %s in clusterQueue %s", want, rName, cq.Name)
+	}
+	return "", fmt.Errorf("resource %s not defined in clusterQueue %s", rName, cq.Name)
+}
+
+func (i *Importer) constructWorkloadForPod(ctx context.Context, p *corev1.Pod) (*kueue.Workload, error) {
+	wl := &kueue.Workload{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-" + p.Name,
+			Namespace: p.Namespace,
+		},
+		Spec: kueue.WorkloadSpec{
+			PodSets: []kueue.PodSet{
+				{
+					Spec:  *p.Spec.DeepCopy(),
+					Count: 1,
+				},
+			},
+			QueueName: i.opts.QueueName,
+		},
+	}
+	priorityClassName, priority, err := utilpriority.GetPriorityFromPriorityClass(
+		ctx, i.client, p.Spec.PriorityClassName)
+	if err != nil {
+		return nil, err
+	}
+	wl.Spec.Priority = &priority
+	wl.Spec.PriorityClassName = priorityClassName
+	if err := ctrl.SetControllerReference(p, wl, i.scheme); err != nil {
+		return nil, err
+	}
+	return wl, nil
+}
+
+// requestedResources returns the names of the resources requested by the
+// containers of the pod, in a stable order.
+func requestedResources(spec *corev1.PodSpec) []corev1.ResourceName {
+	names := sets.NewString()
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			for rName := range c.Resources.Requests {
+				names.Insert(string(rName))
+			}
+		}
+	}
+	for rName := range spec.Overhead {
+		names.Insert(string(rName))
+	}
+	resources := make([]corev1.ResourceName, 0, names.Len())
+	for _, n := range names.List() {
+		resources = append(resources, corev1.ResourceName(n))
+	}
+	return resources
+}
+
+// jobInactive returns whether the job is suspended or finished, and so
+// doesn't use quota.
+func jobInactive(j *batchv1.Job) bool {
+	if j.Spec.Suspend != nil && *j.Spec.Suspend {
+		return true
+	}
+	for _, c := range j.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// podInactive returns whether the pod finished or wasn't scheduled yet.
+func podInactive(p *corev1.Pod) bool {
+	return p.Spec.NodeName == "" || podFinished(p)
+}
+
+// podFinished returns whether the pod succeeded or failed.
+func podFinished(p *corev1.Pod) bool {
+	return p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestImport(t *testing.T) {
+	runningPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+			Spec: corev1.PodSpec{
+				NodeName: "node",
+				Containers: []corev1.Container{{
+					Name: "c",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	baseObjects := func() []client.Object {
+		return []client.Object{
+			utiltesting.MakeClusterQueue("cq").
+				Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+					Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).
+					Flavor(utiltesting.MakeFlavor("spot", "10").Obj()).
+					Obj()).
+				Obj(),
+			utiltesting.MakeQueue("main", "default").ClusterQueue("cq").Obj(),
+			utiltesting.MakeQueue("other", "default").ClusterQueue("missing").Obj(),
+		}
+	}
+	cases := map[string]struct {
+		objs          []client.Object
+		opts          Options
+		wantResult    Result
+		wantWorkloads map[string]*kueue.Admission
+		wantQueues    map[string]string
+		wantFinished  []string
+	}{
+		"running job": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			opts:       Options{QueueName: "main"},
+			wantResult: Result{Imported: 1},
+			wantWorkloads: map[string]*kueue.Admission{
+				"job": utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+			wantQueues: map[string]string{"job": "main"},
+		},
+		"job with queue annotation and flavor mapping": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Queue("main").Request(corev1.ResourceCPU, "1").Obj()),
+			opts: Options{
+				QueueName: "other",
+				Flavors:   map[corev1.ResourceName]string{corev1.ResourceCPU: "spot"},
+			},
+			wantResult: Result{Imported: 1},
+			wantWorkloads: map[string]*kueue.Admission{
+				"job": utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "spot").Obj(),
+			},
+			wantQueues: map[string]string{"job": "main"},
+		},
+		"suspended job": {
+			objs:       append(baseObjects(), utiltesting.MakeJob("job", "default").Request(corev1.ResourceCPU, "1").Obj()),
+			opts:       Options{QueueName: "main"},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"job without queue": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			wantResult: Result{Skipped: 1},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"queue with missing clusterQueue": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			opts:       Options{QueueName: "other"},
+			wantResult: Result{Skipped: 1},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"resource not in clusterQueue": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceMemory, "1Gi").Obj()),
+			opts:       Options{QueueName: "main"},
+			wantResult: Result{Skipped: 1},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"unknown flavor": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			opts: Options{
+				QueueName: "main",
+				Flavors:   map[corev1.ResourceName]string{corev1.ResourceCPU: "gpu"},
+			},
+			wantResult: Result{Skipped: 1},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"dry run": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			opts:       Options{QueueName: "main", DryRun: true},
+			wantResult: Result{Imported: 1},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"job not matching the selector": {
+			objs: append(baseObjects(),
+				utiltesting.MakeJob("job", "default").Suspend(false).Request(corev1.ResourceCPU, "1").Obj()),
+			opts: Options{
+				QueueName: "main",
+				Selector:  labels.SelectorFromSet(labels.Set{"import": "true"}),
+			},
+			wantQueues: map[string]string{"job": ""},
+		},
+		"pods": {
+			objs: func() []client.Object {
+				finished := runningPod("finished")
+				finished.Status.Phase = corev1.PodSucceeded
+				unscheduled := runningPod("unscheduled")
+				unscheduled.Spec.NodeName = ""
+				owned := runningPod("owned")
+				owned.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       "rs",
+					UID:        "rs",
+					Controller: pointer.Bool(true),
+				}}
+				return append(baseObjects(), runningPod("running"), finished, unscheduled, owned)
+			}(),
+			opts:       Options{QueueName: "main"},
+			wantResult: Result{Imported: 1},
+			wantWorkloads: map[string]*kueue.Admission{
+				"pod-running": utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+		},
+		"pods with workloads": {
+			objs: func() []client.Object {
+				finished := runningPod("finished")
+				finished.Status.Phase = corev1.PodFailed
+				podWorkload := func(p *corev1.Pod) *kueue.Workload {
+					wl := utiltesting.MakeWorkload("pod-"+p.Name, "default").
+						Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+						Obj()
+					wl.OwnerReferences = []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						Name:       p.Name,
+						UID:        p.UID,
+						Controller: pointer.Bool(true),
+					}}
+					return wl
+				}
+				running := runningPod("running")
+				return append(baseObjects(), running, podWorkload(running), finished, podWorkload(finished))
+			}(),
+			opts:       Options{QueueName: "main"},
+			wantResult: Result{Finished: 1},
+			wantWorkloads: map[string]*kueue.Admission{
+				"pod-running":  utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+				"pod-finished": utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+			wantFinished: []string{"pod-finished"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objs...).Build()
+			tc.opts.Namespaces = []string{"default"}
+			var out bytes.Buffer
+			res, err := New(cl, scheme, tc.opts, &out).Import(ctx)
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantResult, res); diff != "" {
+				t.Errorf("Unexpected result (-want,+got):\n%s\nOutput:\n%s", diff, out.String())
+			}
+
+			var workloads kueue.WorkloadList
+			if err := cl.List(ctx, &workloads); err != nil {
+				t.Fatalf("Failed listing workloads: %v", err)
+			}
+			gotWorkloads := make(map[string]*kueue.Admission)
+			var gotFinished []string
+			for _, wl := range workloads.Items {
+				gotWorkloads[wl.Name] = wl.Spec.Admission
+				if workload.InCondition(&wl, kueue.WorkloadFinished) {
+					gotFinished = append(gotFinished, wl.Name)
+				}
+			}
+			if tc.wantWorkloads == nil {
+				tc.wantWorkloads = map[string]*kueue.Admission{}
+			}
+			// The podSet names are defaulted by the API server.
+			if diff := cmp.Diff(tc.wantWorkloads, gotWorkloads, cmpopts.IgnoreFields(kueue.PodSetFlavors{}, "Name")); diff != "" {
+				t.Errorf("Unexpected workloads (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantFinished, gotFinished, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Unexpected finished workloads (-want,+got):\n%s", diff)
+			}
+
+			for jobName, wantQueue := range tc.wantQueues {
+				var job batchv1.Job
+				if err := cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: jobName}, &job); err != nil {
+					t.Fatalf("Failed getting job: %v", err)
+				}
+				if got := job.Annotations[constants.QueueAnnotation]; got != wantQueue {
+					t.Errorf("Job has queue %q, want %q", got, wantQueue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+type createClusterQueueOptions struct {
+	*KueuectlOptions
+	cohort            string
+	queueingStrategy  string
+	namespaceSelector string
+	nominalQuota      string
+	borrowingLimit    string
+	dryRun            bool
+}
+
+type createQueueOptions struct {
+	*KueuectlOptions
+	clusterQueue    string
+	ignoreUnknownCQ bool
+	dryRun          bool
+}
+
+func newCreateCmd(o *KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create Kueue resources from a few flags",
+	}
+	cmd.AddCommand(newCreateClusterQueueCmd(o))
+	cmd.AddCommand(newCreateQueueCmd(o))
+	return cmd
+}
+
+func newCreateClusterQueueCmd(o *KueuectlOptions) *cobra.Command {
+	co := &createClusterQueueOptions{KueuectlOptions: o}
+	cmd := &cobra.Command{
+		Use:     "clusterqueue NAME",
+		Aliases: []string{"cq"},
+		Short:   "Create a ClusterQueue",
+		Example: `  # Create a ClusterQueue with 10 cpus of the on-demand flavor, that can
+  # borrow 5 more cpus from the other ClusterQueues in the cohort.
+  kueuectl create cq team-a --cohort research \
+    --nominal-quota "on-demand:cpu=10,memory=32Gi" \
+    --borrowing-limit "on-demand:cpu=5"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cq, err := co.clusterQueue(args[0])
+			if err != nil {
+				return err
+			}
+			if co.dryRun {
+				return (&printers.YAMLPrinter{}).PrintObj(cq, o.Out)
+			}
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			if err := c.Create(cmd.Context(), cq); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "clusterqueue/%s created\n", cq.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&co.cohort, "cohort", "", "The cohort that the ClusterQueue belongs to.")
+	cmd.Flags().StringVar(&co.queueingStrategy, "queueing-strategy", string(kueue.BestEffortFIFO),
+		"The queueing strategy of the workloads: StrictFIFO or BestEffortFIFO.")
+	cmd.Flags().StringVar(&co.namespaceSelector, "namespace-selector", "",
+		"A label selector for the namespaces that can submit workloads. Defaults to all the namespaces.")
+	cmd.Flags().StringVar(&co.nominalQuota, "nominal-quota", "",
+		`The quota of each flavor, in the form "flavor1:cpu=10,memory=32Gi;flavor2:cpu=5".`)
+	cmd.Flags().StringVar(&co.borrowingLimit, "borrowing-limit", "",
+		`How much quota can be borrowed from the cohort on top of the nominal quota, in the same form as --nominal-quota. Unlimited if not set.`)
+	cmd.Flags().BoolVar(&co.dryRun, "dry-run", false, "Only print the object that would be created.")
+	return cmd
+}
+
+func (o *createClusterQueueOptions) clusterQueue(name string) (*kueue.ClusterQueue, error) {
+	strategy := kueue.QueueingStrategy(o.queueingStrategy)
+	if strategy != kueue.StrictFIFO && strategy != kueue.BestEffortFIFO {
+		return nil, fmt.Errorf("invalid queueing strategy %q", o.queueingStrategy)
+	}
+	nsSelector := &metav1.LabelSelector{}
+	if o.namespaceSelector != "" {
+		var err error
+		if nsSelector, err = metav1.ParseToLabelSelector(o.namespaceSelector); err != nil {
+			return nil, fmt.Errorf("parsing namespace selector: %w", err)
+		}
+	}
+	nominal, err := parseFlavorQuotas(o.nominalQuota)
+	if err != nil {
+		return nil, fmt.Errorf("parsing nominal quota: %w", err)
+	}
+	borrowing, err := parseFlavorQuotas(o.borrowingLimit)
+	if err != nil {
+		return nil, fmt.Errorf("parsing borrowing limit: %w", err)
+	}
+	resources, err := buildResources(nominal, borrowing)
+	if err != nil {
+		return nil, err
+	}
+	return &kueue.ClusterQueue{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueue.GroupVersion.String(),
+			Kind:       "ClusterQueue",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: kueue.ClusterQueueSpec{
+			Cohort:            o.cohort,
+			QueueingStrategy:  strategy,
+			NamespaceSelector: nsSelector,
+			Resources:         resources,
+		},
+	}, nil
+}
+
+func newCreateQueueCmd(o *KueuectlOptions) *cobra.Command {
+	co := &createQueueOptions{KueuectlOptions: o}
+	cmd := &cobra.Command{
+		Use:     "localqueue NAME",
+		Aliases: []string{"lq", "queue"},
+		Short:   "Create a Queue in the current namespace",
+		Example: `  # Create a queue for the team-a ClusterQueue.
+  kueuectl create lq main --clusterqueue team-a`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ns, err := o.ClientGetter.Namespace()
+			if err != nil {
+				return err
+			}
+			q := &kueue.Queue{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: kueue.GroupVersion.String(),
+					Kind:       "Queue",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: args[0], Namespace: ns},
+				Spec:       kueue.QueueSpec{ClusterQueue: kueue.ClusterQueueReference(co.clusterQueue)},
+			}
+			if co.dryRun {
+				return (&printers.YAMLPrinter{}).PrintObj(q, o.Out)
+			}
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			if !co.ignoreUnknownCQ {
+				var cq kueue.ClusterQueue
+				if err := c.Get(cmd.Context(), types.NamespacedName{Name: co.clusterQueue}, &cq); err != nil {
+					if apierrors.IsNotFound(err) {
+						return fmt.Errorf("ClusterQueue %q doesn't exist, use --ignore-unknown-cq to create the queue anyway", co.clusterQueue)
+					}
+					return err
+				}
+			}
+			if err := c.Create(cmd.Context(), q); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "localqueue/%s created\n", q.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&co.clusterQueue, "clusterqueue", "c", "", "The ClusterQueue that backs the queue.")
+	cmd.Flags().BoolVar(&co.ignoreUnknownCQ, "ignore-unknown-cq", false, "Create the queue even if the ClusterQueue doesn't exist yet.")
+	cmd.Flags().BoolVar(&co.dryRun, "dry-run", false, "Only print the object that would be created.")
+	_ = cmd.MarkFlagRequired("clusterqueue")
+	return cmd
+}
+
+// flavorQuota holds the quantities of the resources of a flavor, in the
+// order they were given.
+type flavorQuota struct {
+	flavor     string
+	resources  []corev1.ResourceName
+	quantities map[corev1.ResourceName]resource.Quantity
+}
+
+// parseFlavorQuotas parses quotas in the form
+// "flavor1:cpu=10,memory=32Gi;flavor2:cpu=5".
+func parseFlavorQuotas(s string) ([]flavorQuota, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var quotas []flavorQuota
+	seen := make(map[string]bool)
+	for _, fs := range strings.Split(s, ";") {
+		flavor, list, ok := strings.Cut(strings.TrimSpace(fs), ":")
+		if !ok || flavor == "" || list == "" {
+			return nil, fmt.Errorf("%q is not in the form flavor:resource=quantity,...", fs)
+		}
+		if seen[flavor] {
+			return nil, fmt.Errorf("flavor %q is repeated", flavor)
+		}
+		seen[flavor] = true
+		fq := flavorQuota{
+			flavor:     flavor,
+			quantities: make(map[corev1.ResourceName]resource.Quantity),
+		}
+		for _, rs := range strings.Split(list, ",") {
+			name, value, ok := strings.Cut(rs, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%q is not in the form resource=quantity", rs)
+			}
+			rName := corev1.ResourceName(name)
+			if _, ok := fq.quantities[rName]; ok {
+				return nil, fmt.Errorf("resource %q is repeated for flavor %q", name, flavor)
+			}
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quantity for resource %q: %w", name, err)
+			}
+			fq.resources = append(fq.resources, rName)
+			fq.quantities[rName] = q
+		}
+		quotas = append(quotas, fq)
+	}
+	return quotas, nil
+}
+
+// buildResources converts the per-flavor quotas into the per-resource list of
+// the ClusterQueue. The max quota is the nominal quota plus the borrowing
+// limit, if there is one.
+func buildResources(nominal, borrowing []flavorQuota) ([]kueue.Resource, error) {
+	var resources []kueue.Resource
+	index := make(map[corev1.ResourceName]int)
+	for _, fq := range nominal {
+		for _, rName := range fq.resources {
+			i, ok := index[rName]
+			if !ok {
+				i = len(resources)
+				index[rName] = i
+				resources = append(resources, kueue.Resource{Name: rName})
+			}
+			resources[i].Flavors = append(resources[i].Flavors, kueue.Flavor{
+				Name:  kueue.ResourceFlavorReference(fq.flavor),
+				Quota: kueue.Quota{Min: fq.quantities[rName]},
+			})
+		}
+	}
+	for _, fq := range borrowing {
+		for _, rName := range fq.resources {
+			flv := findFlavor(resources, index, rName, fq.flavor)
+			if flv == nil {
+				return nil, fmt.Errorf("borrowing limit for resource %q in flavor %q has no nominal quota", rName, fq.flavor)
+			}
+			max := flv.Quota.Min.DeepCopy()
+			max.Add(fq.quantities[rName])
+			flv.Quota.Max = &max
+		}
+	}
+	return resources, nil
+}
+
+func findFlavor(resources []kueue.Resource, index map[corev1.ResourceName]int, rName corev1.ResourceName, flavor string) *kueue.Flavor {
+	i, ok := index[rName]
+	if !ok {
+		return nil
+	}
+	for j := range resources[i].Flavors {
+		if string(resources[i].Flavors[j].Name) == flavor {
+			return &resources[i].Flavors[j]
+		}
+	}
+	return nil
+}
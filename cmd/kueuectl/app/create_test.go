@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestCreateClusterQueue(t *testing.T) {
+	maxCPU := resource.MustParse("15")
+	cases := map[string]struct {
+		args    []string
+		wantOut string
+		wantErr bool
+		wantCQ  *kueue.ClusterQueueSpec
+	}{
+		"defaults": {
+			args:    []string{"create", "cq", "new"},
+			wantOut: "clusterqueue/new created\n",
+			wantCQ: &kueue.ClusterQueueSpec{
+				QueueingStrategy:  kueue.BestEffortFIFO,
+				NamespaceSelector: &metav1.LabelSelector{},
+			},
+		},
+		"quotas": {
+			args: []string{"create", "clusterqueue", "new",
+				"--cohort", "research",
+				"--queueing-strategy", "StrictFIFO",
+				"--namespace-selector", "team=a",
+				"--nominal-quota", "on-demand:cpu=10,memory=32Gi;spot:cpu=20",
+				"--borrowing-limit", "on-demand:cpu=5",
+			},
+			wantOut: "clusterqueue/new created\n",
+			wantCQ: &kueue.ClusterQueueSpec{
+				Cohort:           "research",
+				QueueingStrategy: kueue.StrictFIFO,
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+				Resources: []kueue.Resource{
+					{
+						Name: corev1.ResourceCPU,
+						Flavors: []kueue.Flavor{
+							{
+								Name:  "on-demand",
+								Quota: kueue.Quota{Min: resource.MustParse("10"), Max: &maxCPU},
+							},
+							{
+								Name:  "spot",
+								Quota: kueue.Quota{Min: resource.MustParse("20")},
+							},
+						},
+					},
+					{
+						Name: corev1.ResourceMemory,
+						Flavors: []kueue.Flavor{
+							{
+								Name:  "on-demand",
+								Quota: kueue.Quota{Min: resource.MustParse("32Gi")},
+							},
+						},
+					},
+				},
+			},
+		},
+		"borrowing without nominal quota": {
+			args: []string{"create", "cq", "new",
+				"--nominal-quota", "on-demand:cpu=10",
+				"--borrowing-limit", "spot:cpu=5",
+			},
+			wantErr: true,
+		},
+		"invalid quantity": {
+			args:    []string{"create", "cq", "new", "--nominal-quota", "on-demand:cpu=ten"},
+			wantErr: true,
+		},
+		"missing flavor": {
+			args:    []string{"create", "cq", "new", "--nominal-quota", "cpu=10"},
+			wantErr: true,
+		},
+		"repeated flavor": {
+			args:    []string{"create", "cq", "new", "--nominal-quota", "spot:cpu=10;spot:memory=1Gi"},
+			wantErr: true,
+		},
+		"invalid queueing strategy": {
+			args:    []string{"create", "cq", "new", "--queueing-strategy", "LIFO"},
+			wantErr: true,
+		},
+		"dry run": {
+			args: []string{"create", "cq", "new", "--nominal-quota", "default:cpu=1", "--dry-run"},
+			wantOut: `apiVersion: kueue.x-k8s.io/v1alpha1
+kind: ClusterQueue
+metadata:
+  creationTimestamp: null
+  name: new
+spec:
+  namespaceSelector: {}
+  queueingStrategy: BestEffortFIFO
+  resources:
+  - flavors:
+    - name: default
+      quota:
+        min: "1"
+    name: cpu
+status:
+  admittedWorkloads: 0
+  pendingWorkloads: 0
+  usedResources: null
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+			out, err := runCmdWithClient(t, cl, tc.args...)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantOut, out); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+			var cq kueue.ClusterQueue
+			err = cl.Get(context.Background(), types.NamespacedName{Name: "new"}, &cq)
+			if tc.wantCQ == nil {
+				if err == nil {
+					t.Errorf("ClusterQueue was created: %v", cq.Spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed getting ClusterQueue: %v", err)
+			}
+			if diff := cmp.Diff(*tc.wantCQ, cq.Spec, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Unexpected ClusterQueue spec (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateQueue(t *testing.T) {
+	cases := map[string]struct {
+		objs      []client.Object
+		args      []string
+		wantOut   string
+		wantErr   bool
+		wantQueue *kueue.QueueSpec
+	}{
+		"create": {
+			objs:      []client.Object{utiltesting.MakeClusterQueue("cq").Obj()},
+			args:      []string{"create", "lq", "new", "-c", "cq"},
+			wantOut:   "localqueue/new created\n",
+			wantQueue: &kueue.QueueSpec{ClusterQueue: "cq"},
+		},
+		"unknown clusterQueue": {
+			args:    []string{"create", "localqueue", "new", "--clusterqueue", "cq"},
+			wantErr: true,
+		},
+		"ignore unknown clusterQueue": {
+			args:      []string{"create", "localqueue", "new", "--clusterqueue", "cq", "--ignore-unknown-cq"},
+			wantOut:   "localqueue/new created\n",
+			wantQueue: &kueue.QueueSpec{ClusterQueue: "cq"},
+		},
+		"missing clusterQueue flag": {
+			args:    []string{"create", "lq", "new"},
+			wantErr: true,
+		},
+		"dry run": {
+			args: []string{"create", "lq", "new", "-c", "cq", "--dry-run"},
+			wantOut: `apiVersion: kueue.x-k8s.io/v1alpha1
+kind: Queue
+metadata:
+  creationTimestamp: null
+  name: new
+  namespace: default
+spec:
+  clusterQueue: cq
+status:
+  admittedWorkloads: 0
+  pendingWorkloads: 0
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objs...).Build()
+			out, err := runCmdWithClient(t, cl, tc.args...)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantOut, out); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+			var q kueue.Queue
+			err = cl.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "new"}, &q)
+			if tc.wantQueue == nil {
+				if err == nil {
+					t.Errorf("Queue was created: %v", q.Spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed getting Queue: %v", err)
+			}
+			if diff := cmp.Diff(*tc.wantQueue, q.Spec); diff != "" {
+				t.Errorf("Unexpected Queue spec (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
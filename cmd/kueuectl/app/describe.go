@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func newDescribeCmd(o *KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Show details of Kueue resources",
+	}
+	cmd.AddCommand(newDescribeWorkloadCmd(o))
+	return cmd
+}
+
+func newDescribeWorkloadCmd(o *KueuectlOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "workload NAME",
+		Aliases: []string{"workloads", "wl"},
+		Short:   "Show the queueing and admission details of a Workload",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			ns, err := o.ClientGetter.Namespace()
+			if err != nil {
+				return err
+			}
+			return describeWorkload(cmd.Context(), o.Out, c, types.NamespacedName{Namespace: ns, Name: args[0]})
+		},
+	}
+}
+
+func describeWorkload(ctx context.Context, out io.Writer, c client.Client, key types.NamespacedName) error {
+	var wl kueue.Workload
+	if err := c.Get(ctx, key, &wl); err != nil {
+		return err
+	}
+	state, _, err := loadQueueState(ctx, c)
+	if err != nil {
+		return err
+	}
+	jobDetails, err := ownerJobDetails(ctx, c, &wl)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", wl.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", wl.Namespace)
+	fmt.Fprintf(w, "Owner:\t%s\n", owner(&wl))
+	if jobDetails != "" {
+		fmt.Fprintf(w, "Job:\t%s\n", jobDetails)
+	}
+	fmt.Fprintf(w, "LocalQueue:\t%s\n", valueOrNone(wl.Spec.QueueName))
+	fmt.Fprintf(w, "ClusterQueue:\t%s\n", valueOrNone(state.clusterQueueFor(&wl)))
+	fmt.Fprintf(w, "Priority:\t%s\n", priority(&wl))
+	status := workloadStatus(&wl)
+	fmt.Fprintf(w, "Status:\t%s\n", status)
+	if status == statusAdmitted {
+		fmt.Fprintf(w, "Admitted By:\t%s\n", wl.Spec.Admission.ClusterQueue)
+	}
+	if status == statusPending {
+		fmt.Fprintf(w, "Position:\t%s (approximate)\n", state.position(&wl))
+		fmt.Fprintf(w, "Pending Reason:\t%s\n", valueOrNone(pendingReason(&wl)))
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", wl.CreationTimestamp.Format("2006-01-02T15:04:05Z07:00"))
+
+	fmt.Fprintln(w, "Pod Sets:")
+	fmt.Fprintln(w, "  NAME\tCOUNT\tREQUESTS\tFLAVORS")
+	info := workload.NewInfo(&wl)
+	for i, ps := range info.TotalRequests {
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\n", ps.Name, wl.Spec.PodSets[i].Count,
+			formatRequests(ps.Requests), formatFlavors(ps.Flavors))
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	if len(wl.Status.Conditions) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	} else {
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, cond := range wl.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", cond.Type, cond.Status, valueOrNone(cond.Reason), cond.Message)
+		}
+	}
+	return w.Flush()
+}
+
+// ownerJobDetails summarizes the state of the Job owning the workload, if any.
+func ownerJobDetails(ctx context.Context, c client.Client, wl *kueue.Workload) (string, error) {
+	ref := metav1.GetControllerOf(wl)
+	if ref == nil || ref.Kind != "Job" || ref.APIVersion != batchv1.SchemeGroupVersion.String() {
+		return "", nil
+	}
+	var job batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Namespace: wl.Namespace, Name: ref.Name}, &job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "<not found>", nil
+		}
+		return "", err
+	}
+	suspended := job.Spec.Suspend != nil && *job.Spec.Suspend
+	return fmt.Sprintf("suspended=%t, active=%d, succeeded=%d, failed=%d",
+		suspended, job.Status.Active, job.Status.Succeeded, job.Status.Failed), nil
+}
+
+func priority(wl *kueue.Workload) string {
+	if wl.Spec.Priority == nil {
+		return none
+	}
+	if wl.Spec.PriorityClassName != "" {
+		return fmt.Sprintf("%d (%s)", *wl.Spec.Priority, wl.Spec.PriorityClassName)
+	}
+	return fmt.Sprint(*wl.Spec.Priority)
+}
+
+func formatRequests(reqs workload.Requests) string {
+	if len(reqs) == 0 {
+		return none
+	}
+	parts := make([]string, 0, len(reqs))
+	for name, v := range reqs {
+		q := workload.ResourceQuantity(name, v)
+		parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatFlavors(flavors map[corev1.ResourceName]string) string {
+	if len(flavors) == 0 {
+		return none
+	}
+	parts := make([]string, 0, len(flavors))
+	for name, f := range flavors {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, f))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestDescribeWorkload(t *testing.T) {
+	job := utiltesting.MakeJob("low", "default").Obj()
+	objs := testObjects()
+	for _, o := range objs {
+		if wl, ok := o.(*kueue.Workload); ok && wl.Name == "low" {
+			wl.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Name:       job.Name,
+				Controller: pointer.Bool(true),
+			}}
+			wl.Status.Conditions = []kueue.WorkloadCondition{{
+				Type:    kueue.WorkloadAdmitted,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Pending",
+				Message: "Workload didn't fit, insufficient cpu for podSet main: insufficient quota for flavor on-demand, 1000 more needed",
+			}}
+		}
+	}
+	objs = append(objs, job)
+
+	cases := map[string]struct {
+		objs []client.Object
+		args []string
+		want string
+	}{
+		"pending workload": {
+			objs: objs,
+			args: []string{"describe", "wl", "low"},
+			want: `Name:            low
+Namespace:       default
+Owner:           Job/low
+Job:             suspended=true, active=0, succeeded=0, failed=0
+LocalQueue:      main
+ClusterQueue:    cq
+Priority:        <none>
+Status:          Pending
+Position:        2 (approximate)
+Pending Reason:  Workload didn't fit, insufficient cpu for podSet main: insufficient quota for flavor on-demand, 1000 more needed
+Created:         2022-05-01T11:40:00Z
+Pod Sets:
+  NAME  COUNT  REQUESTS  FLAVORS
+  main  1      cpu=2     <none>
+Conditions:
+  TYPE      STATUS  REASON   MESSAGE
+  Admitted  False   Pending  Workload didn't fit, insufficient cpu for podSet main: insufficient quota for flavor on-demand, 1000 more needed
+`,
+		},
+		"admitted workload": {
+			objs: testObjects(),
+			args: []string{"describe", "workload", "running"},
+			want: `Name:          running
+Namespace:     default
+Owner:         <none>
+LocalQueue:    main
+ClusterQueue:  cq
+Priority:      <none>
+Status:        Admitted
+Admitted By:   cq
+Created:       2022-05-01T11:30:00Z
+Pod Sets:
+  NAME  COUNT  REQUESTS  FLAVORS
+  main  1      <none>    cpu=on-demand
+Conditions:
+  <none>
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := runCmd(t, tc.objs, tc.args...)
+			if err != nil {
+				t.Fatalf("Failed running command: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDescribeWorkloadNotFound(t *testing.T) {
+	if _, err := runCmd(t, testObjects(), "describe", "wl", "missing"); err == nil {
+		t.Error("Expected error for a missing workload")
+	}
+}
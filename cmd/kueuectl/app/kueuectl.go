@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kueue.AddToScheme(scheme))
+}
+
+// ClientGetter provides the client and the default namespace used by the
+// commands.
+type ClientGetter interface {
+	// Client returns a client for the cluster.
+	Client() (client.Client, error)
+	// Namespace returns the namespace selected by the user or the one in the
+	// current kubeconfig context.
+	Namespace() (string, error)
+}
+
+// KueuectlOptions holds the dependencies shared by all the commands.
+type KueuectlOptions struct {
+	ClientGetter ClientGetter
+	genericclioptions.IOStreams
+}
+
+type configFlagsClientGetter struct {
+	flags *genericclioptions.ConfigFlags
+}
+
+var _ ClientGetter = &configFlagsClientGetter{}
+
+func (g *configFlagsClientGetter) Client() (client.Client, error) {
+	cfg, err := g.flags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+func (g *configFlagsClientGetter) Namespace() (string, error) {
+	ns, _, err := g.flags.ToRawKubeConfigLoader().Namespace()
+	return ns, err
+}
+
+// NewDefaultKueuectlCmd creates the kueuectl command with the standard
+// kubeconfig flags and the process' standard streams.
+func NewDefaultKueuectlCmd() *cobra.Command {
+	configFlags := genericclioptions.NewConfigFlags(true)
+	cmd := NewKueuectlCmd(KueuectlOptions{
+		ClientGetter: &configFlagsClientGetter{flags: configFlags},
+		IOStreams:    genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr},
+	})
+	configFlags.AddFlags(cmd.PersistentFlags())
+	return cmd
+}
+
+// NewKueuectlCmd creates the kueuectl command tree.
+func NewKueuectlCmd(o KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kueuectl",
+		Short: "Controls Kueue queueing and admission",
+		Long: `kueuectl controls Kueue queues and workloads.
+
+It can be installed as a kubectl plugin and invoked as "kubectl kueue".`,
+		SilenceUsage: true,
+	}
+	cmd.SetIn(o.In)
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	cmd.AddCommand(newListCmd(&o))
+	cmd.AddCommand(newDescribeCmd(&o))
+	cmd.AddCommand(newStopCmd(&o))
+	cmd.AddCommand(newResumeCmd(&o))
+	cmd.AddCommand(newCreateCmd(&o))
+	for _, verb := range passThroughVerbs {
+		cmd.AddCommand(newPassThroughCmd(&o, verb))
+	}
+	return cmd
+}
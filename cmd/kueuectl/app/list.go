@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+// timeNow is overridden in tests.
+var timeNow = time.Now
+
+type listOptions struct {
+	*KueuectlOptions
+	allNamespaces bool
+	clusterQueue  string
+	queue         string
+}
+
+func newListCmd(o *KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Display a summary of Kueue resources",
+	}
+	cmd.AddCommand(newListClusterQueuesCmd(o))
+	cmd.AddCommand(newListQueuesCmd(o))
+	cmd.AddCommand(newListWorkloadsCmd(o))
+	return cmd
+}
+
+func newListClusterQueuesCmd(o *KueuectlOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "clusterqueue",
+		Aliases: []string{"clusterqueues", "cq"},
+		Short:   "List ClusterQueues",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			var cqs kueue.ClusterQueueList
+			if err := c.List(cmd.Context(), &cqs); err != nil {
+				return err
+			}
+			return printClusterQueues(o.Out, cqs.Items)
+		},
+	}
+}
+
+func newListQueuesCmd(o *KueuectlOptions) *cobra.Command {
+	lo := &listOptions{KueuectlOptions: o}
+	cmd := &cobra.Command{
+		Use:     "localqueue",
+		Aliases: []string{"localqueues", "lq", "queue", "queues"},
+		Short:   "List the namespaced Queues",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			opts, err := lo.namespaceListOptions()
+			if err != nil {
+				return err
+			}
+			var queues kueue.QueueList
+			if err := c.List(cmd.Context(), &queues, opts...); err != nil {
+				return err
+			}
+			items := queues.Items
+			if lo.clusterQueue != "" {
+				items = nil
+				for _, q := range queues.Items {
+					if string(q.Spec.ClusterQueue) == lo.clusterQueue {
+						items = append(items, q)
+					}
+				}
+			}
+			return printQueues(o.Out, items, lo.allNamespaces)
+		},
+	}
+	cmd.Flags().BoolVarP(&lo.allNamespaces, "all-namespaces", "A", false, "List the queues across all namespaces.")
+	cmd.Flags().StringVarP(&lo.clusterQueue, "clusterqueue", "c", "", "Only list the queues pointing to this ClusterQueue.")
+	return cmd
+}
+
+func newListWorkloadsCmd(o *KueuectlOptions) *cobra.Command {
+	lo := &listOptions{KueuectlOptions: o}
+	cmd := &cobra.Command{
+		Use:     "workload",
+		Aliases: []string{"workloads", "wl"},
+		Short:   "List Workloads along with their queueing state",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := o.ClientGetter.Client()
+			if err != nil {
+				return err
+			}
+			ns := ""
+			if !lo.allNamespaces {
+				if ns, err = o.ClientGetter.Namespace(); err != nil {
+					return err
+				}
+			}
+			// All the workloads are needed to calculate the positions in the
+			// ClusterQueues, which can span multiple namespaces.
+			state, workloads, err := loadQueueState(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+			var items []kueue.Workload
+			for i := range workloads {
+				wl := &workloads[i]
+				if ns != "" && wl.Namespace != ns {
+					continue
+				}
+				if lo.queue != "" && wl.Spec.QueueName != lo.queue {
+					continue
+				}
+				if lo.clusterQueue != "" && state.clusterQueueFor(wl) != lo.clusterQueue {
+					continue
+				}
+				items = append(items, *wl)
+			}
+			return printWorkloads(o.Out, items, state, lo.allNamespaces)
+		},
+	}
+	cmd.Flags().BoolVarP(&lo.allNamespaces, "all-namespaces", "A", false, "List the workloads across all namespaces.")
+	cmd.Flags().StringVarP(&lo.clusterQueue, "clusterqueue", "c", "", "Only list the workloads queued in or admitted by this ClusterQueue.")
+	cmd.Flags().StringVarP(&lo.queue, "localqueue", "q", "", "Only list the workloads submitted to this queue.")
+	return cmd
+}
+
+func (o *listOptions) namespaceListOptions() ([]client.ListOption, error) {
+	if o.allNamespaces {
+		return nil, nil
+	}
+	ns, err := o.ClientGetter.Namespace()
+	if err != nil {
+		return nil, err
+	}
+	return []client.ListOption{client.InNamespace(ns)}, nil
+}
+
+func printClusterQueues(out io.Writer, cqs []kueue.ClusterQueue) error {
+	sort.Slice(cqs, func(i, j int) bool { return cqs[i].Name < cqs[j].Name })
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCOHORT\tPENDING WORKLOADS\tADMITTED WORKLOADS\tAGE")
+	now := timeNow()
+	for _, cq := range cqs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", cq.Name, valueOrNone(cq.Spec.Cohort),
+			cq.Status.PendingWorkloads, cq.Status.AdmittedWorkloads, age(cq.CreationTimestamp, now))
+	}
+	return w.Flush()
+}
+
+func printQueues(out io.Writer, queues []kueue.Queue, withNamespace bool) error {
+	sort.Slice(queues, func(i, j int) bool {
+		if queues[i].Namespace != queues[j].Namespace {
+			return queues[i].Namespace < queues[j].Namespace
+		}
+		return queues[i].Name < queues[j].Name
+	})
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	printHeader(w, withNamespace, "NAME", "CLUSTERQUEUE", "PENDING WORKLOADS", "AGE")
+	now := timeNow()
+	for _, q := range queues {
+		printRow(w, withNamespace, q.Namespace, q.Name, string(q.Spec.ClusterQueue),
+			fmt.Sprint(q.Status.PendingWorkloads), age(q.CreationTimestamp, now))
+	}
+	return w.Flush()
+}
+
+func printWorkloads(out io.Writer, workloads []kueue.Workload, state *queueState, withNamespace bool) error {
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	printHeader(w, withNamespace, "NAME", "OWNER", "LOCALQUEUE", "CLUSTERQUEUE", "STATUS", "POSITION", "AGE")
+	now := timeNow()
+	for i := range workloads {
+		wl := &workloads[i]
+		printRow(w, withNamespace, wl.Namespace, wl.Name, owner(wl), wl.Spec.QueueName,
+			valueOrNone(state.clusterQueueFor(wl)), workloadStatus(wl), state.position(wl),
+			age(wl.CreationTimestamp, now))
+	}
+	return w.Flush()
+}
+
+func printHeader(w io.Writer, withNamespace bool, columns ...string) {
+	if withNamespace {
+		columns = append([]string{"NAMESPACE"}, columns...)
+	}
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+func printRow(w io.Writer, withNamespace bool, namespace string, values ...string) {
+	if withNamespace {
+		values = append([]string{namespace}, values...)
+	}
+	fmt.Fprintln(w, strings.Join(values, "\t"))
+}
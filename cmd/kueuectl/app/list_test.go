@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+var testNow = time.Date(2022, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+type fakeClientGetter struct {
+	client    client.Client
+	namespace string
+}
+
+func (g *fakeClientGetter) Client() (client.Client, error) {
+	return g.client, nil
+}
+
+func (g *fakeClientGetter) Namespace() (string, error) {
+	return g.namespace, nil
+}
+
+// runCmd runs kueuectl with the given arguments against the objects and
+// returns its output.
+func runCmd(t *testing.T, objs []client.Object, args ...string) (string, error) {
+	t.Helper()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return runCmdWithClient(t, cl, args...)
+}
+
+// runCmdWithClient runs kueuectl with the given arguments against the client
+// and returns its output.
+func runCmdWithClient(t *testing.T, cl client.Client, args ...string) (string, error) {
+	t.Helper()
+	origNow := timeNow
+	timeNow = func() time.Time { return testNow }
+	t.Cleanup(func() { timeNow = origNow })
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewKueuectlCmd(KueuectlOptions{
+		ClientGetter: &fakeClientGetter{client: cl, namespace: "default"},
+		IOStreams:    streams,
+	})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func testObjects() []client.Object {
+	created := metav1.NewTime(testNow.Add(-time.Hour))
+	withCreation := func(wl *kueue.Workload, t time.Time) *kueue.Workload {
+		wl.CreationTimestamp = metav1.NewTime(t)
+		return wl
+	}
+	cq := utiltesting.MakeClusterQueue("cq").Cohort("research").Obj()
+	cq.CreationTimestamp = created
+	cq.Status.PendingWorkloads = 2
+	cq.Status.AdmittedWorkloads = 1
+	main := utiltesting.MakeQueue("main", "default").ClusterQueue("cq").Obj()
+	main.CreationTimestamp = created
+	main.Status.PendingWorkloads = 1
+	other := utiltesting.MakeQueue("other", "team-b").ClusterQueue("cq").Obj()
+	other.CreationTimestamp = created
+	return []client.Object{
+		cq, main, other,
+		withCreation(utiltesting.MakeWorkload("running", "default").Queue("main").
+			Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+			testNow.Add(-30*time.Minute)),
+		withCreation(utiltesting.MakeWorkload("low", "default").Queue("main").Request(corev1.ResourceCPU, "2").Obj(),
+			testNow.Add(-20*time.Minute)),
+		withCreation(utiltesting.MakeWorkload("high", "team-b").Queue("other").Priority(pointer.Int32(100)).Obj(),
+			testNow.Add(-10*time.Minute)),
+	}
+}
+
+func TestListClusterQueues(t *testing.T) {
+	got, err := runCmd(t, testObjects(), "list", "cq")
+	if err != nil {
+		t.Fatalf("Failed running command: %v", err)
+	}
+	want := `NAME  COHORT    PENDING WORKLOADS  ADMITTED WORKLOADS  AGE
+cq    research  2                  1                   60m
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+	}
+}
+
+func TestListQueues(t *testing.T) {
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"current namespace": {
+			args: []string{"list", "lq"},
+			want: `NAME  CLUSTERQUEUE  PENDING WORKLOADS  AGE
+main  cq            1                  60m
+`,
+		},
+		"all namespaces": {
+			args: []string{"list", "localqueues", "-A"},
+			want: `NAMESPACE  NAME   CLUSTERQUEUE  PENDING WORKLOADS  AGE
+default    main   cq            1                  60m
+team-b     other  cq            0                  60m
+`,
+		},
+		"filter by clusterQueue": {
+			args: []string{"list", "lq", "-A", "--clusterqueue", "other-cq"},
+			want: `NAMESPACE  NAME  CLUSTERQUEUE  PENDING WORKLOADS  AGE
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := runCmd(t, testObjects(), tc.args...)
+			if err != nil {
+				t.Fatalf("Failed running command: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListWorkloads(t *testing.T) {
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"current namespace": {
+			args: []string{"list", "wl"},
+			want: `NAME     OWNER   LOCALQUEUE  CLUSTERQUEUE  STATUS    POSITION  AGE
+low      <none>  main        cq            Pending   2         20m
+running  <none>  main        cq            Admitted  <none>    30m
+`,
+		},
+		"all namespaces": {
+			args: []string{"list", "workloads", "-A"},
+			want: `NAMESPACE  NAME     OWNER   LOCALQUEUE  CLUSTERQUEUE  STATUS    POSITION  AGE
+default    low      <none>  main        cq            Pending   2         20m
+default    running  <none>  main        cq            Admitted  <none>    30m
+team-b     high     <none>  other       cq            Pending   1         10m
+`,
+		},
+		"filter by queue": {
+			args: []string{"list", "wl", "-A", "--localqueue", "other"},
+			want: `NAMESPACE  NAME  OWNER   LOCALQUEUE  CLUSTERQUEUE  STATUS   POSITION  AGE
+team-b     high  <none>  other       cq            Pending  1         10m
+`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := runCmd(t, testObjects(), tc.args...)
+			if err != nil {
+				t.Fatalf("Failed running command: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// passThroughVerbs are the kubectl verbs that kueuectl forwards to kubectl,
+// after expanding the Kueue resource names.
+var passThroughVerbs = []string{"get", "delete", "edit", "patch"}
+
+// kueueResources maps the names and short names accepted by kueuectl to the
+// fully qualified resource names, so that kubectl doesn't pick another API
+// group's resource with the same name.
+var kueueResources = map[string]string{
+	"clusterqueue":    "clusterqueues.kueue.x-k8s.io",
+	"clusterqueues":   "clusterqueues.kueue.x-k8s.io",
+	"cq":              "clusterqueues.kueue.x-k8s.io",
+	"localqueue":      "queues.kueue.x-k8s.io",
+	"localqueues":     "queues.kueue.x-k8s.io",
+	"lq":              "queues.kueue.x-k8s.io",
+	"queue":           "queues.kueue.x-k8s.io",
+	"queues":          "queues.kueue.x-k8s.io",
+	"workload":        "workloads.kueue.x-k8s.io",
+	"workloads":       "workloads.kueue.x-k8s.io",
+	"wl":              "workloads.kueue.x-k8s.io",
+	"resourceflavor":  "resourceflavors.kueue.x-k8s.io",
+	"resourceflavors": "resourceflavors.kueue.x-k8s.io",
+	"rf":              "resourceflavors.kueue.x-k8s.io",
+}
+
+// valueFlags are the kubectl flags that take their value in the next
+// argument when not given as --flag=value.
+var valueFlags = map[string]bool{
+	"-n": true, "--namespace": true,
+	"-o": true, "--output": true,
+	"-l": true, "--selector": true,
+	"-p": true, "--patch": true,
+	"--type": true, "--field-selector": true,
+	"--context": true, "--cluster": true, "--user": true,
+	"--kubeconfig": true, "-s": true, "--server": true,
+	"--request-timeout": true, "--as": true, "--as-group": true,
+	"--token": true, "--sort-by": true, "--template": true,
+	"--patch-file": true, "--grace-period": true, "--timeout": true,
+}
+
+// runKubectl runs kubectl with the arguments. Overridden in tests.
+var runKubectl = func(ctx context.Context, streams genericclioptions.IOStreams, args []string) error {
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	cmd.Stdin = streams.In
+	cmd.Stdout = streams.Out
+	cmd.Stderr = streams.ErrOut
+	return cmd.Run()
+}
+
+func newPassThroughCmd(o *KueuectlOptions, verb string) *cobra.Command {
+	return &cobra.Command{
+		Use:   verb + " TYPE[/NAME] [NAME...] [flags]",
+		Short: fmt.Sprintf("Run kubectl %s on Kueue resources", verb),
+		Long: fmt.Sprintf(`Run "kubectl %s" on ClusterQueues (cq), LocalQueues (lq), Workloads (wl) or
+ResourceFlavors (rf). All the flags are passed to kubectl.`, verb),
+		Example:            fmt.Sprintf("  kueuectl %s wl sample-job-x2l4k -n team-a", verb),
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+				return cmd.Help()
+			}
+			kubectlArgs, err := passThroughArgs(verb, args)
+			if err != nil {
+				return err
+			}
+			return runKubectl(cmd.Context(), o.IOStreams, kubectlArgs)
+		},
+	}
+}
+
+// passThroughArgs builds the kubectl arguments, replacing the resource type
+// with its fully qualified name. Only Kueue resources are accepted.
+func passThroughArgs(verb string, args []string) ([]string, error) {
+	kubectlArgs := append([]string{verb}, args...)
+	for i := 1; i < len(kubectlArgs); i++ {
+		arg := kubectlArgs[i]
+		if strings.HasPrefix(arg, "-") {
+			if valueFlags[arg] {
+				i++
+			}
+			continue
+		}
+		resources, err := expandResources(arg)
+		if err != nil {
+			return nil, err
+		}
+		kubectlArgs[i] = resources
+		return kubectlArgs, nil
+	}
+	return nil, errors.New("you must specify the type of resource: clusterqueue (cq), localqueue (lq), workload (wl) or resourceflavor (rf)")
+}
+
+// expandResources expands a resource argument in the forms TYPE, TYPE/NAME
+// or TYPE1,TYPE2.
+func expandResources(arg string) (string, error) {
+	parts := strings.Split(arg, ",")
+	for i, part := range parts {
+		kind, name, hasName := strings.Cut(part, "/")
+		resource, ok := kueueResources[strings.ToLower(kind)]
+		if !ok {
+			return "", fmt.Errorf("%q is not a Kueue resource", kind)
+		}
+		if hasName {
+			resource += "/" + name
+		}
+		parts[i] = resource
+	}
+	return strings.Join(parts, ","), nil
+}
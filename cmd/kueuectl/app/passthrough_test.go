@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPassThrough(t *testing.T) {
+	cases := map[string]struct {
+		args     []string
+		wantArgs []string
+		wantErr  bool
+	}{
+		"get clusterQueues": {
+			args:     []string{"get", "cq"},
+			wantArgs: []string{"get", "clusterqueues.kueue.x-k8s.io"},
+		},
+		"get with flags before the type": {
+			args:     []string{"get", "-n", "team-a", "--output=yaml", "wl", "sample-job"},
+			wantArgs: []string{"get", "-n", "team-a", "--output=yaml", "workloads.kueue.x-k8s.io", "sample-job"},
+		},
+		"get several types": {
+			args:     []string{"get", "lq,WL", "-A"},
+			wantArgs: []string{"get", "queues.kueue.x-k8s.io,workloads.kueue.x-k8s.io", "-A"},
+		},
+		"delete by type and name": {
+			args:     []string{"delete", "localqueue/main"},
+			wantArgs: []string{"delete", "queues.kueue.x-k8s.io/main"},
+		},
+		"edit": {
+			args:     []string{"edit", "rf", "default"},
+			wantArgs: []string{"edit", "resourceflavors.kueue.x-k8s.io", "default"},
+		},
+		"patch": {
+			args:     []string{"patch", "clusterqueue", "cq", "--type", "merge", "-p", `{"spec":{"cohort":"all"}}`},
+			wantArgs: []string{"patch", "clusterqueues.kueue.x-k8s.io", "cq", "--type", "merge", "-p", `{"spec":{"cohort":"all"}}`},
+		},
+		"non kueue resource": {
+			args:    []string{"get", "pods"},
+			wantErr: true,
+		},
+		"non kueue resource in list": {
+			args:    []string{"get", "cq,jobs"},
+			wantErr: true,
+		},
+		"missing type": {
+			args:    []string{"delete", "-n", "team-a"},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var gotArgs []string
+			origRun := runKubectl
+			runKubectl = func(_ context.Context, _ genericclioptions.IOStreams, args []string) error {
+				gotArgs = args
+				return nil
+			}
+			t.Cleanup(func() { runKubectl = origRun })
+
+			cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+			_, err := runCmdWithClient(t, cl, tc.args...)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantArgs, gotArgs); diff != "" {
+				t.Errorf("Unexpected kubectl arguments (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// waitInterval is the polling interval used by --wait. Overridden in tests.
+var waitInterval = time.Second
+
+// Values of the stopPolicy field of the queues.
+const (
+	stopPolicyNone         = "None"
+	stopPolicyHold         = "Hold"
+	stopPolicyHoldAndDrain = "HoldAndDrain"
+)
+
+// stopTarget describes how to stop, resume and check the draining of a kind
+// of object.
+type stopTarget struct {
+	use        string
+	aliases    []string
+	kind       string
+	namespaced bool
+	// newObject returns an empty object of the kind.
+	newObject func() client.Object
+	// stopPatch returns the merge patch that sets the stopPolicy, or the
+	// active field, of the object.
+	stopPatch func(policy string) string
+	// drained returns whether there are no admitted workloads left running.
+	drained func(ctx context.Context, c client.Client, key types.NamespacedName) (bool, error)
+}
+
+var stopTargets = []stopTarget{
+	{
+		use:       "clusterqueue",
+		aliases:   []string{"clusterqueues", "cq"},
+		kind:      "ClusterQueue",
+		newObject: func() client.Object { return &kueue.ClusterQueue{} },
+		stopPatch: stopPolicyPatch,
+		drained:   clusterQueueDrained,
+	},
+	{
+		use:        "localqueue",
+		aliases:    []string{"localqueues", "lq", "queue", "queues"},
+		kind:       "Queue",
+		namespaced: true,
+		newObject:  func() client.Object { return &kueue.Queue{} },
+		stopPatch:  stopPolicyPatch,
+		drained:    queueDrained,
+	},
+	{
+		use:        "workload",
+		aliases:    []string{"workloads", "wl"},
+		kind:       "Workload",
+		namespaced: true,
+		newObject:  func() client.Object { return &kueue.Workload{} },
+		stopPatch:  activePatch,
+		drained:    workloadDrained,
+	},
+}
+
+type stopOptions struct {
+	*KueuectlOptions
+	keepAlreadyRunning bool
+	wait               bool
+	timeout            time.Duration
+}
+
+func newStopCmd(o *KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop admitting workloads from a queue, or deactivate a workload",
+	}
+	for _, t := range stopTargets {
+		cmd.AddCommand(newStopTargetCmd(o, t))
+	}
+	return cmd
+}
+
+func newStopTargetCmd(o *KueuectlOptions, t stopTarget) *cobra.Command {
+	so := &stopOptions{KueuectlOptions: o}
+	short := fmt.Sprintf("Hold the pending workloads of a %s and evict its admitted workloads", t.kind)
+	if t.kind == "Workload" {
+		short = "Deactivate a Workload, evicting it if it was admitted"
+	}
+	cmd := &cobra.Command{
+		Use:     t.use + " NAME",
+		Aliases: t.aliases,
+		Short:   short,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, key, err := so.clientAndKey(t, args[0])
+			if err != nil {
+				return err
+			}
+			policy := stopPolicyHoldAndDrain
+			if so.keepAlreadyRunning {
+				policy = stopPolicyHold
+			}
+			if err := setStopped(cmd.Context(), c, t, key, policy); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "%s/%s stopped\n", t.use, key.Name)
+			if !so.wait {
+				return nil
+			}
+			err = wait.PollImmediate(waitInterval, so.timeout, func() (bool, error) {
+				return t.drained(cmd.Context(), c, key)
+			})
+			if err != nil {
+				return fmt.Errorf("waiting for %s/%s to drain: %w", t.use, key.Name, err)
+			}
+			fmt.Fprintf(o.Out, "%s/%s drained\n", t.use, key.Name)
+			return nil
+		},
+	}
+	if t.kind != "Workload" {
+		cmd.Flags().BoolVar(&so.keepAlreadyRunning, "keep-already-running", false,
+			"Let the admitted workloads run to completion, only holding the pending ones.")
+	}
+	cmd.Flags().BoolVar(&so.wait, "wait", false, "Wait until there are no admitted workloads left running.")
+	cmd.Flags().DurationVar(&so.timeout, "timeout", 5*time.Minute, "The maximum time to wait when --wait is set.")
+	return cmd
+}
+
+func newResumeCmd(o *KueuectlOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume admitting workloads from a queue, or reactivate a workload",
+	}
+	for _, t := range stopTargets {
+		cmd.AddCommand(newResumeTargetCmd(o, t))
+	}
+	return cmd
+}
+
+func newResumeTargetCmd(o *KueuectlOptions, t stopTarget) *cobra.Command {
+	so := &stopOptions{KueuectlOptions: o}
+	return &cobra.Command{
+		Use:     t.use + " NAME",
+		Aliases: t.aliases,
+		Short:   fmt.Sprintf("Resume a stopped %s", t.kind),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, key, err := so.clientAndKey(t, args[0])
+			if err != nil {
+				return err
+			}
+			if err := setStopped(cmd.Context(), c, t, key, stopPolicyNone); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "%s/%s resumed\n", t.use, key.Name)
+			return nil
+		},
+	}
+}
+
+func (o *stopOptions) clientAndKey(t stopTarget, name string) (client.Client, types.NamespacedName, error) {
+	key := types.NamespacedName{Name: name}
+	c, err := o.ClientGetter.Client()
+	if err != nil {
+		return nil, key, err
+	}
+	if t.namespaced {
+		if key.Namespace, err = o.ClientGetter.Namespace(); err != nil {
+			return nil, key, err
+		}
+	}
+	return c, key, nil
+}
+
+// setStopped patches the object with the stop policy. The patch is sent
+// without checking the object first, so a missing object is reported by the
+// API server.
+func setStopped(ctx context.Context, c client.Client, t stopTarget, key types.NamespacedName, policy string) error {
+	obj := t.newObject()
+	obj.SetName(key.Name)
+	obj.SetNamespace(key.Namespace)
+	return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, []byte(t.stopPatch(policy))))
+}
+
+func stopPolicyPatch(policy string) string {
+	return fmt.Sprintf(`{"spec":{"stopPolicy":%q}}`, policy)
+}
+
+// activePatch deactivates the workload for any policy other than None.
+func activePatch(policy string) string {
+	return fmt.Sprintf(`{"spec":{"active":%t}}`, policy == stopPolicyNone)
+}
+
+func clusterQueueDrained(ctx context.Context, c client.Client, key types.NamespacedName) (bool, error) {
+	var workloads kueue.WorkloadList
+	if err := c.List(ctx, &workloads); err != nil {
+		return false, err
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if workloadStatus(wl) == statusAdmitted && string(wl.Spec.Admission.ClusterQueue) == key.Name {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func queueDrained(ctx context.Context, c client.Client, key types.NamespacedName) (bool, error) {
+	var workloads kueue.WorkloadList
+	if err := c.List(ctx, &workloads, client.InNamespace(key.Namespace)); err != nil {
+		return false, err
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if workloadStatus(wl) == statusAdmitted && wl.Spec.QueueName == key.Name {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func workloadDrained(ctx context.Context, c client.Client, key types.NamespacedName) (bool, error) {
+	var wl kueue.Workload
+	if err := c.Get(ctx, key, &wl); err != nil {
+		return false, err
+	}
+	return wl.Spec.Admission == nil || workload.InCondition(&wl, kueue.WorkloadFinished), nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// patchRecorder records the patches applied through the client, prefixed by
+// the key of the patched object.
+type patchRecorder struct {
+	client.Client
+	patches []string
+}
+
+func (r *patchRecorder) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	if err := r.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	r.patches = append(r.patches, fmt.Sprintf("%s %s", client.ObjectKeyFromObject(obj), data))
+	return nil
+}
+
+func TestStopAndResume(t *testing.T) {
+	origInterval := waitInterval
+	waitInterval = time.Millisecond
+	t.Cleanup(func() { waitInterval = origInterval })
+
+	cases := map[string]struct {
+		args        []string
+		wantOut     string
+		wantErr     bool
+		wantPatches []string
+	}{
+		"stop clusterQueue": {
+			args:        []string{"stop", "cq", "cq"},
+			wantOut:     "clusterqueue/cq stopped\n",
+			wantPatches: []string{`/cq {"spec":{"stopPolicy":"HoldAndDrain"}}`},
+		},
+		"stop clusterQueue keeping the running workloads": {
+			args:        []string{"stop", "clusterqueue", "cq", "--keep-already-running"},
+			wantOut:     "clusterqueue/cq stopped\n",
+			wantPatches: []string{`/cq {"spec":{"stopPolicy":"Hold"}}`},
+		},
+		"stop clusterQueue and time out waiting": {
+			args:        []string{"stop", "cq", "cq", "--wait", "--timeout=10ms"},
+			wantOut:     "clusterqueue/cq stopped\n",
+			wantErr:     true,
+			wantPatches: []string{`/cq {"spec":{"stopPolicy":"HoldAndDrain"}}`},
+		},
+		"stop localQueue": {
+			args:        []string{"stop", "lq", "main"},
+			wantOut:     "localqueue/main stopped\n",
+			wantPatches: []string{`default/main {"spec":{"stopPolicy":"HoldAndDrain"}}`},
+		},
+		"stop workload and wait": {
+			args:        []string{"stop", "wl", "low", "--wait"},
+			wantOut:     "workload/low stopped\nworkload/low drained\n",
+			wantPatches: []string{`default/low {"spec":{"active":false}}`},
+		},
+		"stop missing workload": {
+			args:    []string{"stop", "wl", "missing"},
+			wantErr: true,
+		},
+		"resume clusterQueue": {
+			args:        []string{"resume", "cq", "cq"},
+			wantOut:     "clusterqueue/cq resumed\n",
+			wantPatches: []string{`/cq {"spec":{"stopPolicy":"None"}}`},
+		},
+		"resume localQueue": {
+			args:        []string{"resume", "localqueue", "main"},
+			wantOut:     "localqueue/main resumed\n",
+			wantPatches: []string{`default/main {"spec":{"stopPolicy":"None"}}`},
+		},
+		"resume workload": {
+			args:        []string{"resume", "workload", "low"},
+			wantOut:     "workload/low resumed\n",
+			wantPatches: []string{`default/low {"spec":{"active":true}}`},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl := &patchRecorder{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(testObjects()...).Build(),
+			}
+			out, err := runCmdWithClient(t, cl, tc.args...)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantOut, out); diff != "" {
+				t.Errorf("Unexpected output (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPatches, cl.patches); diff != "" {
+				t.Errorf("Unexpected patches (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+const (
+	statusPending  = "Pending"
+	statusAdmitted = "Admitted"
+	statusFinished = "Finished"
+
+	none = "<none>"
+)
+
+// queueState joins the Workloads with the queues they are submitted to.
+type queueState struct {
+	// clusterQueues maps a queue key (namespace/name) to the name of its ClusterQueue.
+	clusterQueues map[string]string
+	// positions maps a workload key to its 1-based position among the
+	// pending workloads of its ClusterQueue.
+	positions map[string]int
+}
+
+// loadQueueState lists all the Queues and Workloads in the cluster to
+// calculate the position of the pending workloads in their ClusterQueues.
+func loadQueueState(ctx context.Context, c client.Client) (*queueState, []kueue.Workload, error) {
+	var queues kueue.QueueList
+	if err := c.List(ctx, &queues); err != nil {
+		return nil, nil, fmt.Errorf("listing queues: %w", err)
+	}
+	var workloads kueue.WorkloadList
+	if err := c.List(ctx, &workloads); err != nil {
+		return nil, nil, fmt.Errorf("listing workloads: %w", err)
+	}
+	s := &queueState{
+		clusterQueues: make(map[string]string, len(queues.Items)),
+	}
+	for _, q := range queues.Items {
+		s.clusterQueues[queueKey(q.Namespace, q.Name)] = string(q.Spec.ClusterQueue)
+	}
+	s.positions = s.pendingPositions(workloads.Items)
+	return s, workloads.Items, nil
+}
+
+// clusterQueueFor returns the ClusterQueue that admitted the workload or,
+// if it's pending, the ClusterQueue it's queued in.
+func (s *queueState) clusterQueueFor(wl *kueue.Workload) string {
+	if wl.Spec.Admission != nil {
+		return string(wl.Spec.Admission.ClusterQueue)
+	}
+	return s.clusterQueues[queueKey(wl.Namespace, wl.Spec.QueueName)]
+}
+
+// pendingPositions sorts the pending workloads of each ClusterQueue by
+// priority and then by creation time. The positions are approximate, as the
+// order in the queue manager also depends on its state, which is not
+// available to the client.
+func (s *queueState) pendingPositions(workloads []kueue.Workload) map[string]int {
+	byCQ := make(map[string][]*kueue.Workload)
+	for i := range workloads {
+		wl := &workloads[i]
+		if workloadStatus(wl) != statusPending {
+			continue
+		}
+		cq := s.clusterQueueFor(wl)
+		if cq == "" {
+			continue
+		}
+		byCQ[cq] = append(byCQ[cq], wl)
+	}
+	positions := make(map[string]int)
+	for _, wls := range byCQ {
+		sort.SliceStable(wls, func(i, j int) bool {
+			pi, pj := utilpriority.Priority(wls[i]), utilpriority.Priority(wls[j])
+			if pi != pj {
+				return pi > pj
+			}
+			return wls[i].CreationTimestamp.Before(&wls[j].CreationTimestamp)
+		})
+		for i, wl := range wls {
+			positions[workload.Key(wl)] = i + 1
+		}
+	}
+	return positions
+}
+
+func (s *queueState) position(wl *kueue.Workload) string {
+	if p, ok := s.positions[workload.Key(wl)]; ok {
+		return fmt.Sprint(p)
+	}
+	return none
+}
+
+func queueKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+func workloadStatus(wl *kueue.Workload) string {
+	if workload.InCondition(wl, kueue.WorkloadFinished) {
+		return statusFinished
+	}
+	if wl.Spec.Admission != nil {
+		return statusAdmitted
+	}
+	return statusPending
+}
+
+// pendingReason returns the message of the Admitted condition of a pending
+// workload, which holds the reason why it couldn't be admitted yet.
+func pendingReason(wl *kueue.Workload) string {
+	if workloadStatus(wl) != statusPending {
+		return ""
+	}
+	i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted)
+	if i == -1 || wl.Status.Conditions[i].Status != corev1.ConditionFalse {
+		return ""
+	}
+	return wl.Status.Conditions[i].Message
+}
+
+// owner returns the kind and name of the object controlling the workload.
+func owner(wl *kueue.Workload) string {
+	ref := metav1.GetControllerOf(wl)
+	if ref == nil {
+		return none
+	}
+	return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+}
+
+func age(t metav1.Time, now time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t.Time))
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return none
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package batchuser holds the RBAC markers of the permissions that the
+// batch-user-role ClusterRole aggregates. The ClusterRole generated from them
+// is labeled for the aggregation in kustomization.yaml.
+//
+// Users can't create or update Workloads: the Workloads of their Jobs are
+// created by Kueue, and the Workload webhook doesn't prevent setting
+// .spec.admission, which would bypass the quotas.
+package batchuser
+
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=queues,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=queues/status,verbs=get
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/status,verbs=get
@@ -19,9 +19,12 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -34,23 +37,35 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	configv1alpha1 "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueuev1alpha1 "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/config"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/controller/core"
 	"sigs.k8s.io/kueue/pkg/controller/workload/job"
+	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
 	"sigs.k8s.io/kueue/pkg/scheduler"
 	"sigs.k8s.io/kueue/pkg/util/cert"
+	"sigs.k8s.io/kueue/pkg/util/pprof"
+	"sigs.k8s.io/kueue/pkg/util/tracing"
+	"sigs.k8s.io/kueue/pkg/visibility"
 	//+kubebuilder:scaffold:imports
 )
 
+// readyzSyncTimeout is how long a readiness probe waits for the informers to
+// sync before failing.
+const readyzSyncTimeout = time.Second
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -61,6 +76,7 @@ func init() {
 	utilruntime.Must(schedulingv1.AddToScheme(scheme))
 
 	utilruntime.Must(kueuev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kueuev1beta1.AddToScheme(scheme))
 	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
@@ -80,60 +96,101 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	options := ctrl.Options{
-		Scheme:                 scheme,
-		HealthProbeBindAddress: ":8081",
-		MetricsBindAddress:     ":8080",
-		Port:                   9443,
-		LeaderElectionID:       "c1f6bfd2.kueue.x-k8s.io",
-	}
-	var err error
-	config := configv1alpha1.Configuration{}
-	if configFile != "" {
-		options, err = options.AndFrom(ctrl.ConfigFile().AtPath(configFile).OfKind(&config))
-		if err != nil {
-			setupLog.Error(err, "unable to load the config file")
-			os.Exit(1)
-		}
-
-		cfgStr, err := encodeConfig(&config)
-		if err != nil {
-			setupLog.Error(err, "unable to encode config file")
-			os.Exit(1)
-		}
-		setupLog.Info("Successfully loaded config file", "config", cfgStr)
+	options, cfg, err := config.Load(scheme, configFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load the configuration")
+		os.Exit(1)
+	}
+	cfgStr, err := encodeConfig(&cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to encode the configuration")
+		os.Exit(1)
+	}
+	setupLog.Info("Successfully loaded the configuration", "config", cfgStr)
+	if err := features.DefaultMutableFeatureGate.SetFromMap(cfg.FeatureGates); err != nil {
+		setupLog.Error(err, "unable to set the feature gates")
+		os.Exit(1)
 	}
 	metrics.Register()
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+	kubeConfig := ctrl.GetConfigOrDie()
+	config.ApplyClientConnection(kubeConfig, &cfg)
+	mgr, err := ctrl.NewManager(kubeConfig, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if cfg.PprofBindAddress != "" && cfg.PprofBindAddress != "0" {
+		if err := mgr.Add(pprof.NewServer(cfg.PprofBindAddress)); err != nil {
+			setupLog.Error(err, "unable to set up the pprof server")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Tracing != nil {
+		provider, err := tracing.NewProvider(cfg.Tracing)
+		if err != nil {
+			setupLog.Error(err, "Unable to set up the tracing")
+			os.Exit(1)
+		}
+		if err := mgr.Add(provider); err != nil {
+			setupLog.Error(err, "Unable to add the tracing provider to manager")
+			os.Exit(1)
+		}
+	}
+
 	certsReady := make(chan struct{})
-	if err = cert.ManageCerts(mgr, certsReady); err != nil {
-		setupLog.Error(err, "unable to set up cert rotation")
-		os.Exit(1)
+	if *cfg.InternalCertManagement.Enable {
+		if err = cert.ManageCerts(mgr, &cfg, certsReady); err != nil {
+			setupLog.Error(err, "unable to set up cert rotation")
+			os.Exit(1)
+		}
+	} else {
+		// The certs are provided by a third party, like cert-manager.
+		close(certsReady)
 	}
 
-	cCache := cache.New(mgr.GetClient())
-	queues := queue.NewManager(mgr.GetClient(), cCache)
+	var cacheOpts []cache.Option
+	queueOpts := []queue.Option{
+		queue.WithLocalQueueMetrics(cfg.Metrics.EnableLocalQueueMetrics),
+	}
+	if r := cfg.Resources; r != nil {
+		if len(r.ExcludeResourcePrefixes) > 0 {
+			cacheOpts = append(cacheOpts, cache.WithExcludedResourcePrefixes(r.ExcludeResourcePrefixes))
+			queueOpts = append(queueOpts, queue.WithExcludedResourcePrefixes(r.ExcludeResourcePrefixes))
+		}
+		if len(r.Transformations) > 0 {
+			cacheOpts = append(cacheOpts, cache.WithResourceTransformations(r.Transformations))
+			queueOpts = append(queueOpts, queue.WithResourceTransformations(r.Transformations))
+		}
+	}
+	cCache := cache.New(mgr.GetClient(), cacheOpts...)
+	queues := queue.NewManager(mgr.GetClient(), cCache, queueOpts...)
 
-	setupIndexes(mgr)
+	setupIndexes(mgr, &cfg)
 
-	setupProbeEndpoints(mgr)
+	auditor := setupAuditor(mgr, cCache, &cfg)
+	sched := setupScheduler(mgr, cCache, queues, auditor, &cfg)
+	if cfg.VisibilityBindAddress != "" && cfg.VisibilityBindAddress != "0" {
+		if err := mgr.Add(visibility.NewServer(cfg.VisibilityBindAddress, sched, cCache, queues)); err != nil {
+			setupLog.Error(err, "unable to set up the visibility server")
+			os.Exit(1)
+		}
+	}
+	setupProbeEndpoints(mgr, certsReady, core.NewWarmupChecker(mgr.GetClient(), cCache, queues), sched)
 	// Cert won't be ready until manager starts, so start a goroutine here which
 	// will block until the cert is ready before setting up the controllers.
 	// Controllers who register after manager starts will start directly.
-	go setupControllers(mgr, cCache, queues, certsReady, config.ManageJobsWithoutQueueName)
+	go setupControllers(mgr, cCache, queues, auditor, certsReady, &cfg)
 
 	ctx := ctrl.SetupSignalHandler()
 	go func() {
 		queues.CleanUpOnContext(ctx)
 	}()
-
-	setupScheduler(ctx, mgr, cCache, queues)
+	go func() {
+		cCache.CleanUpOnContext(ctx)
+	}()
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -142,37 +199,57 @@ func main() {
 	}
 }
 
-func setupIndexes(mgr ctrl.Manager) {
+func setupIndexes(mgr ctrl.Manager, cfg *configv1alpha1.Configuration) {
 	if err := queue.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
 		setupLog.Error(err, "Unable to setup queue indexes")
 	}
 	if err := cache.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
 		setupLog.Error(err, "Unable to setup cache indexes")
 	}
-	if err := job.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
-		setupLog.Error(err, "Unable to setup job indexes")
+	if err := core.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "Unable to setup core indexes")
+	}
+	if isFrameworkEnabled(cfg, job.FrameworkName) {
+		if err := job.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
+			setupLog.Error(err, "Unable to setup job indexes")
+		}
 	}
 }
 
-func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, certsReady chan struct{}, manageJobsWithoutQueueName bool) {
+func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, auditor *audit.Auditor, certsReady chan struct{}, cfg *configv1alpha1.Configuration) {
 	// The controllers won't work until the webhooks are operating, and the webhook won't work until the
 	// certs are all in place.
 	setupLog.Info("Waiting for certificate generation to complete")
 	<-certsReady
 	setupLog.Info("Certs ready")
 
-	if failedCtrl, err := core.SetupControllers(mgr, queues, cCache); err != nil {
+	if failedCtrl, err := core.SetupControllers(mgr, queues, cCache,
+		core.WithLocalQueueMetrics(cfg.Metrics.EnableLocalQueueMetrics),
+		core.WithWaitForPodsReady(cfg.WaitForPodsReady),
+		core.WithFinishedWorkloadRetention(finishedWorkloadRetention(cfg)),
+		core.WithStuckWorkloads(cfg.StuckWorkloads),
+		core.WithAuditor(auditor),
+		core.WithRateLimiter(controllerRateLimiter(cfg)),
+		core.WithUpdatesBatchPeriod(updatesBatchPeriod(cfg)),
+	); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", failedCtrl)
 		os.Exit(1)
 	}
-	if err := job.NewReconciler(mgr.GetScheme(),
-		mgr.GetClient(),
-		mgr.GetEventRecorderFor(constants.JobControllerName),
-		job.WithManageJobsWithoutQueueName(manageJobsWithoutQueueName),
-	).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Job")
-		os.Exit(1)
+	if isFrameworkEnabled(cfg, job.FrameworkName) {
+		if err := job.NewReconciler(mgr.GetScheme(),
+			mgr.GetClient(),
+			mgr.GetEventRecorderFor(constants.JobControllerName),
+			job.WithManageJobsWithoutQueueName(cfg.ManageJobsWithoutQueueName),
+			job.WithRateLimiter(controllerRateLimiter(cfg)),
+		).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Job")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Skipping disabled integration", "framework", job.FrameworkName)
 	}
+	// Setting up the Workload webhook also registers the conversion webhook,
+	// which serves the conversions of all the kueue types in the scheme.
 	if err := (&kueuev1alpha1.Workload{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Workload")
 		os.Exit(1)
@@ -180,28 +257,128 @@ func setupControllers(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manag
 	//+kubebuilder:scaffold:builder
 }
 
+// isFrameworkEnabled returns whether the job framework is listed in
+// integrations.frameworks.
+func isFrameworkEnabled(cfg *configv1alpha1.Configuration, name string) bool {
+	for _, framework := range cfg.Integrations.Frameworks {
+		if framework == name {
+			return true
+		}
+	}
+	return false
+}
+
 // setupProbeEndpoints registers the health endpoints
-func setupProbeEndpoints(mgr ctrl.Manager) {
+func setupProbeEndpoints(mgr ctrl.Manager, certsReady <-chan struct{}, warmup *core.WarmupChecker, sched *scheduler.Scheduler) {
 	defer setupLog.Info("Probe endpoints are configured on healthz and readyz")
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddReadyzCheck("readyz", readyzCheck(mgr, certsReady, warmup, sched)); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 }
 
-func setupScheduler(ctx context.Context, mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager) {
+// readyzCheck returns a check that succeeds once the certs are ready, the
+// informers are synced and the cache and queues are warm. In the leader, the
+// scheduler also needs to be started.
+func readyzCheck(mgr ctrl.Manager, certsReady <-chan struct{}, warmup *core.WarmupChecker, sched *scheduler.Scheduler) healthz.Checker {
+	return func(req *http.Request) error {
+		select {
+		case <-certsReady:
+		default:
+			return errors.New("certs are not ready")
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), readyzSyncTimeout)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return errors.New("informers are not synced")
+		}
+		if err := warmup.Check(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-mgr.Elected():
+			if !sched.Started() {
+				return errors.New("scheduler is not started")
+			}
+		default:
+		}
+		return nil
+	}
+}
+
+// setupAuditor adds the auditor to the manager, if the audit is configured.
+// Otherwise, it returns nil.
+func setupAuditor(mgr ctrl.Manager, cCache *cache.Cache, cfg *configv1alpha1.Configuration) *audit.Auditor {
+	if cfg.Audit == nil {
+		return nil
+	}
+	sink, err := audit.NewSink(cfg.Audit)
+	if err != nil {
+		setupLog.Error(err, "Unable to create the audit sink")
+		os.Exit(1)
+	}
+	auditor := audit.New(sink, cCache)
+	if err := mgr.Add(auditor); err != nil {
+		setupLog.Error(err, "Unable to add auditor to manager")
+		os.Exit(1)
+	}
+	return auditor
+}
+
+// setupScheduler adds the scheduler to the manager, which only starts it in the
+// leader replica.
+func setupScheduler(mgr ctrl.Manager, cCache *cache.Cache, queues *queue.Manager, auditor *audit.Auditor, cfg *configv1alpha1.Configuration) *scheduler.Scheduler {
 	sched := scheduler.New(
 		queues,
 		cCache,
 		mgr.GetClient(),
 		mgr.GetEventRecorderFor(constants.ManagerName),
+		scheduler.WithWaitForPodsReady(blockAdmissionForPodsReady(cfg)),
+		scheduler.WithAuditor(auditor),
 	)
-	go sched.Start(ctx)
+	if err := mgr.Add(sched); err != nil {
+		setupLog.Error(err, "Unable to add scheduler to manager")
+		os.Exit(1)
+	}
+	return sched
+}
+
+// finishedWorkloadRetention returns the retention policy of the finished
+// workloads, if any.
+func finishedWorkloadRetention(cfg *configv1alpha1.Configuration) *configv1alpha1.FinishedWorkloadRetention {
+	if cfg.ObjectRetentionPolicies == nil {
+		return nil
+	}
+	return cfg.ObjectRetentionPolicies.FinishedWorkloads
+}
+
+// controllerRateLimiter returns the configuration of the rate limiter of the
+// controllers, if any.
+func controllerRateLimiter(cfg *configv1alpha1.Configuration) *configv1alpha1.ControllerRateLimiter {
+	if cfg.Controller == nil {
+		return nil
+	}
+	return cfg.Controller.RateLimiter
+}
+
+// updatesBatchPeriod returns the time that the Queue and ClusterQueue
+// controllers hold the workload updates for.
+func updatesBatchPeriod(cfg *configv1alpha1.Configuration) time.Duration {
+	if cfg.Controller == nil || cfg.Controller.UpdatesBatchPeriod == nil {
+		return constants.UpdatesBatchPeriod
+	}
+	return cfg.Controller.UpdatesBatchPeriod.Duration
+}
+
+// blockAdmissionForPodsReady returns whether the scheduler should wait for the
+// pods of the admitted workloads to be ready before admitting more workloads.
+func blockAdmissionForPodsReady(cfg *configv1alpha1.Configuration) bool {
+	return cfg.WaitForPodsReady != nil && cfg.WaitForPodsReady.Enable && pointer.BoolDeref(cfg.WaitForPodsReady.BlockAdmission, false)
 }
 
 func encodeConfig(cfg *configv1alpha1.Configuration) (string, error) {
@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+const (
+	// bufferSize is the number of records waiting to be written. When the
+	// sink can't keep up, the new records are dropped.
+	bufferSize = 1000
+	// flushTimeout is how long the pending records are written for after
+	// the manager stops.
+	flushTimeout = 10 * time.Second
+)
+
+// Decision is the kind of an admission decision.
+type Decision string
+
+const (
+	// Admitted means that the scheduler admitted the workload.
+	Admitted Decision = "Admitted"
+	// Evicted means that the admission of the workload was cleared.
+	Evicted Decision = "Evicted"
+)
+
+// Record is an admission decision, with its inputs at decision time.
+type Record struct {
+	Time     time.Time `json:"time"`
+	Decision Decision  `json:"decision"`
+	Workload Reference `json:"workload"`
+	Queue    string    `json:"queue"`
+	// ClusterQueue is the ClusterQueue that admitted the workload.
+	ClusterQueue string `json:"clusterQueue"`
+	Priority     *int32 `json:"priority,omitempty"`
+	// Requests are the total requests of each podSet.
+	Requests map[string]corev1.ResourceList `json:"requests,omitempty"`
+	// Admission holds the flavors assigned to the workload.
+	Admission *kueue.Admission `json:"admission,omitempty"`
+	// Reason and Message explain an eviction.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Inputs are the quota and usage when the decision was made, before
+	// applying it. Not set if the ClusterQueue doesn't exist anymore.
+	Inputs *Inputs `json:"inputs,omitempty"`
+}
+
+// Reference identifies a workload.
+type Reference struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid,omitempty"`
+}
+
+// Inputs are the quota and usage of a ClusterQueue and its cohort.
+type Inputs struct {
+	// Quota is the quota of the ClusterQueue, by resource and flavor.
+	Quota map[corev1.ResourceName]map[string]Quota `json:"quota,omitempty"`
+	// Usage is the usage of the ClusterQueue, by resource and flavor,
+	// including the quota borrowed from the cohort.
+	Usage             map[corev1.ResourceName]map[string]resource.Quantity `json:"usage,omitempty"`
+	AdmittedWorkloads int                                                  `json:"admittedWorkloads"`
+	Cohort            *CohortInputs                                        `json:"cohort,omitempty"`
+}
+
+// Quota is the quota of a resource flavor in a ClusterQueue.
+type Quota struct {
+	Min resource.Quantity  `json:"min"`
+	Max *resource.Quantity `json:"max,omitempty"`
+}
+
+// CohortInputs are the quota and usage of the active ClusterQueues in a
+// cohort.
+type CohortInputs struct {
+	Name string `json:"name"`
+	// Requestable is the sum of the min quota, by resource and flavor.
+	Requestable map[corev1.ResourceName]map[string]resource.Quantity `json:"requestable,omitempty"`
+	Usage       map[corev1.ResourceName]map[string]resource.Quantity `json:"usage,omitempty"`
+}
+
+// NewRecord returns a record of the decision about the workload, with the
+// quota and usage of the snapshot of its ClusterQueue. The snapshot can be
+// nil.
+func NewRecord(decision Decision, info *workload.Info, cq *cache.ClusterQueue) *Record {
+	wl := info.Obj
+	r := &Record{
+		Time:     time.Now(),
+		Decision: decision,
+		Workload: Reference{
+			Namespace: wl.Namespace,
+			Name:      wl.Name,
+			UID:       wl.UID,
+		},
+		Queue:     wl.Spec.QueueName,
+		Priority:  wl.Spec.Priority,
+		Admission: wl.Spec.Admission,
+		Inputs:    newInputs(cq),
+	}
+	if wl.Spec.Admission != nil {
+		r.ClusterQueue = string(wl.Spec.Admission.ClusterQueue)
+	}
+	if len(info.TotalRequests) > 0 {
+		r.Requests = make(map[string]corev1.ResourceList, len(info.TotalRequests))
+		for _, ps := range info.TotalRequests {
+			requests := make(corev1.ResourceList, len(ps.Requests))
+			for rName, v := range ps.Requests {
+				requests[rName] = workload.ResourceQuantity(rName, v)
+			}
+			r.Requests[ps.Name] = requests
+		}
+	}
+	return r
+}
+
+func newInputs(cq *cache.ClusterQueue) *Inputs {
+	if cq == nil {
+		return nil
+	}
+	in := &Inputs{
+		Quota:             make(map[corev1.ResourceName]map[string]Quota, len(cq.RequestableResources)),
+		Usage:             quantities(cq.UsedResources),
+		AdmittedWorkloads: len(cq.Workloads),
+	}
+	for rName, flavors := range cq.RequestableResources {
+		in.Quota[rName] = make(map[string]Quota, len(flavors))
+		for _, f := range flavors {
+			q := Quota{Min: workload.ResourceQuantity(rName, f.Min)}
+			if f.Max != nil {
+				max := workload.ResourceQuantity(rName, *f.Max)
+				q.Max = &max
+			}
+			in.Quota[rName][f.Name] = q
+		}
+	}
+	if cq.Cohort != nil {
+		in.Cohort = &CohortInputs{
+			Name:        cq.Cohort.Name,
+			Requestable: quantities(cq.Cohort.RequestableResources),
+			Usage:       quantities(cq.Cohort.UsedResources),
+		}
+	}
+	return in
+}
+
+func quantities(resources cache.Resources) map[corev1.ResourceName]map[string]resource.Quantity {
+	if len(resources) == 0 {
+		return nil
+	}
+	out := make(map[corev1.ResourceName]map[string]resource.Quantity, len(resources))
+	for rName, flavors := range resources {
+		out[rName] = make(map[string]resource.Quantity, len(flavors))
+		for fName, v := range flavors {
+			out[rName][fName] = workload.ResourceQuantity(rName, v)
+		}
+	}
+	return out
+}
+
+// Auditor writes the records of the admission decisions to a sink. The
+// records are written in the background, so that the decisions don't wait
+// for the sink. A nil Auditor doesn't record anything.
+type Auditor struct {
+	sink    Sink
+	cache   *cache.Cache
+	records chan *Record
+}
+
+var _ manager.Runnable = &Auditor{}
+var _ manager.LeaderElectionRunnable = &Auditor{}
+
+// New returns an Auditor that writes to the sink and reads the quota and
+// usage of the evicted workloads from the cache.
+func New(sink Sink, cache *cache.Cache) *Auditor {
+	return &Auditor{
+		sink:    sink,
+		cache:   cache,
+		records: make(chan *Record, bufferSize),
+	}
+}
+
+// Record queues the record to be written. The record is dropped if the sink
+// can't keep up.
+func (a *Auditor) Record(ctx context.Context, r *Record) {
+	if a == nil || r == nil {
+		return
+	}
+	select {
+	case a.records <- r:
+	default:
+		ctrl.LoggerFrom(ctx).Error(nil, "Dropping audit record, the sink can't keep up",
+			"decision", r.Decision, "workload", klog.KRef(r.Workload.Namespace, r.Workload.Name))
+	}
+}
+
+// EvictionRecord returns a record of the eviction of the admitted workload,
+// with the current quota and usage of its ClusterQueue. It should be called
+// before evicting the workload, and the record written once the eviction
+// succeeds.
+func (a *Auditor) EvictionRecord(wl *kueue.Workload, reason, message string) *Record {
+	if a == nil || wl.Spec.Admission == nil {
+		return nil
+	}
+	cq := a.cache.ClusterQueueSnapshot(string(wl.Spec.Admission.ClusterQueue))
+	var info *workload.Info
+	if cq != nil {
+		if cached := cq.Workloads[workload.Key(wl)]; cached != nil {
+			// Reuse the requests computed by the cache.
+			infoCopy := *cached
+			infoCopy.Obj = wl
+			info = &infoCopy
+		}
+	}
+	if info == nil {
+		info = workload.NewInfo(wl)
+	}
+	r := NewRecord(Evicted, info, cq)
+	r.Reason = reason
+	r.Message = message
+	return r
+}
+
+// Start writes the queued records until the context is done. Then, it
+// writes the pending records for up to flushTimeout and closes the sink.
+func (a *Auditor) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("audit")
+	for {
+		select {
+		case r := <-a.records:
+			a.write(ctx, log, r)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+			for {
+				select {
+				case r := <-a.records:
+					a.write(flushCtx, log, r)
+				default:
+					return a.sink.Close()
+				}
+			}
+		}
+	}
+}
+
+func (a *Auditor) write(ctx context.Context, log logr.Logger, r *Record) {
+	if err := a.sink.Write(ctx, r); err != nil {
+		log.Error(err, "Writing audit record", "decision", r.Decision, "workload", klog.KRef(r.Workload.Namespace, r.Workload.Name))
+	}
+}
+
+// NeedLeaderElection returns false, so that the records queued while the
+// replica loses the leadership are still written.
+func (a *Auditor) NeedLeaderElection() bool {
+	return false
+}
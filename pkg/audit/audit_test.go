@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+type fakeSink struct {
+	sync.Mutex
+	records []*Record
+	closed  bool
+}
+
+func (s *fakeSink) Write(_ context.Context, r *Record) error {
+	s.Lock()
+	defer s.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	return nil
+}
+
+func newTestCache(t *testing.T) *cache.Cache {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cCache := cache.New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cCache.AddOrUpdateResourceFlavor(utiltesting.MakeResourceFlavor("default").Obj())
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("a").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "4").Max("6").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("b").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "2").Obj()).Obj()).
+			Obj(),
+	} {
+		if err := cCache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	return cCache
+}
+
+func TestNewRecord(t *testing.T) {
+	cCache := newTestCache(t)
+	cCache.AddOrUpdateWorkload(utiltesting.MakeWorkload("running", "default").
+		Queue("main").
+		Request(corev1.ResourceCPU, "3").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj())
+	priority := int32(100)
+	wl := utiltesting.MakeWorkload("new", "default").
+		Queue("main").
+		Priority(&priority).
+		Request(corev1.ResourceCPU, "2").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	wl.UID = "new-uid"
+
+	got := NewRecord(Admitted, workload.NewInfo(wl), cCache.Snapshot().ClusterQueues["a"])
+	want := &Record{
+		Decision: Admitted,
+		Workload: Reference{
+			Namespace: "default",
+			Name:      "new",
+			UID:       "new-uid",
+		},
+		Queue:        "main",
+		ClusterQueue: "a",
+		Priority:     &priority,
+		Requests: map[string]corev1.ResourceList{
+			"main": {corev1.ResourceCPU: resource.MustParse("2")},
+		},
+		Admission: utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj(),
+		Inputs: &Inputs{
+			Quota: map[corev1.ResourceName]map[string]Quota{
+				corev1.ResourceCPU: {
+					"default": {Min: resource.MustParse("4"), Max: quantity("6")},
+				},
+			},
+			Usage: map[corev1.ResourceName]map[string]resource.Quantity{
+				corev1.ResourceCPU: {"default": resource.MustParse("3")},
+			},
+			AdmittedWorkloads: 1,
+			Cohort: &CohortInputs{
+				Name: "all",
+				Requestable: map[corev1.ResourceName]map[string]resource.Quantity{
+					corev1.ResourceCPU: {"default": resource.MustParse("6")},
+				},
+				Usage: map[corev1.ResourceName]map[string]resource.Quantity{
+					corev1.ResourceCPU: {"default": resource.MustParse("3")},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Record{}, "Time")); diff != "" {
+		t.Errorf("Unexpected record (-want,+got):\n%s", diff)
+	}
+}
+
+func TestEvictionRecord(t *testing.T) {
+	cCache := newTestCache(t)
+	wl := utiltesting.MakeWorkload("running", "default").
+		Queue("main").
+		Request(corev1.ResourceCPU, "3").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Obj()
+	cCache.AddOrUpdateWorkload(wl)
+
+	var nilAuditor *Auditor
+	if r := nilAuditor.EvictionRecord(wl, workload.EvictedByDeactivation, "The workload is deactivated"); r != nil {
+		t.Errorf("Got record %v from a nil auditor", r)
+	}
+	nilAuditor.Record(context.Background(), &Record{})
+
+	auditor := New(&fakeSink{}, cCache)
+	got := auditor.EvictionRecord(wl, workload.EvictedByDeactivation, "The workload is deactivated")
+	want := &Record{
+		Decision: Evicted,
+		Workload: Reference{
+			Namespace: "default",
+			Name:      "running",
+		},
+		Queue:        "main",
+		ClusterQueue: "a",
+		Requests: map[string]corev1.ResourceList{
+			"main": {corev1.ResourceCPU: resource.MustParse("3")},
+		},
+		Admission: utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj(),
+		Reason:    workload.EvictedByDeactivation,
+		Message:   "The workload is deactivated",
+		Inputs: &Inputs{
+			Quota: map[corev1.ResourceName]map[string]Quota{
+				corev1.ResourceCPU: {
+					"default": {Min: resource.MustParse("4"), Max: quantity("6")},
+				},
+			},
+			Usage: map[corev1.ResourceName]map[string]resource.Quantity{
+				corev1.ResourceCPU: {"default": resource.MustParse("3")},
+			},
+			AdmittedWorkloads: 1,
+			Cohort: &CohortInputs{
+				Name: "all",
+				Requestable: map[corev1.ResourceName]map[string]resource.Quantity{
+					corev1.ResourceCPU: {"default": resource.MustParse("6")},
+				},
+				Usage: map[corev1.ResourceName]map[string]resource.Quantity{
+					corev1.ResourceCPU: {"default": resource.MustParse("3")},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Record{}, "Time")); diff != "" {
+		t.Errorf("Unexpected record (-want,+got):\n%s", diff)
+	}
+
+	unknown := wl.DeepCopy()
+	unknown.Spec.Admission.ClusterQueue = "deleted"
+	if got := auditor.EvictionRecord(unknown, workload.EvictedByDeactivation, ""); got.Inputs != nil {
+		t.Errorf("Got inputs %v for a deleted ClusterQueue, want none", got.Inputs)
+	}
+}
+
+func TestAuditorStart(t *testing.T) {
+	sink := &fakeSink{}
+	auditor := New(sink, nil)
+	for _, name := range []string{"a", "b", "c"} {
+		auditor.Record(context.Background(), &Record{Decision: Admitted, Workload: Reference{Name: name}})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := auditor.Start(ctx); err != nil {
+		t.Fatalf("Running the auditor: %v", err)
+	}
+	var got []string
+	for _, r := range sink.records {
+		got = append(got, r.Workload.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, got); diff != "" {
+		t.Errorf("Unexpected records written (-want,+got):\n%s", diff)
+	}
+	if !sink.closed {
+		t.Error("The sink wasn't closed")
+	}
+}
+
+func quantity(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	records := []*Record{
+		{Decision: Admitted, Workload: Reference{Namespace: "default", Name: "a"}, ClusterQueue: "cq"},
+		{Decision: Evicted, Workload: Reference{Namespace: "default", Name: "a"}, ClusterQueue: "cq", Reason: "InactiveWorkload"},
+	}
+	// The records are appended across restarts.
+	for _, r := range records {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("Opening the sink: %v", err)
+		}
+		if err := sink.Write(context.Background(), r); err != nil {
+			t.Fatalf("Writing record: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Closing the sink: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Opening the audit file: %v", err)
+	}
+	defer f.Close()
+	var got []*Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Decoding line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, &r)
+	}
+	if diff := cmp.Diff(records, got, cmpopts.IgnoreFields(Record{}, "Time")); diff != "" {
+		t.Errorf("Unexpected records in the file (-want,+got):\n%s", diff)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	cases := map[string]struct {
+		status  int
+		wantErr bool
+	}{
+		"accepted": {
+			status: http.StatusNoContent,
+		},
+		"rejected": {
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *Record
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("Got method %s, want POST", r.Method)
+				}
+				got = &Record{}
+				if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+					t.Errorf("Decoding record: %v", err)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			sink := NewWebhookSink(srv.URL)
+			defer sink.Close()
+			want := &Record{Decision: Admitted, Workload: Reference{Namespace: "default", Name: "a"}, ClusterQueue: "cq"}
+			err := sink.Write(context.Background(), want)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Write returned error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Record{}, "Time")); diff != "" {
+				t.Errorf("Unexpected record received (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -26,14 +26,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
-const workloadClusterQueueKey = "spec.admission.clusterQueue"
+// WorkloadClusterQueueKey is the index of the Workloads by the ClusterQueue that
+// admitted them.
+const WorkloadClusterQueueKey = "spec.admission.clusterQueue"
 
 var (
 	errCqNotFound          = errors.New("cluster queue not found")
@@ -49,16 +54,102 @@ type Cache struct {
 	cohorts          map[string]*Cohort
 	assumedWorkloads map[string]string
 	resourceFlavors  map[string]*kueue.ResourceFlavor
+	nodePools        map[string]*NodePool
+	// podsReadyCond is signaled when the admitted workloads change, to wake
+	// up the routines waiting for their pods to be ready.
+	podsReadyCond sync.Cond
+	// workloadInfoOptions are used to compute the requests of the admitted
+	// workloads.
+	workloadInfoOptions []workload.InfoOption
 }
 
-func New(client client.Client) *Cache {
-	return &Cache{
-		client:           client,
-		clusterQueues:    make(map[string]*ClusterQueue),
-		cohorts:          make(map[string]*Cohort),
-		assumedWorkloads: make(map[string]string),
-		resourceFlavors:  make(map[string]*kueue.ResourceFlavor),
+type options struct {
+	workloadInfoOptions []workload.InfoOption
+}
+
+// Option configures the cache.
+type Option func(*options)
+
+// WithExcludedResourcePrefixes sets the prefixes of the resource names that
+// are not accounted in the usage of the ClusterQueues.
+func WithExcludedResourcePrefixes(prefixes []string) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithExcludedResourcePrefixes(prefixes))
+	}
+}
+
+// WithResourceTransformations sets the transformations applied to the
+// requests of the workloads before accounting them in the usage of the
+// ClusterQueues.
+func WithResourceTransformations(transformations []configapi.ResourceTransformation) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithResourceTransformations(transformations))
+	}
+}
+
+func New(client client.Client, opts ...Option) *Cache {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c := &Cache{
+		client:              client,
+		clusterQueues:       make(map[string]*ClusterQueue),
+		cohorts:             make(map[string]*Cohort),
+		assumedWorkloads:    make(map[string]string),
+		resourceFlavors:     make(map[string]*kueue.ResourceFlavor),
+		nodePools:           make(map[string]*NodePool),
+		workloadInfoOptions: options.workloadInfoOptions,
+	}
+	c.podsReadyCond.L = &c.RWMutex
+	return c
+}
+
+// CleanUpOnContext tracks the context. When closed, it wakes routines waiting
+// for the pods of the admitted workloads to be ready. It should be called
+// before doing any calls to WaitForPodsReady.
+func (c *Cache) CleanUpOnContext(ctx context.Context) {
+	<-ctx.Done()
+	c.podsReadyCond.Broadcast()
+}
+
+// WaitForPodsReady blocks until all the admitted workloads have the
+// PodsReady condition or the context terminates.
+func (c *Cache) WaitForPodsReady(ctx context.Context) {
+	c.Lock()
+	defer c.Unlock()
+	log := ctrl.LoggerFrom(ctx)
+	for {
+		if c.podsReadyForAllAdmittedWorkloads() {
+			return
+		}
+		log.V(3).Info("Waiting for the pods of the admitted workloads to be ready")
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			c.podsReadyCond.Wait()
+		}
+	}
+}
+
+// PodsReadyForAllAdmittedWorkloads returns whether all the admitted workloads
+// have the PodsReady condition.
+func (c *Cache) PodsReadyForAllAdmittedWorkloads() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.podsReadyForAllAdmittedWorkloads()
+}
+
+func (c *Cache) podsReadyForAllAdmittedWorkloads() bool {
+	for _, cq := range c.clusterQueues {
+		for _, wl := range cq.Workloads {
+			if !workload.InCondition(wl.Obj, kueue.WorkloadPodsReady) {
+				return false
+			}
+		}
 	}
+	return true
 }
 
 type Resources map[corev1.ResourceName]map[string]int64
@@ -84,7 +175,8 @@ type ClusterQueueStatus int
 
 const (
 	// Pending means the ClusterQueue is accepted but not yet active,
-	// this can be because of a missing ResourceFlavor referenced by the ClusterQueue.
+	// this can be because of a missing ResourceFlavor referenced by the ClusterQueue
+	// or because the ClusterQueue is stopped.
 	// In this state, the ClusterQueue can't admit new workloads and its quota can't be borrowed
 	// by other active ClusterQueues in the cohort.
 	Pending ClusterQueueStatus = iota
@@ -107,6 +199,20 @@ type ClusterQueue struct {
 	// that can be matched against the flavors.
 	LabelKeys map[corev1.ResourceName]sets.String
 	Status    ClusterQueueStatus
+	// QueueingStrategy is the queueing strategy of the workloads of the
+	// ClusterQueue.
+	QueueingStrategy kueue.QueueingStrategy
+	// Stopped is true when the ClusterQueue has a stopPolicy other than None.
+	Stopped bool
+	// MaxWorkloads and MaxPods are the admission limits of the ClusterQueue.
+	// Nil means no limit.
+	MaxWorkloads *int32
+	MaxPods      *int32
+	// MaxNamespaceQuotaPercent is the percentage of the min quota of each
+	// flavor that the workloads of a namespace can use. Nil means no limit.
+	MaxNamespaceQuotaPercent *int32
+	// AdmittedPods is the total count of the pods of the admitted workloads.
+	AdmittedPods int64
 }
 
 // FlavorLimits holds a processed ClusterQueue flavor quota.
@@ -124,6 +230,7 @@ func (c *Cache) newClusterQueue(cq *kueue.ClusterQueue) (*ClusterQueue, error) {
 	if err := cqImpl.update(cq, c.resourceFlavors); err != nil {
 		return nil, err
 	}
+	cqImpl.reportWorkloadMetrics()
 
 	return cqImpl, nil
 }
@@ -133,12 +240,22 @@ func (c *ClusterQueue) Active() bool {
 }
 
 func (c *ClusterQueue) update(in *kueue.ClusterQueue, resourceFlavors map[string]*kueue.ResourceFlavor) error {
+	oldRequestableResources := c.RequestableResources
 	c.RequestableResources = resourceLimitsByName(in.Spec.Resources)
+	c.clearRemovedResourceMetrics(oldRequestableResources)
 	nsSelector, err := metav1.LabelSelectorAsSelector(in.Spec.NamespaceSelector)
 	if err != nil {
 		return err
 	}
 	c.NamespaceSelector = nsSelector
+	c.QueueingStrategy = in.Spec.QueueingStrategy
+	c.Stopped = in.Spec.StopPolicy != nil && *in.Spec.StopPolicy != kueue.None
+	c.MaxWorkloads, c.MaxPods, c.MaxNamespaceQuotaPercent = nil, nil, nil
+	if l := in.Spec.AdmissionLimits; l != nil {
+		c.MaxWorkloads = l.MaxWorkloads
+		c.MaxPods = l.MaxPods
+		c.MaxNamespaceQuotaPercent = l.MaxNamespaceQuotaPercent
+	}
 
 	usedResources := make(Resources, len(in.Spec.Resources))
 	for _, r := range in.Spec.Resources {
@@ -155,13 +272,14 @@ func (c *ClusterQueue) update(in *kueue.ClusterQueue, resourceFlavors map[string
 	}
 	c.UsedResources = usedResources
 	c.UpdateWithFlavors(resourceFlavors)
+	c.reportResourceMetrics()
 	return nil
 }
 
 // UpdateWithFlavors updates a ClusterQueue based on the passed ResourceFlavors set.
 // Exported only for testing.
 func (c *ClusterQueue) UpdateWithFlavors(flavors map[string]*kueue.ResourceFlavor) {
-	if flavorNotFound := c.updateLabelKeys(flavors); flavorNotFound {
+	if flavorNotFound := c.updateLabelKeys(flavors); flavorNotFound || c.Stopped {
 		c.Status = Pending
 		return
 	}
@@ -199,14 +317,15 @@ func (c *ClusterQueue) updateLabelKeys(flavors map[string]*kueue.ResourceFlavor)
 	return flavorNotFound
 }
 
-func (c *ClusterQueue) addWorkload(w *kueue.Workload) error {
+func (c *ClusterQueue) addWorkload(w *kueue.Workload, opts ...workload.InfoOption) error {
 	k := workload.Key(w)
 	if _, exist := c.Workloads[k]; exist {
 		return fmt.Errorf("workload already exists in ClusterQueue")
 	}
-	wi := workload.NewInfo(w)
+	wi := workload.NewInfo(w, opts...)
 	c.Workloads[k] = wi
 	c.updateWorkloadUsage(wi, 1)
+	c.reportWorkloadMetrics()
 	return nil
 }
 
@@ -218,13 +337,125 @@ func (c *ClusterQueue) deleteWorkload(w *kueue.Workload) {
 	}
 	c.updateWorkloadUsage(wi, -1)
 	delete(c.Workloads, k)
+	c.reportWorkloadMetrics()
+}
+
+// reportResourceMetrics reports the nominal quota and usage of each resource
+// flavor.
+func (c *ClusterQueue) reportResourceMetrics() {
+	for rName, flvLimits := range c.RequestableResources {
+		for _, l := range flvLimits {
+			quota := workload.ResourceQuantity(rName, l.Min)
+			metrics.ReportClusterQueueNominalQuota(c.Name, l.Name, string(rName), quota.AsApproximateFloat64())
+		}
+	}
+	c.reportUsageMetrics()
+}
+
+func (c *ClusterQueue) reportUsageMetrics() {
+	for rName, usedFlavors := range c.UsedResources {
+		for flavor, used := range usedFlavors {
+			usage := workload.ResourceQuantity(rName, used)
+			metrics.ReportClusterQueueResourceUsage(c.Name, flavor, string(rName), usage.AsApproximateFloat64())
+		}
+	}
+}
+
+func (c *ClusterQueue) reportWorkloadMetrics() {
+	metrics.ReportAdmittedActiveWorkloads(c.Name, len(c.Workloads))
+	c.reportUsageMetrics()
+}
+
+// clearRemovedResourceMetrics removes the metrics of the resource flavors
+// that are no longer part of the ClusterQueue.
+func (c *ClusterQueue) clearRemovedResourceMetrics(oldResources map[corev1.ResourceName][]FlavorLimits) {
+	for rName, oldLimits := range oldResources {
+		for _, oldLimit := range oldLimits {
+			found := false
+			for _, l := range c.RequestableResources[rName] {
+				if l.Name == oldLimit.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				metrics.ClearClusterQueueResourceMetrics(c.Name, oldLimit.Name, string(rName))
+			}
+		}
+	}
+}
+
+// clearMetrics removes all the metrics of the ClusterQueue.
+func (c *ClusterQueue) clearMetrics() {
+	for rName, flvLimits := range c.RequestableResources {
+		for _, l := range flvLimits {
+			metrics.ClearClusterQueueResourceMetrics(c.Name, l.Name, string(rName))
+		}
+	}
+	metrics.ClearCacheMetrics(c.Name)
 }
 
 func (c *ClusterQueue) updateWorkloadUsage(wi *workload.Info, m int64) {
+	updateUsage(c.UsedResources, wi, m)
+	c.AdmittedPods += int64(wi.PodsCount()) * m
+}
+
+// NamespaceQuota returns the quantity of the flavor that the workloads of a
+// single namespace can use, and whether it's limited.
+func (c *ClusterQueue) NamespaceQuota(flavor *FlavorLimits) (int64, bool) {
+	if c.MaxNamespaceQuotaPercent == nil {
+		return 0, false
+	}
+	return flavor.Min * int64(*c.MaxNamespaceQuotaPercent) / 100, true
+}
+
+// NamespaceUsage returns the resources used by the admitted workloads of the
+// namespace, by resource and flavor.
+func (c *ClusterQueue) NamespaceUsage(ns string) Resources {
+	usage := make(Resources)
+	for _, wi := range c.Workloads {
+		if wi.Obj.Namespace != ns {
+			continue
+		}
+		for _, ps := range wi.TotalRequests {
+			for rName, flavor := range ps.Flavors {
+				if usage[rName] == nil {
+					usage[rName] = make(map[string]int64)
+				}
+				usage[rName][flavor] += ps.Requests[rName]
+			}
+		}
+	}
+	return usage
+}
+
+// ExceededAdmissionLimit returns a message describing the admission limit of
+// the ClusterQueue that would be exceeded by admitting the workload, or an
+// empty string if the workload fits in the limits.
+func (c *ClusterQueue) ExceededAdmissionLimit(wi *workload.Info) string {
+	if c.MaxWorkloads != nil && int64(len(c.Workloads)) >= int64(*c.MaxWorkloads) {
+		return fmt.Sprintf("ClusterQueue %s reached its limit of %d admitted workloads", c.Name, *c.MaxWorkloads)
+	}
+	if c.MaxPods != nil {
+		left := int64(*c.MaxPods) - c.AdmittedPods
+		if left < 0 {
+			left = 0
+		}
+		if pods := int64(wi.PodsCount()); pods > left {
+			return fmt.Sprintf("Workload has %d pods, which exceeds the %d pods left under the limit of ClusterQueue %s",
+				pods, left, c.Name)
+		}
+	}
+	return ""
+}
+
+// updateUsage adds the usage of the workload, multiplied by m, to the flavors
+// tracked in used.
+func updateUsage(used Resources, wi *workload.Info, m int64) {
 	for _, ps := range wi.TotalRequests {
 		for wlRes, wlResFlv := range ps.Flavors {
 			v, wlResExist := ps.Requests[wlRes]
-			cqResFlv, cqResExist := c.UsedResources[wlRes]
+			cqResFlv, cqResExist := used[wlRes]
 			if cqResExist && wlResExist {
 				if _, cqFlvExist := cqResFlv[wlResFlv]; cqFlvExist {
 					cqResFlv[wlResFlv] += v * m
@@ -265,6 +496,14 @@ func (c *Cache) DeleteResourceFlavor(rf *kueue.ResourceFlavor) sets.String {
 	return c.updateClusterQueues()
 }
 
+// ClusterQueueExists returns whether the ClusterQueue was added to the cache.
+func (c *Cache) ClusterQueueExists(name string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	_, exists := c.clusterQueues[name]
+	return exists
+}
+
 func (c *Cache) ClusterQueueActive(name string) bool {
 	c.RLock()
 	defer c.RUnlock()
@@ -292,7 +531,7 @@ func (c *Cache) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) err
 	// On controller restart, an add ClusterQueue event may come after
 	// add workload events, and so here we explicitly list and add existing workloads.
 	var workloads kueue.WorkloadList
-	if err := c.client.List(ctx, &workloads, client.MatchingFields{workloadClusterQueueKey: cq.Name}); err != nil {
+	if err := c.client.List(ctx, &workloads, client.MatchingFields{WorkloadClusterQueueKey: cq.Name}); err != nil {
 		return fmt.Errorf("listing workloads that match the queue: %w", err)
 	}
 	for i, w := range workloads.Items {
@@ -338,11 +577,14 @@ func (c *Cache) DeleteClusterQueue(cq *kueue.ClusterQueue) {
 	}
 	c.deleteClusterQueueFromCohort(cqImpl)
 	delete(c.clusterQueues, cq.Name)
+	cqImpl.clearMetrics()
+	c.podsReadyCond.Broadcast()
 }
 
 func (c *Cache) AddOrUpdateWorkload(w *kueue.Workload) bool {
 	c.Lock()
 	defer c.Unlock()
+	defer c.podsReadyCond.Broadcast()
 	return c.addOrUpdateWorkload(w)
 }
 
@@ -362,12 +604,13 @@ func (c *Cache) addOrUpdateWorkload(w *kueue.Workload) bool {
 		clusterQueue.deleteWorkload(w)
 	}
 
-	return clusterQueue.addWorkload(w) == nil
+	return clusterQueue.addWorkload(w, c.workloadInfoOptions...) == nil
 }
 
 func (c *Cache) UpdateWorkload(oldWl, newWl *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
+	defer c.podsReadyCond.Broadcast()
 	if oldWl.Spec.Admission != nil {
 		cq, ok := c.clusterQueues[string(oldWl.Spec.Admission.ClusterQueue)]
 		if !ok {
@@ -384,12 +627,13 @@ func (c *Cache) UpdateWorkload(oldWl, newWl *kueue.Workload) error {
 	if !ok {
 		return fmt.Errorf("new ClusterQueue doesn't exist")
 	}
-	return cq.addWorkload(newWl)
+	return cq.addWorkload(newWl, c.workloadInfoOptions...)
 }
 
 func (c *Cache) DeleteWorkload(w *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
+	defer c.podsReadyCond.Broadcast()
 	if w.Spec.Admission == nil {
 		return errWorkloadNotAdmitted
 	}
@@ -424,7 +668,7 @@ func (c *Cache) AssumeWorkload(w *kueue.Workload) error {
 		return errCqNotFound
 	}
 
-	if err := cq.addWorkload(w); err != nil {
+	if err := cq.addWorkload(w, c.workloadInfoOptions...); err != nil {
 		return err
 	}
 	c.assumedWorkloads[k] = string(w.Spec.Admission.ClusterQueue)
@@ -434,6 +678,7 @@ func (c *Cache) AssumeWorkload(w *kueue.Workload) error {
 func (c *Cache) ForgetWorkload(w *kueue.Workload) error {
 	c.Lock()
 	defer c.Unlock()
+	defer c.podsReadyCond.Broadcast()
 
 	if _, assumed := c.assumedWorkloads[workload.Key(w)]; !assumed {
 		return fmt.Errorf("the workload is not assumed")
@@ -482,6 +727,42 @@ func (c *Cache) Usage(cqObj *kueue.ClusterQueue) (kueue.UsedResources, int, erro
 	return usage, len(cq.Workloads), nil
 }
 
+// LocalQueueUsage reports the resources used by, and the number of, the
+// workloads submitted to the queue that were admitted by its ClusterQueue.
+// The usage includes every flavor of the ClusterQueue, even if it's zero.
+func (c *Cache) LocalQueueUsage(q *kueue.Queue) (Resources, int, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.clusterQueues[string(q.Spec.ClusterQueue)]
+	if cq == nil {
+		return nil, 0, errCqNotFound
+	}
+
+	usage := make(Resources, len(cq.RequestableResources))
+	for rName, flvLimits := range cq.RequestableResources {
+		usage[rName] = make(map[string]int64, len(flvLimits))
+		for _, l := range flvLimits {
+			usage[rName][l.Name] = 0
+		}
+	}
+	admitted := 0
+	for _, wi := range cq.Workloads {
+		if wi.Obj.Namespace != q.Namespace || wi.Obj.Spec.QueueName != q.Name {
+			continue
+		}
+		admitted++
+		for _, ps := range wi.TotalRequests {
+			for rName, flavor := range ps.Flavors {
+				if used, ok := usage[rName][flavor]; ok {
+					usage[rName][flavor] = used + ps.Requests[rName]
+				}
+			}
+		}
+	}
+	return usage, admitted, nil
+}
+
 func (c *Cache) cleanupAssumedState(w *kueue.Workload) {
 	k := workload.Key(w)
 	assumedCQName, assumed := c.assumedWorkloads[k]
@@ -543,7 +824,7 @@ func resourceLimitsByName(in []kueue.Resource) map[corev1.ResourceName][]FlavorL
 }
 
 func SetupIndexes(indexer client.FieldIndexer) error {
-	return indexer.IndexField(context.Background(), &kueue.Workload{}, workloadClusterQueueKey, func(o client.Object) []string {
+	return indexer.IndexField(context.Background(), &kueue.Workload{}, WorkloadClusterQueueKey, func(o client.Object) []string {
 		wl := o.(*kueue.Workload)
 		if wl.Spec.Admission == nil {
 			return nil
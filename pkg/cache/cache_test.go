@@ -19,10 +19,13 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +35,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/util/pointer"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
@@ -455,6 +459,74 @@ func TestCacheClusterQueueOperations(t *testing.T) {
 				"two": sets.NewString("c", "e"),
 			},
 		},
+		{
+			name: "stop",
+			operation: func(cache *Cache) {
+				setup(cache)
+				hold, none := kueue.Hold, kueue.None
+				cq := initialClusterQueues[3].DeepCopy()
+				cq.Spec.StopPolicy = &hold
+				if err := cache.UpdateClusterQueue(cq); err != nil {
+					t.Fatalf("Failed updating ClusterQueue: %v", err)
+				}
+				cq = initialClusterQueues[2].DeepCopy()
+				cq.Spec.StopPolicy = &none
+				if err := cache.UpdateClusterQueue(cq); err != nil {
+					t.Fatalf("Failed updating ClusterQueue: %v", err)
+				}
+			},
+			wantClusterQueues: map[string]*ClusterQueue{
+				"a": {
+					Name: "a",
+					RequestableResources: map[corev1.ResourceName][]FlavorLimits{
+						corev1.ResourceCPU: {{Name: "default", Min: 10000, Max: pointer.Int64(20000)}},
+					},
+					NamespaceSelector: labels.Nothing(),
+					LabelKeys:         map[corev1.ResourceName]sets.String{corev1.ResourceCPU: sets.NewString("cpuType")},
+					UsedResources:     Resources{corev1.ResourceCPU: {"default": 0}},
+					Status:            Active,
+				},
+				"b": {
+					Name: "b",
+					RequestableResources: map[corev1.ResourceName][]FlavorLimits{
+						corev1.ResourceCPU: {{Name: "default", Min: 15000}},
+					},
+					NamespaceSelector: labels.Nothing(),
+					UsedResources:     Resources{corev1.ResourceCPU: {"default": 0}},
+					LabelKeys:         map[corev1.ResourceName]sets.String{corev1.ResourceCPU: sets.NewString("cpuType")},
+					Status:            Active,
+				},
+				"c": {
+					Name:                 "c",
+					RequestableResources: map[corev1.ResourceName][]FlavorLimits{},
+					NamespaceSelector:    labels.Nothing(),
+					UsedResources:        Resources{},
+					Status:               Active,
+				},
+				"d": {
+					Name:                 "d",
+					RequestableResources: map[corev1.ResourceName][]FlavorLimits{},
+					NamespaceSelector:    labels.Nothing(),
+					UsedResources:        Resources{},
+					Status:               Pending,
+					Stopped:              true,
+				},
+				"e": {
+					Name: "e",
+					RequestableResources: map[corev1.ResourceName][]FlavorLimits{
+						corev1.ResourceCPU: {{Name: "nonexistent-flavor", Min: 15000}},
+					},
+					NamespaceSelector: labels.Nothing(),
+					UsedResources:     Resources{corev1.ResourceCPU: {"nonexistent-flavor": 0}},
+					LabelKeys:         nil,
+					Status:            Pending,
+				},
+			},
+			wantCohorts: map[string]sets.String{
+				"one": sets.NewString("a", "b"),
+				"two": sets.NewString("c", "e"),
+			},
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1085,6 +1157,7 @@ func TestClusterQueueUsage(t *testing.T) {
 		},
 	}
 	cases := map[string]struct {
+		opts              []Option
 		workloads         []kueue.Workload
 		wantUsedResources kueue.UsedResources
 		wantWorkloads     int
@@ -1129,6 +1202,26 @@ func TestClusterQueueUsage(t *testing.T) {
 			},
 			wantWorkloads: 2,
 		},
+		"excluded resource prefixes": {
+			opts:      []Option{WithExcludedResourcePrefixes([]string{"example.com/"})},
+			workloads: workloads[:1],
+			wantUsedResources: kueue.UsedResources{
+				corev1.ResourceCPU: {
+					"default": kueue.Usage{
+						Total: pointer.Quantity(resource.MustParse("8")),
+					},
+				},
+				"example.com/gpu": {
+					"model_a": kueue.Usage{
+						Total: pointer.Quantity(resource.MustParse("0")),
+					},
+					"model_b": kueue.Usage{
+						Total: pointer.Quantity(resource.MustParse("0")),
+					},
+				},
+			},
+			wantWorkloads: 1,
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -1136,7 +1229,7 @@ func TestClusterQueueUsage(t *testing.T) {
 			if err := kueue.AddToScheme(scheme); err != nil {
 				t.Fatalf("Failed adding kueue scheme: %v", err)
 			}
-			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build(), tc.opts...)
 			ctx := context.Background()
 			err := cache.AddClusterQueue(ctx, &cq)
 			if err != nil {
@@ -1161,9 +1254,328 @@ func TestClusterQueueUsage(t *testing.T) {
 	}
 }
 
+func TestLocalQueueUsage(t *testing.T) {
+	cq := utiltesting.MakeClusterQueue("foo").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).
+			Flavor(utiltesting.MakeFlavor("spot", "10").Obj()).
+			Obj()).
+		Obj()
+	admission := func(flavor string) *kueue.Admission {
+		return utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, flavor).Obj()
+	}
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("one", "ns1").Queue("main").Request(corev1.ResourceCPU, "2").Admit(admission("on-demand")).Obj(),
+		utiltesting.MakeWorkload("two", "ns1").Queue("main").Request(corev1.ResourceCPU, "3").Admit(admission("spot")).Obj(),
+		utiltesting.MakeWorkload("three", "ns1").Queue("other").Request(corev1.ResourceCPU, "4").Admit(admission("on-demand")).Obj(),
+		utiltesting.MakeWorkload("four", "ns2").Queue("main").Request(corev1.ResourceCPU, "5").Admit(admission("on-demand")).Obj(),
+	}
+	cases := map[string]struct {
+		queue        *kueue.Queue
+		wantUsage    Resources
+		wantAdmitted int
+		wantErr      error
+	}{
+		"queue with workloads": {
+			queue: utiltesting.MakeQueue("main", "ns1").ClusterQueue("foo").Obj(),
+			wantUsage: Resources{
+				corev1.ResourceCPU: {"on-demand": 2_000, "spot": 3_000},
+			},
+			wantAdmitted: 2,
+		},
+		"queue without workloads": {
+			queue: utiltesting.MakeQueue("empty", "ns1").ClusterQueue("foo").Obj(),
+			wantUsage: Resources{
+				corev1.ResourceCPU: {"on-demand": 0, "spot": 0},
+			},
+		},
+		"missing clusterQueue": {
+			queue:   utiltesting.MakeQueue("main", "ns1").ClusterQueue("bar").Obj(),
+			wantErr: errCqNotFound,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+			if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+				t.Fatalf("Adding ClusterQueue: %v", err)
+			}
+			for _, w := range workloads {
+				if added := cache.AddOrUpdateWorkload(w); !added {
+					t.Fatalf("Workload %s was not added", workload.Key(w))
+				}
+			}
+			usage, admitted, err := cache.LocalQueueUsage(tc.queue)
+			if err != tc.wantErr {
+				t.Fatalf("Got error %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantUsage, usage); diff != "" {
+				t.Errorf("Unexpected usage (-want,+got):\n%s", diff)
+			}
+			if admitted != tc.wantAdmitted {
+				t.Errorf("Got %d admitted workloads, want %d", admitted, tc.wantAdmitted)
+			}
+		})
+	}
+}
+
 func messageOrEmpty(err error) string {
 	if err == nil {
 		return ""
 	}
 	return err.Error()
 }
+
+func TestClusterQueueMetrics(t *testing.T) {
+	cq := utiltesting.MakeClusterQueue("metrics").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("default", "10").Obj()).Obj()).
+		Resource(utiltesting.MakeResource("example.com/gpu").
+			Flavor(utiltesting.MakeFlavor("model_a", "5").Obj()).Obj()).
+		Obj()
+	wl := utiltesting.MakeWorkload("one", "").
+		Request(corev1.ResourceCPU, "500m").
+		Request("example.com/gpu", "2").
+		Admit(utiltesting.MakeAdmission("metrics").
+			Flavor(corev1.ResourceCPU, "default").
+			Flavor("example.com/gpu", "model_a").Obj()).
+		Obj()
+	type resourceMetrics struct {
+		Quota float64
+		Usage float64
+	}
+	wantMetrics := func(t *testing.T, wantAdmitted float64, want map[string]resourceMetrics) {
+		t.Helper()
+		if got := testutil.ToFloat64(metrics.AdmittedActiveWorkloads.WithLabelValues("metrics")); got != wantAdmitted {
+			t.Errorf("Got %v admitted workloads, want %v", got, wantAdmitted)
+		}
+		got := make(map[string]resourceMetrics, len(want))
+		for key := range want {
+			flavor, rName, _ := strings.Cut(key, "/")
+			got[key] = resourceMetrics{
+				Quota: testutil.ToFloat64(metrics.ClusterQueueNominalQuota.WithLabelValues("metrics", flavor, rName)),
+				Usage: testutil.ToFloat64(metrics.ClusterQueueResourceUsage.WithLabelValues("metrics", flavor, rName)),
+			}
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected resource metrics (-want,+got):\n%s", diff)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	wantMetrics(t, 0, map[string]resourceMetrics{
+		"default/cpu":             {Quota: 10},
+		"model_a/example.com/gpu": {Quota: 5},
+	})
+
+	if !cache.AddOrUpdateWorkload(wl) {
+		t.Fatalf("Failed adding workload")
+	}
+	wantMetrics(t, 1, map[string]resourceMetrics{
+		"default/cpu":             {Quota: 10, Usage: 0.5},
+		"model_a/example.com/gpu": {Quota: 5, Usage: 2},
+	})
+
+	if err := cache.DeleteWorkload(wl); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	wantMetrics(t, 0, map[string]resourceMetrics{
+		"default/cpu":             {Quota: 10},
+		"model_a/example.com/gpu": {Quota: 5},
+	})
+}
+
+func TestWaitForPodsReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cq := utiltesting.MakeClusterQueue("foo").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("default", "10").Obj()).
+			Obj()).
+		Obj()
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cache.CleanUpOnContext(ctx)
+	if err := cache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Adding ClusterQueue: %v", err)
+	}
+	wl := utiltesting.MakeWorkload("one", "ns").Request(corev1.ResourceCPU, "1").
+		Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()).Obj()
+	if !cache.AddOrUpdateWorkload(wl) {
+		t.Fatalf("Workload was not added")
+	}
+	if cache.PodsReadyForAllAdmittedWorkloads() {
+		t.Fatalf("Pods ready for all the admitted workloads before the workload has the PodsReady condition")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cache.WaitForPodsReady(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("WaitForPodsReady returned before the workload has the PodsReady condition")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	readyWl := utiltesting.MakeWorkload("one", "ns").Request(corev1.ResourceCPU, "1").
+		Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()).
+		Condition(kueue.WorkloadCondition{Type: kueue.WorkloadPodsReady, Status: corev1.ConditionTrue}).Obj()
+	if err := cache.UpdateWorkload(wl, readyWl); err != nil {
+		t.Fatalf("Updating workload: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("WaitForPodsReady didn't return after the workload got the PodsReady condition")
+	}
+	if !cache.PodsReadyForAllAdmittedWorkloads() {
+		t.Errorf("Pods not ready for all the admitted workloads after the workload got the PodsReady condition")
+	}
+
+	// Canceling the context unblocks the waiting routines.
+	if !cache.AddOrUpdateWorkload(utiltesting.MakeWorkload("two", "ns").Request(corev1.ResourceCPU, "1").
+		Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()).Obj()) {
+		t.Fatalf("Workload was not added")
+	}
+	done = make(chan struct{})
+	go func() {
+		cache.WaitForPodsReady(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("WaitForPodsReady didn't return after canceling the context")
+	}
+}
+
+func TestExceededAdmissionLimit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cq := utiltesting.MakeClusterQueue("foo").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("default", "10").Obj()).Obj()).
+		AdmissionLimits(pointer.Int32(2), pointer.Int32(5)).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	admission := utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "default").Obj()
+	newInfo := func(pods int32) *workload.Info {
+		return workload.NewInfo(utiltesting.MakeWorkload("new", "").PodCount(pods).Request(corev1.ResourceCPU, "1").Obj())
+	}
+	check := func(step string, pods int32, wantPods int64, wantReason string) {
+		t.Helper()
+		cqImpl := cache.clusterQueues["foo"]
+		if cqImpl.AdmittedPods != wantPods {
+			t.Errorf("%s: got %d admitted pods, want %d", step, cqImpl.AdmittedPods, wantPods)
+		}
+		if got := cqImpl.ExceededAdmissionLimit(newInfo(pods)); got != wantReason {
+			t.Errorf("%s: got reason %q, want %q", step, got, wantReason)
+		}
+	}
+
+	a := utiltesting.MakeWorkload("a", "").PodCount(3).Request(corev1.ResourceCPU, "1").Admit(admission).Obj()
+	cache.AddOrUpdateWorkload(a)
+	check("fits", 2, 3, "")
+	check("too many pods", 3, 3, "Workload has 3 pods, which exceeds the 2 pods left under the limit of ClusterQueue foo")
+
+	scaledDown := a.DeepCopy()
+	scaledDown.Spec.PodSets[0].Count = 1
+	if err := cache.UpdateWorkload(a, scaledDown); err != nil {
+		t.Fatalf("Failed updating workload: %v", err)
+	}
+	check("scaled down", 3, 1, "")
+
+	cache.AddOrUpdateWorkload(utiltesting.MakeWorkload("b", "").Request(corev1.ResourceCPU, "1").Admit(admission).Obj())
+	check("too many workloads", 1, 2, "ClusterQueue foo reached its limit of 2 admitted workloads")
+
+	if err := cache.DeleteWorkload(scaledDown); err != nil {
+		t.Fatalf("Failed deleting workload: %v", err)
+	}
+	check("deleted", 4, 1, "")
+}
+
+func TestNamespaceUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cq := utiltesting.MakeClusterQueue("foo").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).
+			Flavor(utiltesting.MakeFlavor("spot", "5").Obj()).Obj()).
+		MaxNamespaceQuotaPercent(50).
+		Obj()
+	if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "sales").Request(corev1.ResourceCPU, "2").
+			Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+		utiltesting.MakeWorkload("b", "sales").Request(corev1.ResourceCPU, "1").
+			Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "spot").Obj()).Obj(),
+		utiltesting.MakeWorkload("c", "sales").Request(corev1.ResourceCPU, "3").
+			Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+		utiltesting.MakeWorkload("d", "eng").Request(corev1.ResourceCPU, "4").
+			Admit(utiltesting.MakeAdmission("foo").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+	}
+	for _, w := range workloads {
+		cache.AddOrUpdateWorkload(w)
+	}
+	cqImpl := cache.clusterQueues["foo"]
+	wantUsage := Resources{
+		corev1.ResourceCPU: {
+			"on-demand": 5_000,
+			"spot":      1_000,
+		},
+	}
+	if diff := cmp.Diff(wantUsage, cqImpl.NamespaceUsage("sales")); diff != "" {
+		t.Errorf("Unexpected usage of namespace sales (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff(Resources{}, cqImpl.NamespaceUsage("finance")); diff != "" {
+		t.Errorf("Unexpected usage of namespace finance (-want,+got):\n%s", diff)
+	}
+
+	gotQuota := make(map[string]int64)
+	for _, f := range cqImpl.RequestableResources[corev1.ResourceCPU] {
+		q, limited := cqImpl.NamespaceQuota(&f)
+		if !limited {
+			t.Errorf("Flavor %s is not limited", f.Name)
+		}
+		gotQuota[f.Name] = q
+	}
+	wantQuota := map[string]int64{
+		"on-demand": 5_000,
+		"spot":      2_500,
+	}
+	if diff := cmp.Diff(wantQuota, gotQuota); diff != "" {
+		t.Errorf("Unexpected namespace quota (-want,+got):\n%s", diff)
+	}
+
+	cqImpl.MaxNamespaceQuotaPercent = nil
+	if _, limited := cqImpl.NamespaceQuota(&cqImpl.RequestableResources[corev1.ResourceCPU][0]); limited {
+		t.Error("Flavor is limited without a namespace limit")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// NodePool is a group of nodes that a provisioner, like Karpenter, creates on
+// demand.
+type NodePool struct {
+	Name string
+	// Labels are the labels of the nodes of the pool.
+	Labels map[string]string
+	// Limits are the maximum resources of the nodes of the pool. The
+	// resources without a limit can be provisioned without bounds.
+	Limits corev1.ResourceList
+	// Resources are the resources of the nodes that the pool already
+	// provisioned, including the nodes for pods not managed by Kueue.
+	Resources corev1.ResourceList
+}
+
+// AddOrUpdateNodePool adds or updates the node pool. Returns the names of the
+// ClusterQueues that use a flavor whose labels match the nodes of the pool.
+func (c *Cache) AddOrUpdateNodePool(np *NodePool) sets.String {
+	c.Lock()
+	defer c.Unlock()
+	c.nodePools[np.Name] = np
+	return c.clusterQueuesForNodePool(np)
+}
+
+// DeleteNodePool deletes the node pool. Returns the names of the
+// ClusterQueues that use a flavor whose labels match the nodes of the pool.
+func (c *Cache) DeleteNodePool(name string) sets.String {
+	c.Lock()
+	defer c.Unlock()
+	np := c.nodePools[name]
+	if np == nil {
+		return nil
+	}
+	delete(c.nodePools, name)
+	return c.clusterQueuesForNodePool(np)
+}
+
+// clusterQueuesForNodePool returns the names of the ClusterQueues that use at
+// least one ResourceFlavor whose labels match the labels of the nodes of the
+// pool.
+func (c *Cache) clusterQueuesForNodePool(np *NodePool) sets.String {
+	matching := sets.NewString()
+	for name, rf := range c.resourceFlavors {
+		if labels.SelectorFromSet(rf.Labels).Matches(labels.Set(np.Labels)) {
+			matching.Insert(name)
+		}
+	}
+	cqs := sets.NewString()
+	for _, cq := range c.clusterQueues {
+		for _, flavors := range cq.RequestableResources {
+			for _, f := range flavors {
+				if matching.Has(f.Name) {
+					cqs.Insert(cq.Name)
+				}
+			}
+		}
+	}
+	return cqs
+}
+
+// provisionableResources returns an estimate of the resources that the node
+// pools can still provision for the admitted workloads, by resource and
+// flavor: the limits of the node pools whose nodes match the labels of the
+// flavor, minus the resources of their nodes or the usage of the flavor by all
+// the ClusterQueues, whichever is higher. The nodes account for the pods not
+// managed by Kueue, and the usage for the admitted workloads whose nodes are
+// not provisioned yet. The flavors without labels or node pools, and the
+// resources that any of the node pools of the flavor doesn't limit, are not
+// included.
+func (c *Cache) provisionableResources() Resources {
+	if len(c.nodePools) == 0 {
+		return nil
+	}
+	provisionable := make(Resources)
+	provisioned := make(Resources)
+	for _, rf := range c.resourceFlavors {
+		if len(rf.Labels) == 0 {
+			continue
+		}
+		limits, resources := flavorCapacity(rf, c.nodePools)
+		for rName, v := range limits {
+			if provisionable[rName] == nil {
+				provisionable[rName] = make(map[string]int64)
+				provisioned[rName] = make(map[string]int64)
+			}
+			provisionable[rName][rf.Name] = v
+			provisioned[rName][rf.Name] = resources[rName]
+		}
+	}
+	used := make(Resources)
+	for _, cq := range c.clusterQueues {
+		for rName, flavors := range cq.UsedResources {
+			for flavor, v := range flavors {
+				if _, ok := provisionable[rName][flavor]; ok {
+					if used[rName] == nil {
+						used[rName] = make(map[string]int64)
+					}
+					used[rName][flavor] += v
+				}
+			}
+		}
+	}
+	for rName, flavors := range provisionable {
+		for flavor, limit := range flavors {
+			taken := provisioned[rName][flavor]
+			if u := used[rName][flavor]; u > taken {
+				taken = u
+			}
+			flavors[flavor] = limit - taken
+		}
+	}
+	return provisionable
+}
+
+// flavorCapacity returns the sums of the limits and of the resources of the
+// node pools whose nodes match the labels of the flavor, for the resources
+// that all of them limit.
+func flavorCapacity(rf *kueue.ResourceFlavor, nodePools map[string]*NodePool) (map[corev1.ResourceName]int64, map[corev1.ResourceName]int64) {
+	selector := labels.SelectorFromSet(rf.Labels)
+	var limits map[corev1.ResourceName]int64
+	resources := make(map[corev1.ResourceName]int64)
+	for _, np := range nodePools {
+		if !selector.Matches(labels.Set(np.Labels)) {
+			continue
+		}
+		if limits == nil {
+			limits = make(map[corev1.ResourceName]int64, len(np.Limits))
+			for rName := range np.Limits {
+				limits[rName] = 0
+			}
+		}
+		for rName, total := range limits {
+			q, limited := np.Limits[rName]
+			if !limited {
+				delete(limits, rName)
+				continue
+			}
+			limits[rName] = total + workload.ResourceValue(rName, q)
+			if q, ok := np.Resources[rName]; ok {
+				resources[rName] += workload.ResourceValue(rName, q)
+			}
+		}
+	}
+	return limits, resources
+}
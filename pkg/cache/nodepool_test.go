@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestProvisionableResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	for _, rf := range []*kueue.ResourceFlavor{
+		utiltesting.MakeResourceFlavor("default").Obj(),
+		utiltesting.MakeResourceFlavor("gpu").Label("pool", "gpu").Obj(),
+		utiltesting.MakeResourceFlavor("spot").Label("capacity-type", "spot").Obj(),
+		utiltesting.MakeResourceFlavor("static").Label("pool", "static").Obj(),
+	} {
+		cache.AddOrUpdateResourceFlavor(rf)
+	}
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("a").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("gpu", "10").Obj()).
+				Flavor(utiltesting.MakeFlavor("spot", "10").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("b").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("gpu", "10").Obj()).Obj()).
+			StopPolicy(kueue.Hold).
+			Obj(),
+		utiltesting.MakeClusterQueue("c").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "10").Obj()).Obj()).
+			Obj(),
+	} {
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	cache.AddOrUpdateWorkload(utiltesting.MakeWorkload("running", "").Request(corev1.ResourceCPU, "2").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "gpu").Obj()).Obj())
+	// The workloads of the stopped ClusterQueues still use the nodes.
+	cache.AddOrUpdateWorkload(utiltesting.MakeWorkload("stopped", "").Request(corev1.ResourceCPU, "1").
+		Admit(utiltesting.MakeAdmission("b").Flavor(corev1.ResourceCPU, "gpu").Obj()).Obj())
+
+	if got := cache.Snapshot().ProvisionableResources; got != nil {
+		t.Errorf("Got provisionable resources %v without node pools", got)
+	}
+
+	nodePools := []*NodePool{
+		{
+			Name:   "gpu-on-demand",
+			Labels: map[string]string{"pool": "gpu", "capacity-type": "on-demand"},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("64Gi"),
+			},
+		},
+		{
+			Name:   "gpu-spot",
+			Labels: map[string]string{"pool": "gpu", "capacity-type": "spot"},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+		},
+	}
+	wantCQs := []sets.String{sets.NewString("a", "b", "c"), sets.NewString("a", "b", "c")}
+	for i, np := range nodePools {
+		if diff := cmp.Diff(wantCQs[i], cache.AddOrUpdateNodePool(np)); diff != "" {
+			t.Errorf("Unexpected ClusterQueues for node pool %s (-want,+got):\n%s", np.Name, diff)
+		}
+	}
+	snapshot := cache.Snapshot()
+	want := Resources{
+		corev1.ResourceCPU: {
+			"gpu":  9_000,
+			"spot": 4_000,
+		},
+	}
+	if diff := cmp.Diff(want, snapshot.ProvisionableResources); diff != "" {
+		t.Errorf("Unexpected provisionable resources (-want,+got):\n%s", diff)
+	}
+
+	info := workload.NewInfo(utiltesting.MakeWorkload("new", "").Request(corev1.ResourceCPU, "3").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "gpu").Obj()).Obj())
+	info.ClusterQueue = "a"
+	snapshot.AddWorkload(info)
+	want[corev1.ResourceCPU]["gpu"] = 6_000
+	if diff := cmp.Diff(want, snapshot.ProvisionableResources); diff != "" {
+		t.Errorf("Unexpected provisionable resources after adding a workload (-want,+got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(sets.NewString("a", "b", "c"), cache.DeleteNodePool("gpu-spot")); diff != "" {
+		t.Errorf("Unexpected ClusterQueues for the deleted node pool (-want,+got):\n%s", diff)
+	}
+	want = Resources{
+		corev1.ResourceCPU: {
+			"gpu": 5_000,
+		},
+		corev1.ResourceMemory: {
+			"gpu": 64 * utiltesting.Gi,
+		},
+	}
+	if diff := cmp.Diff(want, cache.Snapshot().ProvisionableResources); diff != "" {
+		t.Errorf("Unexpected provisionable resources after deleting a node pool (-want,+got):\n%s", diff)
+	}
+
+	// The nodes that the pool provisioned for pods not managed by Kueue
+	// reduce the headroom beyond the usage of the admitted workloads.
+	onDemand := *nodePools[0]
+	onDemand.Resources = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("7"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	cache.AddOrUpdateNodePool(&onDemand)
+	want = Resources{
+		corev1.ResourceCPU: {
+			"gpu": 1_000,
+		},
+		corev1.ResourceMemory: {
+			"gpu": 48 * utiltesting.Gi,
+		},
+	}
+	if diff := cmp.Diff(want, cache.Snapshot().ProvisionableResources); diff != "" {
+		t.Errorf("Unexpected provisionable resources with provisioned nodes (-want,+got):\n%s", diff)
+	}
+}
@@ -27,6 +27,11 @@ type Snapshot struct {
 	ClusterQueues            map[string]*ClusterQueue
 	ResourceFlavors          map[string]*kueue.ResourceFlavor
 	InactiveClusterQueueSets sets.String
+	// ProvisionableResources are the resources that the node pools can still
+	// provision for the flavors whose nodes they create, by resource and
+	// flavor. The resources that are not limited by node pools are not
+	// included.
+	ProvisionableResources Resources
 }
 
 func (c *Cache) Snapshot() Snapshot {
@@ -37,6 +42,7 @@ func (c *Cache) Snapshot() Snapshot {
 		ClusterQueues:            make(map[string]*ClusterQueue, len(c.clusterQueues)),
 		ResourceFlavors:          make(map[string]*kueue.ResourceFlavor, len(c.resourceFlavors)),
 		InactiveClusterQueueSets: sets.NewString(),
+		ProvisionableResources:   c.provisionableResources(),
 	}
 	for _, cq := range c.clusterQueues {
 		if !cq.Active() {
@@ -63,17 +69,63 @@ func (c *Cache) Snapshot() Snapshot {
 	return snap
 }
 
+// ClusterQueueSnapshot returns a snapshot of the ClusterQueue, even if it's
+// inactive, with the usage of the active ClusterQueues in its cohort. Returns
+// nil if the ClusterQueue doesn't exist.
+func (c *Cache) ClusterQueueSnapshot(name string) *ClusterQueue {
+	c.RLock()
+	defer c.RUnlock()
+
+	cq := c.clusterQueues[name]
+	if cq == nil {
+		return nil
+	}
+	cqCopy := cq.snapshot()
+	if cq.Cohort != nil {
+		cohortCopy := newCohort(cq.Cohort.Name, 0)
+		for member := range cq.Cohort.members {
+			if member.Active() {
+				member.accumulateResources(cohortCopy)
+			}
+		}
+		cqCopy.Cohort = cohortCopy
+	}
+	return cqCopy
+}
+
+// AddWorkload adds the usage of the workload, with its assigned flavors, to
+// its ClusterQueue and cohort in the snapshot, so that other workloads can be
+// evaluated as if it was already admitted.
+func (s *Snapshot) AddWorkload(wi *workload.Info) {
+	cq := s.ClusterQueues[wi.ClusterQueue]
+	if cq == nil {
+		return
+	}
+	cq.Workloads[workload.Key(wi.Obj)] = wi
+	updateUsage(cq.UsedResources, wi, 1)
+	cq.AdmittedPods += int64(wi.PodsCount())
+	updateUsage(s.ProvisionableResources, wi, -1)
+	if cq.Cohort != nil {
+		updateUsage(cq.Cohort.UsedResources, wi, 1)
+	}
+}
+
 // Snapshot creates a copy of ClusterQueue that includes references to immutable
 // objects and deep copies of changing ones. A reference to the cohort is not included.
 func (c *ClusterQueue) snapshot() *ClusterQueue {
 	cc := &ClusterQueue{
-		Name:                 c.Name,
-		RequestableResources: c.RequestableResources, // Shallow copy is enough.
-		UsedResources:        make(Resources, len(c.UsedResources)),
-		Workloads:            make(map[string]*workload.Info, len(c.Workloads)),
-		LabelKeys:            c.LabelKeys, // Shallow copy is enough.
-		NamespaceSelector:    c.NamespaceSelector,
-		Status:               c.Status,
+		Name:                     c.Name,
+		RequestableResources:     c.RequestableResources, // Shallow copy is enough.
+		UsedResources:            make(Resources, len(c.UsedResources)),
+		Workloads:                make(map[string]*workload.Info, len(c.Workloads)),
+		LabelKeys:                c.LabelKeys, // Shallow copy is enough.
+		NamespaceSelector:        c.NamespaceSelector,
+		Status:                   c.Status,
+		QueueingStrategy:         c.QueueingStrategy,
+		MaxWorkloads:             c.MaxWorkloads,
+		MaxPods:                  c.MaxPods,
+		MaxNamespaceQuotaPercent: c.MaxNamespaceQuotaPercent,
+		AdmittedPods:             c.AdmittedPods,
 	}
 	for res, flavors := range c.UsedResources {
 		flavorsCopy := make(map[string]int64, len(flavors))
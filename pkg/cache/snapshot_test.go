@@ -318,6 +318,7 @@ func TestSnapshot(t *testing.T) {
 				LabelKeys:         map[corev1.ResourceName]sets.String{corev1.ResourceCPU: {"baz": {}, "foo": {}, "instance": {}}},
 				NamespaceSelector: labels.Nothing(),
 				Status:            Active,
+				AdmittedPods:      5,
 			},
 			"foobar": {
 				Name:   "foobar",
@@ -351,6 +352,7 @@ func TestSnapshot(t *testing.T) {
 				NamespaceSelector: labels.Nothing(),
 				LabelKeys:         map[corev1.ResourceName]sets.String{corev1.ResourceCPU: {"baz": {}, "instance": {}}},
 				Status:            Active,
+				AdmittedPods:      10,
 			},
 			"bar": {
 				Name: "bar",
@@ -390,3 +392,67 @@ func TestSnapshot(t *testing.T) {
 		t.Errorf("Unexpected Snapshot (-want,+got):\n%s", diff)
 	}
 }
+
+func TestClusterQueueSnapshot(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	cache := New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cache.AddOrUpdateResourceFlavor(utiltesting.MakeResourceFlavor("default").Obj())
+	clusterQueues := []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("a").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "4").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("b").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "2").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("stopped").
+			Cohort("all").
+			StopPolicy(kueue.HoldAndDrain).
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "8").Obj()).Obj()).
+			Obj(),
+	}
+	for _, cq := range clusterQueues {
+		if err := cache.AddClusterQueue(context.Background(), cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("in-a", "").
+			Request(corev1.ResourceCPU, "3").
+			Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "default").Obj()).
+			Obj(),
+		utiltesting.MakeWorkload("in-stopped", "").
+			Request(corev1.ResourceCPU, "5").
+			Admit(utiltesting.MakeAdmission("stopped").Flavor(corev1.ResourceCPU, "default").Obj()).
+			Obj(),
+	} {
+		cache.AddOrUpdateWorkload(wl)
+	}
+
+	if got := cache.ClusterQueueSnapshot("missing"); got != nil {
+		t.Errorf("Got snapshot of a missing ClusterQueue: %v", got)
+	}
+	got := cache.ClusterQueueSnapshot("stopped")
+	if got == nil {
+		t.Fatal("Got no snapshot of the stopped ClusterQueue")
+	}
+	if diff := cmp.Diff(Resources{corev1.ResourceCPU: {"default": 5_000}}, got.UsedResources); diff != "" {
+		t.Errorf("Unexpected usage of the ClusterQueue (-want,+got):\n%s", diff)
+	}
+	// The cohort only accounts for the active ClusterQueues.
+	wantCohort := &Cohort{
+		Name:                 "all",
+		RequestableResources: Resources{corev1.ResourceCPU: {"default": 6_000}},
+		UsedResources:        Resources{corev1.ResourceCPU: {"default": 3_000}},
+	}
+	if diff := cmp.Diff(wantCohort, got.Cohort, cmpopts.IgnoreUnexported(Cohort{})); diff != "" {
+		t.Errorf("Unexpected cohort (-want,+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+)
+
+// Load reads the configuration from the given file, or uses the defaults if
+// the file is empty, validates it and returns the options to create the
+// manager together with the configuration.
+func Load(scheme *runtime.Scheme, configFile string) (ctrl.Options, configapi.Configuration, error) {
+	var cfg configapi.Configuration
+	if configFile == "" {
+		scheme.Default(&cfg)
+	} else if err := fromFile(configFile, scheme, &cfg); err != nil {
+		return ctrl.Options{}, cfg, err
+	}
+	if errs := validate(&cfg); len(errs) > 0 {
+		return ctrl.Options{}, cfg, fmt.Errorf("invalid configuration: %w", errs.ToAggregate())
+	}
+	return managerOptions(scheme, &cfg), cfg, nil
+}
+
+// ApplyClientConnection sets the client rate limits of the configuration to
+// the REST config.
+func ApplyClientConnection(kubeConfig *rest.Config, cfg *configapi.Configuration) {
+	kubeConfig.QPS = *cfg.ClientConnection.QPS
+	kubeConfig.Burst = int(*cfg.ClientConnection.Burst)
+}
+
+func fromFile(path string, scheme *runtime.Scheme, cfg *configapi.Configuration) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading the config file: %w", err)
+	}
+	codecs := serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+	// The universal decoder applies the defaults.
+	if err := runtime.DecodeInto(codecs.UniversalDecoder(), content, cfg); err != nil {
+		return fmt.Errorf("decoding the config file: %w", err)
+	}
+	return nil
+}
+
+func managerOptions(scheme *runtime.Scheme, cfg *configapi.Configuration) ctrl.Options {
+	options := ctrl.Options{
+		Scheme:                 scheme,
+		Port:                   *cfg.Webhook.Port,
+		Host:                   cfg.Webhook.Host,
+		CertDir:                cfg.Webhook.CertDir,
+		MetricsBindAddress:     cfg.Metrics.BindAddress,
+		HealthProbeBindAddress: cfg.Health.HealthProbeBindAddress,
+		ReadinessEndpointName:  cfg.Health.ReadinessEndpointName,
+		LivenessEndpointName:   cfg.Health.LivenessEndpointName,
+	}
+	if le := cfg.LeaderElection; le != nil {
+		if le.LeaderElect != nil {
+			options.LeaderElection = *le.LeaderElect
+		}
+		// Releasing the lease when the manager stops lets a standby replica
+		// take over without waiting for the lease to expire.
+		options.LeaderElectionReleaseOnCancel = options.LeaderElection
+		options.LeaderElectionResourceLock = le.ResourceLock
+		options.LeaderElectionNamespace = le.ResourceNamespace
+		options.LeaderElectionID = le.ResourceName
+		// Zero durations are left unset, so that the manager uses its defaults.
+		if le.LeaseDuration.Duration != 0 {
+			options.LeaseDuration = &le.LeaseDuration.Duration
+		}
+		if le.RenewDeadline.Duration != 0 {
+			options.RenewDeadline = &le.RenewDeadline.Duration
+		}
+		if le.RetryPeriod.Duration != 0 {
+			options.RetryPeriod = &le.RetryPeriod.Duration
+		}
+	}
+	if c := cfg.Controller; c != nil {
+		options.Controller = ctrlconfig.ControllerConfigurationSpec{
+			GroupKindConcurrency: c.GroupKindConcurrency,
+		}
+		if c.CacheSyncTimeout != nil {
+			options.Controller.CacheSyncTimeout = &c.CacheSyncTimeout.Duration
+		}
+	}
+	return options
+}
@@ -0,0 +1,577 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	componentconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+)
+
+func TestLoad(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := configapi.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding the config scheme: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), os.FileMode(0600)); err != nil {
+			t.Fatalf("Failed writing %s: %v", name, err)
+		}
+		return path
+	}
+	fullConfig := writeFile("full.yaml", `
+apiVersion: config.kueue.x-k8s.io/v1alpha1
+kind: Configuration
+namespace: kueue-tenant
+health:
+  healthProbeBindAddress: :9081
+metrics:
+  bindAddress: :9080
+  enableLocalQueueMetrics: true
+webhook:
+  port: 9444
+  certDir: /certs
+leaderElection:
+  leaderElect: true
+  leaseDuration: 30s
+controller:
+  groupKindConcurrency:
+    Job.batch: 5
+  cacheSyncTimeout: 3m
+  rateLimiter:
+    maxDelay: 5m
+    qps: 50
+  updatesBatchPeriod: 3s
+internalCertManagement:
+  enable: false
+waitForPodsReady:
+  enable: true
+  recoveryTimeout: 1m
+stuckWorkloads: {}
+integrations:
+  frameworks: []
+resources:
+  excludeResourcePrefixes:
+  - networking.example.com/
+  transformations:
+  - input: nvidia.com/mig-1g.5gb
+    outputs:
+      example.com/gpu-credits: 500m
+clientConnection:
+  qps: 50
+  burst: 100
+objectRetentionPolicies:
+  finishedWorkloads:
+    ttl: 24h
+    maxCount: 100
+manageJobsWithoutQueueName: true
+`)
+	minimalConfig := writeFile("minimal.yaml", `
+apiVersion: config.kueue.x-k8s.io/v1alpha1
+kind: Configuration
+`)
+	unknownField := writeFile("unknown.yaml", `
+apiVersion: config.kueue.x-k8s.io/v1alpha1
+kind: Configuration
+unknown: true
+`)
+	invalidConfig := writeFile("invalid.yaml", `
+apiVersion: config.kueue.x-k8s.io/v1alpha1
+kind: Configuration
+namespace: Invalid_Namespace
+metrics:
+  bindAddress: 8080
+webhook:
+  port: 70000
+controller:
+  groupKindConcurrency:
+    Job.batch: 0
+clientConnection:
+  qps: -1
+`)
+
+	retain := configapi.Retain
+	defaultConfig := configapi.Configuration{
+		Namespace: pointer.String(configapi.DefaultNamespace),
+		ControllerManager: configapi.ControllerManager{
+			Webhook: configapi.ControllerWebhook{
+				Port:    pointer.Int(configapi.DefaultWebhookPort),
+				CertDir: configapi.DefaultWebhookCertDir,
+			},
+			Metrics: configapi.ControllerMetrics{
+				BindAddress: configapi.DefaultMetricsBindAddress,
+			},
+			Health: configapi.ControllerHealth{
+				HealthProbeBindAddress: configapi.DefaultHealthProbeBindAddress,
+				ReadinessEndpointName:  configapi.DefaultReadinessEndpoint,
+				LivenessEndpointName:   configapi.DefaultLivenessEndpoint,
+			},
+		},
+		InternalCertManagement: &configapi.InternalCertManagement{
+			Enable:             pointer.Bool(true),
+			WebhookServiceName: pointer.String(configapi.DefaultWebhookServiceName),
+			WebhookSecretName:  pointer.String(configapi.DefaultWebhookSecretName),
+		},
+		ClientConnection: &configapi.ClientConnection{
+			QPS:   pointer.Float32(configapi.DefaultClientConnectionQPS),
+			Burst: pointer.Int32(configapi.DefaultClientConnectionBurst),
+		},
+		Integrations: &configapi.Integrations{
+			Frameworks: []string{configapi.DefaultJobFrameworkName},
+		},
+	}
+	defaultOptions := ctrl.Options{
+		Port:                   configapi.DefaultWebhookPort,
+		CertDir:                configapi.DefaultWebhookCertDir,
+		MetricsBindAddress:     configapi.DefaultMetricsBindAddress,
+		HealthProbeBindAddress: configapi.DefaultHealthProbeBindAddress,
+		ReadinessEndpointName:  configapi.DefaultReadinessEndpoint,
+		LivenessEndpointName:   configapi.DefaultLivenessEndpoint,
+	}
+
+	leaseDuration := 30 * time.Second
+	cacheSyncTimeout := 3 * time.Minute
+	cases := map[string]struct {
+		configFile  string
+		wantConfig  configapi.Configuration
+		wantOptions ctrl.Options
+		wantErr     bool
+	}{
+		"no config file": {
+			wantConfig:  defaultConfig,
+			wantOptions: defaultOptions,
+		},
+		"minimal config": {
+			configFile: minimalConfig,
+			wantConfig: func() configapi.Configuration {
+				cfg := *defaultConfig.DeepCopy()
+				cfg.TypeMeta = metav1.TypeMeta{APIVersion: configapi.GroupVersion.String(), Kind: "Configuration"}
+				return cfg
+			}(),
+			wantOptions: defaultOptions,
+		},
+		"full config": {
+			configFile: fullConfig,
+			wantConfig: configapi.Configuration{
+				TypeMeta:  metav1.TypeMeta{APIVersion: configapi.GroupVersion.String(), Kind: "Configuration"},
+				Namespace: pointer.String("kueue-tenant"),
+				ControllerManager: configapi.ControllerManager{
+					Webhook: configapi.ControllerWebhook{
+						Port:    pointer.Int(9444),
+						CertDir: "/certs",
+					},
+					LeaderElection: &componentconfigv1alpha1.LeaderElectionConfiguration{
+						LeaderElect:   pointer.Bool(true),
+						LeaseDuration: metav1.Duration{Duration: leaseDuration},
+						ResourceName:  configapi.DefaultLeaderElectionID,
+					},
+					Metrics: configapi.ControllerMetrics{
+						BindAddress:             ":9080",
+						EnableLocalQueueMetrics: true,
+					},
+					Health: configapi.ControllerHealth{
+						HealthProbeBindAddress: ":9081",
+						ReadinessEndpointName:  configapi.DefaultReadinessEndpoint,
+						LivenessEndpointName:   configapi.DefaultLivenessEndpoint,
+					},
+					Controller: &configapi.ControllerConfigurationSpec{
+						GroupKindConcurrency: map[string]int{"Job.batch": 5},
+						CacheSyncTimeout:     &metav1.Duration{Duration: cacheSyncTimeout},
+						RateLimiter: &configapi.ControllerRateLimiter{
+							BaseDelay: &metav1.Duration{Duration: configapi.DefaultRateLimiterBaseDelay},
+							MaxDelay:  &metav1.Duration{Duration: 5 * time.Minute},
+							QPS:       pointer.Float32(50),
+							Burst:     pointer.Int32(configapi.DefaultRateLimiterBurst),
+						},
+						UpdatesBatchPeriod: &metav1.Duration{Duration: 3 * time.Second},
+					},
+				},
+				InternalCertManagement: &configapi.InternalCertManagement{
+					Enable: pointer.Bool(false),
+				},
+				ClientConnection: &configapi.ClientConnection{
+					QPS:   pointer.Float32(50),
+					Burst: pointer.Int32(100),
+				},
+				ManageJobsWithoutQueueName: true,
+				WaitForPodsReady: &configapi.WaitForPodsReady{
+					Enable:          true,
+					BlockAdmission:  pointer.Bool(true),
+					Timeout:         &metav1.Duration{Duration: configapi.DefaultPodsReadyTimeout},
+					RecoveryTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+				StuckWorkloads: &configapi.StuckWorkloads{
+					GracePeriod: &metav1.Duration{Duration: configapi.DefaultStuckWorkloadsGrace},
+				},
+				Integrations: &configapi.Integrations{
+					Frameworks: []string{},
+				},
+				Resources: &configapi.Resources{
+					ExcludeResourcePrefixes: []string{"networking.example.com/"},
+					Transformations: []configapi.ResourceTransformation{
+						{
+							Input:    "nvidia.com/mig-1g.5gb",
+							Strategy: &retain,
+							Outputs: corev1.ResourceList{
+								"example.com/gpu-credits": resource.MustParse("500m"),
+							},
+						},
+					},
+				},
+				ObjectRetentionPolicies: &configapi.ObjectRetentionPolicies{
+					FinishedWorkloads: &configapi.FinishedWorkloadRetention{
+						TTL:      &metav1.Duration{Duration: 24 * time.Hour},
+						MaxCount: pointer.Int32(100),
+					},
+				},
+			},
+			wantOptions: ctrl.Options{
+				Port:                          9444,
+				CertDir:                       "/certs",
+				MetricsBindAddress:            ":9080",
+				HealthProbeBindAddress:        ":9081",
+				ReadinessEndpointName:         configapi.DefaultReadinessEndpoint,
+				LivenessEndpointName:          configapi.DefaultLivenessEndpoint,
+				LeaderElection:                true,
+				LeaderElectionID:              configapi.DefaultLeaderElectionID,
+				LeaderElectionReleaseOnCancel: true,
+				LeaseDuration:                 &leaseDuration,
+				Controller: ctrlconfig.ControllerConfigurationSpec{
+					GroupKindConcurrency: map[string]int{"Job.batch": 5},
+					CacheSyncTimeout:     &cacheSyncTimeout,
+				},
+			},
+		},
+		"unknown field": {
+			configFile: unknownField,
+			wantErr:    true,
+		},
+		"invalid config": {
+			configFile: invalidConfig,
+			wantErr:    true,
+		},
+		"missing file": {
+			configFile: filepath.Join(tmpDir, "missing.yaml"),
+			wantErr:    true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			options, cfg, err := Load(scheme, tc.configFile)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Load returned error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.wantConfig, cfg); diff != "" {
+				t.Errorf("Unexpected config (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantOptions, options, cmpopts.IgnoreFields(ctrl.Options{}, "Scheme", "Logger"), cmpopts.IgnoreUnexported(ctrl.Options{})); diff != "" {
+				t.Errorf("Unexpected options (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	unknownStrategy := configapi.ResourceTransformationStrategy("Unknown")
+	cases := map[string]struct {
+		cfg      configapi.Configuration
+		wantErrs []string
+	}{
+		"defaults": {},
+		"disabled endpoints": {
+			cfg: configapi.Configuration{
+				ControllerManager: configapi.ControllerManager{
+					Metrics:               configapi.ControllerMetrics{BindAddress: "0"},
+					PprofBindAddress:      "0",
+					VisibilityBindAddress: "0",
+				},
+			},
+		},
+		"invalid values": {
+			cfg: configapi.Configuration{
+				Namespace: pointer.String("Invalid_Namespace"),
+				ControllerManager: configapi.ControllerManager{
+					Webhook:               configapi.ControllerWebhook{Port: pointer.Int(70000)},
+					Metrics:               configapi.ControllerMetrics{BindAddress: "8080"},
+					Health:                configapi.ControllerHealth{HealthProbeBindAddress: "localhost"},
+					VisibilityBindAddress: "8083",
+					Controller: &configapi.ControllerConfigurationSpec{
+						GroupKindConcurrency: map[string]int{"Job.batch": 0},
+						RateLimiter: &configapi.ControllerRateLimiter{
+							BaseDelay: &metav1.Duration{Duration: time.Second},
+							MaxDelay:  &metav1.Duration{Duration: time.Millisecond},
+							QPS:       pointer.Float32(0),
+							Burst:     pointer.Int32(0),
+						},
+						UpdatesBatchPeriod: &metav1.Duration{Duration: -time.Second},
+					},
+				},
+				ClientConnection: &configapi.ClientConnection{
+					QPS:   pointer.Float32(-1),
+					Burst: pointer.Int32(-1),
+				},
+			},
+			wantErrs: []string{
+				"namespace",
+				"webhook.port",
+				"metrics.bindAddress",
+				"health.healthProbeBindAddress",
+				"visibilityBindAddress",
+				"controller.groupKindConcurrency[Job.batch]",
+				"controller.updatesBatchPeriod",
+				"controller.rateLimiter.maxDelay",
+				"controller.rateLimiter.qps",
+				"controller.rateLimiter.burst",
+				"clientConnection.qps",
+				"clientConnection.burst",
+			},
+		},
+		"inconsistent leader election durations": {
+			cfg: configapi.Configuration{
+				ControllerManager: configapi.ControllerManager{
+					LeaderElection: &componentconfigv1alpha1.LeaderElectionConfiguration{
+						LeaderElect:   pointer.Bool(true),
+						LeaseDuration: metav1.Duration{Duration: 10 * time.Second},
+						RenewDeadline: metav1.Duration{Duration: 15 * time.Second},
+						RetryPeriod:   metav1.Duration{Duration: 15 * time.Second},
+					},
+				},
+			},
+			wantErrs: []string{
+				"leaderElection.leaseDuration",
+				"leaderElection.renewDeadline",
+			},
+		},
+		"invalid internal cert management names": {
+			cfg: configapi.Configuration{
+				InternalCertManagement: &configapi.InternalCertManagement{
+					WebhookServiceName: pointer.String("webhook.service"),
+					WebhookSecretName:  pointer.String("Secret"),
+				},
+			},
+			wantErrs: []string{
+				"internalCertManagement.webhookServiceName",
+				"internalCertManagement.webhookSecretName",
+			},
+		},
+		"invalid waitForPodsReady timeouts": {
+			cfg: configapi.Configuration{
+				WaitForPodsReady: &configapi.WaitForPodsReady{
+					Enable:          true,
+					Timeout:         &metav1.Duration{},
+					RecoveryTimeout: &metav1.Duration{Duration: -time.Second},
+				},
+			},
+			wantErrs: []string{
+				"waitForPodsReady.timeout",
+				"waitForPodsReady.recoveryTimeout",
+			},
+		},
+		"invalid stuckWorkloads grace period": {
+			cfg: configapi.Configuration{
+				StuckWorkloads: &configapi.StuckWorkloads{
+					GracePeriod: &metav1.Duration{Duration: -time.Minute},
+				},
+			},
+			wantErrs: []string{
+				"stuckWorkloads.gracePeriod",
+			},
+		},
+		"invalid finished workloads retention": {
+			cfg: configapi.Configuration{
+				ObjectRetentionPolicies: &configapi.ObjectRetentionPolicies{
+					FinishedWorkloads: &configapi.FinishedWorkloadRetention{
+						TTL:      &metav1.Duration{Duration: -time.Second},
+						MaxCount: pointer.Int32(-1),
+					},
+				},
+			},
+			wantErrs: []string{
+				"objectRetentionPolicies.finishedWorkloads.ttl",
+				"objectRetentionPolicies.finishedWorkloads.maxCount",
+			},
+		},
+		"audit to a file": {
+			cfg: configapi.Configuration{
+				Audit: &configapi.Audit{File: "/var/log/kueue/audit.log"},
+			},
+		},
+		"audit to a webhook": {
+			cfg: configapi.Configuration{
+				Audit: &configapi.Audit{WebhookURL: "https://audit.example.com/kueue"},
+			},
+		},
+		"audit without sink": {
+			cfg: configapi.Configuration{
+				Audit: &configapi.Audit{},
+			},
+			wantErrs: []string{
+				"audit",
+			},
+		},
+		"audit with two sinks": {
+			cfg: configapi.Configuration{
+				Audit: &configapi.Audit{
+					File:       "/var/log/kueue/audit.log",
+					WebhookURL: "https://audit.example.com/kueue",
+				},
+			},
+			wantErrs: []string{
+				"audit.webhookURL",
+			},
+		},
+		"audit to a relative webhook URL": {
+			cfg: configapi.Configuration{
+				Audit: &configapi.Audit{WebhookURL: "audit.example.com/kueue"},
+			},
+			wantErrs: []string{
+				"audit.webhookURL",
+			},
+		},
+		"tracing to stdout": {
+			cfg: configapi.Configuration{
+				Tracing: &configapi.Tracing{
+					Exporter:               configapi.StdoutTracingExporter,
+					SamplingRatePerMillion: pointer.Int32(1000),
+				},
+			},
+		},
+		"tracing with unsupported exporter and sampling rate": {
+			cfg: configapi.Configuration{
+				Tracing: &configapi.Tracing{
+					Exporter:               "otlp",
+					SamplingRatePerMillion: pointer.Int32(2000000),
+				},
+			},
+			wantErrs: []string{
+				"tracing.exporter",
+				"tracing.samplingRatePerMillion",
+			},
+		},
+		"unsupported and duplicated frameworks": {
+			cfg: configapi.Configuration{
+				Integrations: &configapi.Integrations{
+					Frameworks: []string{"batch/job", "ray.io/rayjob", "batch/job"},
+				},
+			},
+			wantErrs: []string{
+				"integrations.frameworks[1]",
+				"integrations.frameworks[2]",
+			},
+		},
+		"empty excluded resource prefix": {
+			cfg: configapi.Configuration{
+				Resources: &configapi.Resources{
+					ExcludeResourcePrefixes: []string{"networking.example.com/", ""},
+				},
+			},
+			wantErrs: []string{
+				"resources.excludeResourcePrefixes[1]",
+			},
+		},
+		"invalid resource transformations": {
+			cfg: configapi.Configuration{
+				Resources: &configapi.Resources{
+					Transformations: []configapi.ResourceTransformation{
+						{
+							Input: "nvidia.com/mig-1g.5gb",
+							Outputs: corev1.ResourceList{
+								"example.com/gpu-credits": resource.MustParse("-1"),
+							},
+						},
+						{
+							Input:    "nvidia.com/mig-1g.5gb",
+							Strategy: &unknownStrategy,
+						},
+						{
+							Outputs: corev1.ResourceList{
+								"example.com/gpu-credits": resource.MustParse("1"),
+							},
+						},
+					},
+				},
+			},
+			wantErrs: []string{
+				"resources.transformations[0].outputs[example.com/gpu-credits]",
+				"resources.transformations[1].input",
+				"resources.transformations[1].strategy",
+				"resources.transformations[1].outputs",
+				"resources.transformations[2].input",
+			},
+		},
+		"unknown feature gate": {
+			cfg: configapi.Configuration{
+				FeatureGates: map[string]bool{"UnknownFeature": true},
+			},
+			wantErrs: []string{"featureGates"},
+		},
+		"negative leader election duration": {
+			cfg: configapi.Configuration{
+				ControllerManager: configapi.ControllerManager{
+					LeaderElection: &componentconfigv1alpha1.LeaderElectionConfiguration{
+						LeaderElect: pointer.Bool(true),
+						RetryPeriod: metav1.Duration{Duration: -time.Second},
+					},
+				},
+			},
+			wantErrs: []string{"leaderElection.retryPeriod"},
+		},
+		"leader election disabled": {
+			cfg: configapi.Configuration{
+				ControllerManager: configapi.ControllerManager{
+					LeaderElection: &componentconfigv1alpha1.LeaderElectionConfiguration{
+						LeaderElect:   pointer.Bool(false),
+						LeaseDuration: metav1.Duration{Duration: time.Second},
+						RenewDeadline: metav1.Duration{Duration: 2 * time.Second},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			configapi.SetDefaults_Configuration(&tc.cfg)
+			var gotErrs []string
+			for _, err := range validate(&tc.cfg) {
+				gotErrs = append(gotErrs, err.Field)
+			}
+			if diff := cmp.Diff(tc.wantErrs, gotErrs); diff != "" {
+				t.Errorf("Unexpected errors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/controller/workload/job"
+	"sigs.k8s.io/kueue/pkg/features"
+)
+
+// supportedFrameworks are the job frameworks that can be listed in
+// integrations.frameworks.
+var supportedFrameworks = sets.NewString(job.FrameworkName)
+
+var supportedTransformationStrategies = sets.NewString(string(configapi.Retain), string(configapi.Replace))
+
+func validate(cfg *configapi.Configuration) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if cfg.Namespace != nil {
+		for _, msg := range apivalidation.ValidateNamespaceName(*cfg.Namespace, false) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("namespace"), *cfg.Namespace, msg))
+		}
+	}
+
+	webhookPath := field.NewPath("webhook")
+	if cfg.Webhook.Port != nil {
+		for _, msg := range validation.IsValidPortNum(*cfg.Webhook.Port) {
+			allErrs = append(allErrs, field.Invalid(webhookPath.Child("port"), *cfg.Webhook.Port, msg))
+		}
+	}
+
+	allErrs = append(allErrs, validateBindAddress(field.NewPath("metrics", "bindAddress"), cfg.Metrics.BindAddress)...)
+	allErrs = append(allErrs, validateBindAddress(field.NewPath("health", "healthProbeBindAddress"), cfg.Health.HealthProbeBindAddress)...)
+	allErrs = append(allErrs, validateBindAddress(field.NewPath("pprofBindAddress"), cfg.PprofBindAddress)...)
+	allErrs = append(allErrs, validateBindAddress(field.NewPath("visibilityBindAddress"), cfg.VisibilityBindAddress)...)
+
+	allErrs = append(allErrs, validateLeaderElection(cfg)...)
+
+	if c := cfg.Controller; c != nil {
+		concurrencyPath := field.NewPath("controller", "groupKindConcurrency")
+		for gk, n := range c.GroupKindConcurrency {
+			if n <= 0 {
+				allErrs = append(allErrs, field.Invalid(concurrencyPath.Key(gk), n, "must be greater than 0"))
+			}
+		}
+		if c.CacheSyncTimeout != nil && c.CacheSyncTimeout.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("controller", "cacheSyncTimeout"), c.CacheSyncTimeout.Duration.String(), "must not be negative"))
+		}
+		if c.UpdatesBatchPeriod != nil && c.UpdatesBatchPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("controller", "updatesBatchPeriod"), c.UpdatesBatchPeriod.Duration.String(), "must not be negative"))
+		}
+		if rl := c.RateLimiter; rl != nil {
+			rlPath := field.NewPath("controller", "rateLimiter")
+			if rl.BaseDelay != nil && rl.BaseDelay.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(rlPath.Child("baseDelay"), rl.BaseDelay.Duration.String(), "must be greater than 0"))
+			}
+			if rl.MaxDelay != nil && rl.BaseDelay != nil && rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+				allErrs = append(allErrs, field.Invalid(rlPath.Child("maxDelay"), rl.MaxDelay.Duration.String(), "must not be less than baseDelay"))
+			}
+			if rl.QPS != nil && *rl.QPS <= 0 {
+				allErrs = append(allErrs, field.Invalid(rlPath.Child("qps"), *rl.QPS, "must be greater than 0"))
+			}
+			if rl.Burst != nil && *rl.Burst <= 0 {
+				allErrs = append(allErrs, field.Invalid(rlPath.Child("burst"), *rl.Burst, "must be greater than 0"))
+			}
+		}
+	}
+
+	if icm := cfg.InternalCertManagement; icm != nil && icm.Enable != nil && *icm.Enable {
+		icmPath := field.NewPath("internalCertManagement")
+		if icm.WebhookServiceName != nil {
+			for _, msg := range validation.IsDNS1035Label(*icm.WebhookServiceName) {
+				allErrs = append(allErrs, field.Invalid(icmPath.Child("webhookServiceName"), *icm.WebhookServiceName, msg))
+			}
+		}
+		if icm.WebhookSecretName != nil {
+			for _, msg := range validation.IsDNS1123Subdomain(*icm.WebhookSecretName) {
+				allErrs = append(allErrs, field.Invalid(icmPath.Child("webhookSecretName"), *icm.WebhookSecretName, msg))
+			}
+		}
+	}
+
+	if w := cfg.WaitForPodsReady; w != nil && w.Enable {
+		wPath := field.NewPath("waitForPodsReady")
+		if w.Timeout != nil && w.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(wPath.Child("timeout"), w.Timeout.Duration.String(), "must be greater than 0"))
+		}
+		if w.RecoveryTimeout != nil && w.RecoveryTimeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(wPath.Child("recoveryTimeout"), w.RecoveryTimeout.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	if s := cfg.StuckWorkloads; s != nil && s.GracePeriod != nil && s.GracePeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("stuckWorkloads", "gracePeriod"), s.GracePeriod.Duration.String(), "must be greater than 0"))
+	}
+
+	if p := cfg.ObjectRetentionPolicies; p != nil && p.FinishedWorkloads != nil {
+		fwPath := field.NewPath("objectRetentionPolicies", "finishedWorkloads")
+		if ttl := p.FinishedWorkloads.TTL; ttl != nil && ttl.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(fwPath.Child("ttl"), ttl.Duration.String(), "must not be negative"))
+		}
+		if c := p.FinishedWorkloads.MaxCount; c != nil && *c < 0 {
+			allErrs = append(allErrs, field.Invalid(fwPath.Child("maxCount"), *c, "must not be negative"))
+		}
+	}
+
+	if a := cfg.Audit; a != nil {
+		allErrs = append(allErrs, validateAudit(field.NewPath("audit"), a)...)
+	}
+
+	if t := cfg.Tracing; t != nil {
+		tracingPath := field.NewPath("tracing")
+		if t.Exporter != configapi.StdoutTracingExporter {
+			allErrs = append(allErrs, field.NotSupported(tracingPath.Child("exporter"), t.Exporter, []string{string(configapi.StdoutTracingExporter)}))
+		}
+		if r := t.SamplingRatePerMillion; r != nil && (*r < 0 || *r > 1000000) {
+			allErrs = append(allErrs, field.Invalid(tracingPath.Child("samplingRatePerMillion"), *r, "must be between 0 and 1000000"))
+		}
+	}
+
+	if i := cfg.Integrations; i != nil {
+		frameworksPath := field.NewPath("integrations", "frameworks")
+		seen := sets.NewString()
+		for idx, name := range i.Frameworks {
+			path := frameworksPath.Index(idx)
+			if !supportedFrameworks.Has(name) {
+				allErrs = append(allErrs, field.NotSupported(path, name, supportedFrameworks.List()))
+			} else if seen.Has(name) {
+				allErrs = append(allErrs, field.Duplicate(path, name))
+			}
+			seen.Insert(name)
+		}
+	}
+
+	if r := cfg.Resources; r != nil {
+		prefixesPath := field.NewPath("resources", "excludeResourcePrefixes")
+		for idx, prefix := range r.ExcludeResourcePrefixes {
+			if prefix == "" {
+				allErrs = append(allErrs, field.Required(prefixesPath.Index(idx), "must not be empty"))
+			}
+		}
+		allErrs = append(allErrs, validateResourceTransformations(field.NewPath("resources", "transformations"), r.Transformations)...)
+	}
+
+	if len(cfg.FeatureGates) > 0 {
+		// Validate on a copy, so that the gates are only set once the whole
+		// configuration is valid.
+		if err := features.DefaultMutableFeatureGate.DeepCopy().SetFromMap(cfg.FeatureGates); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("featureGates"), cfg.FeatureGates, err.Error()))
+		}
+	}
+
+	if cc := cfg.ClientConnection; cc != nil {
+		ccPath := field.NewPath("clientConnection")
+		if cc.QPS != nil && *cc.QPS < 0 {
+			allErrs = append(allErrs, field.Invalid(ccPath.Child("qps"), *cc.QPS, "must not be negative"))
+		}
+		if cc.Burst != nil && *cc.Burst < 0 {
+			allErrs = append(allErrs, field.Invalid(ccPath.Child("burst"), *cc.Burst, "must not be negative"))
+		}
+	}
+	return allErrs
+}
+
+// validateLeaderElection validates that the set durations are not negative
+// and that the leader renews the lease before it expires and retries acquiring
+// it more often than the renew deadline.
+func validateLeaderElection(cfg *configapi.Configuration) field.ErrorList {
+	le := cfg.LeaderElection
+	if le == nil || le.LeaderElect == nil || !*le.LeaderElect {
+		return nil
+	}
+	var allErrs field.ErrorList
+	lePath := field.NewPath("leaderElection")
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"leaseDuration", le.LeaseDuration.Duration},
+		{"renewDeadline", le.RenewDeadline.Duration},
+		{"retryPeriod", le.RetryPeriod.Duration},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			allErrs = append(allErrs, field.Invalid(lePath.Child(d.name), d.value.String(), "must not be negative"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	if lease, renew := le.LeaseDuration.Duration, le.RenewDeadline.Duration; lease != 0 && renew != 0 && lease <= renew {
+		allErrs = append(allErrs, field.Invalid(lePath.Child("leaseDuration"), lease.String(), "must be greater than renewDeadline"))
+	}
+	if renew, retry := le.RenewDeadline.Duration, le.RetryPeriod.Duration; renew != 0 && retry != 0 && renew <= retry {
+		allErrs = append(allErrs, field.Invalid(lePath.Child("renewDeadline"), renew.String(), "must be greater than retryPeriod"))
+	}
+	return allErrs
+}
+
+// validateAudit validates that exactly one sink is set, and that the webhook
+// URL is an absolute http or https URL.
+func validateAudit(path *field.Path, a *configapi.Audit) field.ErrorList {
+	var allErrs field.ErrorList
+	if a.File == "" && a.WebhookURL == "" {
+		return append(allErrs, field.Required(path, "must set file or webhookURL"))
+	}
+	if a.File != "" && a.WebhookURL != "" {
+		return append(allErrs, field.Forbidden(path.Child("webhookURL"), "must not be set together with file"))
+	}
+	if a.WebhookURL != "" {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("webhookURL"), a.WebhookURL, err.Error()))
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("webhookURL"), a.WebhookURL, "must be an absolute http or https URL"))
+		}
+	}
+	return allErrs
+}
+
+// validateResourceTransformations validates that each input resource is
+// transformed at most once, into a non-empty list of non-negative outputs.
+func validateResourceTransformations(path *field.Path, transformations []configapi.ResourceTransformation) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := sets.NewString()
+	for i, t := range transformations {
+		tPath := path.Index(i)
+		if t.Input == "" {
+			allErrs = append(allErrs, field.Required(tPath.Child("input"), "must not be empty"))
+		} else if seen.Has(string(t.Input)) {
+			allErrs = append(allErrs, field.Duplicate(tPath.Child("input"), t.Input))
+		}
+		seen.Insert(string(t.Input))
+		if t.Strategy != nil && !supportedTransformationStrategies.Has(string(*t.Strategy)) {
+			allErrs = append(allErrs, field.NotSupported(tPath.Child("strategy"), *t.Strategy, supportedTransformationStrategies.List()))
+		}
+		if len(t.Outputs) == 0 {
+			allErrs = append(allErrs, field.Required(tPath.Child("outputs"), "must have at least one output"))
+		}
+		for name, q := range t.Outputs {
+			if q.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(tPath.Child("outputs").Key(string(name)), q.String(), "must not be negative"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateBindAddress validates an address in the form host:port. The
+// values "" and "0" disable the endpoint and are accepted.
+func validateBindAddress(path *field.Path, address string) field.ErrorList {
+	if address == "" || address == "0" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return field.ErrorList{field.Invalid(path, address, err.Error())}
+	}
+	return nil
+}
@@ -24,10 +24,50 @@ const (
 	// TODO(#23): Use the kubernetes.io domain when graduating APIs to beta.
 	QueueAnnotation = "kueue.x-k8s.io/queue-name"
 
+	// OriginalNodeSelectorsAnnotation is the annotation in the job that holds
+	// the nodeSelector that the job had before Kueue injected the nodeSelector
+	// of the assigned flavors. It's used to restore the nodeSelector when the
+	// job is suspended.
+	OriginalNodeSelectorsAnnotation = "kueue.x-k8s.io/original-node-selectors"
+
+	// OriginalParallelismAnnotation is the annotation in the job that holds
+	// the parallelism that the job had before Kueue reduced it to the
+	// admitted count of a partially admitted workload, or of a workload whose
+	// scale up is pending. It's used to restore the parallelism when the job
+	// is suspended or the extra replicas are admitted.
+	OriginalParallelismAnnotation = "kueue.x-k8s.io/original-parallelism"
+
+	// WorkloadSliceOfAnnotation is the annotation in a workload slice that
+	// holds the name of the workload that the slice scales up.
+	WorkloadSliceOfAnnotation = "kueue.x-k8s.io/workload-slice-of"
+
+	// MergedWorkloadSliceAnnotation is the annotation in the workload that
+	// holds the name of the last workload slice merged into it.
+	MergedWorkloadSliceAnnotation = "kueue.x-k8s.io/merged-workload-slice"
+
+	// RequiredFlavorsAnnotation is the annotation in the job that holds the
+	// comma-separated names of the ResourceFlavors that can be assigned to
+	// its pods.
+	RequiredFlavorsAnnotation = "kueue.x-k8s.io/required-flavors"
+
+	// FlavorSelectorAnnotation is the annotation in the job that holds the
+	// label selector of the ResourceFlavors that can be assigned to its pods,
+	// like "hardware=gpu".
+	FlavorSelectorAnnotation = "kueue.x-k8s.io/flavor-selector"
+
+	// WorkloadStatusAnnotation is the annotation in the job that mirrors the
+	// status of its workload: Pending, Admitted, Evicted or Finished.
+	WorkloadStatusAnnotation = "kueue.x-k8s.io/workload-status"
+
+	// WorkloadStatusReasonAnnotation is the annotation in the job that holds
+	// the reason of the last transition of its workload, like
+	// "ClusterQueueStopped".
+	WorkloadStatusReasonAnnotation = "kueue.x-k8s.io/workload-status-reason"
+
 	ManagerName       = "kueue-manager"
 	JobControllerName = "kueue-job-controller"
 
-	// UpdatesBatchPeriod is the batch period to hold workload updates
+	// UpdatesBatchPeriod is the default batch period to hold workload updates
 	// before syncing a Queue and ClusterQueue objects.
 	UpdatesBatchPeriod = time.Second
 
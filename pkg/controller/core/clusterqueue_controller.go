@@ -18,22 +18,31 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
-	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/leader"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const wlUpdateChBuffer = 10
@@ -42,18 +51,26 @@ const wlUpdateChBuffer = 10
 type ClusterQueueReconciler struct {
 	client     client.Client
 	log        logr.Logger
+	recorder   record.EventRecorder
 	qManager   *queue.Manager
 	cache      *cache.Cache
+	auditor    *audit.Auditor
 	wlUpdateCh chan event.GenericEvent
+	// updatesBatchPeriod is the time that the workload updates are held
+	// before updating the status of their ClusterQueue.
+	updatesBatchPeriod time.Duration
 }
 
-func NewClusterQueueReconciler(client client.Client, qMgr *queue.Manager, cache *cache.Cache) *ClusterQueueReconciler {
+func NewClusterQueueReconciler(client client.Client, recorder record.EventRecorder, qMgr *queue.Manager, cache *cache.Cache, auditor *audit.Auditor, updatesBatchPeriod time.Duration) *ClusterQueueReconciler {
 	return &ClusterQueueReconciler{
-		client:     client,
-		log:        ctrl.Log.WithName("cluster-queue-reconciler"),
-		qManager:   qMgr,
-		cache:      cache,
-		wlUpdateCh: make(chan event.GenericEvent, wlUpdateChBuffer),
+		client:             client,
+		log:                ctrl.Log.WithName("cluster-queue-reconciler"),
+		recorder:           recorder,
+		qManager:           qMgr,
+		cache:              cache,
+		auditor:            auditor,
+		wlUpdateCh:         make(chan event.GenericEvent, wlUpdateChBuffer),
+		updatesBatchPeriod: updatesBatchPeriod,
 	}
 }
 
@@ -73,6 +90,13 @@ func (r *ClusterQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling ClusterQueue")
 
+	if cqObj.Spec.StopPolicy != nil && *cqObj.Spec.StopPolicy == kueue.HoldAndDrain {
+		if err := r.drain(ctx, &cqObj); err != nil {
+			log.Error(err, "Failed evicting admitted workloads")
+			return ctrl.Result{}, err
+		}
+	}
+
 	status, err := r.Status(&cqObj)
 	if err != nil {
 		log.Error(err, "Failed getting status from cache")
@@ -88,6 +112,27 @@ func (r *ClusterQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// drain evicts the unfinished workloads admitted by the ClusterQueue.
+func (r *ClusterQueueReconciler) drain(ctx context.Context, cq *kueue.ClusterQueue) error {
+	var workloads kueue.WorkloadList
+	if err := r.client.List(ctx, &workloads, client.MatchingFields{cache.WorkloadClusterQueueKey: cq.Name}); err != nil {
+		return fmt.Errorf("listing admitted workloads: %w", err)
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if wl.Spec.Admission == nil || string(wl.Spec.Admission.ClusterQueue) != cq.Name ||
+			workload.InCondition(wl, kueue.WorkloadFinished) {
+			continue
+		}
+		err := evict(ctx, r.client, r.recorder, r.auditor, wl, workload.EvictedByClusterQueueStopped,
+			fmt.Sprintf("ClusterQueue %s is stopped", cq.Name))
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *ClusterQueueReconciler) NotifyWorkloadUpdate(w *kueue.Workload) {
 	r.wlUpdateCh <- event.GenericEvent{Object: w}
 }
@@ -154,7 +199,8 @@ func (r *ClusterQueueReconciler) Generic(e event.GenericEvent) bool {
 // Since the events come from a channel Source, only the Generic handler will
 // receive events.
 type cqWorkloadHandler struct {
-	qManager *queue.Manager
+	qManager    *queue.Manager
+	batchPeriod time.Duration
 }
 
 func (h *cqWorkloadHandler) Create(event.CreateEvent, workqueue.RateLimitingInterface) {
@@ -170,7 +216,7 @@ func (h *cqWorkloadHandler) Generic(e event.GenericEvent, q workqueue.RateLimiti
 	w := e.Object.(*kueue.Workload)
 	req := h.requestForWorkloadClusterQueue(w)
 	if req != nil {
-		q.AddAfter(*req, constants.UpdatesBatchPeriod)
+		q.AddAfter(*req, h.batchPeriod)
 	}
 }
 
@@ -193,15 +239,18 @@ func (h *cqWorkloadHandler) requestForWorkloadClusterQueue(w *kueue.Workload) *r
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *ClusterQueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *ClusterQueueReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	wHandler := cqWorkloadHandler{
-		qManager: r.qManager,
+		qManager:    r.qManager,
+		batchPeriod: r.updatesBatchPeriod,
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	return ctrl.NewControllerManagedBy(leader.AllReplicas(mgr)).
 		For(&kueue.ClusterQueue{}).
+		Watches(leader.ElectionSource(mgr.Elected(), mgr.GetClient(), &kueue.ClusterQueueList{}), &handler.EnqueueRequestForObject{}).
 		Watches(&source.Channel{Source: r.wlUpdateCh}, &wHandler).
 		WithEventFilter(r).
-		Complete(r)
+		WithOptions(opts).
+		Complete(leader.AwareReconciler(mgr.Elected(), r))
 }
 
 func (r *ClusterQueueReconciler) Status(cq *kueue.ClusterQueue) (kueue.ClusterQueueStatus, error) {
@@ -213,9 +262,39 @@ func (r *ClusterQueueReconciler) Status(cq *kueue.ClusterQueue) (kueue.ClusterQu
 		return kueue.ClusterQueueStatus{}, err
 	}
 
+	conditions := append([]metav1.Condition(nil), cq.Status.Conditions...)
+	meta.SetStatusCondition(&conditions, activeCondition(cq, r.cache.ClusterQueueActive(cq.Name)))
 	return kueue.ClusterQueueStatus{
 		UsedResources:     usage,
 		AdmittedWorkloads: int32(workloads),
 		PendingWorkloads:  r.qManager.Pending(cq),
+		Conditions:        conditions,
 	}, nil
 }
+
+// activeCondition returns the Active condition of the ClusterQueue.
+func activeCondition(cq *kueue.ClusterQueue, active bool) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               kueue.ClusterQueueActive,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cq.Generation,
+		Reason:             "Ready",
+		Message:            "Can admit new workloads",
+	}
+	if active {
+		return cond
+	}
+	cond.Status = metav1.ConditionFalse
+	switch {
+	case cq.Spec.StopPolicy != nil && *cq.Spec.StopPolicy == kueue.HoldAndDrain:
+		cond.Reason = "Stopped"
+		cond.Message = "Can't admit new workloads and admitted workloads are evicted; the stopPolicy is HoldAndDrain"
+	case cq.Spec.StopPolicy != nil && *cq.Spec.StopPolicy != kueue.None:
+		cond.Reason = "Stopped"
+		cond.Message = "Can't admit new workloads; the stopPolicy is Hold"
+	default:
+		cond.Reason = "FlavorNotFound"
+		cond.Message = "Can't admit new workloads; some ResourceFlavors are missing"
+	}
+	return cond
+}
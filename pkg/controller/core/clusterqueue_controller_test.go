@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestActiveCondition(t *testing.T) {
+	cases := map[string]struct {
+		cq         *kueue.ClusterQueue
+		active     bool
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		"active": {
+			cq:         utiltesting.MakeClusterQueue("cq").Obj(),
+			active:     true,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Ready",
+		},
+		"stopped with Hold": {
+			cq:         utiltesting.MakeClusterQueue("cq").StopPolicy(kueue.Hold).Obj(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Stopped",
+		},
+		"stopped with HoldAndDrain": {
+			cq:         utiltesting.MakeClusterQueue("cq").StopPolicy(kueue.HoldAndDrain).Obj(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Stopped",
+		},
+		"missing flavors": {
+			cq:         utiltesting.MakeClusterQueue("cq").StopPolicy(kueue.None).Obj(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "FlavorNotFound",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := activeCondition(tc.cq, tc.active)
+			if diff := cmp.Diff(tc.wantStatus, got.Status); diff != "" {
+				t.Errorf("Unexpected status (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReason, got.Reason); diff != "" {
+				t.Errorf("Unexpected reason (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
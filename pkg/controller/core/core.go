@@ -17,28 +17,127 @@ limitations under the License.
 package core
 
 import (
+	"time"
+
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/ratelimiter"
 )
 
+type options struct {
+	localQueueMetrics  bool
+	waitForPodsReady   *configapi.WaitForPodsReady
+	retention          *configapi.FinishedWorkloadRetention
+	stuckWorkloads     *configapi.StuckWorkloads
+	auditor            *audit.Auditor
+	rateLimiter        *configapi.ControllerRateLimiter
+	updatesBatchPeriod time.Duration
+}
+
+// Option configures the core controllers.
+type Option func(*options)
+
+// WithLocalQueueMetrics indicates if the Queue controller should report the
+// metrics labeled by Queue and namespace.
+func WithLocalQueueMetrics(enabled bool) Option {
+	return func(o *options) {
+		o.localQueueMetrics = enabled
+	}
+}
+
+// WithWaitForPodsReady sets the configuration used by the Workload controller
+// to evict the admitted workloads whose pods don't become ready in time.
+func WithWaitForPodsReady(w *configapi.WaitForPodsReady) Option {
+	return func(o *options) {
+		o.waitForPodsReady = w
+	}
+}
+
+// WithFinishedWorkloadRetention sets the retention policy used by the Workload
+// controller to delete the finished workloads.
+func WithFinishedWorkloadRetention(r *configapi.FinishedWorkloadRetention) Option {
+	return func(o *options) {
+		o.retention = r
+	}
+}
+
+// WithStuckWorkloads sets the configuration used by the Workload controller to
+// evict the admitted workloads whose pods are never created.
+func WithStuckWorkloads(s *configapi.StuckWorkloads) Option {
+	return func(o *options) {
+		o.stuckWorkloads = s
+	}
+}
+
+// WithAuditor sets the auditor that records the evictions.
+func WithAuditor(a *audit.Auditor) Option {
+	return func(o *options) {
+		o.auditor = a
+	}
+}
+
+// WithRateLimiter sets the configuration of the rate limiter of the retries of
+// each controller.
+func WithRateLimiter(rl *configapi.ControllerRateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = rl
+	}
+}
+
+// WithUpdatesBatchPeriod sets the time that the Queue and ClusterQueue
+// controllers hold the workload updates before updating their statuses.
+func WithUpdatesBatchPeriod(d time.Duration) Option {
+	return func(o *options) {
+		o.updatesBatchPeriod = d
+	}
+}
+
 // SetupControllers sets up the core controllers. It returns the name of the
 // controller that failed to create and an error, if any.
-func SetupControllers(mgr ctrl.Manager, qManager *queue.Manager, cc *cache.Cache) (string, error) {
-	qRec := NewQueueReconciler(mgr.GetClient(), qManager)
-	if err := qRec.SetupWithManager(mgr); err != nil {
+// The controllers watch their objects in all the replicas, to keep the cache
+// and the queues warm, but they only reconcile in the leader, which reconciles
+// all the objects once it's elected.
+func SetupControllers(mgr ctrl.Manager, qManager *queue.Manager, cc *cache.Cache, opts ...Option) (string, error) {
+	options := options{
+		updatesBatchPeriod: constants.UpdatesBatchPeriod,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// Each controller needs its own rate limiter, as the rate limiters track
+	// the failures of the objects.
+	ctrlOptions := func() controller.Options {
+		return controller.Options{RateLimiter: ratelimiter.New(options.rateLimiter)}
+	}
+	recorder := mgr.GetEventRecorderFor(constants.ManagerName)
+	qRec := NewQueueReconciler(mgr.GetClient(), recorder, qManager, cc, options.auditor, options.localQueueMetrics, options.updatesBatchPeriod)
+	if err := qRec.SetupWithManager(mgr, ctrlOptions()); err != nil {
 		return "Queue", err
 	}
-	cqRec := NewClusterQueueReconciler(mgr.GetClient(), qManager, cc)
-	if err := cqRec.SetupWithManager(mgr); err != nil {
+	cqRec := NewClusterQueueReconciler(mgr.GetClient(), recorder, qManager, cc, options.auditor, options.updatesBatchPeriod)
+	if err := cqRec.SetupWithManager(mgr, ctrlOptions()); err != nil {
 		return "ClusterQueue", err
 	}
-	if err := NewWorkloadReconciler(mgr.GetClient(), qManager, cc, qRec, cqRec).SetupWithManager(mgr); err != nil {
+	if err := NewWorkloadReconciler(mgr.GetClient(), recorder, qManager, cc, options.waitForPodsReady, options.retention, options.stuckWorkloads, options.auditor, qRec, cqRec).SetupWithManager(mgr, ctrlOptions()); err != nil {
 		return "Workload", err
 	}
-	if err := NewResourceFlavorReconciler(qManager, cc).SetupWithManager(mgr); err != nil {
+	if err := NewResourceFlavorReconciler(qManager, cc).SetupWithManager(mgr, ctrlOptions()); err != nil {
 		return "ResourceFlavor", err
 	}
+	if err := mgr.Add(NewOrphanWorkloadCollector(mgr.GetClient(), mgr.GetAPIReader(), recorder, defaultOrphanCollectionInterval)); err != nil {
+		return "OrphanWorkloadCollector", err
+	}
+	if features.Enabled(features.NodePoolCapacity) {
+		if err := NewNodePoolReconciler(qManager, cc).SetupWithManager(mgr, ctrlOptions()); err != nil {
+			return "NodePool", err
+		}
+	}
 	return "", nil
 }
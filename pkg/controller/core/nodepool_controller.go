@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/leader"
+)
+
+// nodePoolGK is the kind of the Karpenter NodePools. The version is the one
+// that the cluster prefers, as the fields that are read are the same in all
+// of them.
+var nodePoolGK = schema.GroupKind{Group: "karpenter.sh", Kind: "NodePool"}
+
+// nodePoolLabel is the label that Karpenter sets in the nodes of a NodePool.
+const nodePoolLabel = "karpenter.sh/nodepool"
+
+// NodePoolReconciler watches the Karpenter NodePools to limit the resources
+// that can be admitted for the flavors whose nodes they provision, and to
+// requeue the inadmissible workloads when the NodePools change.
+type NodePoolReconciler struct {
+	log      logr.Logger
+	qManager *queue.Manager
+	cache    *cache.Cache
+}
+
+func NewNodePoolReconciler(qMgr *queue.Manager, cache *cache.Cache) *NodePoolReconciler {
+	return &NodePoolReconciler{
+		log:      ctrl.Log.WithName("nodepool-reconciler"),
+		qManager: qMgr,
+		cache:    cache,
+	}
+}
+
+//+kubebuilder:rbac:groups=karpenter.sh,resources=nodepools,verbs=get;list;watch
+
+func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Nothing to do here.
+	return ctrl.Result{}, nil
+}
+
+func (r *NodePoolReconciler) Create(e event.CreateEvent) bool {
+	r.addOrUpdate(e.Object)
+	return false
+}
+
+func (r *NodePoolReconciler) Update(e event.UpdateEvent) bool {
+	r.addOrUpdate(e.ObjectNew)
+	return false
+}
+
+func (r *NodePoolReconciler) Delete(e event.DeleteEvent) bool {
+	log := r.log.WithValues("nodePool", klog.KObj(e.Object))
+	log.V(2).Info("NodePool delete event")
+	r.queueInadmissibleWorkloads(log, r.cache.DeleteNodePool(e.Object.GetName()))
+	return false
+}
+
+func (r *NodePoolReconciler) Generic(e event.GenericEvent) bool {
+	r.log.V(3).Info("Ignore generic event", "obj", klog.KObj(e.Object), "kind", e.Object.GetObjectKind().GroupVersionKind())
+	return false
+}
+
+func (r *NodePoolReconciler) addOrUpdate(obj interface{}) {
+	u, match := obj.(*unstructured.Unstructured)
+	if !match {
+		return
+	}
+	log := r.log.WithValues("nodePool", klog.KObj(u))
+	log.V(2).Info("NodePool event")
+	np, err := nodePoolFromUnstructured(u)
+	if err != nil {
+		log.Error(err, "Parsing NodePool")
+		return
+	}
+	// The inadmissible workloads are requeued on every change. It's cheap,
+	// as the NodePools change rarely.
+	r.queueInadmissibleWorkloads(log, r.cache.AddOrUpdateNodePool(np))
+}
+
+func (r *NodePoolReconciler) queueInadmissibleWorkloads(log logr.Logger, cqNames sets.String) {
+	if len(cqNames) > 0 {
+		log.V(2).Info("Queueing inadmissible workloads after a NodePool change", "clusterQueues", cqNames.List())
+		r.qManager.QueueInadmissibleWorkloads(cqNames)
+	}
+}
+
+// nodePoolFromUnstructured returns the labels, limits and provisioned
+// resources of the nodes of a Karpenter NodePool.
+func nodePoolFromUnstructured(u *unstructured.Unstructured) (*cache.NodePool, error) {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, err
+	}
+	var obj struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Labels map[string]string `json:"labels"`
+				} `json:"metadata"`
+			} `json:"template"`
+			Limits corev1.ResourceList `json:"limits"`
+		} `json:"spec"`
+		Status struct {
+			Resources corev1.ResourceList `json:"resources"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("decoding the spec: %w", err)
+	}
+	np := &cache.NodePool{
+		Name:      u.GetName(),
+		Labels:    make(map[string]string, len(obj.Spec.Template.Metadata.Labels)+1),
+		Limits:    obj.Spec.Limits,
+		Resources: obj.Status.Resources,
+	}
+	for k, v := range obj.Spec.Template.Metadata.Labels {
+		np.Labels[k] = v
+	}
+	np.Labels[nodePoolLabel] = np.Name
+	return np, nil
+}
+
+// SetupWithManager sets up the controller with the Manager, if the NodePool
+// API is installed in the cluster.
+func (r *NodePoolReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	mapping, err := mgr.GetRESTMapper().RESTMapping(nodePoolGK)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			r.log.Info("The NodePool API is not installed, skipping", "kind", nodePoolGK)
+			return nil
+		}
+		return err
+	}
+	gvk := mapping.GroupVersionKind
+	r.log.V(2).Info("Watching NodePools", "version", gvk.Version)
+	nodePool := &unstructured.Unstructured{}
+	nodePool.SetGroupVersionKind(gvk)
+	nodePools := &unstructured.UnstructuredList{}
+	nodePools.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	return ctrl.NewControllerManagedBy(leader.AllReplicas(mgr)).
+		For(nodePool).
+		Watches(leader.ElectionSource(mgr.Elected(), mgr.GetClient(), nodePools), &handler.EnqueueRequestForObject{}).
+		WithEventFilter(r).
+		WithOptions(opts).
+		Complete(leader.AwareReconciler(mgr.Elected(), r))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kueue/pkg/cache"
+)
+
+func TestNodePoolFromUnstructured(t *testing.T) {
+	cases := map[string]struct {
+		obj     map[string]interface{}
+		want    *cache.NodePool
+		wantErr bool
+	}{
+		"labels, limits and resources": {
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"labels": map[string]interface{}{"hardware": "gpu"},
+						},
+					},
+					"limits": map[string]interface{}{
+						"cpu":    int64(1000),
+						"memory": "1000Gi",
+					},
+				},
+				"status": map[string]interface{}{
+					"resources": map[string]interface{}{
+						"cpu":    "16",
+						"memory": "64Gi",
+					},
+				},
+			},
+			want: &cache.NodePool{
+				Name: "gpu",
+				Labels: map[string]string{
+					"hardware":              "gpu",
+					"karpenter.sh/nodepool": "gpu",
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1000"),
+					corev1.ResourceMemory: resource.MustParse("1000Gi"),
+				},
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("16"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+				},
+			},
+		},
+		"no limits": {
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{},
+			},
+			want: &cache.NodePool{
+				Name:   "gpu",
+				Labels: map[string]string{"karpenter.sh/nodepool": "gpu"},
+			},
+		},
+		"invalid limit": {
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"limits": map[string]interface{}{"cpu": "a lot"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: tc.obj}
+			u.SetGroupVersionKind(nodePoolGK.WithVersion("v1"))
+			u.SetName("gpu")
+			got, err := nodePoolFromUnstructured(u)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("nodePoolFromUnstructured() returned error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected NodePool (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+// defaultOrphanCollectionInterval is how often the workloads are checked for
+// owners that no longer exist.
+const defaultOrphanCollectionInterval = 5 * time.Minute
+
+// OrphanWorkloadCollector periodically deletes the workloads whose controller
+// owner no longer exists. Usually, the garbage collector or the controller of
+// the owner deletes them along with their owner, but the workloads are left
+// behind if the garbage collector is not running and the controller missed the
+// deletion of the owner. Deleting an admitted workload releases its quota.
+type OrphanWorkloadCollector struct {
+	log      logr.Logger
+	client   client.Client
+	recorder record.EventRecorder
+	// ownerReader reads the owners directly from the API server, so that no
+	// informers are started for their kinds.
+	ownerReader client.Reader
+	interval    time.Duration
+}
+
+func NewOrphanWorkloadCollector(client client.Client, ownerReader client.Reader, recorder record.EventRecorder, interval time.Duration) *OrphanWorkloadCollector {
+	return &OrphanWorkloadCollector{
+		log:         ctrl.Log.WithName("orphan-workload-collector"),
+		client:      client,
+		recorder:    recorder,
+		ownerReader: ownerReader,
+		interval:    interval,
+	}
+}
+
+// Start runs the collection until the context is done. It only runs in the
+// leader.
+func (c *OrphanWorkloadCollector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, c.collect, c.interval)
+	return nil
+}
+
+func (c *OrphanWorkloadCollector) collect(ctx context.Context) {
+	var workloads kueue.WorkloadList
+	if err := c.client.List(ctx, &workloads); err != nil {
+		c.log.Error(err, "Listing workloads")
+		return
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if !wl.DeletionTimestamp.IsZero() {
+			continue
+		}
+		orphan, err := c.orphaned(ctx, wl)
+		if err != nil {
+			c.log.V(2).Info("Unable to check the owner of the workload", "workload", klog.KObj(wl), "error", err)
+			continue
+		}
+		if !orphan {
+			continue
+		}
+		if err := c.client.Delete(ctx, wl); client.IgnoreNotFound(err) != nil {
+			c.log.Error(err, "Deleting orphaned workload", "workload", klog.KObj(wl))
+			continue
+		}
+		ref := metav1.GetControllerOf(wl)
+		c.log.V(2).Info("Deleted orphaned workload", "workload", klog.KObj(wl), "owner", ref.Name)
+		c.recorder.Eventf(wl, corev1.EventTypeNormal, "DeletedOrphan",
+			"Deleted workload because its owner %s %s no longer exists", ref.Kind, ref.Name)
+	}
+}
+
+// orphaned returns whether the controller owner of the workload no longer
+// exists. An owner with the same name but a different UID is a different
+// object, so the workload is orphaned as well.
+func (c *OrphanWorkloadCollector) orphaned(ctx context.Context, wl *kueue.Workload) (bool, error) {
+	ref := metav1.GetControllerOf(wl)
+	if ref == nil {
+		return false, nil
+	}
+	var owner metav1.PartialObjectMetadata
+	owner.APIVersion = ref.APIVersion
+	owner.Kind = ref.Kind
+	err := c.ownerReader.Get(ctx, client.ObjectKey{Namespace: wl.Namespace, Name: ref.Name}, &owner)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner.UID != ref.UID, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestOrphanWorkloadCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding batch scheme: %v", err)
+	}
+	jobGVK := batchv1.SchemeGroupVersion.WithKind("Job")
+	job := utiltesting.MakeJob("job", "default").Obj()
+	job.UID = "job-uid"
+	recreated := utiltesting.MakeJob("recreated", "default").Obj()
+	recreated.UID = "new-uid"
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		job,
+		recreated,
+		utiltesting.MakeWorkload("no-owner", "default").Obj(),
+		utiltesting.MakeWorkload("owned", "default").ControllerOwner(jobGVK, "job", "job-uid").Obj(),
+		utiltesting.MakeWorkload("owner-deleted", "default").ControllerOwner(jobGVK, "deleted", "deleted-uid").Obj(),
+		utiltesting.MakeWorkload("owner-recreated", "default").ControllerOwner(jobGVK, "recreated", "old-uid").Obj(),
+	).Build()
+	ctx := context.Background()
+	collector := NewOrphanWorkloadCollector(cl, cl, record.NewFakeRecorder(10), time.Minute)
+
+	collector.collect(ctx)
+
+	var workloads kueue.WorkloadList
+	if err := cl.List(ctx, &workloads); err != nil {
+		t.Fatalf("Failed listing workloads: %v", err)
+	}
+	var got []string
+	for _, wl := range workloads.Items {
+		got = append(got, wl.Name)
+	}
+	want := []string{"no-owner", "owned"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected remaining workloads (-want,+got):\n%s", diff)
+	}
+}
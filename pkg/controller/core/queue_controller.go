@@ -18,40 +18,79 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
-	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/audit"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/leader"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 // QueueReconciler reconciles a Queue object
 type QueueReconciler struct {
 	client     client.Client
 	log        logr.Logger
+	recorder   record.EventRecorder
 	queues     *queue.Manager
+	cache      *cache.Cache
+	auditor    *audit.Auditor
 	wlUpdateCh chan event.GenericEvent
+	// reportMetrics enables the metrics labeled by Queue and namespace.
+	reportMetrics bool
+	// usageMetricsLock protects usageMetrics.
+	usageMetricsLock sync.Mutex
+	// usageMetrics holds the flavors and resources of the usage metrics
+	// reported for each queue, so that they can be removed when the queue
+	// no longer uses them, even if its ClusterQueue changed or was deleted.
+	usageMetrics map[string]map[usageMetricLabels]struct{}
+	// updatesBatchPeriod is the time that the workload updates are held
+	// before updating the status of their Queue.
+	updatesBatchPeriod time.Duration
 }
 
-func NewQueueReconciler(client client.Client, queues *queue.Manager) *QueueReconciler {
+func NewQueueReconciler(client client.Client, recorder record.EventRecorder, queues *queue.Manager, cache *cache.Cache, auditor *audit.Auditor, reportMetrics bool, updatesBatchPeriod time.Duration) *QueueReconciler {
 	return &QueueReconciler{
-		log:        ctrl.Log.WithName("queue-reconciler"),
-		queues:     queues,
-		client:     client,
-		wlUpdateCh: make(chan event.GenericEvent, wlUpdateChBuffer),
+		log:                ctrl.Log.WithName("queue-reconciler"),
+		recorder:           recorder,
+		queues:             queues,
+		cache:              cache,
+		auditor:            auditor,
+		client:             client,
+		wlUpdateCh:         make(chan event.GenericEvent, wlUpdateChBuffer),
+		reportMetrics:      reportMetrics,
+		usageMetrics:       make(map[string]map[usageMetricLabels]struct{}),
+		updatesBatchPeriod: updatesBatchPeriod,
 	}
 }
 
+// usageMetricLabels are the labels, besides the queue, of a usage metric.
+type usageMetricLabels struct {
+	flavor   string
+	resource string
+}
+
 func (r *QueueReconciler) NotifyWorkloadUpdate(w *kueue.Workload) {
 	r.wlUpdateCh <- event.GenericEvent{Object: w}
 }
@@ -71,6 +110,13 @@ func (r *QueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling Queue")
 
+	if queueObj.Spec.StopPolicy != nil && *queueObj.Spec.StopPolicy == kueue.HoldAndDrain {
+		if err := r.drain(ctx, &queueObj); err != nil {
+			log.Error(err, "Failed evicting admitted workloads")
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Shallow copy enough for now.
 	oldStatus := queueObj.Status
 
@@ -81,6 +127,12 @@ func (r *QueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	queueObj.Status.PendingWorkloads = pending
+	queueObj.Status.UsedResources, queueObj.Status.AdmittedWorkloads = r.usage(&queueObj)
+	queueObj.Status.Conditions = append([]metav1.Condition(nil), oldStatus.Conditions...)
+	meta.SetStatusCondition(&queueObj.Status.Conditions, queueActiveCondition(&queueObj))
+	if r.reportMetrics {
+		r.reportQueueMetrics(&queueObj)
+	}
 	if !equality.Semantic.DeepEqual(oldStatus, queueObj.Status) {
 		err := r.client.Status().Update(ctx, &queueObj)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
@@ -88,6 +140,110 @@ func (r *QueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	return ctrl.Result{}, nil
 }
 
+// queueActiveCondition returns the Active condition of the queue.
+func queueActiveCondition(q *kueue.Queue) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               kueue.QueueActive,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: q.Generation,
+		Reason:             "Ready",
+		Message:            "Can submit new workloads to the clusterQueue",
+	}
+	if q.Spec.StopPolicy == nil || *q.Spec.StopPolicy == kueue.None {
+		return cond
+	}
+	cond.Status = metav1.ConditionFalse
+	cond.Reason = "Stopped"
+	if *q.Spec.StopPolicy == kueue.HoldAndDrain {
+		cond.Message = "Can't submit new workloads and admitted workloads are evicted; the stopPolicy is HoldAndDrain"
+	} else {
+		cond.Message = "Can't submit new workloads; the stopPolicy is Hold"
+	}
+	return cond
+}
+
+// usage returns the resources used by the admitted workloads submitted to
+// the queue and their number. There is no usage if the ClusterQueue doesn't
+// exist.
+func (r *QueueReconciler) usage(q *kueue.Queue) (kueue.UsedResources, int32) {
+	usage, admitted, err := r.cache.LocalQueueUsage(q)
+	if err != nil {
+		return nil, 0
+	}
+	used := make(kueue.UsedResources, len(usage))
+	for rName, flavors := range usage {
+		used[rName] = make(map[string]kueue.Usage, len(flavors))
+		for flavor, v := range flavors {
+			used[rName][flavor] = kueue.Usage{
+				Total: pointer.Quantity(workload.ResourceQuantity(rName, v)),
+			}
+		}
+	}
+	return used, int32(admitted)
+}
+
+// reportQueueMetrics reports the number of pending and admitted workloads of
+// the queue and the resources used by the admitted ones. The usage metrics
+// of the flavors and resources that the queue no longer uses are removed.
+func (r *QueueReconciler) reportQueueMetrics(q *kueue.Queue) {
+	// If the ClusterQueue doesn't exist, no workload is admitted and there is
+	// no usage to report.
+	usage, admitted, _ := r.cache.LocalQueueUsage(q)
+	metrics.ReportLocalQueueWorkloads(q.Name, q.Namespace, int(q.Status.PendingWorkloads), admitted)
+
+	r.usageMetricsLock.Lock()
+	defer r.usageMetricsLock.Unlock()
+	reported := make(map[usageMetricLabels]struct{})
+	for rName, flavors := range usage {
+		for flavor, used := range flavors {
+			quantity := workload.ResourceQuantity(rName, used)
+			metrics.ReportLocalQueueResourceUsage(q.Name, q.Namespace, flavor, string(rName), quantity.AsApproximateFloat64())
+			reported[usageMetricLabels{flavor: flavor, resource: string(rName)}] = struct{}{}
+		}
+	}
+	key := queue.Key(q)
+	for l := range r.usageMetrics[key] {
+		if _, ok := reported[l]; !ok {
+			metrics.ClearLocalQueueResourceUsage(q.Name, q.Namespace, l.flavor, l.resource)
+		}
+	}
+	r.usageMetrics[key] = reported
+}
+
+// clearQueueMetrics removes the metrics of the queue, including the usage
+// metrics of all the flavors and resources that were reported for it.
+func (r *QueueReconciler) clearQueueMetrics(q *kueue.Queue) {
+	metrics.ClearLocalQueueWorkloads(q.Name, q.Namespace)
+
+	r.usageMetricsLock.Lock()
+	defer r.usageMetricsLock.Unlock()
+	key := queue.Key(q)
+	for l := range r.usageMetrics[key] {
+		metrics.ClearLocalQueueResourceUsage(q.Name, q.Namespace, l.flavor, l.resource)
+	}
+	delete(r.usageMetrics, key)
+}
+
+// drain evicts the unfinished admitted workloads that were submitted to the queue.
+func (r *QueueReconciler) drain(ctx context.Context, q *kueue.Queue) error {
+	var workloads kueue.WorkloadList
+	if err := r.client.List(ctx, &workloads, client.MatchingFields{queue.WorkloadQueueKey: q.Name}, client.InNamespace(q.Namespace)); err != nil {
+		return fmt.Errorf("listing workloads in the queue: %w", err)
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if wl.Spec.QueueName != q.Name || wl.Spec.Admission == nil || workload.InCondition(wl, kueue.WorkloadFinished) {
+			continue
+		}
+		err := evict(ctx, r.client, r.recorder, r.auditor, wl, workload.EvictedByQueueStopped,
+			fmt.Sprintf("Queue %s is stopped", q.Name))
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *QueueReconciler) Create(e event.CreateEvent) bool {
 	q, match := e.Object.(*kueue.Queue)
 	if !match {
@@ -111,6 +267,9 @@ func (r *QueueReconciler) Delete(e event.DeleteEvent) bool {
 	}
 	r.log.V(2).Info("Queue delete event", "queue", klog.KObj(q))
 	r.queues.DeleteQueue(q)
+	if r.reportMetrics {
+		r.clearQueueMetrics(q)
+	}
 	return true
 }
 
@@ -137,7 +296,9 @@ func (r *QueueReconciler) Generic(e event.GenericEvent) bool {
 // to the workload in the event.
 // Since the events come from a channel Source, only the Generic handler will
 // receive events.
-type qWorkloadHandler struct{}
+type qWorkloadHandler struct {
+	batchPeriod time.Duration
+}
 
 func (h *qWorkloadHandler) Create(event.CreateEvent, workqueue.RateLimitingInterface) {
 }
@@ -159,14 +320,16 @@ func (h *qWorkloadHandler) Generic(e event.GenericEvent, q workqueue.RateLimitin
 			Namespace: w.Namespace,
 		},
 	}
-	q.AddAfter(req, constants.UpdatesBatchPeriod)
+	q.AddAfter(req, h.batchPeriod)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *QueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+func (r *QueueReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(leader.AllReplicas(mgr)).
 		For(&kueue.Queue{}).
-		Watches(&source.Channel{Source: r.wlUpdateCh}, &qWorkloadHandler{}).
+		Watches(leader.ElectionSource(mgr.Elected(), mgr.GetClient(), &kueue.QueueList{}), &handler.EnqueueRequestForObject{}).
+		Watches(&source.Channel{Source: r.wlUpdateCh}, &qWorkloadHandler{batchPeriod: r.updatesBatchPeriod}).
 		WithEventFilter(r).
-		Complete(r)
+		WithOptions(opts).
+		Complete(leader.AwareReconciler(mgr.Elected(), r))
 }
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/metrics"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestQueueActiveCondition(t *testing.T) {
+	cases := map[string]struct {
+		queue      *kueue.Queue
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		"no stop policy": {
+			queue:      utiltesting.MakeQueue("q", "ns").Obj(),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Ready",
+		},
+		"stop policy None": {
+			queue:      utiltesting.MakeQueue("q", "ns").StopPolicy(kueue.None).Obj(),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Ready",
+		},
+		"stopped with Hold": {
+			queue:      utiltesting.MakeQueue("q", "ns").StopPolicy(kueue.Hold).Obj(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Stopped",
+		},
+		"stopped with HoldAndDrain": {
+			queue:      utiltesting.MakeQueue("q", "ns").StopPolicy(kueue.HoldAndDrain).Obj(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Stopped",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := queueActiveCondition(tc.queue)
+			if diff := cmp.Diff(tc.wantStatus, got.Status); diff != "" {
+				t.Errorf("Unexpected status (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReason, got.Reason); diff != "" {
+				t.Errorf("Unexpected reason (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueueUsageMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	ctx := context.Background()
+	cCache := cache.New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	r := NewQueueReconciler(nil, nil, nil, cCache, nil, true, 0)
+	q := utiltesting.MakeQueue("metrics", "default").ClusterQueue("cq").Obj()
+	cq := utiltesting.MakeClusterQueue("cq").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).Obj()).
+		Obj()
+	if err := cCache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	otherCQ := utiltesting.MakeClusterQueue("other-cq").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("spot", "10").Obj()).Obj()).
+		Obj()
+	if err := cCache.AddClusterQueue(ctx, otherCQ); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+
+	r.reportQueueMetrics(q)
+	if got := testutil.CollectAndCount(metrics.LocalQueueResourceUsage); got != 1 {
+		t.Errorf("Got %d usage series after reporting, want 1", got)
+	}
+
+	// The series of the flavors of the previous ClusterQueue are removed.
+	q.Spec.ClusterQueue = "other-cq"
+	r.reportQueueMetrics(q)
+	if got := testutil.CollectAndCount(metrics.LocalQueueResourceUsage); got != 1 {
+		t.Errorf("Got %d usage series after changing the ClusterQueue, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.LocalQueueResourceUsage.WithLabelValues("metrics", "default", "spot", "cpu")); got != 0 {
+		t.Errorf("Got usage %v for the new flavor, want 0", got)
+	}
+
+	// The series are removed even if the ClusterQueue no longer exists.
+	cCache.DeleteClusterQueue(otherCQ)
+	r.clearQueueMetrics(q)
+	if got := testutil.CollectAndCount(metrics.LocalQueueResourceUsage); got != 0 {
+		t.Errorf("Got %d usage series after clearing, want 0", got)
+	}
+}
+
+func TestQueueUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cCache := cache.New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	r := NewQueueReconciler(nil, nil, nil, cCache, nil, false, 0)
+	q := utiltesting.MakeQueue("main", "default").ClusterQueue("cq").Obj()
+
+	used, admitted := r.usage(q)
+	if used != nil || admitted != 0 {
+		t.Errorf("Got usage %v and %d admitted workloads without a ClusterQueue, want none", used, admitted)
+	}
+
+	cq := utiltesting.MakeClusterQueue("cq").
+		Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+			Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).
+			Flavor(utiltesting.MakeFlavor("spot", "10").Obj()).Obj()).
+		Obj()
+	if err := cCache.AddClusterQueue(context.Background(), cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue: %v", err)
+	}
+	for _, w := range []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "default").Queue("main").Request(corev1.ResourceCPU, "2").
+			Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+		utiltesting.MakeWorkload("b", "default").Queue("main").Request(corev1.ResourceCPU, "500m").
+			Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj()).Obj(),
+		utiltesting.MakeWorkload("c", "default").Queue("other").Request(corev1.ResourceCPU, "1").
+			Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "spot").Obj()).Obj(),
+	} {
+		cCache.AddOrUpdateWorkload(w)
+	}
+	used, admitted = r.usage(q)
+	wantUsed := kueue.UsedResources{
+		corev1.ResourceCPU: {
+			"on-demand": {Total: pointer.Quantity(resource.MustParse("2500m"))},
+			"spot":      {Total: pointer.Quantity(resource.MustParse("0"))},
+		},
+	}
+	if diff := cmp.Diff(wantUsed, used); diff != "" {
+		t.Errorf("Unexpected usage (-want,+got):\n%s", diff)
+	}
+	if admitted != 2 {
+		t.Errorf("Got %d admitted workloads, want 2", admitted)
+	}
+}
@@ -22,11 +22,14 @@ import (
 	"github.com/go-logr/logr"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/leader"
 )
 
 // ResourceFlavorReconciler reconciles a ResourceFlavor object
@@ -108,9 +111,11 @@ func (r *ResourceFlavorReconciler) Generic(e event.GenericEvent) bool {
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *ResourceFlavorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+func (r *ResourceFlavorReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(leader.AllReplicas(mgr)).
 		For(&kueue.ResourceFlavor{}).
+		Watches(leader.ElectionSource(mgr.Elected(), mgr.GetClient(), &kueue.ResourceFlavorList{}), &handler.EnqueueRequestForObject{}).
 		WithEventFilter(r).
-		Complete(r)
+		WithOptions(opts).
+		Complete(leader.AwareReconciler(mgr.Elected(), r))
 }
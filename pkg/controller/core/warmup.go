@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+)
+
+// WarmupChecker checks whether the cache and the queue manager were populated
+// with the ClusterQueues and Queues that exist in the cluster. Adding a
+// ClusterQueue to the cache also adds its admitted workloads, so a warm cache
+// has the usage of all the ClusterQueues.
+type WarmupChecker struct {
+	client   client.Reader
+	cache    *cache.Cache
+	qManager *queue.Manager
+	// warm is set to 1 once the check succeeds, so that creating new objects
+	// doesn't make the check fail while they are processed.
+	warm int32
+}
+
+// NewWarmupChecker returns a WarmupChecker that lists the objects with the
+// given reader, usually the manager's client, backed by the informers.
+func NewWarmupChecker(c client.Reader, cc *cache.Cache, qManager *queue.Manager) *WarmupChecker {
+	return &WarmupChecker{
+		client:   c,
+		cache:    cc,
+		qManager: qManager,
+	}
+}
+
+// Check returns an error describing the first object that is missing from
+// the cache or the queue manager, if any. Once it succeeds, it keeps
+// succeeding.
+func (w *WarmupChecker) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&w.warm) == 1 {
+		return nil
+	}
+	var cqs kueue.ClusterQueueList
+	if err := w.client.List(ctx, &cqs); err != nil {
+		return fmt.Errorf("listing ClusterQueues: %w", err)
+	}
+	for _, cq := range cqs.Items {
+		if !w.cache.ClusterQueueExists(cq.Name) {
+			return fmt.Errorf("ClusterQueue %s is not in the cache yet", cq.Name)
+		}
+		if !w.qManager.ClusterQueueExists(cq.Name) {
+			return fmt.Errorf("ClusterQueue %s is not in the queue manager yet", cq.Name)
+		}
+	}
+	var queues kueue.QueueList
+	if err := w.client.List(ctx, &queues); err != nil {
+		return fmt.Errorf("listing Queues: %w", err)
+	}
+	for i := range queues.Items {
+		q := &queues.Items[i]
+		if !w.qManager.QueueExists(q) {
+			return fmt.Errorf("Queue %s is not in the queue manager yet", klog.KObj(q))
+		}
+	}
+	atomic.StoreInt32(&w.warm, 1)
+	return nil
+}
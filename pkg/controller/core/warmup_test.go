@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestWarmupChecker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	q := utiltesting.MakeQueue("main", "default").ClusterQueue("cq").Obj()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cq, q).Build()
+	ctx := context.Background()
+	cCache := cache.New(cl)
+	qManager := queue.NewManager(cl, cCache)
+	checker := NewWarmupChecker(cl, cCache, qManager)
+
+	if err := checker.Check(ctx); err == nil {
+		t.Error("Check succeeded with an empty cache")
+	}
+	if err := cCache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue to the cache: %v", err)
+	}
+	if err := qManager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue to the queue manager: %v", err)
+	}
+	if err := checker.Check(ctx); err == nil {
+		t.Error("Check succeeded without the Queue in the queue manager")
+	}
+	if err := qManager.AddQueue(ctx, q); err != nil {
+		t.Fatalf("Failed adding Queue to the queue manager: %v", err)
+	}
+	if err := checker.Check(ctx); err != nil {
+		t.Errorf("Check failed with a warm cache: %v", err)
+	}
+
+	// New objects don't make the check fail after the cache is warm.
+	if err := cl.Create(ctx, utiltesting.MakeClusterQueue("new").Obj()); err != nil {
+		t.Fatalf("Failed creating ClusterQueue: %v", err)
+	}
+	if err := checker.Check(ctx); err != nil {
+		t.Errorf("Check failed after creating a ClusterQueue: %v", err)
+	}
+}
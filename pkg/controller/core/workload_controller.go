@@ -19,19 +19,28 @@ package core
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
-	nodev1 "k8s.io/api/node/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/util/leader"
+	"sigs.k8s.io/kueue/pkg/util/tracing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -42,6 +51,9 @@ const (
 	finished = "finished"
 )
 
+// WorkloadFinishedKey is the index of the finished Workloads.
+const WorkloadFinishedKey = "status.finished"
+
 type WorkloadUpdateWatcher interface {
 	NotifyWorkloadUpdate(*kueue.Workload)
 }
@@ -49,19 +61,36 @@ type WorkloadUpdateWatcher interface {
 // WorkloadReconciler reconciles a Workload object
 type WorkloadReconciler struct {
 	log      logr.Logger
+	recorder record.EventRecorder
 	queues   *queue.Manager
 	cache    *cache.Cache
 	client   client.Client
+	auditor  *audit.Auditor
 	watchers []WorkloadUpdateWatcher
+	// podsReady is the waitForPodsReady configuration. The admitted
+	// workloads are only evicted for not having their pods ready when it is
+	// enabled.
+	podsReady *configapi.WaitForPodsReady
+	// retention is the retention policy of the finished workloads. They are
+	// only deleted when it is set.
+	retention *configapi.FinishedWorkloadRetention
+	// stuck is the configuration of the eviction of the admitted workloads
+	// whose pods are never created. They are only evicted when it is set.
+	stuck *configapi.StuckWorkloads
 }
 
-func NewWorkloadReconciler(client client.Client, queues *queue.Manager, cache *cache.Cache, watchers ...WorkloadUpdateWatcher) *WorkloadReconciler {
+func NewWorkloadReconciler(client client.Client, recorder record.EventRecorder, queues *queue.Manager, cache *cache.Cache, podsReady *configapi.WaitForPodsReady, retention *configapi.FinishedWorkloadRetention, stuck *configapi.StuckWorkloads, auditor *audit.Auditor, watchers ...WorkloadUpdateWatcher) *WorkloadReconciler {
 	return &WorkloadReconciler{
-		log:      ctrl.Log.WithName("workload-reconciler"),
-		client:   client,
-		queues:   queues,
-		cache:    cache,
-		watchers: watchers,
+		log:       ctrl.Log.WithName("workload-reconciler"),
+		recorder:  recorder,
+		client:    client,
+		queues:    queues,
+		cache:     cache,
+		auditor:   auditor,
+		watchers:  watchers,
+		podsReady: podsReady,
+		retention: retention,
+		stuck:     stuck,
 	}
 }
 
@@ -69,9 +98,10 @@ func NewWorkloadReconciler(client client.Client, queues *queue.Manager, cache *c
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/finalizers,verbs=update
-//+kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list
+//+kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=limitranges,verbs=get;list;watch
 
-func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
 	var wl kueue.Workload
 	if err := r.client.Get(ctx, req.NamespacedName, &wl); err != nil {
 		// we'll ignore not-found errors, since there is nothing to do.
@@ -80,8 +110,26 @@ func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	log := ctrl.LoggerFrom(ctx).WithValues("workload", klog.KObj(&wl))
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling Workload")
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, &wl), "ReconcileWorkload",
+		trace.WithAttributes(attribute.String("workload", workload.Key(&wl))))
+	defer func() { tracing.End(span, err) }()
 
 	status := workloadStatus(&wl)
+	if status == finished {
+		return r.reconcileRetention(ctx, &wl)
+	}
+	if !workload.IsActive(&wl) {
+		switch status {
+		case admitted:
+			err := evict(ctx, r.client, r.recorder, r.auditor, &wl, workload.EvictedByDeactivation, "The workload is deactivated")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		case pending:
+			err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, corev1.ConditionFalse,
+				"Inactive", "The workload is deactivated")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+
 	if status == pending && !r.queues.QueueForWorkloadExists(&wl) {
 		err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, corev1.ConditionFalse,
 			"Inadmissible", fmt.Sprintf("Queue %s doesn't exist", wl.Spec.QueueName))
@@ -101,13 +149,201 @@ func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	if status == admitted {
-		err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, corev1.ConditionTrue, "", "")
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if !workload.InCondition(&wl, kueue.WorkloadAdmitted) {
+			// Setting the condition triggers another reconcile, which checks
+			// the pods readiness against the time of the admission.
+			err := workload.UpdateStatusIfChanged(ctx, r.client, &wl, kueue.WorkloadAdmitted, corev1.ConditionTrue, "", "")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		remaining, evicted, err := r.reconcilePodsNotCreated(ctx, &wl)
+		if evicted || err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		result, err := r.reconcilePodsReadyTimeout(ctx, &wl)
+		if remaining > 0 && (result.RequeueAfter == 0 || remaining < result.RequeueAfter) {
+			// Check the workload again when the first deadline passes.
+			result.RequeueAfter = remaining
+		}
+		return result, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// reconcilePodsNotCreated evicts the admitted workload if the integration
+// didn't observe its pods within the grace period since the workload was
+// started. Otherwise, it returns the time left for the pods to be created, or
+// zero if the workload has no deadline.
+func (r *WorkloadReconciler) reconcilePodsNotCreated(ctx context.Context, wl *kueue.Workload) (time.Duration, bool, error) {
+	if r.stuck == nil || r.stuck.GracePeriod == nil {
+		return 0, false, nil
+	}
+	gracePeriod := r.stuck.GracePeriod.Duration
+	remaining, ok := podsCreationRemainingTime(wl, gracePeriod)
+	if !ok {
+		return 0, false, nil
+	}
+	if remaining > 0 {
+		return remaining, false, nil
+	}
+	message := fmt.Sprintf("The pods weren't created within %s", gracePeriod)
+	ctrl.LoggerFrom(ctx).V(2).Info("Evicting workload", "reason", workload.EvictedByPodsNotCreated, "message", message)
+	return 0, true, evict(ctx, r.client, r.recorder, r.auditor, wl, workload.EvictedByPodsNotCreated, message)
+}
+
+// podsCreationRemainingTime returns the time left for the pods of the admitted
+// workload to be created. It returns false if the integration didn't report
+// the PodsCreated condition or the pods were already created.
+//
+// The grace period starts when the integration started the workload, but not
+// before the admission, in case the condition is left from a previous
+// admission.
+func podsCreationRemainingTime(wl *kueue.Workload, gracePeriod time.Duration) (time.Duration, bool) {
+	createdIdx := workload.FindConditionIndex(&wl.Status, kueue.WorkloadPodsCreated)
+	if createdIdx == -1 || wl.Status.Conditions[createdIdx].Status != corev1.ConditionFalse {
+		return 0, false
+	}
+	start := wl.Status.Conditions[createdIdx].LastTransitionTime
+	if i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted); i != -1 && wl.Status.Conditions[i].LastTransitionTime.After(start.Time) {
+		start = wl.Status.Conditions[i].LastTransitionTime
+	}
+	return gracePeriod - time.Since(start.Time), true
+}
+
+// reconcilePodsReadyTimeout evicts the admitted workload if its pods didn't
+// become ready within the timeout since the admission, or didn't recover
+// within the recovery timeout since they stopped being ready. Otherwise, the
+// workload is requeued to be checked again when its deadline passes.
+func (r *WorkloadReconciler) reconcilePodsReadyTimeout(ctx context.Context, wl *kueue.Workload) (ctrl.Result, error) {
+	if r.podsReady == nil || !r.podsReady.Enable || workload.InCondition(wl, kueue.WorkloadPodsReady) {
+		return ctrl.Result{}, nil
+	}
+	remaining, message, ok := podsReadyRemainingTime(wl, r.podsReady)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	if remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+	ctrl.LoggerFrom(ctx).V(2).Info("Evicting workload", "reason", workload.EvictedByPodsReadyTimeout, "message", message)
+	err := evict(ctx, r.client, r.recorder, r.auditor, wl, workload.EvictedByPodsReadyTimeout, message)
+	return ctrl.Result{}, client.IgnoreNotFound(err)
+}
+
+// evict evicts the workload and records the eviction in the auditor, with
+// the usage of its ClusterQueue before the eviction.
+func evict(ctx context.Context, c client.Client, recorder record.EventRecorder, auditor *audit.Auditor, wl *kueue.Workload, reason, message string) error {
+	auditRecord := auditor.EvictionRecord(wl, reason, message)
+	if err := workload.Evict(ctx, c, recorder, wl, reason, message); err != nil {
+		return err
+	}
+	auditor.Record(ctx, auditRecord)
+	return nil
+}
+
+// podsReadyRemainingTime returns the time left for the pods of the admitted
+// workload to become ready and the message to evict the workload with once it
+// runs out. It returns false if the workload has no deadline.
+//
+// The workload gets the timeout since it was admitted, unless its pods were
+// ready and stopped being so after the admission, in which case it gets the
+// recovery timeout since that moment.
+func podsReadyRemainingTime(wl *kueue.Workload, cfg *configapi.WaitForPodsReady) (time.Duration, string, bool) {
+	admittedIdx := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted)
+	if admittedIdx == -1 {
+		return 0, "", false
+	}
+	admittedTime := wl.Status.Conditions[admittedIdx].LastTransitionTime
+	if i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadPodsReady); i != -1 {
+		notReadyTime := wl.Status.Conditions[i].LastTransitionTime
+		if notReadyTime.After(admittedTime.Time) {
+			if cfg.RecoveryTimeout == nil {
+				return 0, "", false
+			}
+			timeout := cfg.RecoveryTimeout.Duration
+			return timeout - time.Since(notReadyTime.Time), fmt.Sprintf("The pods didn't recover the ready state within %s", timeout), true
+		}
+	}
+	if cfg.Timeout == nil {
+		return 0, "", false
+	}
+	timeout := cfg.Timeout.Duration
+	return timeout - time.Since(admittedTime.Time), fmt.Sprintf("The pods didn't become ready within %s", timeout), true
+}
+
+// reconcileRetention deletes the finished workload once its TTL expires. It
+// also deletes the finished workloads of the namespace that exceed the maximum
+// count, starting from the ones that finished the earliest.
+func (r *WorkloadReconciler) reconcileRetention(ctx context.Context, wl *kueue.Workload) (ctrl.Result, error) {
+	if r.retention == nil {
+		return ctrl.Result{}, nil
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	if r.retention.MaxCount != nil {
+		var workloads kueue.WorkloadList
+		if err := r.client.List(ctx, &workloads, client.MatchingFields{WorkloadFinishedKey: "true"}, client.InNamespace(wl.Namespace)); err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, w := range exceedingFinishedWorkloads(workloads.Items, *r.retention.MaxCount) {
+			log.V(2).Info("Deleting finished workload exceeding the maximum count", "deletedWorkload", klog.KObj(w))
+			if err := r.client.Delete(ctx, w); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if r.retention.TTL == nil {
+		return ctrl.Result{}, nil
+	}
+	if remaining := r.retention.TTL.Duration - time.Since(finishedTime(wl)); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+	log.V(2).Info("Deleting finished workload after its TTL")
+	return ctrl.Result{}, client.IgnoreNotFound(r.client.Delete(ctx, wl))
+}
+
+// SetupIndexes sets up the index of the finished Workloads, used to enforce the
+// maximum count of the retention policy.
+func SetupIndexes(indexer client.FieldIndexer) error {
+	return indexer.IndexField(context.Background(), &kueue.Workload{}, WorkloadFinishedKey, func(o client.Object) []string {
+		if !workload.InCondition(o.(*kueue.Workload), kueue.WorkloadFinished) {
+			return nil
+		}
+		return []string{"true"}
+	})
+}
+
+// exceedingFinishedWorkloads returns the finished workloads beyond the
+// maxCount most recently finished ones.
+func exceedingFinishedWorkloads(workloads []kueue.Workload, maxCount int32) []*kueue.Workload {
+	var finished []*kueue.Workload
+	for i := range workloads {
+		if workload.InCondition(&workloads[i], kueue.WorkloadFinished) {
+			finished = append(finished, &workloads[i])
+		}
+	}
+	if len(finished) <= int(maxCount) {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		ti, tj := finishedTime(finished[i]), finishedTime(finished[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return finished[i].Name < finished[j].Name
+	})
+	return finished[maxCount:]
+}
+
+// finishedTime returns when the workload finished.
+func finishedTime(wl *kueue.Workload) time.Time {
+	if i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadFinished); i != -1 {
+		return wl.Status.Conditions[i].LastTransitionTime.Time
+	}
+	return wl.CreationTimestamp.Time
+}
+
 func (r *WorkloadReconciler) Create(e event.CreateEvent) bool {
 	wl := e.Object.(*kueue.Workload)
 	defer r.notifyWatchers(wl)
@@ -120,7 +356,7 @@ func (r *WorkloadReconciler) Create(e event.CreateEvent) bool {
 	}
 
 	wlCopy := wl.DeepCopy()
-	handlePodOverhead(r.log, wlCopy, r.client)
+	workload.AdjustResources(ctrl.LoggerInto(context.Background(), log), r.client, wlCopy)
 
 	if wl.Spec.Admission == nil {
 		if !r.queues.AddOrUpdateWorkload(wlCopy) {
@@ -192,7 +428,7 @@ func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 
 	wlCopy := wl.DeepCopy()
 	// We do not handle old workload here as it will be deleted or replaced by new one anyway.
-	handlePodOverhead(r.log, wlCopy, r.client)
+	workload.AdjustResources(ctrl.LoggerInto(context.Background(), log), r.client, wlCopy)
 
 	switch {
 	case status == finished:
@@ -232,6 +468,10 @@ func (r *WorkloadReconciler) Update(e event.UpdateEvent) bool {
 		if err := r.cache.UpdateWorkload(oldWl, wlCopy); err != nil {
 			log.Error(err, "Updating workload in cache")
 		}
+		// A scaled down workload releases quota for the pending workloads.
+		if status == admitted && podSetsShrank(oldWl, wl) {
+			r.queues.QueueAssociatedInadmissibleWorkloads(wl)
+		}
 	}
 
 	return true
@@ -249,11 +489,27 @@ func (r *WorkloadReconciler) notifyWatchers(wl *kueue.Workload) {
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(leader.AllReplicas(mgr)).
 		For(&kueue.Workload{}).
+		Watches(leader.ElectionSource(mgr.Elected(), mgr.GetClient(), &kueue.WorkloadList{}), &handler.EnqueueRequestForObject{}).
 		WithEventFilter(r).
-		Complete(r)
+		WithOptions(opts).
+		Complete(leader.AwareReconciler(mgr.Elected(), r))
+}
+
+// podSetsShrank returns whether the count of any of the pod sets of the
+// workload decreased.
+func podSetsShrank(oldWl, wl *kueue.Workload) bool {
+	if len(oldWl.Spec.PodSets) != len(wl.Spec.PodSets) {
+		return false
+	}
+	for i := range wl.Spec.PodSets {
+		if wl.Spec.PodSets[i].Count < oldWl.Spec.PodSets[i].Count {
+			return true
+		}
+	}
+	return false
 }
 
 func workloadStatus(w *kueue.Workload) string {
@@ -265,24 +521,3 @@ func workloadStatus(w *kueue.Workload) string {
 	}
 	return pending
 }
-
-// We do not verify Pod's RuntimeClass legality here as this will be performed in admission controller.
-// As a result, the pod's Overhead is not always correct. E.g. if we set a non-existent runtime class name to
-// `pod.Spec.RuntimeClassName` and we also set the `pod.Spec.Overhead`, in real world, the pod creation will be
-// rejected due to the mismatch with RuntimeClass. However, in the future we assume that they are correct.
-func handlePodOverhead(log logr.Logger, wl *kueue.Workload, c client.Client) {
-	ctx := context.Background()
-
-	for i, pod := range wl.Spec.PodSets {
-		if pod.Spec.RuntimeClassName != nil && len(pod.Spec.Overhead) == 0 {
-			var runtimeClass nodev1.RuntimeClass
-			if err := c.Get(ctx, types.NamespacedName{Name: *pod.Spec.RuntimeClassName}, &runtimeClass); err != nil {
-				log.Error(err, "Could not get RuntimeClass")
-				continue
-			}
-			if runtimeClass.Overhead != nil {
-				wl.Spec.PodSets[i].Spec.Overhead = runtimeClass.Overhead.PodFixed
-			}
-		}
-	}
-}
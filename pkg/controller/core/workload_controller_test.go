@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestPodsReadyRemainingTime(t *testing.T) {
+	now := time.Now()
+	cfg := &configapi.WaitForPodsReady{
+		Enable:          true,
+		Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		RecoveryTimeout: &metav1.Duration{Duration: time.Minute},
+	}
+	admitted := kueue.WorkloadCondition{
+		Type:               kueue.WorkloadAdmitted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute)),
+	}
+	cases := map[string]struct {
+		workload    *kueue.Workload
+		cfg         *configapi.WaitForPodsReady
+		wantOk      bool
+		wantExpired bool
+		wantMessage string
+	}{
+		"not admitted": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Obj(),
+			cfg:      cfg,
+		},
+		"waiting for the pods to become ready": {
+			workload:    utiltesting.MakeWorkload("wl", "ns").Condition(admitted).Obj(),
+			cfg:         cfg,
+			wantOk:      true,
+			wantMessage: "The pods didn't become ready within 5m0s",
+		},
+		"pods not ready since before the admission": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(kueue.WorkloadCondition{
+					Type:               kueue.WorkloadPodsReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
+				}).
+				Obj(),
+			cfg:         cfg,
+			wantOk:      true,
+			wantMessage: "The pods didn't become ready within 5m0s",
+		},
+		"pods stopped being ready after the admission": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(kueue.WorkloadCondition{
+					Type:               kueue.WorkloadPodsReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(now.Add(-90 * time.Second)),
+				}).
+				Obj(),
+			cfg:         cfg,
+			wantOk:      true,
+			wantExpired: true,
+			wantMessage: "The pods didn't recover the ready state within 1m0s",
+		},
+		"pods stopped being ready without a recovery timeout": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(kueue.WorkloadCondition{
+					Type:               kueue.WorkloadPodsReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(now.Add(-90 * time.Second)),
+				}).
+				Obj(),
+			cfg: &configapi.WaitForPodsReady{
+				Enable:  true,
+				Timeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+		"timeout expired": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Condition(admitted).Obj(),
+			cfg: &configapi.WaitForPodsReady{
+				Enable:  true,
+				Timeout: &metav1.Duration{Duration: time.Minute},
+			},
+			wantOk:      true,
+			wantExpired: true,
+			wantMessage: "The pods didn't become ready within 1m0s",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			remaining, message, ok := podsReadyRemainingTime(tc.workload, tc.cfg)
+			if ok != tc.wantOk {
+				t.Fatalf("podsReadyRemainingTime returned ok=%t, want %t", ok, tc.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if expired := remaining <= 0; expired != tc.wantExpired {
+				t.Errorf("Deadline expired: %t, want %t (remaining %s)", expired, tc.wantExpired, remaining)
+			}
+			if message != tc.wantMessage {
+				t.Errorf("Got message %q, want %q", message, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestPodsCreationRemainingTime(t *testing.T) {
+	now := time.Now()
+	admitted := kueue.WorkloadCondition{
+		Type:               kueue.WorkloadAdmitted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute)),
+	}
+	podsCreated := func(status corev1.ConditionStatus, ago time.Duration) kueue.WorkloadCondition {
+		return kueue.WorkloadCondition{
+			Type:               kueue.WorkloadPodsCreated,
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(now.Add(-ago)),
+		}
+	}
+	cases := map[string]struct {
+		workload    *kueue.Workload
+		wantOk      bool
+		wantExpired bool
+	}{
+		"not started": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Condition(admitted).Obj(),
+		},
+		"pods created": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(podsCreated(corev1.ConditionTrue, 90*time.Second)).
+				Obj(),
+		},
+		"waiting for the pods to be created": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(podsCreated(corev1.ConditionFalse, 30*time.Second)).
+				Obj(),
+			wantOk: true,
+		},
+		"pods not created within the grace period": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(podsCreated(corev1.ConditionFalse, 90*time.Second)).
+				Obj(),
+			wantOk:      true,
+			wantExpired: true,
+		},
+		"pods not created since before the admission": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(admitted).
+				Condition(podsCreated(corev1.ConditionFalse, time.Hour)).
+				Obj(),
+			wantOk:      true,
+			wantExpired: true,
+		},
+		"readmitted after the pods stopped being created": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Condition(kueue.WorkloadCondition{
+					Type:               kueue.WorkloadAdmitted,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now.Add(-30 * time.Second)),
+				}).
+				Condition(podsCreated(corev1.ConditionFalse, time.Hour)).
+				Obj(),
+			wantOk: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			remaining, ok := podsCreationRemainingTime(tc.workload, time.Minute)
+			if ok != tc.wantOk {
+				t.Fatalf("podsCreationRemainingTime returned ok=%t, want %t", ok, tc.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if expired := remaining <= 0; expired != tc.wantExpired {
+				t.Errorf("Grace period expired: %t, want %t (remaining %s)", expired, tc.wantExpired, remaining)
+			}
+		})
+	}
+}
+
+func TestPodSetsShrank(t *testing.T) {
+	podSets := func(counts ...int32) []kueue.PodSet {
+		var podSets []kueue.PodSet
+		for _, c := range counts {
+			podSets = append(podSets, kueue.PodSet{Count: c})
+		}
+		return podSets
+	}
+	cases := map[string]struct {
+		oldPodSets []kueue.PodSet
+		podSets    []kueue.PodSet
+		want       bool
+	}{
+		"same counts": {
+			oldPodSets: podSets(2, 3),
+			podSets:    podSets(2, 3),
+		},
+		"a count decreased": {
+			oldPodSets: podSets(2, 3),
+			podSets:    podSets(2, 1),
+			want:       true,
+		},
+		"a count increased": {
+			oldPodSets: podSets(2, 3),
+			podSets:    podSets(4, 3),
+		},
+		"different pod sets": {
+			oldPodSets: podSets(2, 3),
+			podSets:    podSets(1),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			oldWl := utiltesting.MakeWorkload("wl", "ns").PodSets(tc.oldPodSets).Obj()
+			wl := utiltesting.MakeWorkload("wl", "ns").PodSets(tc.podSets).Obj()
+			if got := podSetsShrank(oldWl, wl); got != tc.want {
+				t.Errorf("podSetsShrank() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExceedingFinishedWorkloads(t *testing.T) {
+	now := time.Now()
+	finishedAt := func(name string, d time.Duration) kueue.Workload {
+		return *utiltesting.MakeWorkload(name, "ns").Condition(kueue.WorkloadCondition{
+			Type:               kueue.WorkloadFinished,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(now.Add(-d)),
+		}).Obj()
+	}
+	workloads := []kueue.Workload{
+		finishedAt("a", 3*time.Minute),
+		*utiltesting.MakeWorkload("pending", "ns").Obj(),
+		finishedAt("b", time.Minute),
+		finishedAt("c", 2*time.Minute),
+		finishedAt("d", 2*time.Minute),
+	}
+	cases := map[string]struct {
+		maxCount int32
+		want     []string
+	}{
+		"under the maximum": {
+			maxCount: 4,
+		},
+		"over the maximum": {
+			maxCount: 2,
+			want:     []string{"d", "a"},
+		},
+		"no finished workloads allowed": {
+			maxCount: 0,
+			want:     []string{"b", "c", "d", "a"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, w := range exceedingFinishedWorkloads(workloads, tc.maxCount) {
+				got = append(got, w.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected workloads (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -18,8 +18,14 @@ package job
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -33,13 +39,23 @@ import (
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/features"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
+	"sigs.k8s.io/kueue/pkg/util/ratelimiter"
+	"sigs.k8s.io/kueue/pkg/util/tracing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
+// FrameworkName is the name of the integration in the
+// integrations.frameworks field of the Configuration.
+const FrameworkName = "batch/job"
+
 var (
 	ownerKey = ".metadata.controller"
 )
@@ -50,10 +66,12 @@ type JobReconciler struct {
 	scheme                     *runtime.Scheme
 	record                     record.EventRecorder
 	manageJobsWithoutQueueName bool
+	rateLimiter                *configapi.ControllerRateLimiter
 }
 
 type options struct {
 	manageJobsWithoutQueueName bool
+	rateLimiter                *configapi.ControllerRateLimiter
 }
 
 // Option configures the reconciler.
@@ -67,6 +85,14 @@ func WithManageJobsWithoutQueueName(f bool) Option {
 	}
 }
 
+// WithRateLimiter sets the configuration of the rate limiter of the retries of
+// the controller.
+func WithRateLimiter(rl *configapi.ControllerRateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = rl
+	}
+}
+
 var defaultOptions = options{}
 
 func NewReconciler(
@@ -85,6 +111,7 @@ func NewReconciler(
 		client:                     client,
 		record:                     record,
 		manageJobsWithoutQueueName: options.manageJobsWithoutQueueName,
+		rateLimiter:                options.rateLimiter,
 	}
 }
 
@@ -94,6 +121,7 @@ func (r *JobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchv1.Job{}).
 		Owns(&kueue.Workload{}).
+		WithOptions(controller.Options{RateLimiter: ratelimiter.New(r.rateLimiter)}).
 		Complete(r)
 }
 
@@ -122,12 +150,18 @@ func SetupIndexes(indexer client.FieldIndexer) error {
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads/finalizers,verbs=update
 //+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=resourceflavors,verbs=get;list;watch
+//+kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=limitranges,verbs=get;list;watch
 
 func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var job batchv1.Job
 	if err := r.client.Get(ctx, req.NamespacedName, &job); err != nil {
-		// we'll ignore not-found errors, since there is nothing to do.
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if apierrors.IsNotFound(err) {
+			// The workloads of a deleted job hold quota until they are
+			// deleted, don't wait for the garbage collector.
+			return ctrl.Result{}, r.deleteOrphanedWorkloads(ctx, req.NamespacedName)
+		}
+		return ctrl.Result{}, err
 	}
 
 	log := ctrl.LoggerFrom(ctx).WithValues("job", klog.KObj(&job))
@@ -146,8 +180,21 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// 1. make sure there is only a single existing instance of the workload
-	wl, err := r.ensureAtMostOneWorkload(ctx, &job, childWorkloads)
+	// 1. make sure there is only a single existing instance of the workload,
+	// besides the workload slices that hold the replicas of a scale up.
+	workloads := childWorkloads
+	if features.Enabled(features.WorkloadSlices) {
+		var slices []kueue.Workload
+		workloads, slices = splitWorkloadSlices(childWorkloads)
+		handled, err := r.reconcileWorkloadSlices(ctx, &job, workloads, slices)
+		if err != nil {
+			log.Error(err, "Reconciling workload slices")
+		}
+		if handled || err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	wl, err := r.ensureAtMostOneWorkload(ctx, &job, workloads)
 	if err != nil {
 		log.Error(err, "Getting existing workloads")
 		return ctrl.Result{}, err
@@ -172,7 +219,7 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		added := false
 		wl.Status.Conditions, added = appendFinishedConditionIfNotExists(wl.Status.Conditions, jobFinishedCond)
 		if !added {
-			return ctrl.Result{}, nil
+			return r.syncWorkloadStatus(ctx, &job, wl)
 		}
 		err := r.client.Status().Update(ctx, wl)
 		if err != nil {
@@ -205,7 +252,7 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			return ctrl.Result{}, err
 		}
 		log.V(3).Info("Job is suspended and workload not yet admitted by a clusterQueue, nothing to do")
-		return ctrl.Result{}, nil
+		return r.syncWorkloadStatus(ctx, &job, wl)
 	}
 
 	if wl.Spec.Admission == nil {
@@ -218,15 +265,152 @@ func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// 4.4 workload is admitted and job is running, nothing to do.
+	// 4.4 the running job was scaled down, release the quota of the removed
+	// replicas.
+	if scaledDown(&job, wl) {
+		log.V(2).Info("Job scaled down, updating the workload count", "parallelism", *job.Spec.Parallelism)
+		prevCount := wl.Spec.PodSets[0].Count
+		wl.Spec.PodSets[0].Count = *job.Spec.Parallelism
+		if err := r.client.Update(ctx, wl); err != nil {
+			log.Error(err, "Updating workload count")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		r.record.Eventf(&job, corev1.EventTypeNormal, "ScaledDown",
+			"Released the quota of %d replicas", prevCount-*job.Spec.Parallelism)
+		return ctrl.Result{}, nil
+	}
+
+	// 4.5 workload is admitted and job is running, report that the job pods
+	// were created, or that the job is waiting for them if it wasn't reported
+	// when the job was started.
+	if jobHasPods(&job) && !workload.InCondition(wl, kueue.WorkloadPodsCreated) {
+		log.V(2).Info("Setting the PodsCreated condition of the workload")
+		err := workload.UpdateStatus(ctx, r.client, wl, kueue.WorkloadPodsCreated, corev1.ConditionTrue,
+			"PodsCreated", "The pods of the job were created")
+		if err != nil {
+			log.Error(err, "Updating workload PodsCreated condition")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !jobHasPods(&job) && !startedSinceAdmission(wl) {
+		log.V(2).Info("Setting the PodsCreated condition of the started workload")
+		err := setStarted(ctx, r.client, wl)
+		if err != nil {
+			log.Error(err, "Updating workload PodsCreated condition")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// 4.6 workload is admitted and job is running, update the PodsReady
+	// condition if the job pods became ready.
+	if ready := jobPodsReady(&job); ready != workload.InCondition(wl, kueue.WorkloadPodsReady) {
+		log.V(2).Info("Updating the PodsReady condition of the workload", "ready", ready)
+		err := r.updatePodsReadyCondition(ctx, wl, ready)
+		if err != nil {
+			log.Error(err, "Updating workload PodsReady condition")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
 	log.V(3).Info("Job running with admitted workload, nothing to do")
-	return ctrl.Result{}, nil
+	return r.syncWorkloadStatus(ctx, &job, wl)
+
+}
+
+// syncWorkloadStatus mirrors the status of the workload in the annotations of
+// the job, so that users find why the job is suspended without looking for
+// the workload. It runs once the job doesn't need other changes, and only
+// updates the job when the status or reason changes, as the messages of the
+// inadmissible workloads change on every scheduling attempt.
+func (r *JobReconciler) syncWorkloadStatus(ctx context.Context, job *batchv1.Job, wl *kueue.Workload) (ctrl.Result, error) {
+	status, reason := workloadStatus(wl)
+	if job.Annotations[constants.WorkloadStatusAnnotation] == status &&
+		job.Annotations[constants.WorkloadStatusReasonAnnotation] == reason {
+		return ctrl.Result{}, nil
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[constants.WorkloadStatusAnnotation] = status
+	if reason != "" {
+		job.Annotations[constants.WorkloadStatusReasonAnnotation] = reason
+	} else {
+		delete(job.Annotations, constants.WorkloadStatusReasonAnnotation)
+	}
+	err := r.client.Update(ctx, job)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Updating the workload status of the job")
+	}
+	return ctrl.Result{}, client.IgnoreNotFound(err)
+}
+
+// workloadStatus returns the status of the workload and the reason of its
+// last transition, as shown in the job annotations.
+func workloadStatus(wl *kueue.Workload) (string, string) {
+	if c := condition(wl, kueue.WorkloadFinished); c != nil && c.Status == corev1.ConditionTrue {
+		return "Finished", c.Reason
+	}
+	c := condition(wl, kueue.WorkloadAdmitted)
+	if wl.Spec.Admission != nil {
+		if c == nil || c.Status != corev1.ConditionTrue {
+			return "Admitted", ""
+		}
+		return "Admitted", c.Reason
+	}
+	if c == nil {
+		return "Pending", ""
+	}
+	if workload.IsEvicted(wl) {
+		return "Evicted", c.Reason
+	}
+	return "Pending", c.Reason
+}
+
+func condition(wl *kueue.Workload, conditionType kueue.WorkloadConditionType) *kueue.WorkloadCondition {
+	if i := workload.FindConditionIndex(&wl.Status, conditionType); i != -1 {
+		return &wl.Status.Conditions[i]
+	}
+	return nil
+}
+
+// updatePodsReadyCondition sets the PodsReady condition of the workload. When
+// the pods become ready for the first time since the workload was admitted,
+// it records the time since the admission.
+func (r *JobReconciler) updatePodsReadyCondition(ctx context.Context, wl *kueue.Workload, ready bool) error {
+	if !ready {
+		return workload.UpdateStatusIfChanged(ctx, r.client, wl, kueue.WorkloadPodsReady, corev1.ConditionFalse,
+			"PodsNotReady", "Not all the pods of the job are ready")
+	}
+	firstReady := firstPodsReadySinceAdmission(wl)
+	if err := workload.UpdateStatusIfChanged(ctx, r.client, wl, kueue.WorkloadPodsReady, corev1.ConditionTrue,
+		"PodsReady", "All the pods of the job are ready"); err != nil {
+		return err
+	}
+	if i := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted); firstReady && i != -1 && wl.Status.Conditions[i].Status == corev1.ConditionTrue {
+		metrics.PodsReadyWorkload(string(wl.Spec.Admission.ClusterQueue), time.Since(wl.Status.Conditions[i].LastTransitionTime.Time))
+	}
+	return nil
+}
 
+// firstPodsReadySinceAdmission returns whether the pods of the workload
+// didn't become ready since it was admitted. The PodsReady condition only
+// transitions to false once the pods were ready, so a condition that
+// transitioned after the admission means that the pods are recovering.
+func firstPodsReadySinceAdmission(wl *kueue.Workload) bool {
+	ready := workload.FindConditionIndex(&wl.Status, kueue.WorkloadPodsReady)
+	if ready == -1 {
+		return true
+	}
+	admitted := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted)
+	if admitted == -1 {
+		return false
+	}
+	return wl.Status.Conditions[ready].LastTransitionTime.Before(&wl.Status.Conditions[admitted].LastTransitionTime)
 }
 
 // stopJob sends updates to suspend the job, reset the startTime so we can update the scheduling directives
-// later when unsuspending and resets the nodeSelector to its previous state based on what is available in
-// the workload (which should include the original affinities that the job had).
+// later when unsuspending and resets the nodeSelector and tolerations to their previous state based on what
+// was saved in the job annotations or, otherwise, what is available in the workload (which should include
+// the original affinities that the job had).
 func (r *JobReconciler) stopJob(ctx context.Context, w *kueue.Workload,
 	job *batchv1.Job, eventMsg string) error {
 	job.Spec.Suspend = pointer.BoolPtr(true)
@@ -243,29 +427,144 @@ func (r *JobReconciler) stopJob(ctx context.Context, w *kueue.Workload,
 		}
 	}
 
-	if w != nil && !equality.Semantic.DeepEqual(job.Spec.Template.Spec.NodeSelector,
-		w.Spec.PodSets[0].Spec.NodeSelector) {
-		job.Spec.Template.Spec.NodeSelector = map[string]string{}
-		for k, v := range w.Spec.PodSets[0].Spec.NodeSelector {
-			job.Spec.Template.Spec.NodeSelector[k] = v
+	changed := false
+	nodeSelector, found, err := originalNodeSelector(w, job)
+	if err != nil {
+		return err
+	}
+	if found && !equality.Semantic.DeepEqual(job.Spec.Template.Spec.NodeSelector, nodeSelector) {
+		job.Spec.Template.Spec.NodeSelector = nodeSelector
+		changed = true
+	}
+	if _, ok := job.Annotations[constants.OriginalNodeSelectorsAnnotation]; ok {
+		delete(job.Annotations, constants.OriginalNodeSelectorsAnnotation)
+		changed = true
+	}
+	if parallelism, ok := originalParallelism(job); ok {
+		job.Spec.Parallelism = pointer.Int32(parallelism)
+		changed = true
+	}
+	if _, ok := job.Annotations[constants.OriginalParallelismAnnotation]; ok {
+		delete(job.Annotations, constants.OriginalParallelismAnnotation)
+		changed = true
+	}
+	if w != nil && !equality.Semantic.DeepEqual(job.Spec.Template.Spec.Tolerations,
+		w.Spec.PodSets[0].Spec.Tolerations) {
+		job.Spec.Template.Spec.Tolerations = nil
+		for _, t := range w.Spec.PodSets[0].Spec.Tolerations {
+			job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, *t.DeepCopy())
 		}
+		changed = true
+	}
+	if changed {
 		return r.client.Update(ctx, job)
 	}
 
 	return nil
 }
 
-func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *batchv1.Job) error {
+// originalNodeSelector returns the nodeSelector that the job had before
+// Kueue injected the nodeSelector of the flavors. It prefers the copy saved
+// in the job annotations and falls back to the nodeSelector of the workload.
+func originalNodeSelector(w *kueue.Workload, job *batchv1.Job) (map[string]string, bool, error) {
+	nodeSelector := map[string]string{}
+	if data, ok := job.Annotations[constants.OriginalNodeSelectorsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &nodeSelector); err != nil {
+			return nil, false, fmt.Errorf("parsing %s annotation: %w", constants.OriginalNodeSelectorsAnnotation, err)
+		}
+		return nodeSelector, true, nil
+	}
+	if w == nil {
+		return nil, false, nil
+	}
+	for k, v := range w.Spec.PodSets[0].Spec.NodeSelector {
+		nodeSelector[k] = v
+	}
+	return nodeSelector, true, nil
+}
+
+// saveOriginalNodeSelector stores the current nodeSelector of the job in its
+// annotations, unless it was already saved by a previous admission.
+func saveOriginalNodeSelector(job *batchv1.Job) error {
+	if _, ok := job.Annotations[constants.OriginalNodeSelectorsAnnotation]; ok {
+		return nil
+	}
+	data, err := json.Marshal(job.Spec.Template.Spec.NodeSelector)
+	if err != nil {
+		return err
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[constants.OriginalNodeSelectorsAnnotation] = string(data)
+	return nil
+}
+
+// originalParallelism returns the parallelism that the job had before Kueue
+// reduced it for a partial admission, as saved in the job annotations.
+func originalParallelism(job *batchv1.Job) (int32, bool) {
+	data, ok := job.Annotations[constants.OriginalParallelismAnnotation]
+	if !ok {
+		return 0, false
+	}
+	parallelism, err := strconv.ParseInt(data, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(parallelism), true
+}
+
+// requestedParallelism returns the parallelism requested for the job, which
+// is higher than the parallelism of the job while it runs with a partial
+// admission.
+func requestedParallelism(job *batchv1.Job) int32 {
+	if parallelism, ok := originalParallelism(job); ok {
+		return parallelism
+	}
+	return *job.Spec.Parallelism
+}
+
+// maxParallelPods returns the number of pods that the job runs at the same time
+// with its requested parallelism. The job never runs more pods at the same
+// time than its completions.
+func maxParallelPods(job *batchv1.Job) int32 {
+	parallelism := requestedParallelism(job)
+	if job.Spec.Completions != nil && *job.Spec.Completions < parallelism {
+		return *job.Spec.Completions
+	}
+	return parallelism
+}
+
+// setAdmittedParallelism reduces the parallelism of the job to the admitted
+// count of its podSet, saving the original parallelism in the job
+// annotations, unless it was already saved by a previous admission.
+func setAdmittedParallelism(job *batchv1.Job, count int32) {
+	if _, ok := originalParallelism(job); !ok {
+		if job.Annotations == nil {
+			job.Annotations = map[string]string{}
+		}
+		job.Annotations[constants.OriginalParallelismAnnotation] = strconv.Itoa(int(*job.Spec.Parallelism))
+	}
+	job.Spec.Parallelism = pointer.Int32(count)
+}
+
+func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *batchv1.Job) (err error) {
 	log := ctrl.LoggerFrom(ctx)
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, w), "StartJob",
+		trace.WithAttributes(attribute.String("job", klog.KObj(job).String())))
+	defer func() { tracing.End(span, err) }()
 
 	if len(w.Spec.PodSets) != 1 {
 		return fmt.Errorf("one podset must exist, found %d", len(w.Spec.PodSets))
 	}
-	nodeSelector, err := r.getNodeSelectors(ctx, w)
+	nodeSelector, tolerations, err := r.getFlavorsSchedulingDirectives(ctx, w)
 	if err != nil {
 		return err
 	}
 	if len(nodeSelector) != 0 {
+		if err := saveOriginalNodeSelector(job); err != nil {
+			return err
+		}
 		if job.Spec.Template.Spec.NodeSelector == nil {
 			job.Spec.Template.Spec.NodeSelector = nodeSelector
 		} else {
@@ -277,6 +576,15 @@ func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *ba
 	} else {
 		log.V(3).Info("no nodeSelectors to inject")
 	}
+	for _, t := range tolerations {
+		if !hasToleration(job.Spec.Template.Spec.Tolerations, t) {
+			job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, t)
+		}
+	}
+	if count := w.Spec.Admission.PodSetFlavors[0].Count; count != nil && *count < maxParallelPods(job) {
+		log.V(2).Info("Workload partially admitted, reducing the parallelism", "parallelism", *count)
+		setAdmittedParallelism(job, *count)
+	}
 
 	job.Spec.Suspend = pointer.BoolPtr(false)
 	if err := r.client.Update(ctx, job); err != nil {
@@ -285,16 +593,39 @@ func (r *JobReconciler) startJob(ctx context.Context, w *kueue.Workload, job *ba
 
 	r.record.Eventf(job, corev1.EventTypeNormal, "Started",
 		"Admitted by clusterQueue %v", w.Spec.Admission.ClusterQueue)
-	return nil
+	// Start the grace period for the pods to be created, once the job can
+	// create them. If it fails, it's retried while the job has no pods.
+	return setStarted(ctx, r.client, w)
+}
+
+// setStarted sets the PodsCreated condition of the workload to false, which
+// starts the grace period for the pods of the job to be created.
+func setStarted(ctx context.Context, c client.Client, wl *kueue.Workload) error {
+	return workload.UpdateStatus(ctx, c, wl, kueue.WorkloadPodsCreated, corev1.ConditionFalse,
+		"Started", "Waiting for the pods of the job to be created")
+}
+
+// startedSinceAdmission returns whether the PodsCreated condition of the
+// workload was set after it was admitted.
+func startedSinceAdmission(wl *kueue.Workload) bool {
+	created := workload.FindConditionIndex(&wl.Status, kueue.WorkloadPodsCreated)
+	if created == -1 {
+		return false
+	}
+	admitted := workload.FindConditionIndex(&wl.Status, kueue.WorkloadAdmitted)
+	return admitted == -1 || !wl.Status.Conditions[created].LastTransitionTime.Before(&wl.Status.Conditions[admitted].LastTransitionTime)
 }
 
-func (r *JobReconciler) getNodeSelectors(ctx context.Context, w *kueue.Workload) (map[string]string, error) {
+// getFlavorsSchedulingDirectives returns the nodeSelector and the tolerations
+// of the flavors assigned to the workload, to be injected in the job.
+func (r *JobReconciler) getFlavorsSchedulingDirectives(ctx context.Context, w *kueue.Workload) (map[string]string, []corev1.Toleration, error) {
 	if len(w.Spec.Admission.PodSetFlavors[0].Flavors) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	processedFlvs := sets.NewString()
 	nodeSelector := map[string]string{}
+	var tolerations []corev1.Toleration
 	for _, flvName := range w.Spec.Admission.PodSetFlavors[0].Flavors {
 		if processedFlvs.Has(flvName) {
 			continue
@@ -302,14 +633,28 @@ func (r *JobReconciler) getNodeSelectors(ctx context.Context, w *kueue.Workload)
 		// Lookup the ResourceFlavors to fetch the node affinity labels to apply on the job.
 		flv := kueue.ResourceFlavor{}
 		if err := r.client.Get(ctx, types.NamespacedName{Name: flvName}, &flv); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for k, v := range flv.Labels {
 			nodeSelector[k] = v
 		}
+		for _, t := range flv.Tolerations {
+			if !hasToleration(tolerations, t) {
+				tolerations = append(tolerations, t)
+			}
+		}
 		processedFlvs.Insert(flvName)
 	}
-	return nodeSelector, nil
+	return nodeSelector, tolerations, nil
+}
+
+func hasToleration(tolerations []corev1.Toleration, t corev1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].MatchToleration(&t) {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *JobReconciler) handleJobWithNoWorkload(ctx context.Context, job *batchv1.Job) error {
@@ -321,12 +666,19 @@ func (r *JobReconciler) handleJobWithNoWorkload(ctx context.Context, job *batchv
 		return nil
 	}
 
-	// Create the corresponding workload.
+	// Create the corresponding workload. Its trace starts here, and it's
+	// continued by the components that admit it and start the job.
+	ctx, span := tracing.Tracer().Start(ctx, "CreateWorkload",
+		trace.WithAttributes(attribute.String("job", klog.KObj(job).String())))
 	wl, err := ConstructWorkloadFor(ctx, r.client, job, r.scheme)
 	if err != nil {
+		tracing.End(span, err)
 		return err
 	}
-	if err = r.client.Create(ctx, wl); err != nil {
+	tracing.Inject(ctx, wl)
+	err = r.client.Create(ctx, wl)
+	tracing.End(span, err)
+	if err != nil {
 		return err
 	}
 
@@ -335,6 +687,32 @@ func (r *JobReconciler) handleJobWithNoWorkload(ctx context.Context, job *batchv
 	return nil
 }
 
+// deleteOrphanedWorkloads deletes the workloads owned by a job that no longer
+// exists, releasing their quota.
+func (r *JobReconciler) deleteOrphanedWorkloads(ctx context.Context, job types.NamespacedName) error {
+	log := ctrl.LoggerFrom(ctx).WithValues("job", job)
+	var workloads kueue.WorkloadList
+	if err := r.client.List(ctx, &workloads, client.InNamespace(job.Namespace),
+		client.MatchingFields{ownerKey: job.Name}); err != nil {
+		log.Error(err, "Unable to list child workloads")
+		return err
+	}
+	for i := range workloads.Items {
+		wl := &workloads.Items[i]
+		if !wl.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.client.Delete(ctx, wl); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Deleting orphaned workload", "workload", klog.KObj(wl))
+			return err
+		}
+		log.V(2).Info("Deleted workload of deleted job", "workload", klog.KObj(wl))
+		r.record.Eventf(wl, corev1.EventTypeNormal, "DeletedOrphan",
+			"Deleted workload because its job %s no longer exists", job.Name)
+	}
+	return nil
+}
+
 // ensureAtmostoneworkload finds a matching workload and deletes redundant ones.
 func (r *JobReconciler) ensureAtMostOneWorkload(ctx context.Context, job *batchv1.Job, workloads kueue.WorkloadList) (*kueue.Workload, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -350,7 +728,7 @@ func (r *JobReconciler) ensureAtMostOneWorkload(ctx context.Context, job *batchv
 		if owner.Name != job.Name {
 			continue
 		}
-		if match == nil && jobAndWorkloadEqual(job, w) {
+		if match == nil && (jobAndWorkloadEqual(job, w) || scaledDown(job, w)) {
 			match = w
 		} else {
 			toDelete = append(toDelete, w)
@@ -409,13 +787,27 @@ func ConstructWorkloadFor(ctx context.Context, client client.Client,
 			PodSets: []kueue.PodSet{
 				{
 					Spec:  *job.Spec.Template.Spec.DeepCopy(),
-					Count: *job.Spec.Parallelism,
+					Count: requestedParallelism(job),
 				},
 			},
 			QueueName: queueName(job),
 		},
 	}
 
+	// Don't account the nodeSelector injected by a previous admission.
+	if nodeSelector, found, err := originalNodeSelector(nil, job); err != nil {
+		return nil, err
+	} else if found {
+		w.Spec.PodSets[0].Spec.NodeSelector = nodeSelector
+	}
+
+	required, selector, err := flavorRequirements(job)
+	if err != nil {
+		return nil, err
+	}
+	w.Spec.PodSets[0].RequiredFlavors = required
+	w.Spec.PodSets[0].FlavorSelector = selector
+
 	// Populate priority from priority class.
 	priorityClassName, p, err := utilpriority.GetPriorityFromPriorityClass(
 		ctx, client, job.Spec.Template.Spec.PriorityClassName)
@@ -425,6 +817,10 @@ func ConstructWorkloadFor(ctx context.Context, client client.Client,
 	w.Spec.Priority = &p
 	w.Spec.PriorityClassName = priorityClassName
 
+	// Store the resources that the pods will request, so that all the
+	// components account the same usage for the workload.
+	workload.AdjustResources(ctx, client, w)
+
 	if err := ctrl.SetControllerReference(job, w, scheme); err != nil {
 		return nil, err
 	}
@@ -473,24 +869,124 @@ func jobSuspended(j *batchv1.Job) bool {
 
 }
 
+// jobHasPods returns whether the job controller created any pods of the job.
+// The counts of finished pods include the previous runs of the job, so a
+// restarted job that ran pods before is never considered stuck.
+func jobHasPods(j *batchv1.Job) bool {
+	return j.Status.Active+j.Status.Succeeded+j.Status.Failed > 0
+}
+
+// jobPodsReady returns whether the number of ready and succeeded pods of the
+// job covers the pods that are expected to run at the same time.
+func jobPodsReady(j *batchv1.Job) bool {
+	expected := int32(1)
+	if j.Spec.Parallelism != nil {
+		expected = *j.Spec.Parallelism
+	}
+	if j.Spec.Completions != nil && *j.Spec.Completions < expected {
+		expected = *j.Spec.Completions
+	}
+	ready := j.Status.Active
+	if j.Status.Ready != nil {
+		ready = *j.Status.Ready
+	}
+	return expected > 0 && ready+j.Status.Succeeded >= expected
+}
+
+// scaledDown returns whether the job is running with an admitted workload and
+// the only difference between them is that the job has a lower parallelism.
+func scaledDown(job *batchv1.Job, wl *kueue.Workload) bool {
+	if jobSuspended(job) || wl.Spec.Admission == nil || len(wl.Spec.PodSets) != 1 {
+		return false
+	}
+	if _, ok := originalParallelism(job); ok {
+		// The parallelism was reduced by a partial admission.
+		return false
+	}
+	if *job.Spec.Parallelism <= 0 || *job.Spec.Parallelism >= wl.Spec.PodSets[0].Count {
+		return false
+	}
+	return containersEqual(job.Spec.Template.Spec.InitContainers, wl.Spec.PodSets[0].Spec.InitContainers) &&
+		containersEqual(job.Spec.Template.Spec.Containers, wl.Spec.PodSets[0].Spec.Containers)
+}
+
 func jobAndWorkloadEqual(job *batchv1.Job, wl *kueue.Workload) bool {
 	if len(wl.Spec.PodSets) != 1 {
 		return false
 	}
-	if *job.Spec.Parallelism != wl.Spec.PodSets[0].Count {
+	if requestedParallelism(job) != wl.Spec.PodSets[0].Count {
+		return false
+	}
+
+	required, selector, err := flavorRequirements(job)
+	if err != nil || !equality.Semantic.DeepEqual(required, wl.Spec.PodSets[0].RequiredFlavors) ||
+		!equality.Semantic.DeepEqual(selector, wl.Spec.PodSets[0].FlavorSelector) {
 		return false
 	}
 
-	// nodeSelector may change, hence we are not checking checking for
+	// nodeSelector and tolerations may change, hence we are not checking for
 	// equality of the whole job.Spec.Template.Spec.
-	if !equality.Semantic.DeepEqual(job.Spec.Template.Spec.InitContainers,
+	if !containersEqual(job.Spec.Template.Spec.InitContainers,
 		wl.Spec.PodSets[0].Spec.InitContainers) {
 		return false
 	}
-	return equality.Semantic.DeepEqual(job.Spec.Template.Spec.Containers,
+	return containersEqual(job.Spec.Template.Spec.Containers,
 		wl.Spec.PodSets[0].Spec.Containers)
 }
 
+// flavorRequirements returns the flavors that can be assigned to the pods of
+// the job, as set in its annotations.
+func flavorRequirements(job *batchv1.Job) ([]string, *metav1.LabelSelector, error) {
+	var required []string
+	if names := job.Annotations[constants.RequiredFlavorsAnnotation]; names != "" {
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				required = append(required, name)
+			}
+		}
+	}
+	var selector *metav1.LabelSelector
+	if s := job.Annotations[constants.FlavorSelectorAnnotation]; s != "" {
+		var err error
+		if selector, err = metav1.ParseToLabelSelector(s); err != nil {
+			return nil, nil, fmt.Errorf("parsing %s annotation: %w", constants.FlavorSelectorAnnotation, err)
+		}
+	}
+	return required, selector, nil
+}
+
+// containersEqual returns whether the containers of the workload match the
+// containers of the job. The containers of the workload can have additional
+// requests and limits, set by workload.AdjustResources, so that a change in
+// the LimitRanges doesn't invalidate the existing workloads.
+func containersEqual(jobContainers, wlContainers []corev1.Container) bool {
+	if len(jobContainers) != len(wlContainers) {
+		return false
+	}
+	for i := range jobContainers {
+		jc, wc := jobContainers[i], wlContainers[i]
+		if !resourcesIncluded(jc.Resources.Requests, wc.Resources.Requests) ||
+			!resourcesIncluded(jc.Resources.Limits, wc.Resources.Limits) {
+			return false
+		}
+		jc.Resources, wc.Resources = corev1.ResourceRequirements{}, corev1.ResourceRequirements{}
+		if !equality.Semantic.DeepEqual(jc, wc) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourcesIncluded returns whether all the quantities in a are equal in b.
+func resourcesIncluded(a, b corev1.ResourceList) bool {
+	for name, q := range a {
+		if bq, ok := b[name]; !ok || q.Cmp(bq) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func queueName(job *batchv1.Job) string {
 	return job.Annotations[constants.QueueAnnotation]
 }
@@ -0,0 +1,376 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestJobPodsReady(t *testing.T) {
+	cases := map[string]struct {
+		parallelism *int32
+		completions *int32
+		active      int32
+		ready       *int32
+		succeeded   int32
+		want        bool
+	}{
+		"no pods": {
+			parallelism: pointer.Int32(3),
+		},
+		"all pods ready": {
+			parallelism: pointer.Int32(3),
+			active:      3,
+			ready:       pointer.Int32(3),
+			want:        true,
+		},
+		"active pods not ready": {
+			parallelism: pointer.Int32(3),
+			active:      3,
+			ready:       pointer.Int32(2),
+		},
+		"ready count not reported": {
+			parallelism: pointer.Int32(3),
+			active:      3,
+			want:        true,
+		},
+		"ready and succeeded pods": {
+			parallelism: pointer.Int32(3),
+			active:      1,
+			ready:       pointer.Int32(1),
+			succeeded:   2,
+			want:        true,
+		},
+		"fewer completions than parallelism": {
+			parallelism: pointer.Int32(3),
+			completions: pointer.Int32(2),
+			active:      2,
+			ready:       pointer.Int32(2),
+			want:        true,
+		},
+		"more completions than parallelism": {
+			parallelism: pointer.Int32(3),
+			completions: pointer.Int32(6),
+			active:      2,
+			ready:       pointer.Int32(2),
+		},
+		"default parallelism": {
+			active: 1,
+			ready:  pointer.Int32(1),
+			want:   true,
+		},
+		"zero parallelism": {
+			parallelism: pointer.Int32(0),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := utiltesting.MakeJob("job", "default").Obj()
+			job.Spec.Parallelism = tc.parallelism
+			job.Spec.Completions = tc.completions
+			job.Status.Active = tc.active
+			job.Status.Ready = tc.ready
+			job.Status.Succeeded = tc.succeeded
+			if got := jobPodsReady(job); got != tc.want {
+				t.Errorf("jobPodsReady() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPartialAdmissionParallelism(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding batch scheme: %v", err)
+	}
+	job := utiltesting.MakeJob("job", "default").Parallelism(5).Obj()
+	wl := utiltesting.MakeWorkload("job", "default").
+		Admit(utiltesting.MakeAdmission("cq").Count(3).Obj()).Obj()
+	wl.Spec.PodSets[0].Count = 5
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job, wl).Build()
+	ctx := context.Background()
+	r := NewReconciler(scheme, cl, record.NewFakeRecorder(10))
+
+	if err := r.startJob(ctx, wl, job); err != nil {
+		t.Fatalf("Failed starting the job: %v", err)
+	}
+	var started batchv1.Job
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(job), &started); err != nil {
+		t.Fatalf("Failed getting the job: %v", err)
+	}
+	if diff := cmp.Diff(int32(3), *started.Spec.Parallelism); diff != "" {
+		t.Errorf("Unexpected parallelism after starting (-want,+got):\n%s", diff)
+	}
+	if diff := cmp.Diff("5", started.Annotations[constants.OriginalParallelismAnnotation]); diff != "" {
+		t.Errorf("Unexpected original parallelism annotation (-want,+got):\n%s", diff)
+	}
+	if got := requestedParallelism(&started); got != 5 {
+		t.Errorf("requestedParallelism() = %d, want 5", got)
+	}
+	if scaledDown(&started, wl) {
+		t.Error("The reduced parallelism of a partial admission was taken as a scale down")
+	}
+
+	if err := r.stopJob(ctx, wl, &started, "Evicted"); err != nil {
+		t.Fatalf("Failed stopping the job: %v", err)
+	}
+	var stopped batchv1.Job
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(job), &stopped); err != nil {
+		t.Fatalf("Failed getting the job: %v", err)
+	}
+	if diff := cmp.Diff(int32(5), *stopped.Spec.Parallelism); diff != "" {
+		t.Errorf("Unexpected parallelism after stopping (-want,+got):\n%s", diff)
+	}
+	if _, ok := stopped.Annotations[constants.OriginalParallelismAnnotation]; ok {
+		t.Errorf("The original parallelism annotation wasn't removed after stopping")
+	}
+}
+
+func TestPartialAdmissionCompletions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding batch scheme: %v", err)
+	}
+	cases := map[string]struct {
+		completions       int32
+		count             int32
+		wantParallelism   int32
+		wantOriginalSaved bool
+	}{
+		"more completions than the admitted count": {
+			completions:       10,
+			count:             3,
+			wantParallelism:   3,
+			wantOriginalSaved: true,
+		},
+		"completions within the admitted count": {
+			completions:     3,
+			count:           3,
+			wantParallelism: 5,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := utiltesting.MakeJob("job", "default").Parallelism(5).Completions(tc.completions).Obj()
+			wl := utiltesting.MakeWorkload("job", "default").
+				Admit(utiltesting.MakeAdmission("cq").Count(tc.count).Obj()).Obj()
+			wl.Spec.PodSets[0].Count = 5
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job, wl).Build()
+			ctx := context.Background()
+			r := NewReconciler(scheme, cl, record.NewFakeRecorder(10))
+
+			if err := r.startJob(ctx, wl, job); err != nil {
+				t.Fatalf("Failed starting the job: %v", err)
+			}
+			var started batchv1.Job
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(job), &started); err != nil {
+				t.Fatalf("Failed getting the job: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantParallelism, *started.Spec.Parallelism); diff != "" {
+				t.Errorf("Unexpected parallelism after starting (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.completions, *started.Spec.Completions); diff != "" {
+				t.Errorf("Unexpected completions after starting (-want,+got):\n%s", diff)
+			}
+			if _, ok := started.Annotations[constants.OriginalParallelismAnnotation]; ok != tc.wantOriginalSaved {
+				t.Errorf("Original parallelism saved %t, want %t", ok, tc.wantOriginalSaved)
+			}
+		})
+	}
+}
+
+func TestFirstPodsReadySinceAdmission(t *testing.T) {
+	admittedAt := metav1.Now()
+	before := metav1.NewTime(admittedAt.Add(-time.Minute))
+	after := metav1.NewTime(admittedAt.Add(time.Minute))
+	admitted := kueue.WorkloadCondition{
+		Type:               kueue.WorkloadAdmitted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: admittedAt,
+	}
+	cases := map[string]struct {
+		conditions []kueue.WorkloadCondition
+		want       bool
+	}{
+		"no PodsReady condition": {
+			conditions: []kueue.WorkloadCondition{admitted},
+			want:       true,
+		},
+		"pods not ready since a previous admission": {
+			conditions: []kueue.WorkloadCondition{
+				admitted,
+				{Type: kueue.WorkloadPodsReady, Status: corev1.ConditionFalse, LastTransitionTime: before},
+			},
+			want: true,
+		},
+		"pods not ready anymore since the admission": {
+			conditions: []kueue.WorkloadCondition{
+				admitted,
+				{Type: kueue.WorkloadPodsReady, Status: corev1.ConditionFalse, LastTransitionTime: after},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := utiltesting.MakeWorkload("job", "default").Obj()
+			wl.Status.Conditions = tc.conditions
+			if got := firstPodsReadySinceAdmission(wl); got != tc.want {
+				t.Errorf("firstPodsReadySinceAdmission() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSyncWorkloadStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding batch scheme: %v", err)
+	}
+	job := utiltesting.MakeJob("job", "default").Obj()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(job).Build()
+	ctx := context.Background()
+	r := NewReconciler(scheme, cl, record.NewFakeRecorder(10))
+
+	steps := []struct {
+		name        string
+		wl          *kueue.Workload
+		wantUpdated bool
+		wantStatus  string
+		wantReason  string
+	}{
+		{
+			name: "inadmissible",
+			wl: utiltesting.MakeWorkload("job", "default").Condition(kueue.WorkloadCondition{
+				Type:    kueue.WorkloadAdmitted,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Pending",
+				Message: "Workload didn't fit, couldn't assign a flavor for cpu",
+			}).Obj(),
+			wantUpdated: true,
+			wantStatus:  "Pending",
+			wantReason:  "Pending",
+		},
+		{
+			name: "inadmissible with a new message",
+			wl: utiltesting.MakeWorkload("job", "default").Condition(kueue.WorkloadCondition{
+				Type:    kueue.WorkloadAdmitted,
+				Status:  corev1.ConditionFalse,
+				Reason:  "Pending",
+				Message: "Workload didn't fit, couldn't assign a flavor for memory",
+			}).Obj(),
+			wantStatus: "Pending",
+			wantReason: "Pending",
+		},
+		{
+			name: "admitted",
+			wl: utiltesting.MakeWorkload("job", "default").
+				Admit(utiltesting.MakeAdmission("cq").Obj()).
+				Condition(kueue.WorkloadCondition{
+					Type:   kueue.WorkloadAdmitted,
+					Status: corev1.ConditionTrue,
+				}).Obj(),
+			wantUpdated: true,
+			wantStatus:  "Admitted",
+		},
+	}
+	for _, s := range steps {
+		var before batchv1.Job
+		if err := cl.Get(ctx, client.ObjectKeyFromObject(job), &before); err != nil {
+			t.Fatalf("Failed getting the job: %v", err)
+		}
+		if _, err := r.syncWorkloadStatus(ctx, before.DeepCopy(), s.wl); err != nil {
+			t.Fatalf("Step %q: failed syncing the workload status: %v", s.name, err)
+		}
+		var after batchv1.Job
+		if err := cl.Get(ctx, client.ObjectKeyFromObject(job), &after); err != nil {
+			t.Fatalf("Failed getting the job: %v", err)
+		}
+		if updated := after.ResourceVersion != before.ResourceVersion; updated != s.wantUpdated {
+			t.Errorf("Step %q: job updated: %t, want %t", s.name, updated, s.wantUpdated)
+		}
+		if got := after.Annotations[constants.WorkloadStatusAnnotation]; got != s.wantStatus {
+			t.Errorf("Step %q: got status annotation %q, want %q", s.name, got, s.wantStatus)
+		}
+		if got := after.Annotations[constants.WorkloadStatusReasonAnnotation]; got != s.wantReason {
+			t.Errorf("Step %q: got reason annotation %q, want %q", s.name, got, s.wantReason)
+		}
+	}
+}
+
+func TestStartedSinceAdmission(t *testing.T) {
+	admittedAt := metav1.Now()
+	admitted := kueue.WorkloadCondition{
+		Type:               kueue.WorkloadAdmitted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: admittedAt,
+	}
+	cases := map[string]struct {
+		conditions []kueue.WorkloadCondition
+		want       bool
+	}{
+		"no PodsCreated condition": {
+			conditions: []kueue.WorkloadCondition{admitted},
+		},
+		"PodsCreated condition left from an eviction": {
+			conditions: []kueue.WorkloadCondition{
+				admitted,
+				{Type: kueue.WorkloadPodsCreated, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(admittedAt.Add(-time.Minute))},
+			},
+		},
+		"started after the admission": {
+			conditions: []kueue.WorkloadCondition{
+				admitted,
+				{Type: kueue.WorkloadPodsCreated, Status: corev1.ConditionFalse, LastTransitionTime: admittedAt},
+			},
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := utiltesting.MakeWorkload("job", "default").Obj()
+			wl.Status.Conditions = tc.conditions
+			if got := startedSinceAdmission(wl); got != tc.want {
+				t.Errorf("startedSinceAdmission() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// splitWorkloadSlices separates the workload slices from the rest of the
+// workloads of a job.
+func splitWorkloadSlices(workloads kueue.WorkloadList) (kueue.WorkloadList, []kueue.Workload) {
+	var rest kueue.WorkloadList
+	var slices []kueue.Workload
+	for _, w := range workloads.Items {
+		if _, ok := w.Annotations[constants.WorkloadSliceOfAnnotation]; ok {
+			slices = append(slices, w)
+		} else {
+			rest.Items = append(rest.Items, w)
+		}
+	}
+	return rest, slices
+}
+
+// reconcileWorkloadSlices handles the parallelism increases of a running job.
+// Instead of suspending the job, the extra replicas are held back and
+// represented by a workload slice that goes through admission on its own.
+// Once the slice is admitted, it's merged into the workload of the job and the
+// job is allowed to grow.
+// Returns whether the job was handled, in which case the rest of the
+// reconciliation should be skipped.
+func (r *JobReconciler) reconcileWorkloadSlices(ctx context.Context, job *batchv1.Job, workloads kueue.WorkloadList, slices []kueue.Workload) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var wl *kueue.Workload
+	if len(workloads.Items) == 1 {
+		wl = &workloads.Items[0]
+	}
+	_, jobFinished := jobFinishedCondition(job)
+	running := wl != nil && wl.Spec.Admission != nil && !jobSuspended(job) && !jobFinished &&
+		len(wl.Spec.PodSets) == 1 && !workload.InCondition(wl, kueue.WorkloadFinished)
+
+	var slice *kueue.Workload
+	for i := range slices {
+		s := &slices[i]
+		if running && slice == nil && s.Annotations[constants.WorkloadSliceOfAnnotation] == wl.Name {
+			slice = s
+			continue
+		}
+		// The slices of workloads that are not running can't be merged.
+		if err := r.client.Delete(ctx, s); client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		r.record.Eventf(job, corev1.EventTypeNormal, "DeletedWorkloadSlice",
+			"Deleted not matching workload slice: %v", workload.Key(s))
+	}
+	if !running {
+		return false, nil
+	}
+
+	admitted := admittedParallelism(wl)
+	if adoptParallelism(job, admitted) {
+		log.V(2).Info("Job parallelism changed while holding replicas", "parallelism", requestedParallelism(job))
+		return true, r.client.Update(ctx, job)
+	}
+
+	if slice != nil && slice.Spec.Admission != nil {
+		log.V(2).Info("Workload slice admitted, merging", "workloadSlice", workload.Key(slice))
+		return true, r.mergeWorkloadSlice(ctx, job, wl, slice)
+	}
+
+	if !scaledUp(job, wl) {
+		if slice != nil && scaledDown(job, wl) {
+			// The job was scaled down while the slice was pending.
+			if err := r.client.Delete(ctx, slice); client.IgnoreNotFound(err) != nil {
+				return false, err
+			}
+			r.record.Eventf(job, corev1.EventTypeNormal, "DeletedWorkloadSlice",
+				"Deleted workload slice after scale down: %v", workload.Key(slice))
+		}
+		return false, nil
+	}
+	count := wl.Spec.PodSets[0].Count
+	delta := requestedParallelism(job) - count
+	if slice == nil {
+		newSlice, err := r.constructWorkloadSlice(ctx, job, wl, delta)
+		if err != nil {
+			return false, err
+		}
+		if err := r.client.Create(ctx, newSlice); err != nil {
+			return false, err
+		}
+		r.record.Eventf(job, corev1.EventTypeNormal, "CreatedWorkloadSlice",
+			"Created workload slice: %v", workload.Key(newSlice))
+	} else if slice.Spec.PodSets[0].Count != delta {
+		slice.Spec.PodSets[0].Count = delta
+		if err := r.client.Update(ctx, slice); err != nil {
+			return false, err
+		}
+	}
+
+	// Hold the extra replicas until the slice is admitted. The requested
+	// parallelism is kept in the annotations of the job.
+	if _, ok := originalParallelism(job); !ok || *job.Spec.Parallelism != admitted {
+		setAdmittedParallelism(job, admitted)
+		if err := r.client.Update(ctx, job); err != nil {
+			return false, err
+		}
+		r.record.Eventf(job, corev1.EventTypeNormal, "ScaleUpPending",
+			"Waiting for %d more replicas to be admitted", delta)
+	}
+	return true, nil
+}
+
+// mergeWorkloadSlice adds the count of an admitted slice to the workload,
+// lets the job grow and deletes the slice. The workload records the name of
+// the merged slice, so that the slice is not merged twice if deleting it
+// fails.
+func (r *JobReconciler) mergeWorkloadSlice(ctx context.Context, job *batchv1.Job, wl, slice *kueue.Workload) error {
+	if wl.Annotations[constants.MergedWorkloadSliceAnnotation] != slice.Name {
+		if !equality.Semantic.DeepEqual(wl.Spec.Admission.PodSetFlavors[0].Flavors, slice.Spec.Admission.PodSetFlavors[0].Flavors) {
+			// The pods of the job can only run in the flavors of the workload.
+			slice.Spec.Admission = nil
+			if err := r.client.Update(ctx, slice); err != nil {
+				return err
+			}
+			r.record.Eventf(job, corev1.EventTypeWarning, "WorkloadSliceFlavorMismatch",
+				"Workload slice %v was admitted with different flavors than the workload, requeueing", workload.Key(slice))
+			return nil
+		}
+		wl.Spec.PodSets[0].Count += slice.Spec.PodSets[0].Count
+		if count := wl.Spec.Admission.PodSetFlavors[0].Count; count != nil {
+			wl.Spec.Admission.PodSetFlavors[0].Count = pointer.Int32(*count + admittedParallelism(slice))
+		}
+		if wl.Annotations == nil {
+			wl.Annotations = map[string]string{}
+		}
+		wl.Annotations[constants.MergedWorkloadSliceAnnotation] = slice.Name
+		if err := r.client.Update(ctx, wl); err != nil {
+			return err
+		}
+	}
+
+	count := admittedParallelism(wl)
+	if releaseParallelism(job, count) {
+		if err := r.client.Update(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	if err := r.client.Delete(ctx, slice); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	r.record.Eventf(job, corev1.EventTypeNormal, "ScaledUp",
+		"Merged workload slice %v, the workload has %d replicas", workload.Key(slice), count)
+	return nil
+}
+
+// constructWorkloadSlice returns a workload slice of the given count for the
+// extra replicas of a job.
+func (r *JobReconciler) constructWorkloadSlice(ctx context.Context, job *batchv1.Job, wl *kueue.Workload, count int32) (*kueue.Workload, error) {
+	slice, err := ConstructWorkloadFor(ctx, r.client, job, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	slice.Name = ""
+	slice.GenerateName = job.Name + "-slice-"
+	slice.Annotations = map[string]string{
+		constants.WorkloadSliceOfAnnotation: wl.Name,
+	}
+	slice.Spec.QueueName = wl.Spec.QueueName
+	slice.Spec.PodSets[0].Count = count
+	// Keep the nodeSelector injected for the flavors of the workload, so that
+	// the slice is assigned the same flavors.
+	slice.Spec.PodSets[0].Spec.NodeSelector = nil
+	if len(job.Spec.Template.Spec.NodeSelector) != 0 {
+		slice.Spec.PodSets[0].Spec.NodeSelector = make(map[string]string, len(job.Spec.Template.Spec.NodeSelector))
+		for k, v := range job.Spec.Template.Spec.NodeSelector {
+			slice.Spec.PodSets[0].Spec.NodeSelector[k] = v
+		}
+	}
+	return slice, nil
+}
+
+// admittedParallelism returns the count of the podSet of the workload that is
+// admitted.
+func admittedParallelism(wl *kueue.Workload) int32 {
+	if count := wl.Spec.Admission.PodSetFlavors[0].Count; count != nil {
+		return *count
+	}
+	return wl.Spec.PodSets[0].Count
+}
+
+// adoptParallelism takes the parallelism of the job as the requested one when
+// it was changed, for example by an autoscaler, after it was reduced to the
+// admitted count. Returns whether the annotations of the job changed.
+func adoptParallelism(job *batchv1.Job, admitted int32) bool {
+	requested, ok := originalParallelism(job)
+	if !ok || *job.Spec.Parallelism == admitted || *job.Spec.Parallelism == requested {
+		return false
+	}
+	if *job.Spec.Parallelism < admitted {
+		// Nothing to hold, the job was scaled down.
+		delete(job.Annotations, constants.OriginalParallelismAnnotation)
+	} else {
+		job.Annotations[constants.OriginalParallelismAnnotation] = strconv.Itoa(int(*job.Spec.Parallelism))
+	}
+	return true
+}
+
+// releaseParallelism lets the job grow up to its requested parallelism, within
+// the admitted count. The annotation with the requested parallelism is removed
+// once it's fully admitted. Returns whether the job changed.
+func releaseParallelism(job *batchv1.Job, admitted int32) bool {
+	requested, ok := originalParallelism(job)
+	if !ok {
+		return false
+	}
+	if requested > admitted {
+		if *job.Spec.Parallelism == admitted {
+			return false
+		}
+		job.Spec.Parallelism = pointer.Int32(admitted)
+		return true
+	}
+	delete(job.Annotations, constants.OriginalParallelismAnnotation)
+	job.Spec.Parallelism = pointer.Int32(requested)
+	return true
+}
+
+// scaledUp returns whether the only difference between the job and its
+// workload is that the job has a higher parallelism.
+func scaledUp(job *batchv1.Job, wl *kueue.Workload) bool {
+	if len(wl.Spec.PodSets) != 1 || requestedParallelism(job) <= wl.Spec.PodSets[0].Count {
+		return false
+	}
+	return containersEqual(job.Spec.Template.Spec.InitContainers, wl.Spec.PodSets[0].Spec.InitContainers) &&
+		containersEqual(job.Spec.Template.Spec.Containers, wl.Spec.PodSets[0].Spec.Containers)
+}
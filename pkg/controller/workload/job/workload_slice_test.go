@@ -0,0 +1,322 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/constants"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func makeSliceTestJob(parallelism int32, requested string) *utiltesting.JobWrapper {
+	job := utiltesting.MakeJob("job", "default").Suspend(false).Parallelism(parallelism)
+	if requested != "" {
+		job.Annotations[constants.OriginalParallelismAnnotation] = requested
+	}
+	return job
+}
+
+func makeSliceTestWorkload(name string, count int32, admission *kueue.Admission) *utiltesting.WorkloadWrapper {
+	podSet := kueue.PodSet{
+		Name:  "main",
+		Count: count,
+		Spec:  *utiltesting.MakeJob("job", "default").Obj().Spec.Template.Spec.DeepCopy(),
+	}
+	wl := utiltesting.MakeWorkload(name, "default").PodSets([]kueue.PodSet{podSet})
+	if admission != nil {
+		wl.Admit(admission)
+	}
+	return wl
+}
+
+func makeSliceTestSlice(name string, count int32, admission *kueue.Admission) kueue.Workload {
+	slice := makeSliceTestWorkload(name, count, admission).Obj()
+	slice.Annotations = map[string]string{constants.WorkloadSliceOfAnnotation: "job"}
+	return *slice
+}
+
+func newSliceTestReconciler(t *testing.T, objs ...client.Object) (*JobReconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding client-go scheme: %v", err)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return NewReconciler(scheme, cl, record.NewFakeRecorder(10)), cl
+}
+
+// sliceTestState returns the parallelism and requested parallelism of the job,
+// the count of the workload and the counts of the slices.
+func sliceTestState(t *testing.T, cl client.Client) (int32, string, int32, []int32) {
+	t.Helper()
+	ctx := context.Background()
+	var job batchv1.Job
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "job"}, &job); err != nil {
+		t.Fatalf("Failed getting the job: %v", err)
+	}
+	var workloads kueue.WorkloadList
+	if err := cl.List(ctx, &workloads, client.InNamespace("default")); err != nil {
+		t.Fatalf("Failed listing the workloads: %v", err)
+	}
+	var count int32
+	var sliceCounts []int32
+	for _, w := range workloads.Items {
+		if _, ok := w.Annotations[constants.WorkloadSliceOfAnnotation]; ok {
+			sliceCounts = append(sliceCounts, w.Spec.PodSets[0].Count)
+		} else {
+			count = w.Spec.PodSets[0].Count
+		}
+	}
+	return *job.Spec.Parallelism, job.Annotations[constants.OriginalParallelismAnnotation], count, sliceCounts
+}
+
+func TestReconcileWorkloadSlices(t *testing.T) {
+	admission := utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()
+	cases := map[string]struct {
+		job             *batchv1.Job
+		slices          []kueue.Workload
+		wantHandled     bool
+		wantParallelism int32
+		wantRequested   string
+		wantCount       int32
+		wantSliceCounts []int32
+	}{
+		"scale up creates a slice and holds the extra replicas": {
+			job:             makeSliceTestJob(5, "").Obj(),
+			wantHandled:     true,
+			wantParallelism: 3,
+			wantRequested:   "5",
+			wantCount:       3,
+			wantSliceCounts: []int32{2},
+		},
+		"pending slice keeps the replicas held": {
+			job:             makeSliceTestJob(3, "5").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, nil)},
+			wantHandled:     true,
+			wantParallelism: 3,
+			wantRequested:   "5",
+			wantCount:       3,
+			wantSliceCounts: []int32{2},
+		},
+		"parallelism changed while holding the replicas": {
+			job:             makeSliceTestJob(7, "5").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, nil)},
+			wantHandled:     true,
+			wantParallelism: 7,
+			wantRequested:   "7",
+			wantCount:       3,
+			wantSliceCounts: []int32{2},
+		},
+		"pending slice resized to the requested parallelism": {
+			job:             makeSliceTestJob(7, "7").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, nil)},
+			wantHandled:     true,
+			wantParallelism: 3,
+			wantRequested:   "7",
+			wantCount:       3,
+			wantSliceCounts: []int32{4},
+		},
+		"scaled down while holding the replicas": {
+			job:             makeSliceTestJob(2, "5").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, nil)},
+			wantHandled:     true,
+			wantParallelism: 2,
+			wantCount:       3,
+			wantSliceCounts: []int32{2},
+		},
+		"pending slice deleted after a scale down": {
+			job:             makeSliceTestJob(2, "").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, nil)},
+			wantParallelism: 2,
+			wantCount:       3,
+		},
+		"admitted slice merged": {
+			job:             makeSliceTestJob(3, "5").Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, admission)},
+			wantHandled:     true,
+			wantParallelism: 5,
+			wantCount:       5,
+		},
+		"slices of a suspended job deleted": {
+			job:             makeSliceTestJob(3, "5").Suspend(true).Obj(),
+			slices:          []kueue.Workload{makeSliceTestSlice("slice", 2, admission)},
+			wantParallelism: 3,
+			wantRequested:   "5",
+			wantCount:       3,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wl := makeSliceTestWorkload("job", 3, admission).Obj()
+			objs := []client.Object{tc.job, wl}
+			for i := range tc.slices {
+				objs = append(objs, &tc.slices[i])
+			}
+			r, cl := newSliceTestReconciler(t, objs...)
+			ctx := context.Background()
+			var job batchv1.Job
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(tc.job), &job); err != nil {
+				t.Fatalf("Failed getting the job: %v", err)
+			}
+			var workloads kueue.WorkloadList
+			if err := cl.List(ctx, &workloads, client.InNamespace("default")); err != nil {
+				t.Fatalf("Failed listing the workloads: %v", err)
+			}
+			rest, slices := splitWorkloadSlices(workloads)
+
+			handled, err := r.reconcileWorkloadSlices(ctx, &job, rest, slices)
+			if err != nil {
+				t.Fatalf("Failed reconciling the workload slices: %v", err)
+			}
+			if handled != tc.wantHandled {
+				t.Errorf("reconcileWorkloadSlices() = %t, want %t", handled, tc.wantHandled)
+			}
+			parallelism, requested, count, sliceCounts := sliceTestState(t, cl)
+			if parallelism != tc.wantParallelism {
+				t.Errorf("Got parallelism %d, want %d", parallelism, tc.wantParallelism)
+			}
+			if requested != tc.wantRequested {
+				t.Errorf("Got requested parallelism %q, want %q", requested, tc.wantRequested)
+			}
+			if count != tc.wantCount {
+				t.Errorf("Got workload count %d, want %d", count, tc.wantCount)
+			}
+			if diff := cmp.Diff(tc.wantSliceCounts, sliceCounts); diff != "" {
+				t.Errorf("Unexpected slice counts (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeWorkloadSlice(t *testing.T) {
+	admission := utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Obj()
+	cases := map[string]struct {
+		job             *batchv1.Job
+		wl              *kueue.Workload
+		slice           kueue.Workload
+		wantParallelism int32
+		wantRequested   string
+		wantCount       int32
+		wantAdmitted    int32
+		wantSliceCounts []int32
+		wantSliceQueued bool
+	}{
+		"merged": {
+			job:             makeSliceTestJob(3, "5").Obj(),
+			wl:              makeSliceTestWorkload("job", 3, admission).Obj(),
+			slice:           makeSliceTestSlice("slice", 2, admission),
+			wantParallelism: 5,
+			wantCount:       5,
+			wantAdmitted:    5,
+		},
+		"already merged": {
+			job: makeSliceTestJob(3, "5").Obj(),
+			wl: func() *kueue.Workload {
+				wl := makeSliceTestWorkload("job", 5, admission).Obj()
+				wl.Annotations = map[string]string{constants.MergedWorkloadSliceAnnotation: "slice"}
+				return wl
+			}(),
+			slice:           makeSliceTestSlice("slice", 2, admission),
+			wantParallelism: 5,
+			wantCount:       5,
+			wantAdmitted:    5,
+		},
+		"partially admitted workload": {
+			job:             makeSliceTestJob(2, "5").Obj(),
+			wl:              makeSliceTestWorkload("job", 3, utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "default").Count(2).Obj()).Obj(),
+			slice:           makeSliceTestSlice("slice", 2, admission),
+			wantParallelism: 4,
+			wantRequested:   "5",
+			wantCount:       5,
+			wantAdmitted:    4,
+		},
+		"slice admitted in other flavors": {
+			job:             makeSliceTestJob(3, "5").Obj(),
+			wl:              makeSliceTestWorkload("job", 3, admission).Obj(),
+			slice:           makeSliceTestSlice("slice", 2, utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "spot").Obj()),
+			wantParallelism: 3,
+			wantRequested:   "5",
+			wantCount:       3,
+			wantAdmitted:    3,
+			wantSliceCounts: []int32{2},
+			wantSliceQueued: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, cl := newSliceTestReconciler(t, tc.job, tc.wl, &tc.slice)
+			ctx := context.Background()
+			var job batchv1.Job
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(tc.job), &job); err != nil {
+				t.Fatalf("Failed getting the job: %v", err)
+			}
+			var wl, slice kueue.Workload
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(tc.wl), &wl); err != nil {
+				t.Fatalf("Failed getting the workload: %v", err)
+			}
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(&tc.slice), &slice); err != nil {
+				t.Fatalf("Failed getting the slice: %v", err)
+			}
+
+			if err := r.mergeWorkloadSlice(ctx, &job, &wl, &slice); err != nil {
+				t.Fatalf("Failed merging the workload slice: %v", err)
+			}
+			parallelism, requested, count, sliceCounts := sliceTestState(t, cl)
+			if parallelism != tc.wantParallelism {
+				t.Errorf("Got parallelism %d, want %d", parallelism, tc.wantParallelism)
+			}
+			if requested != tc.wantRequested {
+				t.Errorf("Got requested parallelism %q, want %q", requested, tc.wantRequested)
+			}
+			if count != tc.wantCount {
+				t.Errorf("Got workload count %d, want %d", count, tc.wantCount)
+			}
+			if diff := cmp.Diff(tc.wantSliceCounts, sliceCounts); diff != "" {
+				t.Errorf("Unexpected slice counts (-want,+got):\n%s", diff)
+			}
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(tc.wl), &wl); err != nil {
+				t.Fatalf("Failed getting the workload: %v", err)
+			}
+			if got := admittedParallelism(&wl); got != tc.wantAdmitted {
+				t.Errorf("Got admitted count %d, want %d", got, tc.wantAdmitted)
+			}
+			if tc.wantSliceQueued {
+				if err := cl.Get(ctx, client.ObjectKeyFromObject(&tc.slice), &slice); err != nil {
+					t.Fatalf("Failed getting the slice: %v", err)
+				}
+				if slice.Spec.Admission != nil {
+					t.Errorf("Slice is still admitted, want it queued again")
+				}
+			}
+		})
+	}
+}
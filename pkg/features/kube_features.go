@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/featuregate"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+)
+
+// Every feature gate should be added here following this template:
+//
+// // owner: @username
+// // kep: http://kep.k8s.io/NNN (or a link to the issue)
+// // alpha: v0.x
+// //
+// // Description of the feature.
+// MyFeature featuregate.Feature = "MyFeature"
+//
+// and to defaultFeatureGates, with its default value and stage.
+
+const (
+	// alpha: v0.2
+	//
+	// Watches the Karpenter NodePools, so that the flavors whose nodes they
+	// provision are preferred for the workloads that fit in the limits of the
+	// NodePools.
+	NodePoolCapacity featuregate.Feature = "NodePoolCapacity"
+
+	// alpha: v0.2
+	//
+	// Lets running jobs grow their parallelism without being suspended. The
+	// extra replicas are represented by a workload slice that goes through
+	// admission on its own and is merged into the workload once admitted.
+	WorkloadSlices featuregate.Feature = "WorkloadSlices"
+)
+
+// DefaultMutableFeatureGate is the feature gate of Kueue. Its values are set
+// from the featureGates field of the Configuration at startup.
+var DefaultMutableFeatureGate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+// DefaultFeatureGate is a read-only view of DefaultMutableFeatureGate.
+var DefaultFeatureGate featuregate.FeatureGate = DefaultMutableFeatureGate
+
+func init() {
+	runtime.Must(DefaultMutableFeatureGate.Add(defaultFeatureGates))
+}
+
+// defaultFeatureGates consists of all known Kueue-specific feature keys.
+// To add a new feature, define a key for it above and add it here.
+//
+// Entries are separated from each other with blank lines to avoid sweeping
+// gofmt changes when adding or removing one entry.
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	NodePoolCapacity: {Default: false, PreRelease: featuregate.Alpha},
+
+	WorkloadSlices: {Default: false, PreRelease: featuregate.Alpha},
+}
+
+// Enabled returns whether the feature is enabled.
+func Enabled(f featuregate.Feature) bool {
+	return DefaultFeatureGate.Enabled(f)
+}
+
+// SetFeatureGateDuringTest sets the value of the feature for the duration of
+// the test. The returned function restores the previous value and should be
+// deferred.
+func SetFeatureGateDuringTest(tb testing.TB, f featuregate.Feature, value bool) func() {
+	return featuregatetesting.SetFeatureGateDuringTest(tb, DefaultFeatureGate, f, value)
+}
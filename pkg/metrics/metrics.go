@@ -30,6 +30,15 @@ const (
 
 	SuccessAdmissionResult      AdmissionResult = "success"
 	InadmissibleAdmissionResult AdmissionResult = "inadmissible"
+
+	PendingStatusActive       = "active"
+	PendingStatusInadmissible = "inadmissible"
+
+	CycleWorkloadsConsidered = "considered"
+	CycleWorkloadsAdmitted   = "admitted"
+
+	LocalQueueStatusPending  = "pending"
+	LocalQueueStatusAdmitted = "admitted"
 )
 
 var (
@@ -49,12 +58,116 @@ var (
 		}, []string{"result"},
 	)
 
+	snapshotLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: subsystemName,
+			Name:      "scheduler_snapshot_duration_seconds",
+			Help:      "Latency of taking the snapshot of the cache in a scheduling cycle.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 15),
+		},
+	)
+
+	schedulingCycleWorkloads = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystemName,
+			Name:      "scheduler_cycle_workloads",
+			Help: `Number of workloads in a scheduling cycle, per status.
+'status' can have the following values:
+- "considered" means that the workloads were at the head of their cluster_queue.
+- "admitted" means that the workloads were admitted in the cycle.`,
+			Buckets: prometheus.ExponentialBuckets(1, 2, 11),
+		}, []string{"status"},
+	)
+
+	admissionWaitTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystemName,
+			Name:      "admission_wait_time_seconds",
+			Help:      "The time between a workload was created until it was admitted, per cluster_queue.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"cluster_queue"},
+	)
+
+	podsReadyWaitTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystemName,
+			Name:      "pods_ready_wait_time_seconds",
+			Help:      "The time between a workload was admitted (its quota was reserved) until all its pods were ready, per cluster_queue.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"cluster_queue"},
+	)
+
+	EvictedWorkloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystemName,
+			Name:      "evicted_workloads_total",
+			Help:      "Number of admitted workloads that were evicted, broken down by reason.",
+		}, []string{"reason"},
+	)
+
+	PreemptedWorkloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystemName,
+			Name:      "preempted_workloads_total",
+			Help:      "Number of admitted workloads that were preempted to admit other workloads, broken down by reason.",
+		}, []string{"reason"},
+	)
+
 	PendingWorkloads = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: subsystemName,
 			Name:      "pending_workloads",
+			Help: `Number of pending workloads, per cluster_queue and status.
+'status' can have the following values:
+- "active" means that the workloads are in the admission queue.
+- "inadmissible" means there was a failed admission attempt for these workloads and they won't be retried until cluster conditions, which could make this workload admissible, change`,
+		}, []string{"cluster_queue", "status"})
+
+	QueuePendingWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "local_queue_pending_workloads",
 			Help:      "Number of pending workloads, per queue and cluster_queue.",
 		}, []string{"cluster_queue", "queue"})
+
+	LocalQueueWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "local_queue_workloads",
+			Help: `Number of workloads submitted to the local_queue, per namespace and status.
+'status' can have the following values:
+- "pending" means that the workloads are waiting to be admitted.
+- "admitted" means that the workloads were admitted and didn't finish.
+Only reported when the local queue metrics are enabled.`,
+		}, []string{"local_queue", "namespace", "status"})
+
+	LocalQueueResourceUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "local_queue_resource_usage",
+			Help:      "Reports the resources used by the admitted workloads of the local_queue, per namespace, flavor and resource. Only reported when the local queue metrics are enabled.",
+		}, []string{"local_queue", "namespace", "flavor", "resource"})
+
+	AdmittedActiveWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "admitted_active_workloads",
+			Help:      "Number of admitted workloads that are active (unsuspended and not finished), per cluster_queue.",
+		}, []string{"cluster_queue"})
+
+	ClusterQueueResourceUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "cluster_queue_resource_usage",
+			Help:      "Reports the cluster_queue's total resource usage, per flavor and resource.",
+		}, []string{"cluster_queue", "flavor", "resource"})
+
+	ClusterQueueNominalQuota = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystemName,
+			Name:      "cluster_queue_nominal_quota",
+			Help:      "Reports the cluster_queue's nominal (min) quota, per flavor and resource.",
+		}, []string{"cluster_queue", "flavor", "resource"})
 )
 
 func AdmissionAttempt(result AdmissionResult, duration time.Duration) {
@@ -62,10 +175,125 @@ func AdmissionAttempt(result AdmissionResult, duration time.Duration) {
 	admissionAttemptLatency.WithLabelValues(string(result)).Observe(duration.Seconds())
 }
 
+// SchedulingCycle records the latency of the snapshot and the number of
+// workloads considered and admitted in a scheduling cycle.
+func SchedulingCycle(snapshotDuration time.Duration, considered, admitted int) {
+	snapshotLatency.Observe(snapshotDuration.Seconds())
+	schedulingCycleWorkloads.WithLabelValues(CycleWorkloadsConsidered).Observe(float64(considered))
+	schedulingCycleWorkloads.WithLabelValues(CycleWorkloadsAdmitted).Observe(float64(admitted))
+}
+
+// AdmittedWorkload records the time the workload waited since its creation
+// until it was admitted in the cluster_queue.
+func AdmittedWorkload(cqName string, waitTime time.Duration) {
+	admissionWaitTime.WithLabelValues(cqName).Observe(waitTime.Seconds())
+}
+
+// PodsReadyWorkload records the time the pods of a workload admitted in the
+// cluster_queue took to be ready since its admission.
+func PodsReadyWorkload(cqName string, waitTime time.Duration) {
+	podsReadyWaitTime.WithLabelValues(cqName).Observe(waitTime.Seconds())
+}
+
+// EvictedWorkload increases the number of workloads evicted for the reason.
+func EvictedWorkload(reason string) {
+	EvictedWorkloadsTotal.WithLabelValues(reason).Inc()
+}
+
+// PreemptedWorkload increases the number of workloads preempted for the
+// reason.
+func PreemptedWorkload(reason string) {
+	PreemptedWorkloadsTotal.WithLabelValues(reason).Inc()
+}
+
+// ReportPendingWorkloads sets the number of pending workloads of the
+// cluster_queue.
+func ReportPendingWorkloads(cqName string, active, inadmissible int) {
+	PendingWorkloads.WithLabelValues(cqName, PendingStatusActive).Set(float64(active))
+	PendingWorkloads.WithLabelValues(cqName, PendingStatusInadmissible).Set(float64(inadmissible))
+}
+
+// ClearQueueSystemMetrics removes the pending workloads metrics of the
+// cluster_queue.
+func ClearQueueSystemMetrics(cqName string) {
+	PendingWorkloads.DeleteLabelValues(cqName, PendingStatusActive)
+	PendingWorkloads.DeleteLabelValues(cqName, PendingStatusInadmissible)
+}
+
+// ReportLocalQueueWorkloads sets the number of pending and admitted
+// workloads of the local_queue.
+func ReportLocalQueueWorkloads(name, namespace string, pending, admitted int) {
+	LocalQueueWorkloads.WithLabelValues(name, namespace, LocalQueueStatusPending).Set(float64(pending))
+	LocalQueueWorkloads.WithLabelValues(name, namespace, LocalQueueStatusAdmitted).Set(float64(admitted))
+}
+
+// ReportLocalQueueResourceUsage sets the usage of a resource flavor in the
+// local_queue.
+func ReportLocalQueueResourceUsage(name, namespace, flavor, resource string, usage float64) {
+	LocalQueueResourceUsage.WithLabelValues(name, namespace, flavor, resource).Set(usage)
+}
+
+// ClearLocalQueueWorkloads removes the workloads metrics of the local_queue.
+// The resource metrics are removed with ClearLocalQueueResourceUsage.
+func ClearLocalQueueWorkloads(name, namespace string) {
+	LocalQueueWorkloads.DeleteLabelValues(name, namespace, LocalQueueStatusPending)
+	LocalQueueWorkloads.DeleteLabelValues(name, namespace, LocalQueueStatusAdmitted)
+}
+
+// ClearLocalQueueResourceUsage removes the usage metric of a resource flavor
+// in the local_queue.
+func ClearLocalQueueResourceUsage(name, namespace, flavor, resource string) {
+	LocalQueueResourceUsage.DeleteLabelValues(name, namespace, flavor, resource)
+}
+
+// ReportAdmittedActiveWorkloads sets the number of admitted workloads of the
+// cluster_queue.
+func ReportAdmittedActiveWorkloads(cqName string, count int) {
+	AdmittedActiveWorkloads.WithLabelValues(cqName).Set(float64(count))
+}
+
+// ReportClusterQueueResourceUsage sets the usage of a resource flavor in the
+// cluster_queue.
+func ReportClusterQueueResourceUsage(cqName, flavor, resource string, usage float64) {
+	ClusterQueueResourceUsage.WithLabelValues(cqName, flavor, resource).Set(usage)
+}
+
+// ReportClusterQueueNominalQuota sets the nominal quota of a resource flavor
+// in the cluster_queue.
+func ReportClusterQueueNominalQuota(cqName, flavor, resource string, quota float64) {
+	ClusterQueueNominalQuota.WithLabelValues(cqName, flavor, resource).Set(quota)
+}
+
+// ClearClusterQueueResourceMetrics removes the usage and quota metrics of a
+// resource flavor in the cluster_queue.
+func ClearClusterQueueResourceMetrics(cqName, flavor, resource string) {
+	ClusterQueueResourceUsage.DeleteLabelValues(cqName, flavor, resource)
+	ClusterQueueNominalQuota.DeleteLabelValues(cqName, flavor, resource)
+}
+
+// ClearCacheMetrics removes the admitted workloads metric of the
+// cluster_queue. The resource metrics are removed with
+// ClearClusterQueueResourceMetrics.
+func ClearCacheMetrics(cqName string) {
+	AdmittedActiveWorkloads.DeleteLabelValues(cqName)
+}
+
 func Register() {
 	metrics.Registry.MustRegister(
 		admissionAttempts,
 		admissionAttemptLatency,
+		snapshotLatency,
+		schedulingCycleWorkloads,
+		admissionWaitTime,
+		podsReadyWaitTime,
+		EvictedWorkloadsTotal,
+		PreemptedWorkloadsTotal,
 		PendingWorkloads,
+		QueuePendingWorkloads,
+		LocalQueueWorkloads,
+		LocalQueueResourceUsage,
+		AdmittedActiveWorkloads,
+		ClusterQueueResourceUsage,
+		ClusterQueueNominalQuota,
 	)
 }
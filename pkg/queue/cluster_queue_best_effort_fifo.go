@@ -18,6 +18,7 @@ package queue
 
 import (
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/workload"
@@ -36,8 +37,8 @@ var _ ClusterQueue = &ClusterQueueBestEffortFIFO{}
 
 const BestEffortFIFO = kueue.BestEffortFIFO
 
-func newClusterQueueBestEffortFIFO(cq *kueue.ClusterQueue) (ClusterQueue, error) {
-	cqImpl := newClusterQueueImpl(keyFunc, byCreationTime)
+func newClusterQueueBestEffortFIFO(cq *kueue.ClusterQueue, o Ordering) (ClusterQueue, error) {
+	cqImpl := newClusterQueueImpl(keyFunc, o)
 	cqBE := &ClusterQueueBestEffortFIFO{
 		ClusterQueueImpl:      cqImpl,
 		inadmissibleWorkloads: make(map[string]*workload.Info),
@@ -108,21 +109,36 @@ func (cq *ClusterQueueBestEffortFIFO) RequeueIfNotPresent(wInfo *workload.Info,
 	return true
 }
 
-// QueueInadmissibleWorkloads moves all workloads from inadmissibleWorkloads to heap.
+// QueueInadmissibleWorkloads moves workloads from inadmissibleWorkloads to heap.
+// When the released resources are given, the workloads that were short of
+// other resources are kept in inadmissibleWorkloads, as the released quota
+// doesn't make them fit.
 // If at least one workload is moved, returns true. Otherwise returns false.
-func (cq *ClusterQueueBestEffortFIFO) QueueInadmissibleWorkloads() bool {
-	if len(cq.inadmissibleWorkloads) == 0 {
-		return false
-	}
-
-	for _, wInfo := range cq.inadmissibleWorkloads {
+func (cq *ClusterQueueBestEffortFIFO) QueueInadmissibleWorkloads(released sets.String) bool {
+	moved := false
+	for key, wInfo := range cq.inadmissibleWorkloads {
+		if released != nil && wInfo.ShortResources.Len() > 0 && !released.HasAny(wInfo.ShortResources.UnsortedList()...) {
+			continue
+		}
 		cq.ClusterQueueImpl.pushIfNotPresent(wInfo)
+		delete(cq.inadmissibleWorkloads, key)
+		moved = true
 	}
-
-	cq.inadmissibleWorkloads = make(map[string]*workload.Info)
-	return true
+	return moved
 }
 
 func (cq *ClusterQueueBestEffortFIFO) Pending() int32 {
-	return cq.ClusterQueueImpl.Pending() + int32(len(cq.inadmissibleWorkloads))
+	return cq.PendingActive() + cq.PendingInadmissible()
+}
+
+func (cq *ClusterQueueBestEffortFIFO) PendingInadmissible() int32 {
+	return int32(len(cq.inadmissibleWorkloads))
+}
+
+func (cq *ClusterQueueBestEffortFIFO) PendingInadmissibleInfo() []*workload.Info {
+	infos := make([]*workload.Info, 0, len(cq.inadmissibleWorkloads))
+	for _, info := range cq.inadmissibleWorkloads {
+		infos = append(infos, info)
+	}
+	return infos
 }
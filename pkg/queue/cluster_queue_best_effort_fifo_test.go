@@ -40,6 +40,9 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 	updatedWorkloads[1] = workloads[1].DeepCopy()
 	updatedWorkloads[1].Spec.QueueName = "q1"
 
+	shortOfCPU := workload.NewInfo(workloads[1])
+	shortOfCPU.ShortResources = sets.NewString("cpu")
+
 	tests := map[string]struct {
 		workloadsToAdd                 []*kueue.Workload
 		inadmissibleWorkloadsToRequeue []*workload.Info
@@ -47,8 +50,11 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 		workloadsToUpdate              []*kueue.Workload
 		workloadsToDelete              []*kueue.Workload
 		queueInadmissibleWorkloads     bool
-		wantActiveWorkloads            sets.String
-		wantPending                    int32
+		// releasedResources are passed when queueing the inadmissible
+		// workloads.
+		releasedResources   sets.String
+		wantActiveWorkloads sets.String
+		wantPending         int32
 	}{
 		"add, update, delete workload": {
 			workloadsToAdd:                 []*kueue.Workload{workloads[0], workloads[1]},
@@ -78,6 +84,22 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 			wantActiveWorkloads:            sets.NewString(workloads[0].Name, workloads[1].Name),
 			wantPending:                    2,
 		},
+		"re-queue inadmissible workload short of a released resource": {
+			workloadsToAdd:                 []*kueue.Workload{workloads[0]},
+			inadmissibleWorkloadsToRequeue: []*workload.Info{shortOfCPU},
+			queueInadmissibleWorkloads:     true,
+			releasedResources:              sets.NewString("cpu", "memory"),
+			wantActiveWorkloads:            sets.NewString(workloads[0].Name, workloads[1].Name),
+			wantPending:                    2,
+		},
+		"keep inadmissible workload short of a resource that wasn't released": {
+			workloadsToAdd:                 []*kueue.Workload{workloads[0]},
+			inadmissibleWorkloadsToRequeue: []*workload.Info{shortOfCPU},
+			queueInadmissibleWorkloads:     true,
+			releasedResources:              sets.NewString("memory"),
+			wantActiveWorkloads:            sets.NewString(workloads[0].Name),
+			wantPending:                    2,
+		},
 		"update inadmissible workload": {
 			workloadsToAdd:                 []*kueue.Workload{workloads[0]},
 			inadmissibleWorkloadsToRequeue: []*workload.Info{workload.NewInfo(workloads[1])},
@@ -97,7 +119,7 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			cq, err := newClusterQueueBestEffortFIFO(clusterQueue)
+			cq, err := newClusterQueueBestEffortFIFO(clusterQueue, PriorityOrdering{})
 			if err != nil {
 				t.Fatalf("Failed creating ClusterQueue %v", err)
 			}
@@ -122,7 +144,7 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 			}
 
 			if test.queueInadmissibleWorkloads {
-				cq.QueueInadmissibleWorkloads()
+				cq.QueueInadmissibleWorkloads(test.releasedResources)
 			}
 
 			gotWorkloads, _ := cq.Dump()
@@ -138,7 +160,7 @@ func TestClusterQueueBestEffortFIFO(t *testing.T) {
 
 func TestDeleteFromQueue(t *testing.T) {
 	cq := utiltesting.MakeClusterQueue("cq").Obj()
-	cqImpl, err := newClusterQueueBestEffortFIFO(cq)
+	cqImpl, err := newClusterQueueBestEffortFIFO(cq, PriorityOrdering{})
 	if err != nil {
 		t.Fatalf("Failed creating ClusterQueue %v", err)
 	}
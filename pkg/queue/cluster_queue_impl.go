@@ -17,10 +17,14 @@ limitations under the License.
 package queue
 
 import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/util/heap"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -33,11 +37,56 @@ type ClusterQueueImpl struct {
 
 	heap   heap.Heap
 	cohort string
+
+	// ordering is the order of the workloads. The priority aging policy, if
+	// any, takes precedence.
+	ordering Ordering
+	// aging is the priority aging policy of the ClusterQueue.
+	aging *kueue.PriorityAging
+	// agedAt is the time at which the aged priorities of the workloads are
+	// computed. The heap is only reordered when it changes.
+	agedAt time.Time
+	// nextBoost is the earliest time after agedAt at which the aged priority
+	// of a workload in the heap grows. Zero if none of them grows.
+	nextBoost time.Time
 }
 
-func newClusterQueueImpl(keyFunc func(obj interface{}) string, lessFunc func(a, b interface{}) bool) *ClusterQueueImpl {
-	return &ClusterQueueImpl{
-		heap: heap.New(keyFunc, lessFunc),
+func newClusterQueueImpl(keyFunc func(obj interface{}) string, o Ordering) *ClusterQueueImpl {
+	c := &ClusterQueueImpl{
+		ordering: o,
+	}
+	c.heap = heap.New(keyFunc, c.less)
+	return c
+}
+
+// less orders the workloads by their aged priority at agedAt if the
+// ClusterQueue has a priority aging policy, and by its ordering otherwise.
+func (c *ClusterQueueImpl) less(a, b interface{}) bool {
+	if c.aging == nil {
+		return c.ordering.Less(a.(*workload.Info), b.(*workload.Info))
+	}
+	return agingOrdering{base: c.ordering, aging: c.aging, now: c.agedAt}.Less(a.(*workload.Info), b.(*workload.Info))
+}
+
+// reorder ages the priorities of the workloads until now, and reorders the
+// heap accordingly.
+func (c *ClusterQueueImpl) reorder(now time.Time) {
+	c.agedAt = now
+	c.nextBoost = time.Time{}
+	c.heap.Reorder()
+	for _, item := range c.heap.List() {
+		c.trackNextBoost(item.(*workload.Info))
+	}
+}
+
+// trackNextBoost updates nextBoost with the time at which the aged priority
+// of the workload grows.
+func (c *ClusterQueueImpl) trackNextBoost(info *workload.Info) {
+	if c.aging == nil {
+		return
+	}
+	if t, ok := utilpriority.NextBoost(info.Obj, c.aging, c.agedAt); ok && (c.nextBoost.IsZero() || t.Before(c.nextBoost)) {
+		c.nextBoost = t
 	}
 }
 
@@ -46,6 +95,10 @@ var _ ClusterQueue = &ClusterQueueImpl{}
 func (c *ClusterQueueImpl) Update(apiCQ *kueue.ClusterQueue) {
 	c.QueueingStrategy = apiCQ.Spec.QueueingStrategy
 	c.cohort = apiCQ.Spec.Cohort
+	if !equality.Semantic.DeepEqual(c.aging, apiCQ.Spec.PriorityAging) {
+		c.aging = apiCQ.Spec.PriorityAging.DeepCopy()
+		c.reorder(time.Now())
+	}
 }
 
 func (c *ClusterQueueImpl) Cohort() string {
@@ -71,11 +124,16 @@ func (c *ClusterQueueImpl) DeleteFromQueue(q *Queue) {
 // pushIfNotPresent pushes the workload to ClusterQueue.
 // If the workload is already present, returns false. Otherwise returns true.
 func (c *ClusterQueueImpl) pushIfNotPresent(info *workload.Info) bool {
-	return c.heap.PushIfNotPresent(info)
+	if !c.heap.PushIfNotPresent(info) {
+		return false
+	}
+	c.trackNextBoost(info)
+	return true
 }
 
 func (c *ClusterQueueImpl) PushOrUpdate(info *workload.Info) {
 	c.heap.PushOrUpdate(info)
+	c.trackNextBoost(info)
 }
 
 func (c *ClusterQueueImpl) Delete(w *kueue.Workload) {
@@ -86,7 +144,7 @@ func (c *ClusterQueueImpl) RequeueIfNotPresent(wInfo *workload.Info, _ bool) boo
 	return c.pushIfNotPresent(wInfo)
 }
 
-func (c *ClusterQueueImpl) QueueInadmissibleWorkloads() bool {
+func (c *ClusterQueueImpl) QueueInadmissibleWorkloads(sets.String) bool {
 	return false
 }
 
@@ -95,6 +153,14 @@ func (c *ClusterQueueImpl) Pop() *workload.Info {
 		return nil
 	}
 
+	// The aged priorities grow and stop growing at different times, so the
+	// order of the workloads might have changed since they were pushed. The
+	// heap is only reordered once a priority grew.
+	if c.aging != nil && !c.nextBoost.IsZero() {
+		if now := time.Now(); !now.Before(c.nextBoost) {
+			c.reorder(now)
+		}
+	}
 	info := c.heap.Pop()
 	if info == nil {
 		return nil
@@ -103,9 +169,17 @@ func (c *ClusterQueueImpl) Pop() *workload.Info {
 }
 
 func (c *ClusterQueueImpl) Pending() int32 {
+	return c.PendingActive() + c.PendingInadmissible()
+}
+
+func (c *ClusterQueueImpl) PendingActive() int32 {
 	return int32(c.heap.Len())
 }
 
+func (c *ClusterQueueImpl) PendingInadmissible() int32 {
+	return 0
+}
+
 func (c *ClusterQueueImpl) Dump() (sets.String, bool) {
 	if c.heap.Len() == 0 {
 		return sets.NewString(), false
@@ -118,6 +192,19 @@ func (c *ClusterQueueImpl) Dump() (sets.String, bool) {
 	return elements, true
 }
 
+func (c *ClusterQueueImpl) PendingActiveInfo() []*workload.Info {
+	items := c.heap.Sorted()
+	infos := make([]*workload.Info, len(items))
+	for i, item := range items {
+		infos[i] = item.(*workload.Info)
+	}
+	return infos
+}
+
+func (c *ClusterQueueImpl) PendingInadmissibleInfo() []*workload.Info {
+	return nil
+}
+
 func (c *ClusterQueueImpl) Info(key string) *workload.Info {
 	info := c.heap.GetByKey(key)
 	if info == nil {
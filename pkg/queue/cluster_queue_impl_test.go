@@ -20,7 +20,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
@@ -30,7 +33,7 @@ const (
 )
 
 func Test_PushOrUpdate(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if cq.Pending() != 0 {
 		t.Error("ClusterQueue should be empty")
@@ -50,7 +53,7 @@ func Test_PushOrUpdate(t *testing.T) {
 }
 
 func Test_Pop(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	now := time.Now()
 	wl1 := workload.NewInfo(utiltesting.MakeWorkload("workload-1", defaultNamespace).Creation(now).Obj())
 	wl2 := workload.NewInfo(utiltesting.MakeWorkload("workload-2", defaultNamespace).Creation(now.Add(time.Second)).Obj())
@@ -72,8 +75,78 @@ func Test_Pop(t *testing.T) {
 	}
 }
 
+func Test_PopWithPriorityAging(t *testing.T) {
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
+	now := time.Now()
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("low", defaultNamespace).Priority(pointer.Int32(0)).Creation(now.Add(-time.Hour)).Obj(),
+		utiltesting.MakeWorkload("medium", defaultNamespace).Priority(pointer.Int32(50)).Creation(now.Add(-2 * time.Minute)).Obj(),
+		utiltesting.MakeWorkload("high", defaultNamespace).Priority(pointer.Int32(100)).Creation(now).Obj(),
+	} {
+		cq.PushOrUpdate(workload.NewInfo(wl))
+	}
+	if diff := cmp.Diff([]string{"high", "medium", "low"}, workloadNames(cq.PendingActiveInfo())); diff != "" {
+		t.Errorf("Unexpected order without priority aging (-want,+got):\n%s", diff)
+	}
+
+	cq.Update(utiltesting.MakeClusterQueue("cq").PriorityAging(10, 200).Obj())
+	var got []string
+	for wl := cq.Pop(); wl != nil; wl = cq.Pop() {
+		got = append(got, wl.Obj.Name)
+	}
+	if diff := cmp.Diff([]string{"low", "high", "medium"}, got); diff != "" {
+		t.Errorf("Unexpected order with priority aging (-want,+got):\n%s", diff)
+	}
+}
+
+// nameOrdering orders the workloads by name.
+type nameOrdering struct{}
+
+func (nameOrdering) Less(a, b *workload.Info) bool {
+	return a.Obj.Name < b.Obj.Name
+}
+
+func Test_PopWithPriorityAgingAndOrdering(t *testing.T) {
+	cq := newClusterQueueImpl(keyFunc, nameOrdering{})
+	now := time.Now()
+	for _, wl := range []*kueue.Workload{
+		utiltesting.MakeWorkload("a", defaultNamespace).Priority(pointer.Int32(50)).Creation(now).Obj(),
+		utiltesting.MakeWorkload("b", defaultNamespace).Priority(pointer.Int32(0)).Creation(now.Add(-time.Hour)).Obj(),
+		utiltesting.MakeWorkload("c", defaultNamespace).Priority(pointer.Int32(100)).Creation(now).Obj(),
+		utiltesting.MakeWorkload("d", defaultNamespace).Priority(pointer.Int32(50)).Creation(now.Add(-time.Hour)).Obj(),
+	} {
+		cq.PushOrUpdate(workload.NewInfo(wl))
+	}
+	cq.Update(utiltesting.MakeClusterQueue("cq").PriorityAging(10, 50).Obj())
+	agedAt := cq.agedAt
+	if !cq.nextBoost.After(agedAt) {
+		t.Errorf("Next boost at %v, want after %v", cq.nextBoost, agedAt)
+	}
+
+	// The aged priorities are 50, 50, 100 and 100, so the ordering breaks
+	// the ties.
+	var got []string
+	for wl := cq.Pop(); wl != nil; wl = cq.Pop() {
+		got = append(got, wl.Obj.Name)
+	}
+	if diff := cmp.Diff([]string{"c", "d", "a", "b"}, got); diff != "" {
+		t.Errorf("Unexpected order with priority aging (-want,+got):\n%s", diff)
+	}
+	if !cq.agedAt.Equal(agedAt) {
+		t.Errorf("The workloads were aged again at %v before the next boost", cq.agedAt)
+	}
+}
+
+func workloadNames(infos []*workload.Info) []string {
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Obj.Name
+	}
+	return names
+}
+
 func Test_Delete(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl1 := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	wl2 := utiltesting.MakeWorkload("workload-2", defaultNamespace).Obj()
 	cq.PushOrUpdate(workload.NewInfo(wl1))
@@ -94,7 +167,7 @@ func Test_Delete(t *testing.T) {
 }
 
 func Test_Dump(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl1 := workload.NewInfo(utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj())
 	wl2 := workload.NewInfo(utiltesting.MakeWorkload("workload-2", defaultNamespace).Obj())
 	if _, ok := cq.Dump(); ok {
@@ -108,7 +181,7 @@ func Test_Dump(t *testing.T) {
 }
 
 func Test_Info(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if info := cq.Info(keyFunc(workload.NewInfo(wl))); info != nil {
 		t.Error("workload doesn't exist")
@@ -120,7 +193,7 @@ func Test_Info(t *testing.T) {
 }
 
 func Test_AddFromQueue(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	queue := &Queue{
 		items: map[string]*workload.Info{
@@ -138,7 +211,7 @@ func Test_AddFromQueue(t *testing.T) {
 }
 
 func Test_DeleteFromQueue(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl1 := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	wl2 := utiltesting.MakeWorkload("workload-2", defaultNamespace).Obj()
 	queue := &Queue{
@@ -158,7 +231,7 @@ func Test_DeleteFromQueue(t *testing.T) {
 }
 
 func Test_RequeueIfNotPresent(t *testing.T) {
-	cq := newClusterQueueImpl(keyFunc, byCreationTime)
+	cq := newClusterQueueImpl(keyFunc, PriorityOrdering{})
 	wl := utiltesting.MakeWorkload("workload-1", defaultNamespace).Obj()
 	if ok := cq.RequeueIfNotPresent(workload.NewInfo(wl), true); !ok {
 		t.Error("failed to requeue nonexistent workload")
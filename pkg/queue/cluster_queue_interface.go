@@ -18,10 +18,12 @@ package queue
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
@@ -60,32 +62,88 @@ type ClusterQueue interface {
 	// The workload should not be reinserted if it's already in the ClusterQueue.
 	// Returns true if the workload was inserted.
 	RequeueIfNotPresent(*workload.Info, bool) bool
-	// QueueInadmissibleWorkloads moves all workloads put in temporary placeholder stage
-	// to the ClusterQueue. If at least one workload is moved,
-	// returns true. Otherwise returns false.
-	QueueInadmissibleWorkloads() bool
+	// QueueInadmissibleWorkloads moves the workloads put in temporary placeholder stage
+	// to the ClusterQueue. If the set of released resources is not nil, it
+	// only moves the workloads that were short of one of the released
+	// resources, or that were not admitted for other reasons.
+	// If at least one workload is moved, returns true. Otherwise returns false.
+	QueueInadmissibleWorkloads(released sets.String) bool
 
 	// Pending returns the number of pending workloads.
 	Pending() int32
+	// PendingActive returns the number of workloads in the heap.
+	PendingActive() int32
+	// PendingInadmissible returns the number of workloads that are waiting
+	// for cluster events to be queued again.
+	PendingInadmissible() int32
 	// Dump produces a dump of the current workloads in the heap of
 	// this ClusterQueue. It returns false if the queue is empty.
 	// Otherwise returns true.
 	Dump() (sets.String, bool)
+	// PendingActiveInfo returns the workloads in the heap, in the order in
+	// which they would be popped.
+	// Users of this method should not modify the returned objects.
+	PendingActiveInfo() []*workload.Info
+	// PendingInadmissibleInfo returns the workloads that are waiting for
+	// cluster events to be queued again, unordered.
+	// Users of this method should not modify the returned objects.
+	PendingInadmissibleInfo() []*workload.Info
 	// Info returns workload.Info for the workload key.
 	// Users of this method should not modify the returned object.
 	Info(string) *workload.Info
 }
 
-var registry = map[kueue.QueueingStrategy]func(cq *kueue.ClusterQueue) (ClusterQueue, error){
+// Ordering defines the order in which the workloads of a ClusterQueue are
+// popped. The workloads are kept in a heap, so popping the head of a
+// ClusterQueue is O(log n) for any ordering.
+type Ordering interface {
+	// Less returns true if workload a should be popped before workload b.
+	Less(a, b *workload.Info) bool
+}
+
+// PriorityOrdering orders the workloads by priority and, when priorities are
+// equal, by creation timestamp. It's the default ordering.
+type PriorityOrdering struct{}
+
+func (PriorityOrdering) Less(a, b *workload.Info) bool {
+	p1 := utilpriority.Priority(a.Obj)
+	p2 := utilpriority.Priority(b.Obj)
+
+	if p1 != p2 {
+		return p1 > p2
+	}
+	return a.Obj.CreationTimestamp.Before(&b.Obj.CreationTimestamp)
+}
+
+// agingOrdering orders the workloads by their priority at the given time,
+// boosted by the aging policy of their ClusterQueue and, when the aged
+// priorities are equal, by the base ordering.
+type agingOrdering struct {
+	base  Ordering
+	aging *kueue.PriorityAging
+	now   time.Time
+}
+
+func (o agingOrdering) Less(a, b *workload.Info) bool {
+	p1 := utilpriority.Aged(a.Obj, o.aging, o.now)
+	p2 := utilpriority.Aged(b.Obj, o.aging, o.now)
+
+	if p1 != p2 {
+		return p1 > p2
+	}
+	return o.base.Less(a, b)
+}
+
+var registry = map[kueue.QueueingStrategy]func(cq *kueue.ClusterQueue, o Ordering) (ClusterQueue, error){
 	StrictFIFO:     newClusterQueueStrictFIFO,
 	BestEffortFIFO: newClusterQueueBestEffortFIFO,
 }
 
-func newClusterQueue(cq *kueue.ClusterQueue) (ClusterQueue, error) {
+func newClusterQueue(cq *kueue.ClusterQueue, o Ordering) (ClusterQueue, error) {
 	strategy := cq.Spec.QueueingStrategy
 	f, exist := registry[strategy]
 	if !exist {
 		return nil, fmt.Errorf("invalid QueueingStrategy %q", cq.Spec.QueueingStrategy)
 	}
-	return f(cq)
+	return f(cq, o)
 }
@@ -18,8 +18,6 @@ package queue
 
 import (
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
-	utilpriority "sigs.k8s.io/kueue/pkg/util/priority"
-	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 // ClusterQueueStrictFIFO is the implementation for the ClusterQueue for
@@ -32,23 +30,8 @@ var _ ClusterQueue = &ClusterQueueStrictFIFO{}
 
 const StrictFIFO = kueue.StrictFIFO
 
-func newClusterQueueStrictFIFO(cq *kueue.ClusterQueue) (ClusterQueue, error) {
-	cqImpl := newClusterQueueImpl(keyFunc, byCreationTime)
+func newClusterQueueStrictFIFO(cq *kueue.ClusterQueue, o Ordering) (ClusterQueue, error) {
+	cqImpl := newClusterQueueImpl(keyFunc, o)
 	cqImpl.Update(cq)
 	return cqImpl, nil
 }
-
-// byCreationTime is the function used by the clusterQueue heap algorithm to sort
-// workloads. It sorts workloads based on their priority.
-// When priorities are equal, it uses workloads.creationTimestamp.
-func byCreationTime(a, b interface{}) bool {
-	objA := a.(*workload.Info)
-	objB := b.(*workload.Info)
-	p1 := utilpriority.Priority(objA.Obj)
-	p2 := utilpriority.Priority(objB.Obj)
-
-	if p1 != p2 {
-		return p1 > p2
-	}
-	return objA.Obj.CreationTimestamp.Before(&objB.Obj.CreationTimestamp)
-}
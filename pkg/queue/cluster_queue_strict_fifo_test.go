@@ -37,7 +37,7 @@ func TestFIFOClusterQueue(t *testing.T) {
 		Spec: kueue.ClusterQueueSpec{
 			QueueingStrategy: kueue.StrictFIFO,
 		},
-	})
+	}, PriorityOrdering{})
 	if err != nil {
 		t.Fatalf("Failed creating ClusterQueue %v", err)
 	}
@@ -175,7 +175,7 @@ func TestStrictFIFO(t *testing.T) {
 				Spec: kueue.ClusterQueueSpec{
 					QueueingStrategy: kueue.StrictFIFO,
 				},
-			})
+			}, PriorityOrdering{})
 			if err != nil {
 				t.Fatalf("Failed creating ClusterQueue %v", err)
 			}
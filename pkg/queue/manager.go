@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -27,12 +28,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const (
-	workloadQueueKey     = "spec.queueName"
+	// WorkloadQueueKey is the index of the Workloads by the queue they are
+	// submitted to.
+	WorkloadQueueKey     = "spec.queueName"
 	queueClusterQueueKey = "spec.clusterQueue"
 )
 
@@ -53,15 +58,76 @@ type Manager struct {
 
 	// Key is cohort's name. Value is a set of associated ClusterQueue names.
 	cohorts map[string]sets.String
+
+	// workloadInfoOptions are used to compute the requests of the pending
+	// workloads.
+	workloadInfoOptions []workload.InfoOption
+	// localQueueMetrics indicates if the number of pending workloads of each
+	// Queue is reported.
+	localQueueMetrics bool
+	// ordering is the order of the workloads in the ClusterQueues.
+	ordering Ordering
+}
+
+type options struct {
+	workloadInfoOptions []workload.InfoOption
+	localQueueMetrics   bool
+	ordering            Ordering
 }
 
-func NewManager(client client.Client, checker StatusChecker) *Manager {
+// Option configures the manager.
+type Option func(*options)
+
+// WithExcludedResourcePrefixes sets the prefixes of the resource names that
+// are left out of the requests of the pending workloads.
+func WithExcludedResourcePrefixes(prefixes []string) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithExcludedResourcePrefixes(prefixes))
+	}
+}
+
+// WithResourceTransformations sets the transformations applied to the
+// requests of the pending workloads.
+func WithResourceTransformations(transformations []configapi.ResourceTransformation) Option {
+	return func(o *options) {
+		o.workloadInfoOptions = append(o.workloadInfoOptions, workload.WithResourceTransformations(transformations))
+	}
+}
+
+// WithLocalQueueMetrics indicates if the manager should report the number of
+// pending workloads of each Queue.
+func WithLocalQueueMetrics(enabled bool) Option {
+	return func(o *options) {
+		o.localQueueMetrics = enabled
+	}
+}
+
+// WithOrdering sets the order in which the workloads of the ClusterQueues are
+// popped. By default, workloads are ordered by priority and creation
+// timestamp. In the ClusterQueues with a priority aging policy, the aged
+// priorities take precedence and the ordering breaks the ties.
+func WithOrdering(o Ordering) Option {
+	return func(opts *options) {
+		opts.ordering = o
+	}
+}
+
+func NewManager(client client.Client, checker StatusChecker, opts ...Option) *Manager {
+	options := options{
+		ordering: PriorityOrdering{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	m := &Manager{
-		client:        client,
-		statusChecker: checker,
-		queues:        make(map[string]*Queue),
-		clusterQueues: make(map[string]ClusterQueue),
-		cohorts:       make(map[string]sets.String),
+		client:              client,
+		statusChecker:       checker,
+		queues:              make(map[string]*Queue),
+		clusterQueues:       make(map[string]ClusterQueue),
+		cohorts:             make(map[string]sets.String),
+		workloadInfoOptions: options.workloadInfoOptions,
+		localQueueMetrics:   options.localQueueMetrics,
+		ordering:            options.ordering,
 	}
 	m.cond.L = &m.RWMutex
 	return m
@@ -75,7 +141,7 @@ func (m *Manager) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) e
 		return errClusterQueueAlreadyExists
 	}
 
-	cqImpl, err := newClusterQueue(cq)
+	cqImpl, err := newClusterQueue(cq, m.ordering)
 	if err != nil {
 		return err
 	}
@@ -99,13 +165,14 @@ func (m *Manager) AddClusterQueue(ctx context.Context, cq *kueue.ClusterQueue) e
 			continue
 		}
 		qImpl := m.queues[Key(&q)]
-		if qImpl != nil {
+		if qImpl != nil && !qImpl.stopped {
 			added := cqImpl.AddFromQueue(qImpl)
 			addedWorkloads = addedWorkloads || added
 		}
 	}
 
-	queued := m.queueAllInadmissibleWorkloadsInCohort(cqImpl)
+	queued := m.queueAllInadmissibleWorkloadsInCohort(cq.Name, cqImpl, nil)
+	m.reportPendingWorkloads(cq.Name, cqImpl)
 	if queued || addedWorkloads {
 		m.Broadcast()
 	}
@@ -129,7 +196,7 @@ func (m *Manager) UpdateClusterQueue(cq *kueue.ClusterQueue) error {
 	}
 
 	// TODO(#8): Selectively move workloads based on the exact event.
-	if m.queueAllInadmissibleWorkloadsInCohort(cqImpl) {
+	if m.queueAllInadmissibleWorkloadsInCohort(cq.Name, cqImpl, nil) {
 		m.Broadcast()
 	}
 
@@ -144,6 +211,7 @@ func (m *Manager) DeleteClusterQueue(cq *kueue.ClusterQueue) {
 		return
 	}
 	delete(m.clusterQueues, cq.Name)
+	metrics.ClearQueueSystemMetrics(cq.Name)
 
 	cohort := cq.Spec.Cohort
 	m.deleteCohort(cohort, cq.Name)
@@ -162,22 +230,25 @@ func (m *Manager) AddQueue(ctx context.Context, q *kueue.Queue) error {
 	// Iterate through existing workloads, as workloads corresponding to this
 	// queue might have been added earlier.
 	var workloads kueue.WorkloadList
-	if err := m.client.List(ctx, &workloads, client.MatchingFields{workloadQueueKey: q.Name}, client.InNamespace(q.Namespace)); err != nil {
+	if err := m.client.List(ctx, &workloads, client.MatchingFields{WorkloadQueueKey: q.Name}, client.InNamespace(q.Namespace)); err != nil {
 		return fmt.Errorf("listing workloads that match the queue: %w", err)
 	}
 	for _, w := range workloads.Items {
 		w := w
 		// Checking queue name again because the field index is not available in tests.
-		if w.Spec.QueueName != q.Name || w.Spec.Admission != nil {
+		if w.Spec.QueueName != q.Name || w.Spec.Admission != nil || !workload.IsActive(&w) {
 			continue
 		}
-		qImpl.AddOrUpdate(workload.NewInfo(&w))
+		qImpl.AddOrUpdate(workload.NewInfo(&w, m.workloadInfoOptions...))
 	}
 	cq := m.clusterQueues[qImpl.ClusterQueue]
-	if cq != nil && cq.AddFromQueue(qImpl) {
-		m.Broadcast()
+	if cq != nil && !qImpl.stopped {
+		if cq.AddFromQueue(qImpl) {
+			m.Broadcast()
+		}
+		m.reportPendingWorkloads(qImpl.ClusterQueue, cq)
 	}
-	qImpl.reportPendingWorkloads()
+	m.reportQueuePendingWorkloads(qImpl)
 	return nil
 }
 
@@ -188,19 +259,26 @@ func (m *Manager) UpdateQueue(q *kueue.Queue) error {
 	if !ok {
 		return errQueueDoesNotExist
 	}
-	if qImpl.ClusterQueue != string(q.Spec.ClusterQueue) {
+	oldCQName, wasStopped := qImpl.ClusterQueue, qImpl.stopped
+	if oldCQName != string(q.Spec.ClusterQueue) {
 		qImpl.resetPendingWorkloads()
-		oldCQ := m.clusterQueues[qImpl.ClusterQueue]
-		if oldCQ != nil {
+	}
+	qImpl.update(q)
+	if oldCQName != qImpl.ClusterQueue || wasStopped != qImpl.stopped {
+		oldCQ := m.clusterQueues[oldCQName]
+		if oldCQ != nil && !wasStopped {
 			oldCQ.DeleteFromQueue(qImpl)
+			m.reportPendingWorkloads(oldCQName, oldCQ)
 		}
-		newCQ := m.clusterQueues[string(q.Spec.ClusterQueue)]
-		if newCQ != nil && newCQ.AddFromQueue(qImpl) {
-			m.Broadcast()
+		newCQ := m.clusterQueues[qImpl.ClusterQueue]
+		if newCQ != nil && !qImpl.stopped {
+			if newCQ.AddFromQueue(qImpl) {
+				m.Broadcast()
+			}
+			m.reportPendingWorkloads(qImpl.ClusterQueue, newCQ)
 		}
 	}
-	qImpl.update(q)
-	qImpl.reportPendingWorkloads()
+	m.reportQueuePendingWorkloads(qImpl)
 	return nil
 }
 
@@ -215,6 +293,7 @@ func (m *Manager) DeleteQueue(q *kueue.Queue) {
 	cq := m.clusterQueues[qImpl.ClusterQueue]
 	if cq != nil {
 		cq.DeleteFromQueue(qImpl)
+		m.reportPendingWorkloads(qImpl.ClusterQueue, cq)
 	}
 	delete(m.queues, key)
 	qImpl.resetPendingWorkloads()
@@ -238,6 +317,22 @@ func (m *Manager) Pending(cq *kueue.ClusterQueue) int32 {
 	return m.clusterQueues[cq.Name].Pending()
 }
 
+// ClusterQueueExists returns whether the ClusterQueue was added to the manager.
+func (m *Manager) ClusterQueueExists(name string) bool {
+	m.RLock()
+	defer m.RUnlock()
+	_, ok := m.clusterQueues[name]
+	return ok
+}
+
+// QueueExists returns whether the Queue was added to the manager.
+func (m *Manager) QueueExists(q *kueue.Queue) bool {
+	m.RLock()
+	defer m.RUnlock()
+	_, ok := m.queues[Key(q)]
+	return ok
+}
+
 func (m *Manager) QueueForWorkloadExists(wl *kueue.Workload) bool {
 	m.RLock()
 	defer m.RUnlock()
@@ -260,6 +355,20 @@ func (m *Manager) ClusterQueueForWorkload(wl *kueue.Workload) (string, bool) {
 	return q.ClusterQueue, ok
 }
 
+// WorkloadInfo returns the Info of the workload, computed like the Info of the
+// queued workloads, with the ClusterQueue where the workload would be queued.
+// The workload is not queued. Returns false if the queue or its ClusterQueue
+// don't exist.
+func (m *Manager) WorkloadInfo(w *kueue.Workload) (*workload.Info, bool) {
+	cqName, ok := m.ClusterQueueForWorkload(w)
+	if !ok {
+		return nil, false
+	}
+	info := workload.NewInfo(w, m.workloadInfoOptions...)
+	info.ClusterQueue = cqName
+	return info, true
+}
+
 // AddOrUpdateWorkload adds or updates workload to the corresponding queue.
 // Returns whether the queue existed.
 func (m *Manager) AddOrUpdateWorkload(w *kueue.Workload) bool {
@@ -274,14 +383,23 @@ func (m *Manager) addOrUpdateWorkload(w *kueue.Workload) bool {
 	if q == nil {
 		return false
 	}
-	wInfo := workload.NewInfo(w)
+	if !workload.IsActive(w) {
+		// Inactive workloads are not queued until they are activated again.
+		m.deleteWorkloadFromQueueAndClusterQueue(w, qKey)
+		return true
+	}
+	wInfo := workload.NewInfo(w, m.workloadInfoOptions...)
 	q.AddOrUpdate(wInfo)
-	q.reportPendingWorkloads()
+	m.reportQueuePendingWorkloads(q)
 	cq := m.clusterQueues[q.ClusterQueue]
 	if cq == nil {
 		return false
 	}
+	if q.stopped {
+		return true
+	}
 	cq.PushOrUpdate(wInfo)
+	m.reportPendingWorkloads(q.ClusterQueue, cq)
 	m.Broadcast()
 	return true
 }
@@ -297,7 +415,7 @@ func (m *Manager) RequeueWorkload(ctx context.Context, info *workload.Info, imme
 	// Always get the newest workload to avoid requeuing the out-of-date obj.
 	err := m.client.Get(ctx, client.ObjectKeyFromObject(info.Obj), &w)
 	// Since the client is cached, the only possible error is NotFound
-	if apierrors.IsNotFound(err) || w.Spec.Admission != nil {
+	if apierrors.IsNotFound(err) || w.Spec.Admission != nil || !workload.IsActive(&w) {
 		return false
 	}
 
@@ -307,13 +425,14 @@ func (m *Manager) RequeueWorkload(ctx context.Context, info *workload.Info, imme
 	}
 	info.Update(&w)
 	q.AddOrUpdate(info)
-	q.reportPendingWorkloads()
+	m.reportQueuePendingWorkloads(q)
 	cq := m.clusterQueues[q.ClusterQueue]
-	if cq == nil {
+	if cq == nil || q.stopped {
 		return false
 	}
 
 	added := cq.RequeueIfNotPresent(info, immediate)
+	m.reportPendingWorkloads(q.ClusterQueue, cq)
 	if added {
 		m.Broadcast()
 	}
@@ -332,16 +451,18 @@ func (m *Manager) deleteWorkloadFromQueueAndClusterQueue(w *kueue.Workload, qKey
 		return
 	}
 	delete(q.items, workload.Key(w))
-	q.reportPendingWorkloads()
+	m.reportQueuePendingWorkloads(q)
 	cq := m.clusterQueues[q.ClusterQueue]
 	if cq != nil {
 		cq.Delete(w)
+		m.reportPendingWorkloads(q.ClusterQueue, cq)
 	}
 }
 
-// QueueAssociatedInadmissibleWorkloads moves all associated workloads from
-// inadmissibleWorkloads to heap. If at least one workload is moved,
-// returns true. Otherwise returns false.
+// QueueAssociatedInadmissibleWorkloads moves the associated workloads from
+// inadmissibleWorkloads to heap, after the quota of the given workload was
+// released. Only the workloads that could fit in the released resources are
+// moved.
 func (m *Manager) QueueAssociatedInadmissibleWorkloads(w *kueue.Workload) {
 	m.Lock()
 	defer m.Unlock()
@@ -356,7 +477,8 @@ func (m *Manager) QueueAssociatedInadmissibleWorkloads(w *kueue.Workload) {
 		return
 	}
 
-	if m.queueAllInadmissibleWorkloadsInCohort(cq) {
+	info := workload.NewInfo(w, m.workloadInfoOptions...)
+	if m.queueAllInadmissibleWorkloadsInCohort(q.ClusterQueue, cq, resourceNames(info)) {
 		m.Broadcast()
 	}
 }
@@ -377,7 +499,7 @@ func (m *Manager) QueueInadmissibleWorkloads(cqNames sets.String) {
 		if !exists {
 			continue
 		}
-		if m.queueAllInadmissibleWorkloadsInCohort(cq) {
+		if m.queueAllInadmissibleWorkloadsInCohort(name, cq, nil) {
 			queued = true
 		}
 	}
@@ -397,16 +519,25 @@ func (m *Manager) QueueInadmissibleWorkloads(cqNames sets.String) {
 // 1. delete events for any admitted workload in the cohort.
 // 2. add events of any cluster queue in the cohort.
 // 3. update events of any cluster queue in the cohort.
-func (m *Manager) queueAllInadmissibleWorkloadsInCohort(cq ClusterQueue) bool {
+// For delete events, the released resources restrict the workloads that are
+// moved to the ones that could fit in them.
+func (m *Manager) queueAllInadmissibleWorkloadsInCohort(cqName string, cq ClusterQueue, released sets.String) bool {
 	cohort := cq.Cohort()
 	if cohort == "" {
-		return cq.QueueInadmissibleWorkloads()
+		queued := cq.QueueInadmissibleWorkloads(released)
+		if queued {
+			m.reportPendingWorkloads(cqName, cq)
+		}
+		return queued
 	}
 
 	queued := false
-	for cqName := range m.cohorts[cohort] {
-		if clusterQueue, ok := m.clusterQueues[cqName]; ok {
-			queued = clusterQueue.QueueInadmissibleWorkloads() || queued
+	for name := range m.cohorts[cohort] {
+		if clusterQueue, ok := m.clusterQueues[name]; ok {
+			if clusterQueue.QueueInadmissibleWorkloads(released) {
+				m.reportPendingWorkloads(name, clusterQueue)
+				queued = true
+			}
 		}
 	}
 	return queued
@@ -434,11 +565,19 @@ func (m *Manager) CleanUpOnContext(ctx context.Context) {
 // Heads returns the heads of the queues, along with their associated ClusterQueue.
 // It blocks if the queues empty until they have elements or the context terminates.
 func (m *Manager) Heads(ctx context.Context) []workload.Info {
+	return m.HeadsUpTo(ctx, 1)
+}
+
+// HeadsUpTo returns up to n workloads from the head of each ClusterQueue,
+// along with their associated ClusterQueue. The workloads of a ClusterQueue
+// are returned consecutively, in queue order.
+// It blocks if the queues empty until they have elements or the context terminates.
+func (m *Manager) HeadsUpTo(ctx context.Context, n int) []workload.Info {
 	m.Lock()
 	defer m.Unlock()
 	log := ctrl.LoggerFrom(ctx)
 	for {
-		workloads := m.heads()
+		workloads := m.heads(n)
 		log.V(3).Info("Obtained ClusterQueue heads", "count", len(workloads))
 		if len(workloads) != 0 {
 			return workloads
@@ -472,23 +611,55 @@ func (m *Manager) Dump() map[string]sets.String {
 	return dump
 }
 
-func (m *Manager) heads() []workload.Info {
+// PendingWorkloads are the workloads waiting for admission in a ClusterQueue.
+type PendingWorkloads struct {
+	// Active are the workloads in the heap, in the order in which they would
+	// be popped.
+	Active []*workload.Info
+	// Inadmissible are the workloads that are waiting for cluster events to
+	// be queued again, sorted by key.
+	Inadmissible []*workload.Info
+}
+
+// PendingWorkloadsInfo returns the pending workloads of each ClusterQueue.
+// Users of this method should not modify the returned objects.
+func (m *Manager) PendingWorkloadsInfo() map[string]PendingWorkloads {
+	m.Lock()
+	defer m.Unlock()
+	pending := make(map[string]PendingWorkloads, len(m.clusterQueues))
+	for name, cq := range m.clusterQueues {
+		inadmissible := cq.PendingInadmissibleInfo()
+		sort.Slice(inadmissible, func(i, j int) bool {
+			return workload.Key(inadmissible[i].Obj) < workload.Key(inadmissible[j].Obj)
+		})
+		pending[name] = PendingWorkloads{
+			Active:       cq.PendingActiveInfo(),
+			Inadmissible: inadmissible,
+		}
+	}
+	return pending
+}
+
+func (m *Manager) heads(n int) []workload.Info {
 	var workloads []workload.Info
 	for cqName, cq := range m.clusterQueues {
 		// Cache might be nil in tests, if cache is nil, we'll skip the check.
 		if m.statusChecker != nil && !m.statusChecker.ClusterQueueActive(cqName) {
 			continue
 		}
-		wl := cq.Pop()
-		if wl == nil {
-			continue
+		for i := 0; i < n; i++ {
+			wl := cq.Pop()
+			if wl == nil {
+				break
+			}
+			wlCopy := *wl
+			wlCopy.ClusterQueue = cqName
+			workloads = append(workloads, wlCopy)
+			q := m.queues[queueKeyForWorkload(wl.Obj)]
+			delete(q.items, workload.Key(wl.Obj))
+			m.reportQueuePendingWorkloads(q)
 		}
-		wlCopy := *wl
-		wlCopy.ClusterQueue = cqName
-		workloads = append(workloads, wlCopy)
-		q := m.queues[queueKeyForWorkload(wl.Obj)]
-		delete(q.items, workload.Key(wl.Obj))
-		q.reportPendingWorkloads()
+		m.reportPendingWorkloads(cqName, cq)
 	}
 	return workloads
 }
@@ -517,12 +688,33 @@ func (m *Manager) updateCohort(oldCohort string, newCohort string, cqName string
 	m.addCohort(newCohort, cqName)
 }
 
+// resourceNames returns the names of the resources requested by the workload.
+func resourceNames(info *workload.Info) sets.String {
+	names := sets.NewString()
+	for _, ps := range info.TotalRequests {
+		for name := range ps.Requests {
+			names.Insert(string(name))
+		}
+	}
+	return names
+}
+
+func (m *Manager) reportPendingWorkloads(cqName string, cq ClusterQueue) {
+	metrics.ReportPendingWorkloads(cqName, int(cq.PendingActive()), int(cq.PendingInadmissible()))
+}
+
+func (m *Manager) reportQueuePendingWorkloads(q *Queue) {
+	if m.localQueueMetrics {
+		q.reportPendingWorkloads()
+	}
+}
+
 func (m *Manager) Broadcast() {
 	m.cond.Broadcast()
 }
 
 func SetupIndexes(indexer client.FieldIndexer) error {
-	err := indexer.IndexField(context.Background(), &kueue.Workload{}, workloadQueueKey, func(o client.Object) []string {
+	err := indexer.IndexField(context.Background(), &kueue.Workload{}, WorkloadQueueKey, func(o client.Object) []string {
 		wl := o.(*kueue.Workload)
 		return []string{wl.Spec.QueueName}
 	})
@@ -24,6 +24,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
@@ -160,6 +162,102 @@ func TestUpdateQueue(t *testing.T) {
 	}
 }
 
+// TestInactiveWorkload tests that inactive workloads are held out of the
+// clusterQueue until they are activated again.
+func TestInactiveWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	ctx := context.Background()
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil)
+	if err := manager.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding clusterQueue: %v", err)
+	}
+	q := utiltesting.MakeQueue("foo", "").ClusterQueue("cq").Obj()
+	if err := manager.AddQueue(ctx, q); err != nil {
+		t.Fatalf("Failed adding queue: %v", err)
+	}
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "").Queue("foo").Obj(),
+		utiltesting.MakeWorkload("b", "").Queue("foo").Active(false).Obj(),
+	}
+	for _, w := range workloads {
+		if !manager.AddOrUpdateWorkload(w) {
+			t.Fatalf("Failed adding workload %s", w.Name)
+		}
+	}
+	wantDump := map[string]sets.String{
+		"cq": sets.NewString("a"),
+	}
+	if diff := cmp.Diff(wantDump, manager.Dump()); diff != "" {
+		t.Errorf("Unexpected elements in the clusterQueue after deactivating (-want,+got):\n%s", diff)
+	}
+
+	workloads[1].Spec.Active = nil
+	if !manager.UpdateWorkload(workloads[1], workloads[1]) {
+		t.Fatalf("Failed updating workload")
+	}
+	wantDump = map[string]sets.String{
+		"cq": sets.NewString("a", "b"),
+	}
+	if diff := cmp.Diff(wantDump, manager.Dump()); diff != "" {
+		t.Errorf("Unexpected elements in the clusterQueue after activating (-want,+got):\n%s", diff)
+	}
+}
+
+// TestStopQueue tests that the workloads of a stopped queue are held out of
+// the clusterQueue until the queue is resumed.
+func TestStopQueue(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	ctx := context.Background()
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil)
+	if err := manager.AddClusterQueue(ctx, utiltesting.MakeClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding clusterQueue: %v", err)
+	}
+	queues := []*kueue.Queue{
+		utiltesting.MakeQueue("foo", "").ClusterQueue("cq").StopPolicy(kueue.Hold).Obj(),
+		utiltesting.MakeQueue("bar", "").ClusterQueue("cq").Obj(),
+	}
+	for _, q := range queues {
+		if err := manager.AddQueue(ctx, q); err != nil {
+			t.Fatalf("Failed adding queue %s: %v", q.Name, err)
+		}
+	}
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "").Queue("foo").Obj(),
+		utiltesting.MakeWorkload("b", "").Queue("bar").Obj(),
+	}
+	for _, w := range workloads {
+		if !manager.AddOrUpdateWorkload(w) {
+			t.Fatalf("Failed adding workload %s", w.Name)
+		}
+	}
+	wantDump := map[string]sets.String{
+		"cq": sets.NewString("b"),
+	}
+	if diff := cmp.Diff(wantDump, manager.Dump()); diff != "" {
+		t.Errorf("Unexpected elements in the clusterQueue after stopping (-want,+got):\n%s", diff)
+	}
+	if pending, _ := manager.PendingWorkloads(queues[0]); pending != 1 {
+		t.Errorf("Got %d pending workloads in the stopped queue, want 1", pending)
+	}
+
+	queues[0].Spec.StopPolicy = nil
+	if err := manager.UpdateQueue(queues[0]); err != nil {
+		t.Fatalf("Failed updating queue: %v", err)
+	}
+	wantDump = map[string]sets.String{
+		"cq": sets.NewString("a", "b"),
+	}
+	if diff := cmp.Diff(wantDump, manager.Dump()); diff != "" {
+		t.Errorf("Unexpected elements in the clusterQueue after resuming (-want,+got):\n%s", diff)
+	}
+}
+
 func TestAddWorkload(t *testing.T) {
 	scheme := runtime.NewScheme()
 	if err := kueue.AddToScheme(scheme); err != nil {
@@ -423,6 +521,154 @@ func TestRequeueWorkloadStrictFIFO(t *testing.T) {
 	}
 }
 
+func TestPendingWorkloadsMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	ctx := context.Background()
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "").Queue("foo").Creation(time.Now().Add(-time.Second)).Obj(),
+		utiltesting.MakeWorkload("b", "").Queue("foo").Creation(time.Now()).Obj(),
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	for _, w := range workloads {
+		if err := cl.Create(ctx, w); err != nil {
+			t.Fatalf("Failed adding workload to client: %v", err)
+		}
+	}
+	manager := NewManager(cl, nil)
+	cq := utiltesting.MakeClusterQueue("metrics").Obj()
+	if err := manager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding cluster queue %s: %v", cq.Name, err)
+	}
+	wantPending := func(wantActive, wantInadmissible float64) {
+		t.Helper()
+		active := testutil.ToFloat64(metrics.PendingWorkloads.WithLabelValues("metrics", metrics.PendingStatusActive))
+		inadmissible := testutil.ToFloat64(metrics.PendingWorkloads.WithLabelValues("metrics", metrics.PendingStatusInadmissible))
+		if active != wantActive || inadmissible != wantInadmissible {
+			t.Errorf("Got %v active and %v inadmissible workloads, want %v and %v", active, inadmissible, wantActive, wantInadmissible)
+		}
+	}
+	wantPending(0, 0)
+
+	if err := manager.AddQueue(ctx, utiltesting.MakeQueue("foo", "").ClusterQueue("metrics").Obj()); err != nil {
+		t.Fatalf("Failed adding queue: %v", err)
+	}
+	wantPending(2, 0)
+
+	heads := manager.Heads(ctx)
+	if len(heads) != 1 {
+		t.Fatalf("Got %d heads, want 1", len(heads))
+	}
+	wantPending(1, 0)
+
+	manager.RequeueWorkload(ctx, &heads[0], false)
+	wantPending(1, 1)
+
+	manager.DeleteWorkload(workloads[1])
+	wantPending(0, 1)
+}
+
+func TestQueuePendingWorkloadsMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	cases := map[string]struct {
+		enabled    bool
+		wantSeries bool
+	}{
+		"enabled": {
+			enabled:    true,
+			wantSeries: true,
+		},
+		"disabled": {},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cqName := "queue-metrics-" + name
+			cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+			if err := cl.Create(ctx, utiltesting.MakeWorkload("a", "").Queue("foo").Obj()); err != nil {
+				t.Fatalf("Failed adding workload to client: %v", err)
+			}
+			manager := NewManager(cl, nil, WithLocalQueueMetrics(tc.enabled))
+			if err := manager.AddClusterQueue(ctx, utiltesting.MakeClusterQueue(cqName).Obj()); err != nil {
+				t.Fatalf("Failed adding cluster queue: %v", err)
+			}
+			q := utiltesting.MakeQueue("foo", "").ClusterQueue(cqName).Obj()
+			if err := manager.AddQueue(ctx, q); err != nil {
+				t.Fatalf("Failed adding queue: %v", err)
+			}
+			if tc.wantSeries {
+				if got := testutil.ToFloat64(metrics.QueuePendingWorkloads.WithLabelValues(cqName, Key(q))); got != 1 {
+					t.Errorf("Got %v pending workloads in the queue, want 1", got)
+				}
+			}
+			// DeleteLabelValues reports if the series existed.
+			if got := metrics.QueuePendingWorkloads.DeleteLabelValues(cqName, Key(q)); got != tc.wantSeries {
+				t.Errorf("Got series reported %t, want %t", got, tc.wantSeries)
+			}
+		})
+	}
+}
+
+func TestPendingWorkloadsInfo(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("a", "").Queue("foo").Creation(now.Add(-2 * time.Second)).Obj(),
+		utiltesting.MakeWorkload("b", "").Queue("foo").Creation(now).Obj(),
+		utiltesting.MakeWorkload("c", "").Queue("foo").Creation(now.Add(-time.Second)).Obj(),
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	for _, w := range workloads {
+		if err := cl.Create(ctx, w); err != nil {
+			t.Fatalf("Failed adding workload to client: %v", err)
+		}
+	}
+	manager := NewManager(cl, nil)
+	for _, cq := range []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").Obj(),
+		utiltesting.MakeClusterQueue("empty").Obj(),
+	} {
+		if err := manager.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding cluster queue %s: %v", cq.Name, err)
+		}
+	}
+	if err := manager.AddQueue(ctx, utiltesting.MakeQueue("foo", "").ClusterQueue("cq").Obj()); err != nil {
+		t.Fatalf("Failed adding queue: %v", err)
+	}
+	heads := manager.Heads(ctx)
+	if len(heads) != 1 {
+		t.Fatalf("Got %d heads, want 1", len(heads))
+	}
+	manager.RequeueWorkload(ctx, &heads[0], false)
+
+	got := make(map[string][]string)
+	for cq, pending := range manager.PendingWorkloadsInfo() {
+		got[cq] = []string{}
+		for _, info := range pending.Active {
+			got[cq] = append(got[cq], "active:"+info.Obj.Name)
+		}
+		for _, info := range pending.Inadmissible {
+			got[cq] = append(got[cq], "inadmissible:"+info.Obj.Name)
+		}
+	}
+	want := map[string][]string{
+		"cq":    {"active:c", "active:b", "inadmissible:a"},
+		"empty": {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected pending workloads (-want,+got):\n%s", diff)
+	}
+}
+
 func TestUpdateWorkload(t *testing.T) {
 	scheme := runtime.NewScheme()
 	if err := kueue.AddToScheme(scheme); err != nil {
@@ -627,8 +873,11 @@ func TestHeads(t *testing.T) {
 		utiltesting.MakeQueue("baz", "").ClusterQueue("pending-bazCq").Obj(),
 	}
 	tests := []struct {
-		name          string
-		workloads     []*kueue.Workload
+		name      string
+		workloads []*kueue.Workload
+		// headsPerCQ is the number of heads to obtain per clusterQueue. If
+		// zero, Heads is called.
+		headsPerCQ    int
 		wantWorkloads sets.String
 	}{
 		{
@@ -653,6 +902,17 @@ func TestHeads(t *testing.T) {
 			},
 			wantWorkloads: sets.NewString("a1", "b"),
 		},
+		{
+			name: "multiple heads per clusterQueue",
+			workloads: []*kueue.Workload{
+				utiltesting.MakeWorkload("a1", "").Creation(now).Queue("foo").Obj(),
+				utiltesting.MakeWorkload("a2", "").Creation(now.Add(time.Hour)).Queue("foo").Obj(),
+				utiltesting.MakeWorkload("a3", "").Creation(now.Add(2 * time.Hour)).Queue("foo").Obj(),
+				utiltesting.MakeWorkload("b", "").Creation(now).Queue("bar").Obj(),
+			},
+			headsPerCQ:    2,
+			wantWorkloads: sets.NewString("a1", "a2", "b"),
+		},
 		{
 			name: "inactive clusterQueues",
 			workloads: []*kueue.Workload{
@@ -686,7 +946,12 @@ func TestHeads(t *testing.T) {
 			}
 
 			wlNames := sets.NewString()
-			heads := manager.Heads(ctx)
+			var heads []workload.Info
+			if tc.headsPerCQ == 0 {
+				heads = manager.Heads(ctx)
+			} else {
+				heads = manager.HeadsUpTo(ctx, tc.headsPerCQ)
+			}
 			for _, h := range heads {
 				wlNames.Insert(h.Obj.Name)
 			}
@@ -697,6 +962,47 @@ func TestHeads(t *testing.T) {
 	}
 }
 
+// byName orders the workloads by name.
+type byName struct{}
+
+func (byName) Less(a, b *workload.Info) bool {
+	return a.Obj.Name < b.Obj.Name
+}
+
+func TestHeadsWithOrdering(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %s", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	q := utiltesting.MakeQueue("foo", "").ClusterQueue("cq").Obj()
+	workloads := []*kueue.Workload{
+		utiltesting.MakeWorkload("c", "").Creation(now).Queue("foo").Obj(),
+		utiltesting.MakeWorkload("a", "").Creation(now.Add(time.Second)).Queue("foo").Obj(),
+		utiltesting.MakeWorkload("b", "").Creation(now.Add(2 * time.Second)).Queue("foo").Obj(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), headsTimeout)
+	defer cancel()
+	manager := NewManager(fake.NewClientBuilder().WithScheme(scheme).Build(), nil, WithOrdering(byName{}))
+	if err := manager.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding clusterQueue %s to manager: %v", cq.Name, err)
+	}
+	if err := manager.AddQueue(ctx, q); err != nil {
+		t.Fatalf("Failed adding queue %s: %s", q.Name, err)
+	}
+	for _, wl := range workloads {
+		manager.AddOrUpdateWorkload(wl)
+	}
+	var got []string
+	for _, h := range manager.HeadsUpTo(ctx, len(workloads)) {
+		got = append(got, h.Obj.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, got); diff != "" {
+		t.Errorf("Unexpected order of the heads (-want,+got):\n%s", diff)
+	}
+}
+
 var ignoreTypeMeta = cmpopts.IgnoreTypes(metav1.TypeMeta{})
 
 // TestHeadAsync ensures that Heads call is blocked until the queues are filled
@@ -43,6 +43,8 @@ func queueKeyForWorkload(w *kueue.Workload) string {
 type Queue struct {
 	Key          string
 	ClusterQueue string
+	// stopped queues keep their workloads out of the ClusterQueue.
+	stopped bool
 
 	items map[string]*workload.Info
 }
@@ -58,6 +60,7 @@ func newQueue(q *kueue.Queue) *Queue {
 
 func (q *Queue) update(apiQueue *kueue.Queue) {
 	q.ClusterQueue = string(apiQueue.Spec.ClusterQueue)
+	q.stopped = apiQueue.Spec.StopPolicy != nil && *apiQueue.Spec.StopPolicy != kueue.None
 }
 
 func (q *Queue) AddOrUpdate(info *workload.Info) {
@@ -76,9 +79,9 @@ func (q *Queue) AddIfNotPresent(w *workload.Info) bool {
 }
 
 func (q *Queue) reportPendingWorkloads() {
-	metrics.PendingWorkloads.WithLabelValues(q.ClusterQueue, q.Key).Set(float64(len(q.items)))
+	metrics.QueuePendingWorkloads.WithLabelValues(q.ClusterQueue, q.Key).Set(float64(len(q.items)))
 }
 
 func (q *Queue) resetPendingWorkloads() {
-	metrics.PendingWorkloads.DeleteLabelValues(q.ClusterQueue, q.Key)
+	metrics.QueuePendingWorkloads.DeleteLabelValues(q.ClusterQueue, q.Key)
 }
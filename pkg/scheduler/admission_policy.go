@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+)
+
+// Candidate is a workload nominated for admission in a scheduling cycle.
+type Candidate struct {
+	// Workload is the workload to admit. It must not be modified.
+	Workload *kueue.Workload
+	// Admission holds the ClusterQueue and the flavors that the workload
+	// would be admitted with.
+	Admission *kueue.Admission
+	// Borrows indicates that the workload needs to borrow quota from the
+	// cohort of the ClusterQueue.
+	Borrows bool
+}
+
+// AdmissionPolicy is an extension point of the scheduler to implement
+// admission policies, such as cost-aware or deadline-aware policies, without
+// changing the scheduler. The policies are called for every candidate in
+// each scheduling cycle, so they should be fast and not block.
+type AdmissionPolicy interface {
+	// Name identifies the policy in the logs and in the reasons for not
+	// admitting a workload.
+	Name() string
+	// Filter returns whether the candidate can be admitted in this cycle.
+	// When it can't, the reason is reported in the workload status and the
+	// workload is treated as inadmissible: it's retried once the usage of
+	// its ClusterQueue or cohort changes.
+	Filter(ctx context.Context, c *Candidate) (bool, string)
+	// Score returns the score of the candidate. The candidates that don't
+	// need to borrow go first; among them, and among the ones that need to
+	// borrow, the candidates with a higher total score across all the
+	// policies are admitted first. The position in the ClusterQueue and the
+	// creation time break the ties. In StrictFIFO ClusterQueues, a candidate
+	// never goes ahead of the candidates before it in the ClusterQueue.
+	Score(ctx context.Context, c *Candidate) int64
+}
+
+// applyAdmissionPolicies filters the nominated entries and sets their score
+// with the admission policies of the scheduler.
+func (s *Scheduler) applyAdmissionPolicies(ctx context.Context, entries []entry, snap cache.Snapshot) {
+	if len(s.admissionPolicies) == 0 {
+		return
+	}
+	log := ctrl.LoggerFrom(ctx)
+	for i := range entries {
+		e := &entries[i]
+		if e.status != nominated {
+			continue
+		}
+		c := &Candidate{
+			Workload:  e.Obj,
+			Admission: e.admission(),
+			Borrows:   len(e.borrows) > 0,
+		}
+		for _, p := range s.admissionPolicies {
+			if ok, reason := p.Filter(ctx, c); !ok {
+				log.V(3).Info("Workload filtered out by admission policy", "workload", klog.KObj(e.Obj), "policy", p.Name(), "reason", reason)
+				e.status = ""
+				e.inadmissibleReason = truncateMessage(fmt.Sprintf("Not admitted by policy %s: %s", p.Name(), reason))
+				break
+			}
+			e.score += p.Score(ctx, c)
+		}
+	}
+
+	// The entries of a StrictFIFO clusterQueue are admitted in queue order, so
+	// the score of an entry is capped to the score of the entries ahead of it,
+	// the same way that an entry behind a borrowing one is considered
+	// borrowing.
+	minScores := make(map[string]int64)
+	for i := range entries {
+		e := &entries[i]
+		if c := snap.ClusterQueues[e.ClusterQueue]; c == nil || c.QueueingStrategy != kueue.StrictFIFO {
+			continue
+		}
+		if score, ok := minScores[e.ClusterQueue]; ok && e.score > score {
+			e.score = score
+		}
+		minScores[e.ClusterQueue] = e.score
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// DryRunResult is the outcome of evaluating the admission of a workload
+// without admitting it.
+type DryRunResult struct {
+	// ClusterQueue is the ClusterQueue where the workload would be queued.
+	ClusterQueue string `json:"clusterQueue,omitempty"`
+	// Fits indicates whether the workload fits in the available quota of the
+	// ClusterQueue and its cohort.
+	Fits bool `json:"fits"`
+	// Admission is the admission that the workload would get, if it fits.
+	Admission *kueue.Admission `json:"admission,omitempty"`
+	// Borrows is the quota, by resource and flavor, that the workload would
+	// borrow from the cohort, if it fits.
+	Borrows map[corev1.ResourceName]map[string]resource.Quantity `json:"borrows,omitempty"`
+	// Reason explains why the workload doesn't fit.
+	Reason string `json:"reason,omitempty"`
+}
+
+// DryRun evaluates whether the workload would be admitted by its
+// ClusterQueue with the current usage, and with which flavors. Neither the
+// cache nor the queues are modified. The workloads pending ahead of the
+// workload in the ClusterQueue are not taken into account.
+func (s *Scheduler) DryRun(ctx context.Context, wl *kueue.Workload) DryRunResult {
+	info, ok := s.queues.WorkloadInfo(wl)
+	if !ok {
+		return DryRunResult{
+			Reason: fmt.Sprintf("Queue %s doesn't exist or its ClusterQueue doesn't exist", wl.Spec.QueueName),
+		}
+	}
+	e := s.nominateWorkload(ctx, *info, s.cache.Snapshot())
+	result := DryRunResult{
+		ClusterQueue: e.ClusterQueue,
+		Fits:         e.status == nominated,
+		Reason:       e.inadmissibleReason,
+	}
+	if !result.Fits {
+		return result
+	}
+	result.Admission = e.admission()
+	if len(e.borrows) > 0 {
+		result.Borrows = make(map[corev1.ResourceName]map[string]resource.Quantity, len(e.borrows))
+		for rName, flavors := range e.borrows {
+			result.Borrows[rName] = make(map[string]resource.Quantity, len(flavors))
+			for fName, v := range flavors {
+				result.Borrows[rName][fName] = workload.ResourceQuantity(rName, v)
+			}
+		}
+	}
+	return result
+}
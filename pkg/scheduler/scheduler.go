@@ -24,6 +24,8 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,23 +34,35 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/audit"
 	"sigs.k8s.io/kueue/pkg/cache"
 	"sigs.k8s.io/kueue/pkg/metrics"
 	"sigs.k8s.io/kueue/pkg/queue"
 	"sigs.k8s.io/kueue/pkg/util/routine"
+	"sigs.k8s.io/kueue/pkg/util/tracing"
 	"sigs.k8s.io/kueue/pkg/workload"
 )
 
 const (
 	errCouldNotAdmitWL = "Could not admit workload and assigning flavors in apiserver"
 	noteLengthLimit    = 1024
+
+	// DefaultHeadsPerClusterQueue is the default number of workloads from
+	// the head of each ClusterQueue that are considered in a scheduling cycle.
+	DefaultHeadsPerClusterQueue = 10
+
+	// DefaultNominationParallelism is the default number of workloads that
+	// are nominated concurrently in a scheduling cycle.
+	DefaultNominationParallelism = 16
 )
 
 type Scheduler struct {
@@ -57,22 +71,113 @@ type Scheduler struct {
 	client                  client.Client
 	recorder                record.EventRecorder
 	admissionRoutineWrapper routine.Wrapper
+	started                 chan struct{}
+	waitForPodsReady        bool
+	headsPerClusterQueue    int
+	nominationParallelism   int
+	admissionPolicies       []AdmissionPolicy
+	auditor                 *audit.Auditor
+}
+
+type options struct {
+	waitForPodsReady      bool
+	headsPerClusterQueue  int
+	nominationParallelism int
+	admissionPolicies     []AdmissionPolicy
+	auditor               *audit.Auditor
+}
+
+// Option configures the scheduler.
+type Option func(*options)
+
+// WithWaitForPodsReady indicates if the scheduler should wait for the pods of
+// the admitted workloads to be ready before admitting other workloads.
+func WithWaitForPodsReady(f bool) Option {
+	return func(o *options) {
+		o.waitForPodsReady = f
+	}
+}
+
+// WithHeadsPerClusterQueue sets the maximum number of workloads from the head
+// of each ClusterQueue that are considered for admission in a scheduling cycle.
+func WithHeadsPerClusterQueue(n int) Option {
+	return func(o *options) {
+		o.headsPerClusterQueue = n
+	}
 }
 
-func New(queues *queue.Manager, cache *cache.Cache, cl client.Client, recorder record.EventRecorder) *Scheduler {
+// WithNominationParallelism sets the number of workloads whose flavors are
+// assigned concurrently in a scheduling cycle.
+func WithNominationParallelism(n int) Option {
+	return func(o *options) {
+		o.nominationParallelism = n
+	}
+}
+
+// WithAdmissionPolicies adds policies that can filter and re-order the
+// workloads nominated for admission in each scheduling cycle.
+func WithAdmissionPolicies(policies ...AdmissionPolicy) Option {
+	return func(o *options) {
+		o.admissionPolicies = append(o.admissionPolicies, policies...)
+	}
+}
+
+// WithAuditor sets the auditor that records the admissions.
+func WithAuditor(a *audit.Auditor) Option {
+	return func(o *options) {
+		o.auditor = a
+	}
+}
+
+var _ manager.Runnable = &Scheduler{}
+var _ manager.LeaderElectionRunnable = &Scheduler{}
+
+func New(queues *queue.Manager, cache *cache.Cache, cl client.Client, recorder record.EventRecorder, opts ...Option) *Scheduler {
+	options := options{
+		headsPerClusterQueue:  DefaultHeadsPerClusterQueue,
+		nominationParallelism: DefaultNominationParallelism,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &Scheduler{
 		queues:                  queues,
 		cache:                   cache,
 		client:                  cl,
 		recorder:                recorder,
 		admissionRoutineWrapper: routine.DefaultWrapper,
+		started:                 make(chan struct{}),
+		waitForPodsReady:        options.waitForPodsReady,
+		headsPerClusterQueue:    options.headsPerClusterQueue,
+		nominationParallelism:   options.nominationParallelism,
+		admissionPolicies:       options.admissionPolicies,
+		auditor:                 options.auditor,
 	}
 }
 
-func (s *Scheduler) Start(ctx context.Context) {
+// Start runs scheduling cycles until the context is done.
+func (s *Scheduler) Start(ctx context.Context) error {
 	log := ctrl.LoggerFrom(ctx).WithName("scheduler")
 	ctx = ctrl.LoggerInto(ctx, log)
+	close(s.started)
 	wait.UntilWithContext(ctx, s.schedule, 0)
+	return nil
+}
+
+// Started returns whether the scheduler started running scheduling cycles.
+func (s *Scheduler) Started() bool {
+	select {
+	case <-s.started:
+		return true
+	default:
+		return false
+	}
+}
+
+// NeedLeaderElection returns true, so that only the leader replica admits
+// workloads.
+func (s *Scheduler) NeedLeaderElection() bool {
+	return true
 }
 
 func (s *Scheduler) setAdmissionRoutineWrapper(wrapper routine.Wrapper) {
@@ -84,55 +189,111 @@ func (s *Scheduler) schedule(ctx context.Context) {
 
 	// 1. Get the heads from the queues, including their desired clusterQueue.
 	// This operation blocks while the queues are empty.
-	headWorkloads := s.queues.Heads(ctx)
+	headWorkloads := s.queues.HeadsUpTo(ctx, s.headsPerClusterQueue)
 	// No elements means the program is finishing.
 	if len(headWorkloads) == 0 {
 		return
 	}
+	if s.waitForPodsReady {
+		// Block the admission until the pods of the admitted workloads are
+		// ready, so that the pods of different workloads don't compete for
+		// the same nodes.
+		s.cache.WaitForPodsReady(ctx)
+	}
 	startTime := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "SchedulingCycle")
+	defer span.End()
 
 	// 2. Take a snapshot of the cache.
+	_, snapshotSpan := tracing.Tracer().Start(ctx, "Snapshot")
 	snapshot := s.cache.Snapshot()
+	snapshotSpan.End()
+	snapshotDuration := time.Since(startTime)
 
 	// 3. Calculate requirements for admitting workloads (resource flavors, borrowing).
-	// (resource flavors, borrowing).
-	entries := s.nominate(ctx, headWorkloads, snapshot)
+	nominateCtx, nominateSpan := tracing.Tracer().Start(ctx, "Nominate", trace.WithAttributes(attribute.Int("heads", len(headWorkloads))))
+	entries := s.nominate(nominateCtx, headWorkloads, snapshot)
+	nominateSpan.End()
+	s.applyAdmissionPolicies(ctx, entries, snapshot)
 
-	// 4. Sort entries based on borrowing and timestamps.
+	// 4. Sort entries based on borrowing, the score of the admission policies,
+	// position in the clusterQueue and timestamps.
 	sort.Sort(entryOrdering(entries))
 
-	// 5. Admit entries, ensuring that no more than one workload gets
-	// admitted by a cohort (if borrowing).
-	// This is because there can be other workloads deeper in a clusterQueue whose
-	// head got admitted that should be scheduled in the cohort before the heads
-	// of other clusterQueues.
-	usedCohorts := sets.NewString()
+	// 5. Admit entries in order. The usage of each admitted workload is added
+	// to the snapshot, and the entries whose clusterQueue or cohort changed
+	// usage in this cycle get their flavors re-assigned against it. This way,
+	// workloads that would overlap in borrowed quota are not admitted together.
+	// The same applies to every entry once a workload is admitted, when node
+	// pools limit the resources that can be provisioned for the flavors.
+	// The entries of a StrictFIFO clusterQueue are admitted in queue order: once
+	// one of them is not admitted, the rest of the clusterQueue waits for the
+	// next cycle. In BestEffortFIFO clusterQueues, the entries behind it can
+	// still be admitted.
+	// If waiting for the pods to be ready, only one workload is admitted.
+	changedCQs := sets.NewString()
+	changedCohorts := sets.NewString()
+	blockedCQs := sets.NewString()
+	block := func(e *entry) {
+		if c := snapshot.ClusterQueues[e.ClusterQueue]; c != nil && c.QueueingStrategy == kueue.StrictFIFO {
+			blockedCQs.Insert(e.ClusterQueue)
+		}
+	}
+	admittedInCycle := false
 	for i := range entries {
 		e := &entries[i]
 		if e.status != nominated {
+			block(e)
 			continue
 		}
-		c := snapshot.ClusterQueues[e.ClusterQueue]
-		if len(e.borrows) > 0 && c.Cohort != nil && usedCohorts.Has(c.Cohort.Name) {
+		if blockedCQs.Has(e.ClusterQueue) {
 			e.status = skipped
-			e.inadmissibleReason = "cohort used in this cycle"
+			e.inadmissibleReason = "a workload ahead in the ClusterQueue was not admitted in this cycle"
+			continue
+		}
+		if s.waitForPodsReady && admittedInCycle {
+			e.status = skipped
+			e.inadmissibleReason = "waiting for the pods of the admitted workloads to be ready"
+			block(e)
 			continue
 		}
 		log := log.WithValues("workload", klog.KObj(e.Obj), "clusterQueue", klog.KRef("", e.ClusterQueue))
-		if err := s.admit(ctrl.LoggerInto(ctx, log), e); err == nil {
-			e.status = assumed
-		} else {
+		c := snapshot.ClusterQueues[e.ClusterQueue]
+		if changedCQs.Has(c.Name) {
+			if reason := c.ExceededAdmissionLimit(&e.Info); reason != "" {
+				e.status = skipped
+				e.inadmissibleReason = reason
+				block(e)
+				continue
+			}
+		}
+		provisioned := admittedInCycle && len(snapshot.ProvisionableResources) != 0
+		if changedCQs.Has(c.Name) || (c.Cohort != nil && changedCohorts.Has(c.Cohort.Name)) || provisioned {
+			if status := e.assignFlavors(log, snapshot.ResourceFlavors, snapshot.ProvisionableResources, c); !status.IsSuccess() {
+				e.status = skipped
+				e.inadmissibleReason = truncateMessage(fmt.Sprintf("Workload no longer fits after admitting other workloads in this cycle: %s", status.Message()))
+				e.waitingForProvisioning = status.waitingForProvisioning
+				block(e)
+				continue
+			}
+		}
+		if err := s.admit(ctrl.LoggerInto(ctx, log), e, c); err != nil {
 			e.inadmissibleReason = fmt.Sprintf("Failed to admit workload: %v", err)
+			block(e)
+			continue
 		}
-		// Even if there was a failure, we shouldn't admit other workloads to this
-		// cohort.
+		e.status = assumed
+		admittedInCycle = true
+		snapshot.AddWorkload(&e.Info)
+		changedCQs.Insert(c.Name)
 		if c.Cohort != nil {
-			usedCohorts.Insert(c.Cohort.Name)
+			changedCohorts.Insert(c.Cohort.Name)
 		}
 	}
 
 	// 6. Requeue the heads that were not scheduled.
 	result := metrics.InadmissibleAdmissionResult
+	admitted := 0
 	for _, e := range entries {
 		log.V(3).Info("Workload evaluated for admission",
 			"workload", klog.KObj(e.Obj),
@@ -143,9 +304,14 @@ func (s *Scheduler) schedule(ctx context.Context) {
 			s.requeueAndUpdate(log, ctx, e)
 		} else {
 			result = metrics.SuccessAdmissionResult
+			admitted++
 		}
 	}
 	metrics.AdmissionAttempt(result, time.Since(startTime))
+	metrics.SchedulingCycle(snapshotDuration, len(entries), admitted)
+	span.SetAttributes(attribute.Int("considered", len(entries)), attribute.Int("admitted", admitted))
+	log.V(3).Info("Scheduling cycle finished", "considered", len(entries), "admitted", admitted,
+		"snapshotDuration", snapshotDuration, "duration", time.Since(startTime))
 }
 
 type entryStatus string
@@ -166,44 +332,92 @@ type entry struct {
 	workload.Info
 	// borrows is the resources that the workload would need to borrow from the
 	// cohort if it was scheduled in the clusterQueue.
-	borrows            cache.Resources
+	borrows cache.Resources
+	// position is the position of the workload among the heads of its
+	// clusterQueue obtained in this cycle.
+	position int
+	// behindBorrowing indicates that a workload ahead of this one in the
+	// clusterQueue needs to borrow.
+	behindBorrowing bool
+	// score is the total score given by the admission policies.
+	score              int64
 	status             entryStatus
 	inadmissibleReason string
+	// waitingForProvisioning indicates that the workload fits in the quota,
+	// but the node pools can't provision the nodes for it yet.
+	waitingForProvisioning bool
 }
 
 // nominate returns the workloads with their requirements (resource flavors, borrowing) if
 // they were admitted by the clusterQueues in the snapshot.
+// The snapshot is not modified while nominating, so the workloads are evaluated
+// concurrently.
 func (s *Scheduler) nominate(ctx context.Context, workloads []workload.Info, snap cache.Snapshot) []entry {
-	log := ctrl.LoggerFrom(ctx)
-	entries := make([]entry, 0, len(workloads))
-	for _, w := range workloads {
-		log := log.WithValues("workload", klog.KObj(w.Obj), "clusterQueue", klog.KRef("", w.ClusterQueue))
-		cq := snap.ClusterQueues[w.ClusterQueue]
-		ns := corev1.Namespace{}
-		e := entry{Info: w}
-		if snap.InactiveClusterQueueSets.Has(w.ClusterQueue) {
-			e.inadmissibleReason = fmt.Sprintf("ClusterQueue %s is inactive", w.ClusterQueue)
-		} else if cq == nil {
-			e.inadmissibleReason = fmt.Sprintf("ClusterQueue %s not found", w.ClusterQueue)
-		} else if err := s.client.Get(ctx, types.NamespacedName{Name: w.Obj.Namespace}, &ns); err != nil {
-			e.inadmissibleReason = fmt.Sprintf("Could not obtain workload namespace: %v", err)
-		} else if !cq.NamespaceSelector.Matches(labels.Set(ns.Labels)) {
-			e.inadmissibleReason = "Workload namespace doesn't match ClusterQueue selector"
-		} else if status := e.assignFlavors(log, snap.ResourceFlavors, cq); !status.IsSuccess() {
-			e.inadmissibleReason = truncateMessage(status.Message())
-		} else {
-			e.status = nominated
+	entries := make([]entry, len(workloads))
+	// Every head needs an entry to be requeued, so the work is not stopped
+	// when the context is done.
+	workqueue.ParallelizeUntil(context.Background(), s.nominationParallelism, len(workloads), func(i int) {
+		entries[i] = s.nominateWorkload(ctx, workloads[i], snap)
+	})
+	positions := make(map[string]int)
+	borrowingCQs := sets.NewString()
+	for i := range entries {
+		e := &entries[i]
+		// The heads of a clusterQueue are obtained in queue order.
+		e.position = positions[e.ClusterQueue]
+		positions[e.ClusterQueue]++
+		e.behindBorrowing = borrowingCQs.Has(e.ClusterQueue)
+		if len(e.borrows) > 0 {
+			borrowingCQs.Insert(e.ClusterQueue)
 		}
-		entries = append(entries, e)
 	}
 	return entries
 }
 
+// nominateWorkload evaluates the requirements for the workload to be admitted
+// by its clusterQueue in the snapshot.
+func (s *Scheduler) nominateWorkload(ctx context.Context, w workload.Info, snap cache.Snapshot) entry {
+	log := ctrl.LoggerFrom(ctx).WithValues("workload", klog.KObj(w.Obj), "clusterQueue", klog.KRef("", w.ClusterQueue))
+	cq := snap.ClusterQueues[w.ClusterQueue]
+	ns := corev1.Namespace{}
+	e := entry{Info: w}
+	// The short resources of a previous attempt don't apply if the workload
+	// is inadmissible for another reason now.
+	e.ShortResources = nil
+	if snap.InactiveClusterQueueSets.Has(w.ClusterQueue) {
+		e.inadmissibleReason = fmt.Sprintf("ClusterQueue %s is inactive", w.ClusterQueue)
+	} else if cq == nil {
+		e.inadmissibleReason = fmt.Sprintf("ClusterQueue %s not found", w.ClusterQueue)
+	} else if err := s.client.Get(ctx, types.NamespacedName{Name: w.Obj.Namespace}, &ns); err != nil {
+		e.inadmissibleReason = fmt.Sprintf("Could not obtain workload namespace: %v", err)
+	} else if !cq.NamespaceSelector.Matches(labels.Set(ns.Labels)) {
+		e.inadmissibleReason = "Workload namespace doesn't match ClusterQueue selector"
+	} else if errs := workload.ValidateLimitRange(ctx, s.client, w.Obj); len(errs) > 0 {
+		// The pods of the workload would be rejected after admission.
+		e.inadmissibleReason = truncateMessage(fmt.Sprintf("Workload doesn't satisfy the LimitRanges of the namespace: %v", errs.ToAggregate()))
+	} else if reason := cq.ExceededAdmissionLimit(&e.Info); reason != "" {
+		e.inadmissibleReason = reason
+	} else if status := e.assignFlavors(log, snap.ResourceFlavors, snap.ProvisionableResources, cq); !status.IsSuccess() {
+		e.inadmissibleReason = truncateMessage(status.Message())
+		e.waitingForProvisioning = status.waitingForProvisioning
+		if !status.IsError() {
+			// Only releasing quota of this resource can make the workload fit.
+			e.ShortResources = sets.NewString(status.resourceName)
+		}
+	} else {
+		e.status = nominated
+	}
+	return e
+}
+
 type admissionStatus struct {
 	podSet       string
 	resourceName string
 	reasons      []string
 	err          error
+	// waitingForProvisioning indicates that a flavor fits in the quota, but
+	// its node pools can't provision enough resources yet.
+	waitingForProvisioning bool
 }
 
 // Message returns a concatenated message on reasons of the admissionStatus.
@@ -214,8 +428,11 @@ func (s *admissionStatus) Message() string {
 	if s.IsError() {
 		return fmt.Sprintf("Could not assign a flavor for %s in podSet %s: %v", s.resourceName, s.podSet, s.err)
 	}
-	msg := strings.Join(s.reasons, ", ")
-	return fmt.Sprintf("Workload didn't fit, insufficient %s for podSet %s: %s", s.resourceName, s.podSet, msg)
+	msg := strings.Join(s.reasons, "; ")
+	if s.waitingForProvisioning {
+		return fmt.Sprintf("Waiting for provisioning, couldn't assign a flavor for %s in podSet %s: %s", s.resourceName, s.podSet, msg)
+	}
+	return fmt.Sprintf("Workload didn't fit, couldn't assign a flavor for %s in podSet %s: %s", s.resourceName, s.podSet, msg)
 }
 
 // AppendReason appends given reasons to the admissionStatus.
@@ -245,14 +462,18 @@ func asStatus(err error) *admissionStatus {
 // borrow from the cohort.
 // It returns admissionStatus indicating whether the entry fits. If it doesn't fit,
 // the entry is unmodified.
-func (e *entry) assignFlavors(log logr.Logger, resourceFlavors map[string]*kueue.ResourceFlavor, cq *cache.ClusterQueue) *admissionStatus {
+func (e *entry) assignFlavors(log logr.Logger, resourceFlavors map[string]*kueue.ResourceFlavor, provisionable cache.Resources, cq *cache.ClusterQueue) *admissionStatus {
 	flavoredRequests := make([]workload.PodSetResources, 0, len(e.TotalRequests))
+	var nsUsed cache.Resources
+	if cq.MaxNamespaceQuotaPercent != nil {
+		nsUsed = cq.NamespaceUsage(e.Obj.Namespace)
+	}
 	wUsed := make(cache.Resources)
 	wBorrows := make(cache.Resources)
 	for i, podSet := range e.TotalRequests {
 		flavors := make(map[corev1.ResourceName]string, len(podSet.Requests))
 		for resName, reqVal := range podSet.Requests {
-			rFlavor, borrow, status := findFlavorForResource(log, resName, reqVal, wUsed[resName], resourceFlavors, cq, &e.Obj.Spec.PodSets[i].Spec)
+			rFlavor, borrow, status := findFlavorForResource(log, resName, reqVal, wUsed[resName], nsUsed[resName], provisionable[resName], resourceFlavors, cq, &e.Obj.Spec.PodSets[i])
 			if !status.IsSuccess() {
 				status.resourceName = string(resName)
 				status.podSet = e.Obj.Spec.PodSets[i].Name
@@ -279,18 +500,16 @@ func (e *entry) assignFlavors(log logr.Logger, resourceFlavors map[string]*kueue
 		})
 	}
 	e.TotalRequests = flavoredRequests
+	e.borrows = nil
 	if len(wBorrows) > 0 {
 		e.borrows = wBorrows
 	}
 	return nil
 }
 
-// admit sets the admitting clusterQueue and flavors into the workload of
-// the entry, and asynchronously updates the object in the apiserver after
-// assuming it in the cache.
-func (s *Scheduler) admit(ctx context.Context, e *entry) error {
-	log := ctrl.LoggerFrom(ctx)
-	newWorkload := e.Obj.DeepCopy()
+// admission returns the admission of the workload with the flavors assigned
+// to the entry.
+func (e *entry) admission() *kueue.Admission {
 	admission := &kueue.Admission{
 		ClusterQueue:  kueue.ClusterQueueReference(e.ClusterQueue),
 		PodSetFlavors: make([]kueue.PodSetFlavors, len(e.TotalRequests)),
@@ -301,16 +520,52 @@ func (s *Scheduler) admit(ctx context.Context, e *entry) error {
 			Flavors: e.TotalRequests[i].Flavors,
 		}
 	}
+	return admission
+}
+
+// admit sets the admitting clusterQueue and flavors into the workload of
+// the entry, and asynchronously updates the object in the apiserver after
+// assuming it in the cache.
+func (s *Scheduler) admit(ctx context.Context, e *entry, cq *cache.ClusterQueue) error {
+	log := ctrl.LoggerFrom(ctx)
+	// The span belongs to the trace of the workload, if it has one, and it's
+	// linked to the scheduling cycle.
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, e.Obj), "Admit",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			attribute.String("workload", workload.Key(e.Obj)),
+			attribute.String("clusterQueue", e.ClusterQueue)))
+	newWorkload := e.Obj.DeepCopy()
+	admission := e.admission()
 	newWorkload.Spec.Admission = admission
-	if err := s.cache.AssumeWorkload(newWorkload); err != nil {
+	var auditRecord *audit.Record
+	if s.auditor != nil {
+		// Take the inputs before the usage of the workload is added to the
+		// snapshot.
+		info := e.Info
+		info.Obj = newWorkload
+		auditRecord = audit.NewRecord(audit.Admitted, &info, cq)
+	}
+	_, assumeSpan := tracing.Tracer().Start(ctx, "AssumeWorkload")
+	err := s.cache.AssumeWorkload(newWorkload)
+	tracing.End(assumeSpan, err)
+	if err != nil {
+		tracing.End(span, err)
 		return err
 	}
 	log.V(2).Info("Workload assumed in the cache")
 
 	s.admissionRoutineWrapper.Run(func() {
 		err := s.client.Update(ctx, newWorkload)
+		defer tracing.End(span, err)
 		if err == nil {
-			s.recorder.Eventf(newWorkload, corev1.EventTypeNormal, "Admitted", "Admitted by ClusterQueue %v", admission.ClusterQueue)
+			waitTime := time.Since(e.Obj.CreationTimestamp.Time)
+			workload.RecordEvent(s.recorder, newWorkload, corev1.EventTypeNormal, "QuotaReserved",
+				fmt.Sprintf("Quota reserved in ClusterQueue %v, flavors: %s", admission.ClusterQueue, flavorsSummary(admission)))
+			workload.RecordEvent(s.recorder, newWorkload, corev1.EventTypeNormal, "Admitted",
+				fmt.Sprintf("Admitted by ClusterQueue %v, wait time was %.3fs", admission.ClusterQueue, waitTime.Seconds()))
+			metrics.AdmittedWorkload(string(admission.ClusterQueue), waitTime)
+			s.auditor.Record(ctx, auditRecord)
 			log.V(2).Info("Workload successfully admitted and assigned flavors")
 			return
 		}
@@ -329,6 +584,21 @@ func (s *Scheduler) admit(ctx context.Context, e *entry) error {
 	return nil
 }
 
+// flavorsSummary lists the flavors assigned to each resource of each podSet,
+// like "main: cpu=on-demand, memory=on-demand".
+func flavorsSummary(admission *kueue.Admission) string {
+	podSets := make([]string, len(admission.PodSetFlavors))
+	for i, psFlavors := range admission.PodSetFlavors {
+		flavors := make([]string, 0, len(psFlavors.Flavors))
+		for rName, flavor := range psFlavors.Flavors {
+			flavors = append(flavors, fmt.Sprintf("%s=%s", rName, flavor))
+		}
+		sort.Strings(flavors)
+		podSets[i] = fmt.Sprintf("%s: %s", psFlavors.Name, strings.Join(flavors, ", "))
+	}
+	return strings.Join(podSets, "; ")
+}
+
 // findFlavorForResources returns a flavor which can satisfy the resource request,
 // given that wUsed is the usage of flavors by previous podsets.
 // If it finds a flavor, also returns any borrowing required.
@@ -337,25 +607,50 @@ func findFlavorForResource(
 	name corev1.ResourceName,
 	val int64,
 	wUsed map[string]int64,
+	nsUsed map[string]int64,
+	provisionable map[string]int64,
 	resourceFlavors map[string]*kueue.ResourceFlavor,
 	cq *cache.ClusterQueue,
-	spec *corev1.PodSpec) (string, int64, *admissionStatus) {
+	podSet *kueue.PodSet) (string, int64, *admissionStatus) {
 	var status admissionStatus
 
 	if _, exists := cq.RequestableResources[name]; !exists {
 		return "", 0, asStatus(fmt.Errorf("resource unavailable in ClusterQueue"))
 	}
 
+	spec := &podSet.Spec
+	requiredFlavors := sets.NewString(podSet.RequiredFlavors...)
+	requiredLabels := labels.Everything()
+	if podSet.FlavorSelector != nil {
+		var err error
+		if requiredLabels, err = metav1.LabelSelectorAsSelector(podSet.FlavorSelector); err != nil {
+			return "", 0, asStatus(fmt.Errorf("parsing the flavor selector of podSet %s: %w", podSet.Name, err))
+		}
+	}
 	// We will only check against the flavors' labels for the resource.
 	selector := flavorSelector(spec, cq.LabelKeys[name])
 	for _, flvLimit := range cq.RequestableResources[name] {
+		if requiredFlavors.Len() != 0 && !requiredFlavors.Has(flvLimit.Name) {
+			status.AppendReason(fmt.Sprintf("flavor %s is not in the required flavors of the podSet", flvLimit.Name))
+			continue
+		}
 		flavor, exist := resourceFlavors[flvLimit.Name]
 		if !exist {
 			log.Error(nil, "Flavor not found", "Flavor", flvLimit.Name)
 			status.AppendReason(fmt.Sprintf("flavor %s not found", flvLimit.Name))
 			continue
 		}
-		taint, untolerated := corev1helpers.FindMatchingUntoleratedTaint(flavor.Taints, spec.Tolerations, func(t *corev1.Taint) bool {
+		if !requiredLabels.Matches(labels.Set(flavor.ObjectMeta.Labels)) {
+			status.AppendReason(fmt.Sprintf("flavor %s doesn't match the flavor selector of the podSet", flvLimit.Name))
+			continue
+		}
+		// The tolerations of the flavor are injected in the pods when the
+		// workload is admitted, so they also count as tolerated.
+		tolerations := spec.Tolerations
+		if len(flavor.Tolerations) != 0 {
+			tolerations = append(append([]corev1.Toleration(nil), spec.Tolerations...), flavor.Tolerations...)
+		}
+		taint, untolerated := corev1helpers.FindMatchingUntoleratedTaint(flavor.Taints, tolerations, func(t *corev1.Taint) bool {
 			return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
 		})
 		if untolerated {
@@ -370,9 +665,22 @@ func findFlavorForResource(
 			continue
 		}
 
+		if nsQuota, limited := cq.NamespaceQuota(&flvLimit); limited && nsUsed[flavor.Name]+val+wUsed[flavor.Name] > nsQuota {
+			status.AppendReason(fmt.Sprintf("namespace limit for %s in flavor %s exceeded", name, flvLimit.Name))
+			continue
+		}
+
 		// Check considering the flavor usage by previous pod sets.
 		borrow, s := fitsFlavorLimits(name, val+wUsed[flavor.Name], cq, &flvLimit)
 		if s.IsSuccess() {
+			// The capacity of the node pools is an estimate, so it only makes
+			// the flavors that can be provisioned preferred. If none of them
+			// can be, the workload waits for provisioning.
+			if left, limited := provisionable[flavor.Name]; limited && val+wUsed[flavor.Name] > left {
+				status.AppendReason(fmt.Sprintf("node pools of flavor %s can't provision enough %s", flvLimit.Name, name))
+				status.waitingForProvisioning = true
+				continue
+			}
 			return flavor.Name, borrow, nil
 		}
 		if s.IsError() {
@@ -435,7 +743,7 @@ func fitsFlavorLimits(name corev1.ResourceName, val int64, cq *cache.ClusterQueu
 	var status admissionStatus
 	used := cq.UsedResources[name][flavor.Name]
 	if flavor.Max != nil && used+val > *flavor.Max {
-		status.AppendReason(fmt.Sprintf("borrowing limit for flavor %s exceeded", flavor.Name))
+		status.AppendReason(fmt.Sprintf("borrowing limit for %s in flavor %s exceeded", name, flavor.Name))
 		return 0, &status
 	}
 	cohortUsed := used
@@ -451,10 +759,11 @@ func fitsFlavorLimits(name corev1.ResourceName, val int64, cq *cache.ClusterQueu
 
 	lack := cohortUsed + val - cohortTotal
 	if lack > 0 {
+		lackQuantity := workload.ResourceQuantity(name, lack)
 		if cq.Cohort == nil {
-			status.AppendReason(fmt.Sprintf("insufficient quota for flavor %s, %d more needed", flavor.Name, lack))
+			status.AppendReason(fmt.Sprintf("insufficient quota for %s in flavor %s, %s more needed", name, flavor.Name, &lackQuantity))
 		} else {
-			status.AppendReason(fmt.Sprintf("insufficient quota for flavor %s, %d more needed after borrowing", flavor.Name, lack))
+			status.AppendReason(fmt.Sprintf("insufficient quota for %s in flavor %s, %s more needed after borrowing", name, flavor.Name, &lackQuantity))
 		}
 		// TODO(PostMVP): preemption could help if borrow == 0
 		return 0, &status
@@ -473,18 +782,30 @@ func (e entryOrdering) Swap(i, j int) {
 }
 
 // Less is the ordering criteria:
-// 1. request under min quota before borrowing.
-// 2. FIFO on creation timestamp.
+// 1. request under min quota before borrowing. A workload behind a borrowing
+// one in the same clusterQueue is considered borrowing.
+// 2. higher score from the admission policies. In a StrictFIFO clusterQueue,
+// an entry doesn't score higher than the entries ahead of it.
+// 3. position in the clusterQueue.
+// 4. FIFO on creation timestamp.
 func (e entryOrdering) Less(i, j int) bool {
 	a := e[i]
 	b := e[j]
 	// 1. Request under min quota.
-	aMin := len(a.borrows) == 0
-	bMin := len(b.borrows) == 0
+	aMin := len(a.borrows) == 0 && !a.behindBorrowing
+	bMin := len(b.borrows) == 0 && !b.behindBorrowing
 	if aMin != bMin {
 		return aMin
 	}
-	// 2. FIFO.
+	// 2. Score from the admission policies.
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	// 3. Position in the clusterQueue.
+	if a.position != b.position {
+		return a.position < b.position
+	}
+	// 4. FIFO.
 	return a.Obj.CreationTimestamp.Before(&b.Obj.CreationTimestamp)
 }
 
@@ -493,11 +814,15 @@ func (s *Scheduler) requeueAndUpdate(log logr.Logger, ctx context.Context, e ent
 	log.V(2).Info("Workload re-queued", "workload", klog.KObj(e.Obj), "clusterQueue", e.ClusterQueue, "queue", klog.KRef(e.Obj.Namespace, e.Obj.Spec.QueueName), "added", added, "status", e.status)
 
 	if e.status == "" {
-		err := workload.UpdateStatus(ctx, s.client, e.Obj, kueue.WorkloadAdmitted, corev1.ConditionFalse, "Pending", e.inadmissibleReason)
+		reason, eventReason := "Pending", "Inadmissible"
+		if e.waitingForProvisioning {
+			reason, eventReason = "WaitingForProvisioning", "WaitingForProvisioning"
+		}
+		err := workload.UpdateStatus(ctx, s.client, e.Obj, kueue.WorkloadAdmitted, corev1.ConditionFalse, reason, e.inadmissibleReason)
 		if err != nil {
 			log.Error(err, "Could not update Workload status")
 		}
-		s.recorder.Eventf(e.Obj, corev1.EventTypeNormal, "Pending", e.inadmissibleReason)
+		workload.RecordEvent(s.recorder, e.Obj, corev1.EventTypeNormal, eventReason, e.inadmissibleReason)
 	}
 }
 
@@ -53,11 +53,13 @@ const (
 )
 
 func TestSchedule(t *testing.T) {
+	now := time.Now()
 	resourceFlavors := []*kueue.ResourceFlavor{
 		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
 		{ObjectMeta: metav1.ObjectMeta{Name: "on-demand"}},
 		{ObjectMeta: metav1.ObjectMeta{Name: "spot"}},
 		{ObjectMeta: metav1.ObjectMeta{Name: "model-a"}},
+		utiltesting.MakeResourceFlavor("provisioned").Label("pool", "gpu").Obj(),
 	}
 	clusterQueues := []kueue.ClusterQueue{
 		{
@@ -175,6 +177,50 @@ func TestSchedule(t *testing.T) {
 				},
 			},
 		},
+		*utiltesting.MakeClusterQueue("limited-workloads").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "100").Obj()).Obj()).
+			AdmissionLimits(pointer.Int32(2), nil).
+			Obj(),
+		*utiltesting.MakeClusterQueue("limited-pods").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("default", "100").Obj()).Obj()).
+			AdmissionLimits(nil, pointer.Int32(10)).
+			Obj(),
+		*utiltesting.MakeClusterQueue("provisioned-a").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("provisioned", "10").Obj()).Obj()).
+			Obj(),
+		*utiltesting.MakeClusterQueue("provisioned-b").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("provisioned", "10").Obj()).Obj()).
+			Obj(),
+		*utiltesting.MakeClusterQueue("limited-namespaces").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("on-demand", "10").Obj()).
+				Flavor(utiltesting.MakeFlavor("spot", "4").Obj()).Obj()).
+			MaxNamespaceQuotaPercent(50).
+			Obj(),
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort"},
+			Spec: kueue.ClusterQueueSpec{
+				NamespaceSelector: &metav1.LabelSelector{},
+				QueueingStrategy:  kueue.BestEffortFIFO,
+				Resources: []kueue.Resource{
+					{
+						Name: corev1.ResourceCPU,
+						Flavors: []kueue.Flavor{
+							{
+								Name: "default",
+								Quota: kueue.Quota{
+									Min: resource.MustParse("50"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			ObjectMeta: metav1.ObjectMeta{Name: "flavor-nonexistent-cq"},
 			Spec: kueue.ClusterQueueSpec{
@@ -232,6 +278,21 @@ func TestSchedule(t *testing.T) {
 				ClusterQueue: "eng-beta",
 			},
 		},
+		*utiltesting.MakeQueue("limited-workloads", "sales").ClusterQueue("limited-workloads").Obj(),
+		*utiltesting.MakeQueue("limited-pods", "sales").ClusterQueue("limited-pods").Obj(),
+		*utiltesting.MakeQueue("limited-namespaces", "sales").ClusterQueue("limited-namespaces").Obj(),
+		*utiltesting.MakeQueue("provisioned-a", "sales").ClusterQueue("provisioned-a").Obj(),
+		*utiltesting.MakeQueue("provisioned-b", "sales").ClusterQueue("provisioned-b").Obj(),
+		*utiltesting.MakeQueue("limited-namespaces", "eng-alpha").ClusterQueue("limited-namespaces").Obj(),
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "sales",
+				Name:      "best-effort",
+			},
+			Spec: kueue.QueueSpec{
+				ClusterQueue: "best-effort",
+			},
+		},
 		{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: "sales",
@@ -259,7 +320,171 @@ func TestSchedule(t *testing.T) {
 		wantScheduled []string
 		// wantLeft is the workload keys that are left in the queues after this cycle.
 		wantLeft map[string]sets.String
+		// waitForPodsReady enables blocking the admission until the pods of
+		// the admitted workloads are ready.
+		waitForPodsReady  bool
+		limitRanges       []corev1.LimitRange
+		admissionPolicies []AdmissionPolicy
+		nodePools         []cache.NodePool
 	}{
+		"workloads exceed the resources that the node pools can provision": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("a", "sales").
+					Queue("provisioned-a").
+					Creation(now).
+					Request(corev1.ResourceCPU, "5").
+					Obj(),
+				*utiltesting.MakeWorkload("b", "sales").
+					Queue("provisioned-b").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "5").
+					Obj(),
+			},
+			nodePools: []cache.NodePool{{
+				Name:   "gpu",
+				Labels: map[string]string{"pool": "gpu"},
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+			}},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/a": *utiltesting.MakeAdmission("provisioned-a").Flavor(corev1.ResourceCPU, "provisioned").Obj(),
+			},
+			wantScheduled: []string{"sales/a"},
+			wantLeft: map[string]sets.String{
+				"provisioned-b": sets.NewString("b"),
+			},
+		},
+		"workload exceeds the maximum of a LimitRange": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("main").
+					Request(corev1.ResourceCPU, "2").
+					Limit(corev1.ResourceCPU, "2").
+					Obj(),
+			},
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("max", "sales").
+					Max(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("foo"),
+			},
+		},
+		"workloads exceed the limit of admitted workloads": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("running", "sales").
+					Queue("limited-workloads").
+					Request(corev1.ResourceCPU, "1").
+					Admit(utiltesting.MakeAdmission("limited-workloads").Flavor(corev1.ResourceCPU, "default").Obj()).
+					Obj(),
+				*utiltesting.MakeWorkload("a", "sales").
+					Queue("limited-workloads").
+					Creation(now).
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+				*utiltesting.MakeWorkload("b", "sales").
+					Queue("limited-workloads").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/running": *utiltesting.MakeAdmission("limited-workloads").Flavor(corev1.ResourceCPU, "default").Obj(),
+				"sales/a":       *utiltesting.MakeAdmission("limited-workloads").Flavor(corev1.ResourceCPU, "default").Obj(),
+			},
+			wantScheduled: []string{"sales/a"},
+			wantLeft: map[string]sets.String{
+				"limited-workloads": sets.NewString("b"),
+			},
+		},
+		"workloads exceed the limit of admitted pods": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("running", "sales").
+					Queue("limited-pods").
+					PodCount(6).
+					Request(corev1.ResourceCPU, "1").
+					Admit(utiltesting.MakeAdmission("limited-pods").Flavor(corev1.ResourceCPU, "default").Obj()).
+					Obj(),
+				*utiltesting.MakeWorkload("too-many-pods", "sales").
+					Queue("limited-pods").
+					Creation(now).
+					PodCount(5).
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/running": *utiltesting.MakeAdmission("limited-pods").Flavor(corev1.ResourceCPU, "default").Obj(),
+			},
+		},
+		"workloads exceed the limit of admitted pods after admitting others in the cycle": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("running", "sales").
+					Queue("limited-pods").
+					PodCount(6).
+					Request(corev1.ResourceCPU, "1").
+					Admit(utiltesting.MakeAdmission("limited-pods").Flavor(corev1.ResourceCPU, "default").Obj()).
+					Obj(),
+				*utiltesting.MakeWorkload("fits", "sales").
+					Queue("limited-pods").
+					Creation(now).
+					PodCount(4).
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+				*utiltesting.MakeWorkload("no-longer-fits", "sales").
+					Queue("limited-pods").
+					Creation(now.Add(time.Second)).
+					PodCount(1).
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/running": *utiltesting.MakeAdmission("limited-pods").Flavor(corev1.ResourceCPU, "default").Obj(),
+				"sales/fits":    *utiltesting.MakeAdmission("limited-pods").Flavor(corev1.ResourceCPU, "default").Obj(),
+			},
+			wantScheduled: []string{"sales/fits"},
+			wantLeft: map[string]sets.String{
+				"limited-pods": sets.NewString("no-longer-fits"),
+			},
+		},
+		"workloads exceed the namespace limit": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("running", "sales").
+					Queue("limited-namespaces").
+					Request(corev1.ResourceCPU, "4").
+					Admit(utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+					Obj(),
+				*utiltesting.MakeWorkload("next-flavor", "sales").
+					Queue("limited-namespaces").
+					Request(corev1.ResourceCPU, "2").
+					Obj(),
+				*utiltesting.MakeWorkload("other-namespace", "eng-alpha").
+					Queue("limited-namespaces").
+					Request(corev1.ResourceCPU, "5").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/running":             *utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+				"sales/next-flavor":         *utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "spot").Obj(),
+				"eng-alpha/other-namespace": *utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+			wantScheduled: []string{"sales/next-flavor", "eng-alpha/other-namespace"},
+		},
+		"workload exceeds the namespace limit in every flavor": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("running", "sales").
+					Queue("limited-namespaces").
+					Request(corev1.ResourceCPU, "4").
+					Admit(utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+					Obj(),
+				*utiltesting.MakeWorkload("too-big", "sales").
+					Queue("limited-namespaces").
+					Request(corev1.ResourceCPU, "3").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/running": *utiltesting.MakeAdmission("limited-namespaces").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+		},
 		"workload fits in single clusterQueue": {
 			workloads: []kueue.Workload{
 				{
@@ -452,6 +677,64 @@ func TestSchedule(t *testing.T) {
 			},
 			wantScheduled: []string{"sales/new", "eng-alpha/new"},
 		},
+		"wait for pods ready admits one workload per cycle": {
+			workloads: []kueue.Workload{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "sales",
+						Name:      "new",
+					},
+					Spec: kueue.WorkloadSpec{
+						QueueName: "main",
+						PodSets: []kueue.PodSet{
+							{
+								Name:  "one",
+								Count: 1,
+								Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+									corev1.ResourceCPU: "1",
+								}),
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "eng-alpha",
+						Name:      "new",
+					},
+					Spec: kueue.WorkloadSpec{
+						QueueName: "main",
+						PodSets: []kueue.PodSet{
+							{
+								Name:  "one",
+								Count: 51, // will borrow.
+								Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+									corev1.ResourceCPU: "1",
+								}),
+							},
+						},
+					},
+				},
+			},
+			waitForPodsReady: true,
+			wantAssignments: map[string]kueue.Admission{
+				"sales/new": {
+					ClusterQueue: "sales",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "one",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "default",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"sales/new"},
+			wantLeft: map[string]sets.String{
+				"eng-alpha": sets.NewString("new"),
+			},
+		},
 		"assign to same cohort no borrowing": {
 			workloads: []kueue.Workload{
 				{
@@ -568,7 +851,251 @@ func TestSchedule(t *testing.T) {
 			},
 			wantScheduled: []string{"eng-beta/new"},
 		},
-		"cannot borrow if cohort was assigned": {
+		"multiple workloads of a clusterQueue admitted in one cycle": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("main").
+					Creation(now).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("bar", "sales").
+					Queue("main").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("baz", "sales").
+					Queue("main").
+					Creation(now.Add(2*time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/foo": {
+					ClusterQueue: "sales",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "default",
+							},
+						},
+					},
+				},
+				"sales/bar": {
+					ClusterQueue: "sales",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "default",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"sales/foo", "sales/bar"},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("baz"),
+			},
+		},
+		"workloads behind a workload that doesn't fit wait in a StrictFIFO clusterQueue": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("big", "sales").
+					Queue("main").
+					Creation(now).
+					Request(corev1.ResourceCPU, "60").
+					Obj(),
+				*utiltesting.MakeWorkload("small", "sales").
+					Queue("main").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "10").
+					Obj(),
+			},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("big", "small"),
+			},
+		},
+		"workloads behind a workload that doesn't fit are admitted in a BestEffortFIFO clusterQueue": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("big", "sales").
+					Queue("best-effort").
+					Creation(now).
+					Request(corev1.ResourceCPU, "60").
+					Obj(),
+				*utiltesting.MakeWorkload("small", "sales").
+					Queue("best-effort").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "10").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/small": {
+					ClusterQueue: "best-effort",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "default",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"sales/small"},
+		},
+		"admission policy re-orders the workloads of a BestEffortFIFO clusterQueue": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("best-effort").
+					Creation(now).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("bar", "sales").
+					Queue("best-effort").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("baz", "sales").
+					Queue("best-effort").
+					Creation(now.Add(2*time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+			},
+			admissionPolicies: []AdmissionPolicy{
+				&testAdmissionPolicy{scores: map[string]int64{"sales/baz": 10}},
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/foo": *utiltesting.MakeAdmission("best-effort").Flavor(corev1.ResourceCPU, "default").Obj(),
+				"sales/baz": *utiltesting.MakeAdmission("best-effort").Flavor(corev1.ResourceCPU, "default").Obj(),
+			},
+			wantScheduled: []string{"sales/baz", "sales/foo"},
+			wantLeft: map[string]sets.String{
+				"best-effort": sets.NewString("bar"),
+			},
+		},
+		"admission policy doesn't re-order the workloads of a StrictFIFO clusterQueue": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("main").
+					Creation(now).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("bar", "sales").
+					Queue("main").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("baz", "sales").
+					Queue("main").
+					Creation(now.Add(2*time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+			},
+			admissionPolicies: []AdmissionPolicy{
+				&testAdmissionPolicy{scores: map[string]int64{"sales/baz": 10}},
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"sales/foo": *utiltesting.MakeAdmission("sales").Flavor(corev1.ResourceCPU, "default").Obj(),
+				"sales/bar": *utiltesting.MakeAdmission("sales").Flavor(corev1.ResourceCPU, "default").Obj(),
+			},
+			wantScheduled: []string{"sales/foo", "sales/bar"},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("baz"),
+			},
+		},
+		"workloads behind a workload filtered out by an admission policy wait in a StrictFIFO clusterQueue": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("main").
+					Creation(now).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+				*utiltesting.MakeWorkload("bar", "sales").
+					Queue("main").
+					Creation(now.Add(time.Second)).
+					Request(corev1.ResourceCPU, "20").
+					Obj(),
+			},
+			admissionPolicies: []AdmissionPolicy{
+				&testAdmissionPolicy{
+					filtered: sets.NewString("sales/foo"),
+					scores:   map[string]int64{"sales/bar": 10},
+				},
+			},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("foo", "bar"),
+			},
+		},
+		"admission policy filters out a workload": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("foo", "sales").
+					Queue("main").
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+				*utiltesting.MakeWorkload("new", "eng-alpha").
+					Queue("main").
+					Request(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			admissionPolicies: []AdmissionPolicy{
+				&testAdmissionPolicy{filtered: sets.NewString("sales/foo")},
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"eng-alpha/new": {
+					ClusterQueue: "eng-alpha",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "on-demand",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"eng-alpha/new"},
+			wantLeft: map[string]sets.String{
+				"sales": sets.NewString("foo"),
+			},
+		},
+		"can borrow if cohort was assigned and there is quota left": {
+			workloads: []kueue.Workload{
+				*utiltesting.MakeWorkload("new", "eng-alpha").
+					Queue("main").
+					Request(corev1.ResourceCPU, "40").
+					Obj(),
+				*utiltesting.MakeWorkload("new", "eng-beta").
+					Queue("main").
+					Request(corev1.ResourceCPU, "51").
+					Obj(),
+			},
+			wantAssignments: map[string]kueue.Admission{
+				"eng-alpha/new": {
+					ClusterQueue: "eng-alpha",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "on-demand",
+							},
+						},
+					},
+				},
+				"eng-beta/new": {
+					ClusterQueue: "eng-beta",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "main",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "on-demand",
+							},
+						},
+					},
+				},
+			},
+			wantScheduled: []string{"eng-alpha/new", "eng-beta/new"},
+		},
+		"assigns the next flavor if the cohort quota was used in this cycle": {
 			workloads: []kueue.Workload{
 				{
 					ObjectMeta: metav1.ObjectMeta{
@@ -580,7 +1107,7 @@ func TestSchedule(t *testing.T) {
 						PodSets: []kueue.PodSet{
 							{
 								Name:  "one",
-								Count: 40,
+								Count: 50,
 								Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
 									corev1.ResourceCPU: "1",
 								}),
@@ -619,11 +1146,19 @@ func TestSchedule(t *testing.T) {
 						},
 					},
 				},
+				"eng-beta/new": {
+					ClusterQueue: "eng-beta",
+					PodSetFlavors: []kueue.PodSetFlavors{
+						{
+							Name: "one",
+							Flavors: map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "spot",
+							},
+						},
+					},
+				},
 			},
-			wantScheduled: []string{"eng-alpha/new"},
-			wantLeft: map[string]sets.String{
-				"eng-beta": sets.NewString("new"),
-			},
+			wantScheduled: []string{"eng-alpha/new", "eng-beta/new"},
 		},
 		"cannot borrow resource not listed in clusterQueue": {
 			workloads: []kueue.Workload{
@@ -787,7 +1322,7 @@ func TestSchedule(t *testing.T) {
 				t.Fatalf("Failed adding kueue scheme: %v", err)
 			}
 			clientBuilder := fake.NewClientBuilder().WithScheme(scheme).
-				WithLists(&kueue.WorkloadList{Items: tc.workloads}, &kueue.QueueList{Items: queues}).
+				WithLists(&kueue.WorkloadList{Items: tc.workloads}, &kueue.QueueList{Items: queues}, &corev1.LimitRangeList{Items: tc.limitRanges}).
 				WithObjects(
 					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "eng-alpha", Labels: map[string]string{"dep": "eng"}}},
 					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "eng-beta", Labels: map[string]string{"dep": "eng"}}},
@@ -808,6 +1343,9 @@ func TestSchedule(t *testing.T) {
 			for i := range resourceFlavors {
 				cqCache.AddOrUpdateResourceFlavor(resourceFlavors[i])
 			}
+			for i := range tc.nodePools {
+				cqCache.AddOrUpdateNodePool(&tc.nodePools[i])
+			}
 			for _, cq := range clusterQueues {
 				if err := cqCache.AddClusterQueue(ctx, &cq); err != nil {
 					t.Fatalf("Inserting clusterQueue %s in cache: %v", cq.Name, err)
@@ -820,7 +1358,7 @@ func TestSchedule(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed setting up watch: %v", err)
 			}
-			scheduler := New(qManager, cqCache, cl, recorder)
+			scheduler := New(qManager, cqCache, cl, recorder, WithWaitForPodsReady(tc.waitForPodsReady), WithAdmissionPolicies(tc.admissionPolicies...))
 			wg := sync.WaitGroup{}
 			scheduler.setAdmissionRoutineWrapper(routine.NewWrapper(
 				func() { wg.Add(1) },
@@ -887,17 +1425,155 @@ func TestSchedule(t *testing.T) {
 	}
 }
 
-func TestEntryAssignFlavors(t *testing.T) {
-	resourceFlavors := map[string]*kueue.ResourceFlavor{
-		"default": {
-			ObjectMeta: metav1.ObjectMeta{Name: "default"},
-		},
-		"one": {
-			ObjectMeta: metav1.ObjectMeta{Name: "one"},
-			Labels:     map[string]string{"type": "one"},
+func TestDryRun(t *testing.T) {
+	resourceFlavors := []*kueue.ResourceFlavor{
+		utiltesting.MakeResourceFlavor("on-demand").Obj(),
+		utiltesting.MakeResourceFlavor("spot").Obj(),
+	}
+	clusterQueues := []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("cq").
+			Cohort("cohort").
+			NamespaceSelector(&metav1.LabelSelector{}).
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("on-demand", "5").Obj()).
+				Flavor(utiltesting.MakeFlavor("spot", "5").Obj()).
+				Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("lender").
+			Cohort("cohort").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("on-demand", "5").Obj()).
+				Obj()).
+			Obj(),
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "default").
+		Request(corev1.ResourceCPU, "4").
+		Admit(utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+		Obj()
+	cases := map[string]struct {
+		workload *kueue.Workload
+		want     DryRunResult
+	}{
+		"fits in the first flavor": {
+			workload: utiltesting.MakeWorkload("new", "default").
+				Queue("main").
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+			want: DryRunResult{
+				ClusterQueue: "cq",
+				Fits:         true,
+				Admission:    utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+			},
+		},
+		"fits borrowing": {
+			workload: utiltesting.MakeWorkload("new", "default").
+				Queue("main").
+				Request(corev1.ResourceCPU, "3").
+				Obj(),
+			want: DryRunResult{
+				ClusterQueue: "cq",
+				Fits:         true,
+				Admission:    utiltesting.MakeAdmission("cq").Flavor(corev1.ResourceCPU, "on-demand").Obj(),
+				Borrows: map[corev1.ResourceName]map[string]resource.Quantity{
+					corev1.ResourceCPU: {"on-demand": resource.MustParse("2")},
+				},
+			},
+		},
+		"doesn't fit": {
+			workload: utiltesting.MakeWorkload("new", "default").
+				Queue("main").
+				Request(corev1.ResourceCPU, "20").
+				Obj(),
+			want: DryRunResult{
+				ClusterQueue: "cq",
+				Reason:       "Workload didn't fit, couldn't assign a flavor for cpu in podSet main: insufficient quota for cpu in flavor on-demand, 14 more needed after borrowing; insufficient quota for cpu in flavor spot, 15 more needed after borrowing",
+			},
+		},
+		"queue doesn't exist": {
+			workload: utiltesting.MakeWorkload("new", "default").
+				Queue("missing").
+				Request(corev1.ResourceCPU, "1").
+				Obj(),
+			want: DryRunResult{
+				Reason: "Queue missing doesn't exist or its ClusterQueue doesn't exist",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding kueue scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding core scheme: %v", err)
+			}
+			q := utiltesting.MakeQueue("main", "default").ClusterQueue("cq").Obj()
+			cl := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(q, admitted, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+				Build()
+			cqCache := cache.New(cl)
+			qManager := queue.NewManager(cl, cqCache)
+			for _, rf := range resourceFlavors {
+				cqCache.AddOrUpdateResourceFlavor(rf)
+			}
+			for _, cq := range clusterQueues {
+				if err := cqCache.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Inserting clusterQueue %s in cache: %v", cq.Name, err)
+				}
+				if err := qManager.AddClusterQueue(ctx, cq); err != nil {
+					t.Fatalf("Inserting clusterQueue %s in manager: %v", cq.Name, err)
+				}
+			}
+			if err := qManager.AddQueue(ctx, q); err != nil {
+				t.Fatalf("Inserting queue in manager: %v", err)
+			}
+			scheduler := New(qManager, cqCache, cl, record.NewFakeRecorder(10))
+
+			got := scheduler.DryRun(ctx, tc.workload)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected result (-want,+got):\n%s", diff)
+			}
+			if pending := qManager.Pending(clusterQueues[0]); pending != 0 {
+				t.Errorf("Dry run queued the workload, got %d pending workloads", pending)
+			}
+		})
+	}
+}
+
+// testAdmissionPolicy filters out and scores the workloads by key.
+type testAdmissionPolicy struct {
+	filtered sets.String
+	scores   map[string]int64
+}
+
+func (p *testAdmissionPolicy) Name() string {
+	return "test"
+}
+
+func (p *testAdmissionPolicy) Filter(_ context.Context, c *Candidate) (bool, string) {
+	if p.filtered.Has(workload.Key(c.Workload)) {
+		return false, "filtered out"
+	}
+	return true, ""
+}
+
+func (p *testAdmissionPolicy) Score(_ context.Context, c *Candidate) int64 {
+	return p.scores[workload.Key(c.Workload)]
+}
+
+func TestEntryAssignFlavors(t *testing.T) {
+	resourceFlavors := map[string]*kueue.ResourceFlavor{
+		"default": {
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		},
+		"one": {
+			ObjectMeta: metav1.ObjectMeta{Name: "one"},
+			Labels:     map[string]string{"type": "one"},
 		},
 		"two": {
-			ObjectMeta: metav1.ObjectMeta{Name: "two"},
+			ObjectMeta: metav1.ObjectMeta{Name: "two", Labels: map[string]string{"hardware": "gpu"}},
 			Labels:     map[string]string{"type": "two"},
 		},
 		"tainted": {
@@ -908,15 +1584,34 @@ func TestEntryAssignFlavors(t *testing.T) {
 				Effect: corev1.TaintEffectNoSchedule,
 			}},
 		},
+		"tolerated": {
+			ObjectMeta: metav1.ObjectMeta{Name: "tolerated"},
+			Taints: []corev1.Taint{{
+				Key:    "instance",
+				Value:  "spot",
+				Effect: corev1.TaintEffectNoSchedule,
+			}},
+			Tolerations: []corev1.Toleration{{
+				Key:      "instance",
+				Operator: corev1.TolerationOpEqual,
+				Value:    "spot",
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+		},
 	}
 
 	cases := map[string]struct {
 		wlPods       []kueue.PodSet
 		clusterQueue cache.ClusterQueue
-		wantFits     bool
-		wantFlavors  map[string]map[corev1.ResourceName]string
-		wantBorrows  cache.Resources
-		wantMsg      string
+		// provisionable are the resources that node pools can still provision.
+		provisionable cache.Resources
+		wantFits      bool
+		wantFlavors   map[string]map[corev1.ResourceName]string
+		wantBorrows   cache.Resources
+		wantMsg       string
+		// wantWaitingForProvisioning indicates that the workload fits in the
+		// quota, but waits for the node pools to provision the nodes.
+		wantWaitingForProvisioning bool
 	}{
 		"single flavor, fits": {
 			wlPods: []kueue.PodSet{
@@ -999,7 +1694,7 @@ func TestEntryAssignFlavors(t *testing.T) {
 					},
 				},
 			},
-			wantMsg: "insufficient quota for flavor default, 1000 more needed",
+			wantMsg: "insufficient quota for cpu in flavor default, 1 more needed",
 		},
 		"multiple flavors, fits": {
 			wlPods: []kueue.PodSet{
@@ -1055,7 +1750,7 @@ func TestEntryAssignFlavors(t *testing.T) {
 					},
 				},
 			},
-			wantMsg: "insufficient quota for flavor one, 2100 more needed, insufficient quota for flavor two, 100 more needed",
+			wantMsg: "insufficient quota for cpu in flavor one, 2100m more needed; insufficient quota for cpu in flavor two, 100m more needed",
 		},
 		"multiple flavors, fits while skipping tainted flavor": {
 			wlPods: []kueue.PodSet{
@@ -1082,6 +1777,81 @@ func TestEntryAssignFlavors(t *testing.T) {
 				},
 			},
 		},
+		"single flavor, fits tainted flavor with flavor tolerations": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "tolerated", Min: 4000},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "tolerated",
+				},
+			},
+		},
+		"single flavor, doesn't fit tainted flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "tainted", Min: 4000},
+					},
+				},
+			},
+			wantMsg: "untolerated taint {instance spot NoSchedule <nil>} in flavor tainted",
+		},
+		"multiple flavors, fits tainted flavor tolerated by the podset": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: func() corev1.PodSpec {
+						spec := utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+							corev1.ResourceCPU: "3",
+						})
+						spec.Tolerations = []corev1.Toleration{{
+							Key:      "instance",
+							Operator: corev1.TolerationOpExists,
+						}}
+						return spec
+					}(),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "tainted", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "tainted",
+				},
+			},
+		},
 		"multiple flavors, skip missing ResourceFlavor": {
 			wlPods: []kueue.PodSet{
 				{
@@ -1314,6 +2084,30 @@ func TestEntryAssignFlavors(t *testing.T) {
 			wantFits: false,
 			wantMsg:  "flavor one doesn't match with node affinity",
 		},
+		"multiple flavors, doesn't fit node selector": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: func() corev1.PodSpec {
+						spec := utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+							corev1.ResourceCPU: "1",
+						})
+						spec.NodeSelector = map[string]string{"type": "three", "ignored1": "foo"}
+						return spec
+					}(),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantMsg: "flavor one doesn't match with node affinity; flavor two doesn't match with node affinity",
+		},
 		"multiple specs, fit different flavors": {
 			wlPods: []kueue.PodSet{
 				{
@@ -1445,7 +2239,7 @@ func TestEntryAssignFlavors(t *testing.T) {
 					},
 				},
 			},
-			wantMsg: "insufficient quota for flavor one, 1000 more needed after borrowing",
+			wantMsg: "insufficient quota for cpu in flavor one, 1 more needed after borrowing",
 		},
 		"past max": {
 			wlPods: []kueue.PodSet{
@@ -1479,7 +2273,7 @@ func TestEntryAssignFlavors(t *testing.T) {
 					},
 				},
 			},
-			wantMsg: "borrowing limit for flavor one exceeded",
+			wantMsg: "borrowing limit for cpu in flavor one exceeded",
 		},
 		"resource not listed in clusterQueue": {
 			wlPods: []kueue.PodSet{
@@ -1518,6 +2312,216 @@ func TestEntryAssignFlavors(t *testing.T) {
 			},
 			wantMsg: "flavor nonexistent-flavor not found",
 		},
+		"multiple flavors, pinned to a required flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+					RequiredFlavors: []string{"two"},
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"multiple flavors, required flavor doesn't fit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "2",
+					}),
+					RequiredFlavors: []string{"one"},
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 1000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantMsg: "flavor two is not in the required flavors of the podSet",
+		},
+		"multiple flavors, selected by the flavor labels": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+					FlavorSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"hardware": "gpu"},
+					},
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU: "two",
+				},
+			},
+		},
+		"flavor selector doesn't match any flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1",
+					}),
+					FlavorSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"hardware": "tpu"},
+					},
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+				},
+			},
+			wantMsg: "flavor two doesn't match the flavor selector of the podSet",
+		},
+		"multiple flavors, node pools can't provision the first one": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU:    "3",
+						corev1.ResourceMemory: "1Gi",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 4000},
+					},
+					corev1.ResourceMemory: {
+						{Name: "default", Min: utiltesting.Gi},
+					},
+				},
+			},
+			provisionable: cache.Resources{
+				corev1.ResourceCPU: {
+					"one": 2000,
+					"two": 3000,
+				},
+			},
+			wantFits: true,
+			wantFlavors: map[string]map[corev1.ResourceName]string{
+				"main": {
+					corev1.ResourceCPU:    "two",
+					corev1.ResourceMemory: "default",
+				},
+			},
+		},
+		"node pools can't provision any flavor": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+					},
+				},
+			},
+			provisionable: cache.Resources{
+				corev1.ResourceCPU: {
+					"one": 2000,
+				},
+			},
+			wantMsg:                    "Waiting for provisioning, couldn't assign a flavor for cpu in podSet main: node pools of flavor one can't provision enough cpu",
+			wantWaitingForProvisioning: true,
+		},
+		"node pools can't provision the flavor that fits in the quota": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "main",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "3",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {
+						{Name: "one", Min: 4000},
+						{Name: "two", Min: 2000},
+					},
+				},
+			},
+			provisionable: cache.Resources{
+				corev1.ResourceCPU: {
+					"one": 2000,
+				},
+			},
+			wantMsg:                    "node pools of flavor one can't provision enough cpu; insufficient quota for cpu in flavor two",
+			wantWaitingForProvisioning: true,
+		},
+		"multiple podsets exceed the namespace limit": {
+			wlPods: []kueue.PodSet{
+				{
+					Count: 1,
+					Name:  "driver",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1500m",
+					}),
+				},
+				{
+					Count: 1,
+					Name:  "worker",
+					Spec: utiltesting.PodSpecForRequest(map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "1500m",
+					}),
+				},
+			},
+			clusterQueue: cache.ClusterQueue{
+				RequestableResources: map[corev1.ResourceName][]cache.FlavorLimits{
+					corev1.ResourceCPU: {{Name: "one", Min: 4000}},
+				},
+				MaxNamespaceQuotaPercent: pointer.Int32(50),
+			},
+			wantMsg: "namespace limit for cpu in flavor one exceeded",
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -1532,7 +2536,7 @@ func TestEntryAssignFlavors(t *testing.T) {
 				}),
 			}
 			tc.clusterQueue.UpdateWithFlavors(resourceFlavors)
-			status := e.assignFlavors(log, resourceFlavors, &tc.clusterQueue)
+			status := e.assignFlavors(log, resourceFlavors, tc.provisionable, &tc.clusterQueue)
 			if status.IsSuccess() != tc.wantFits {
 				t.Errorf("e.assignFlavors(_)=%t, want %t", status.IsSuccess(), tc.wantFits)
 			}
@@ -1540,6 +2544,9 @@ func TestEntryAssignFlavors(t *testing.T) {
 				if len(tc.wantMsg) == 0 || !strings.Contains(status.Message(), tc.wantMsg) {
 					t.Errorf("got msg %s, want msg containing %s", status.Message(), tc.wantMsg)
 				}
+				if status.waitingForProvisioning != tc.wantWaitingForProvisioning {
+					t.Errorf("Got waitingForProvisioning %t, want %t", status.waitingForProvisioning, tc.wantWaitingForProvisioning)
+				}
 			}
 			var flavors map[string]map[corev1.ResourceName]string
 			if status.IsSuccess() {
@@ -1558,6 +2565,22 @@ func TestEntryAssignFlavors(t *testing.T) {
 	}
 }
 
+func TestNominateWorkloadResetsShortResources(t *testing.T) {
+	info := workload.NewInfo(utiltesting.MakeWorkload("foo", "").Request(corev1.ResourceCPU, "1").Obj())
+	info.ClusterQueue = "inactive"
+	info.ShortResources = sets.NewString(string(corev1.ResourceCPU))
+	snapshot := cache.Snapshot{InactiveClusterQueueSets: sets.NewString("inactive")}
+	s := &Scheduler{}
+
+	e := s.nominateWorkload(context.Background(), *info, snapshot)
+	if e.status == nominated {
+		t.Fatalf("Workload of an inactive clusterQueue was nominated")
+	}
+	if e.ShortResources != nil {
+		t.Errorf("Got short resources %v from a previous attempt, want none", e.ShortResources.List())
+	}
+}
+
 func TestEntryOrdering(t *testing.T) {
 	now := time.Now()
 	input := []entry{
@@ -1599,13 +2622,42 @@ func TestEntryOrdering(t *testing.T) {
 				corev1.ResourceCPU: {},
 			},
 		},
+		{
+			Info: workload.Info{
+				Obj: &kueue.Workload{ObjectMeta: metav1.ObjectMeta{
+					Name:              "epsilon",
+					CreationTimestamp: metav1.NewTime(now.Add(-time.Second)),
+				}},
+			},
+			position: 1,
+		},
+		{
+			Info: workload.Info{
+				Obj: &kueue.Workload{ObjectMeta: metav1.ObjectMeta{
+					Name:              "zeta",
+					CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Second)),
+				}},
+			},
+			position:        1,
+			behindBorrowing: true,
+		},
+		{
+			Info: workload.Info{
+				Obj: &kueue.Workload{ObjectMeta: metav1.ObjectMeta{
+					Name:              "eta",
+					CreationTimestamp: metav1.NewTime(now.Add(3 * time.Second)),
+				}},
+			},
+			position: 1,
+			score:    1,
+		},
 	}
 	sort.Sort(entryOrdering(input))
 	order := make([]string, len(input))
 	for i, e := range input {
 		order[i] = e.Obj.Name
 	}
-	wantOrder := []string{"beta", "gamma", "alpha", "delta"}
+	wantOrder := []string{"eta", "beta", "gamma", "epsilon", "alpha", "delta", "zeta"}
 	if diff := cmp.Diff(wantOrder, order); diff != "" {
 		t.Errorf("Unexpected order (-want,+got):\n%s", diff)
 	}
@@ -1641,6 +2693,24 @@ func TestRequeueAndUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "workload waiting for provisioning",
+			e: entry{
+				status:                 "",
+				inadmissibleReason:     "node pools can't provision enough cpu",
+				waitingForProvisioning: true,
+			},
+			wantStatus: kueue.WorkloadStatus{
+				Conditions: []kueue.WorkloadCondition{
+					{
+						Type:    kueue.WorkloadAdmitted,
+						Status:  corev1.ConditionFalse,
+						Reason:  "WaitingForProvisioning",
+						Message: "node pools can't provision enough cpu",
+					},
+				},
+			},
+		},
 		{
 			name: "assumed",
 			e: entry{
@@ -22,44 +22,66 @@ import (
 	cert "github.com/open-policy-agent/cert-controller/pkg/rotator"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	config "sigs.k8s.io/kueue/apis/config/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/util/leader"
 )
 
 const (
-	serviceName     = "kueue-webhook-service"
-	secretName      = "kueue-webhook-server-cert"
-	secretNamespace = "kueue-system"
-	certDir         = "/tmp/k8s-webhook-server/serving-certs"
-	vwcName         = "kueue-validating-webhook-configuration"
-	mwcName         = "kueue-mutating-webhook-configuration"
-	caName          = "kueue-ca"
-	caOrganization  = "kueue"
+	vwcName        = "kueue-validating-webhook-configuration"
+	mwcName        = "kueue-mutating-webhook-configuration"
+	caName         = "kueue-ca"
+	caOrganization = "kueue"
 )
 
-// DNSName is <service name>.<namespace>.svc
-var dnsName = fmt.Sprintf("%s.%s.svc", serviceName, secretNamespace)
-
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
 //+kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=mutatingwebhookconfigurations,verbs=get;list;watch;update
 //+kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get;list;watch;update
 
 // ManageCerts creates all certs for webhooks. This function is called from main.go.
-func ManageCerts(mgr ctrl.Manager, setupFinished chan struct{}) error {
-	return cert.AddRotator(mgr, &cert.CertRotator{
+// It expects the internal cert management to be enabled in cfg.
+func ManageCerts(mgr ctrl.Manager, cfg *config.Configuration, setupFinished chan struct{}) error {
+	// DNSName is <service name>.<namespace>.svc
+	dnsName := fmt.Sprintf("%s.%s.svc", *cfg.InternalCertManagement.WebhookServiceName, *cfg.Namespace)
+
+	// The rotator runs in all the replicas, so that the standby ones also get
+	// the certs ready and set up their controllers.
+	return cert.AddRotator(leader.AllReplicas(mgr), &cert.CertRotator{
 		SecretKey: types.NamespacedName{
-			Namespace: secretNamespace,
-			Name:      secretName,
+			Namespace: *cfg.Namespace,
+			Name:      *cfg.InternalCertManagement.WebhookSecretName,
 		},
-		CertDir:        certDir,
+		CertDir:        cfg.Webhook.CertDir,
 		CAName:         caName,
 		CAOrganization: caOrganization,
 		DNSName:        dnsName,
 		IsReady:        setupFinished,
-		Webhooks: []cert.WebhookInfo{{
+		Webhooks: append([]cert.WebhookInfo{{
 			Type: cert.Validating,
 			Name: vwcName,
 		}, {
 			Type: cert.Mutating,
 			Name: mwcName,
-		}},
+		}}, conversionWebhooks()...),
 	})
 }
+
+// conversionWebhooks returns the CRDs served by the conversion webhook, so
+// that the CA is injected in them.
+func conversionWebhooks() []cert.WebhookInfo {
+	crds := []string{
+		"clusterqueues.kueue.x-k8s.io",
+		"queues.kueue.x-k8s.io",
+		"resourceflavors.kueue.x-k8s.io",
+		"workloads.kueue.x-k8s.io",
+	}
+	webhooks := make([]cert.WebhookInfo, len(crds))
+	for i, crd := range crds {
+		webhooks[i] = cert.WebhookInfo{
+			Type: cert.CRDConversion,
+			Name: crd,
+		}
+	}
+	return webhooks
+}
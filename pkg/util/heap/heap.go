@@ -20,6 +20,7 @@ package heap
 
 import (
 	"container/heap"
+	"sort"
 )
 
 // lessFunc is a function that receives two items and returns true if the first
@@ -149,6 +150,12 @@ func (h *Heap) Pop() interface{} {
 	return heap.Pop(&h.data)
 }
 
+// Reorder restores the heap invariant after the order of the items changed
+// without them being updated, for example, because it depends on the time.
+func (h *Heap) Reorder() {
+	heap.Init(&h.data)
+}
+
 // Get returns the requested item, exists, error.
 func (h *Heap) Get(obj interface{}) (item interface{}) {
 	key := h.data.keyFunc(obj)
@@ -178,6 +185,16 @@ func (h *Heap) List() []interface{} {
 	return list
 }
 
+// Sorted returns a list of all the items, in the order in which they would
+// be popped.
+func (h *Heap) Sorted() []interface{} {
+	list := h.List()
+	sort.Slice(list, func(i, j int) bool {
+		return h.data.lessFunc(list[i], list[j])
+	})
+	return list
+}
+
 // New returns a Heap which can be used to queue up items to process.
 func New(keyFn keyFunc, lessFn lessFunc) Heap {
 	return Heap{
@@ -283,3 +283,64 @@ func TestHeap_List(t *testing.T) {
 		}
 	}
 }
+
+func TestHeap_Sorted(t *testing.T) {
+	h := New(testHeapObjectKeyFunc, compareInts)
+	for k, v := range map[string]int{
+		"foo": 10,
+		"bar": 1,
+		"bal": 30,
+		"baz": 11,
+	} {
+		h.PushOrUpdate(mkHeapObj(k, v))
+	}
+	var got []string
+	for _, obj := range h.Sorted() {
+		got = append(got, obj.(testHeapObject).name)
+	}
+	want := []string{"bar", "foo", "baz", "bal"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if h.Len() != len(want) {
+		t.Errorf("expected the heap to keep %d items, got %d", len(want), h.Len())
+	}
+}
+
+func TestHeap_Reorder(t *testing.T) {
+	descending := false
+	h := New(testHeapObjectKeyFunc, func(a, b interface{}) bool {
+		if descending {
+			return compareInts(b, a)
+		}
+		return compareInts(a, b)
+	})
+	for k, v := range map[string]int{
+		"foo": 10,
+		"bar": 1,
+		"bal": 30,
+		"baz": 11,
+	} {
+		h.PushOrUpdate(mkHeapObj(k, v))
+	}
+	descending = true
+	h.Reorder()
+	var got []string
+	for h.Len() > 0 {
+		got = append(got, h.Pop().(testHeapObject).name)
+	}
+	want := []string{"bal", "baz", "foo", "bar"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
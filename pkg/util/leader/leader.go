@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leader
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// AllReplicas returns a manager that runs the runnables added to it in all the
+// replicas, regardless of whether they were elected as leaders.
+//
+// This allows the controllers built with it to keep their caches warm in the
+// standby replicas, so that they can take over quickly. The reconcilers of
+// such controllers must not write to the API server unless they are the
+// leader, see AwareReconciler.
+func AllReplicas(mgr manager.Manager) manager.Manager {
+	return &allReplicasManager{Manager: mgr}
+}
+
+type allReplicasManager struct {
+	manager.Manager
+}
+
+func (m *allReplicasManager) Add(r manager.Runnable) error {
+	return m.Manager.Add(&nonLeaderElectionRunnable{Runnable: r})
+}
+
+type nonLeaderElectionRunnable struct {
+	manager.Runnable
+}
+
+var _ manager.LeaderElectionRunnable = &nonLeaderElectionRunnable{}
+var _ inject.Injector = &nonLeaderElectionRunnable{}
+
+func (r *nonLeaderElectionRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// InjectFunc sets the manager dependencies on the wrapped runnable, as the
+// manager can't see through the wrapper.
+func (r *nonLeaderElectionRunnable) InjectFunc(f inject.Func) error {
+	return f(r.Runnable)
+}
+
+type awareReconciler struct {
+	elected  <-chan struct{}
+	delegate reconcile.Reconciler
+}
+
+// AwareReconciler returns a reconciler that only passes the requests to r
+// after the elected channel is closed, that is, once the replica becomes the
+// leader. Until then, the requests are dropped. Use ElectionSource to
+// reconcile all the objects once the replica becomes the leader.
+func AwareReconciler(elected <-chan struct{}, r reconcile.Reconciler) reconcile.Reconciler {
+	return &awareReconciler{
+		elected:  elected,
+		delegate: r,
+	}
+}
+
+func (r *awareReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	select {
+	case <-r.elected:
+		return r.delegate.Reconcile(ctx, req)
+	default:
+		ctrl.LoggerFrom(ctx).V(5).Info("Not the leader, dropping the request")
+		return ctrl.Result{}, nil
+	}
+}
+
+// ElectionSource returns a source that, once the elected channel is closed,
+// lists the objects of the type of list and sends a generic event for each of
+// them. This catches up with the requests that AwareReconciler dropped while
+// the replica wasn't the leader. The predicates are not applied, so that all
+// the objects are reconciled.
+func ElectionSource(elected <-chan struct{}, reader client.Reader, list client.ObjectList) source.Source {
+	return &electionSource{
+		elected: elected,
+		reader:  reader,
+		list:    list,
+	}
+}
+
+type electionSource struct {
+	elected <-chan struct{}
+	reader  client.Reader
+	list    client.ObjectList
+}
+
+var _ source.Source = &electionSource{}
+
+func (s *electionSource) Start(ctx context.Context, h handler.EventHandler, q workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.elected:
+		}
+		list := s.list.DeepCopyObject().(client.ObjectList)
+		if err := s.reader.List(ctx, list); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "Listing the objects to reconcile after the election")
+			return
+		}
+		_ = meta.EachListItem(list, func(o runtime.Object) error {
+			if obj, ok := o.(client.Object); ok {
+				h.Generic(event.GenericEvent{Object: obj}, q)
+			}
+			return nil
+		})
+	}()
+	return nil
+}
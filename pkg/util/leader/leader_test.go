@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAwareReconciler(t *testing.T) {
+	cases := map[string]struct {
+		elected        bool
+		wantResult     ctrl.Result
+		wantReconciled bool
+	}{
+		"leader": {
+			elected:        true,
+			wantReconciled: true,
+		},
+		"not leader": {},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			elected := make(chan struct{})
+			if tc.elected {
+				close(elected)
+			}
+			reconciled := false
+			r := AwareReconciler(elected, reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+				reconciled = true
+				return ctrl.Result{}, nil
+			}))
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "obj"}}
+			got, err := r.Reconcile(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantResult, got); diff != "" {
+				t.Errorf("Unexpected result (-want,+got):\n%s", diff)
+			}
+			if reconciled != tc.wantReconciled {
+				t.Errorf("Request reconciled: %t, want %t", reconciled, tc.wantReconciled)
+			}
+		})
+	}
+}
+
+func TestElectionSource(t *testing.T) {
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	).Build()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	elected := make(chan struct{})
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	src := ElectionSource(elected, cl, &corev1.NamespaceList{})
+	if err := src.Start(ctx, &handler.EnqueueRequestForObject{}, q); err != nil {
+		t.Fatalf("Failed starting the source: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if q.Len() != 0 {
+		t.Errorf("Got %d requests before the election, want none", q.Len())
+	}
+
+	close(elected)
+	if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return q.Len() == 2, nil
+	}); err != nil {
+		t.Fatalf("Got %d requests after the election, want 2", q.Len())
+	}
+	got := sets.NewString()
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		got.Insert(item.(reconcile.Request).Name)
+		q.Done(item)
+	}
+	if diff := cmp.Diff(sets.NewString("a", "b"), got); diff != "" {
+		t.Errorf("Unexpected requests (-want,+got):\n%s", diff)
+	}
+}
@@ -18,6 +18,8 @@ package priority
 
 import (
 	"context"
+	"math"
+	"time"
 
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,6 +40,46 @@ func Priority(w *kueue.Workload) int32 {
 	return constants.DefaultPriority
 }
 
+// Aged returns the priority of the given workload, boosted by the aging
+// policy for the full minutes elapsed since the workload was created. If the
+// policy is nil, it returns the priority of the workload.
+func Aged(w *kueue.Workload, aging *kueue.PriorityAging, now time.Time) int32 {
+	p := Priority(w)
+	if aging == nil {
+		return p
+	}
+	boost := int64(now.Sub(w.CreationTimestamp.Time)/time.Minute) * int64(aging.Rate)
+	if boost > int64(aging.MaxBoost) {
+		boost = int64(aging.MaxBoost)
+	}
+	if boost < 0 {
+		boost = 0
+	}
+	aged := int64(p) + boost
+	if aged > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(aged)
+}
+
+// NextBoost returns the first time after now at which the aging policy boosts
+// the priority of the given workload further. Returns false if the boost
+// already reached its maximum or the policy never boosts it.
+func NextBoost(w *kueue.Workload, aging *kueue.PriorityAging, now time.Time) (time.Time, bool) {
+	if aging == nil || aging.Rate <= 0 || aging.MaxBoost <= 0 {
+		return time.Time{}, false
+	}
+	created := w.CreationTimestamp.Time
+	if now.Before(created) {
+		return created.Add(time.Minute), true
+	}
+	minutes := int64(now.Sub(created) / time.Minute)
+	if minutes*int64(aging.Rate) >= int64(aging.MaxBoost) {
+		return time.Time{}, false
+	}
+	return created.Add(time.Duration(minutes+1) * time.Minute), true
+}
+
 // GetPriorityFromPriorityClass returns the priority populated from
 // priority class. If not specified, priority will be default or
 // zero if there is no default.
@@ -18,7 +18,9 @@ package priority
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	schedulingv1 "k8s.io/api/scheduling/v1"
@@ -59,6 +61,118 @@ func TestPriority(t *testing.T) {
 	}
 }
 
+func TestAged(t *testing.T) {
+	now := time.Now()
+	aging := &kueue.PriorityAging{Rate: 10, MaxBoost: 100}
+	tests := map[string]struct {
+		priority int32
+		waited   time.Duration
+		aging    *kueue.PriorityAging
+		want     int32
+	}{
+		"no aging policy": {
+			priority: 5,
+			waited:   time.Hour,
+			want:     5,
+		},
+		"less than a minute": {
+			priority: 5,
+			waited:   59 * time.Second,
+			aging:    aging,
+			want:     5,
+		},
+		"boosted for the full minutes": {
+			priority: 5,
+			waited:   3*time.Minute + 30*time.Second,
+			aging:    aging,
+			want:     35,
+		},
+		"boost capped": {
+			priority: 5,
+			waited:   time.Hour,
+			aging:    aging,
+			want:     105,
+		},
+		"created in the future": {
+			priority: 5,
+			waited:   -time.Hour,
+			aging:    aging,
+			want:     5,
+		},
+		"no overflow": {
+			priority: math.MaxInt32 - 10,
+			waited:   time.Hour,
+			aging:    aging,
+			want:     math.MaxInt32,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			wl := utiltesting.MakeWorkload("name", "ns").
+				Priority(pointer.Int32(tt.priority)).
+				Creation(now.Add(-tt.waited)).
+				Obj()
+			got := Aged(wl, tt.aging, now)
+			if got != tt.want {
+				t.Errorf("Aged priority does not match: got: %d, expected: %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextBoost(t *testing.T) {
+	now := time.Now()
+	aging := &kueue.PriorityAging{Rate: 10, MaxBoost: 100}
+	tests := map[string]struct {
+		waited time.Duration
+		aging  *kueue.PriorityAging
+		want   time.Duration
+		wantOk bool
+	}{
+		"no aging policy": {
+			waited: time.Minute,
+		},
+		"less than a minute": {
+			waited: 20 * time.Second,
+			aging:  aging,
+			want:   40 * time.Second,
+			wantOk: true,
+		},
+		"after the full minutes": {
+			waited: 3*time.Minute + 30*time.Second,
+			aging:  aging,
+			want:   30 * time.Second,
+			wantOk: true,
+		},
+		"boost capped": {
+			waited: 10 * time.Minute,
+			aging:  aging,
+		},
+		"created in the future": {
+			waited: -time.Hour,
+			aging:  aging,
+			want:   time.Hour + time.Minute,
+			wantOk: true,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			wl := utiltesting.MakeWorkload("name", "ns").
+				Creation(now.Add(-tt.waited)).
+				Obj()
+			got, ok := NextBoost(wl, tt.aging, now)
+			if ok != tt.wantOk {
+				t.Fatalf("NextBoost returned ok: %t, expected: %t", ok, tt.wantOk)
+			}
+			if ok && !got.Equal(now.Add(tt.want)) {
+				t.Errorf("Next boost does not match: got: %v, expected: %v", got, now.Add(tt.want))
+			}
+		})
+	}
+}
+
 func TestGetPriorityFromPriorityClass(t *testing.T) {
 	scheme := runtime.NewScheme()
 	if err := schedulingv1.AddToScheme(scheme); err != nil {
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+)
+
+func TestNew(t *testing.T) {
+	if rl := New(nil); rl != nil {
+		t.Errorf("Got rate limiter %v without configuration, want nil", rl)
+	}
+
+	rl := New(&configapi.ControllerRateLimiter{
+		BaseDelay: &metav1.Duration{Duration: time.Second},
+		MaxDelay:  &metav1.Duration{Duration: 3 * time.Second},
+		QPS:       pointer.Float32(1000),
+		Burst:     pointer.Int32(100),
+	})
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		got = append(got, rl.When("a"))
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected delays of the retries (-want,+got):\n%s", diff)
+	}
+	rl.Forget("a")
+	if got := rl.When("a"); got != time.Second {
+		t.Errorf("Got delay %v after forgetting the item, want %v", got, time.Second)
+	}
+
+	// The overall rate delays the retries of new items once the burst is
+	// exhausted.
+	rl = New(&configapi.ControllerRateLimiter{
+		BaseDelay: &metav1.Duration{Duration: time.Millisecond},
+		MaxDelay:  &metav1.Duration{Duration: time.Second},
+		QPS:       pointer.Float32(0.1),
+		Burst:     pointer.Int32(1),
+	})
+	if got := rl.When("a"); got != time.Millisecond {
+		t.Errorf("Got delay %v for the first retry, want %v", got, time.Millisecond)
+	}
+	if got := rl.When("b"); got < 9*time.Second {
+		t.Errorf("Got delay %v after exhausting the burst, want about 10s", got)
+	}
+}
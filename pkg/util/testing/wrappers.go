@@ -25,6 +25,8 @@ import (
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/constants"
@@ -80,6 +82,12 @@ func (j *JobWrapper) Parallelism(p int32) *JobWrapper {
 	return j
 }
 
+// Completions updates job completions.
+func (j *JobWrapper) Completions(c int32) *JobWrapper {
+	j.Spec.Completions = pointer.Int32(c)
+	return j
+}
+
 // PriorityClass updates job priorityclass.
 func (j *JobWrapper) PriorityClass(pc string) *JobWrapper {
 	j.Spec.Template.Spec.PriorityClassName = pc
@@ -92,6 +100,19 @@ func (j *JobWrapper) Queue(queue string) *JobWrapper {
 	return j
 }
 
+// RequiredFlavors sets the flavors that can be assigned to the job.
+func (j *JobWrapper) RequiredFlavors(flavors string) *JobWrapper {
+	j.Annotations[constants.RequiredFlavorsAnnotation] = flavors
+	return j
+}
+
+// FlavorSelector sets the selector of the flavors that can be assigned to
+// the job.
+func (j *JobWrapper) FlavorSelector(selector string) *JobWrapper {
+	j.Annotations[constants.FlavorSelectorAnnotation] = selector
+	return j
+}
+
 // Toleration adds a toleration to the job.
 func (j *JobWrapper) Toleration(t corev1.Toleration) *JobWrapper {
 	j.Spec.Template.Spec.Tolerations = append(j.Spec.Template.Spec.Tolerations, t)
@@ -172,6 +193,36 @@ func (w *WorkloadWrapper) Request(r corev1.ResourceName, q string) *WorkloadWrap
 	return w
 }
 
+// Limit sets the limit of the resource on the first container of the first
+// podset.
+func (w *WorkloadWrapper) Limit(r corev1.ResourceName, q string) *WorkloadWrapper {
+	res := &w.Spec.PodSets[0].Spec.Containers[0].Resources
+	if res.Limits == nil {
+		res.Limits = make(corev1.ResourceList)
+	}
+	res.Limits[r] = resource.MustParse(q)
+	return w
+}
+
+// PodCount sets the count of the first podset.
+func (w *WorkloadWrapper) PodCount(n int32) *WorkloadWrapper {
+	w.Spec.PodSets[0].Count = n
+	return w
+}
+
+// RequiredFlavors sets the flavors that can be assigned to the first podset.
+func (w *WorkloadWrapper) RequiredFlavors(flavors ...string) *WorkloadWrapper {
+	w.Spec.PodSets[0].RequiredFlavors = flavors
+	return w
+}
+
+// FlavorSelector sets the selector of the flavors that can be assigned to
+// the first podset.
+func (w *WorkloadWrapper) FlavorSelector(s *metav1.LabelSelector) *WorkloadWrapper {
+	w.Spec.PodSets[0].FlavorSelector = s
+	return w
+}
+
 func (w *WorkloadWrapper) Queue(q string) *WorkloadWrapper {
 	w.Spec.QueueName = q
 	return w
@@ -214,6 +265,31 @@ func (w *WorkloadWrapper) Toleration(t corev1.Toleration) *WorkloadWrapper {
 	return w
 }
 
+// ControllerOwner sets the controller owner reference of the workload.
+func (w *WorkloadWrapper) ControllerOwner(gvk schema.GroupVersionKind, name string, uid types.UID) *WorkloadWrapper {
+	w.OwnerReferences = append(w.OwnerReferences, *metav1.NewControllerRef(&metav1.ObjectMeta{Name: name, UID: uid}, gvk))
+	return w
+}
+
+// Active sets whether the workload can be admitted.
+func (w *WorkloadWrapper) Active(a bool) *WorkloadWrapper {
+	w.Spec.Active = &a
+	return w
+}
+
+// Condition sets a condition of the workload, replacing the existing one of
+// the same type.
+func (w *WorkloadWrapper) Condition(c kueue.WorkloadCondition) *WorkloadWrapper {
+	for i := range w.Status.Conditions {
+		if w.Status.Conditions[i].Type == c.Type {
+			w.Status.Conditions[i] = c
+			return w
+		}
+	}
+	w.Status.Conditions = append(w.Status.Conditions, c)
+	return w
+}
+
 // AdmissionWrapper wraps an Admission
 type AdmissionWrapper struct{ kueue.Admission }
 
@@ -238,6 +314,12 @@ func (w *AdmissionWrapper) Flavor(r corev1.ResourceName, f string) *AdmissionWra
 	return w
 }
 
+// Count sets the admitted count of the podSet, for a partial admission.
+func (w *AdmissionWrapper) Count(c int32) *AdmissionWrapper {
+	w.PodSetFlavors[0].Count = &c
+	return w
+}
+
 // QueueWrapper wraps a Queue.
 type QueueWrapper struct{ kueue.Queue }
 
@@ -262,6 +344,12 @@ func (q *QueueWrapper) ClusterQueue(c string) *QueueWrapper {
 	return q
 }
 
+// StopPolicy sets the stop policy of the queue.
+func (q *QueueWrapper) StopPolicy(p kueue.StopPolicy) *QueueWrapper {
+	q.Spec.StopPolicy = &p
+	return q
+}
+
 // ClusterQueueWrapper wraps a ClusterQueue.
 type ClusterQueueWrapper struct{ kueue.ClusterQueue }
 
@@ -308,6 +396,40 @@ func (c *ClusterQueueWrapper) NamespaceSelector(s *metav1.LabelSelector) *Cluste
 	return c
 }
 
+// StopPolicy sets the stop policy.
+func (c *ClusterQueueWrapper) StopPolicy(p kueue.StopPolicy) *ClusterQueueWrapper {
+	c.Spec.StopPolicy = &p
+	return c
+}
+
+// AdmissionLimits sets the maximum number of admitted workloads and pods.
+func (c *ClusterQueueWrapper) AdmissionLimits(maxWorkloads, maxPods *int32) *ClusterQueueWrapper {
+	c.Spec.AdmissionLimits = &kueue.AdmissionLimits{
+		MaxWorkloads: maxWorkloads,
+		MaxPods:      maxPods,
+	}
+	return c
+}
+
+// MaxNamespaceQuotaPercent sets the percentage of the quota that each
+// namespace can use.
+func (c *ClusterQueueWrapper) MaxNamespaceQuotaPercent(p int32) *ClusterQueueWrapper {
+	if c.Spec.AdmissionLimits == nil {
+		c.Spec.AdmissionLimits = &kueue.AdmissionLimits{}
+	}
+	c.Spec.AdmissionLimits.MaxNamespaceQuotaPercent = &p
+	return c
+}
+
+// PriorityAging sets the priority aging policy.
+func (c *ClusterQueueWrapper) PriorityAging(rate, maxBoost int32) *ClusterQueueWrapper {
+	c.Spec.PriorityAging = &kueue.PriorityAging{
+		Rate:     rate,
+		MaxBoost: maxBoost,
+	}
+	return c
+}
+
 // ResourceWrapper wraps a resource.
 type ResourceWrapper struct{ kueue.Resource }
 
@@ -383,6 +505,12 @@ func (rf *ResourceFlavorWrapper) Taint(t corev1.Taint) *ResourceFlavorWrapper {
 	return rf
 }
 
+// Toleration adds a toleration to the ResourceFlavor.
+func (rf *ResourceFlavorWrapper) Toleration(t corev1.Toleration) *ResourceFlavorWrapper {
+	rf.Tolerations = append(rf.Tolerations, t)
+	return rf
+}
+
 // RuntimeClassWrapper wraps a RuntimeClass.
 type RuntimeClassWrapper struct{ nodev1.RuntimeClass }
 
@@ -408,3 +536,63 @@ func (rc *RuntimeClassWrapper) PodOverhead(resources corev1.ResourceList) *Runti
 func (rc *RuntimeClassWrapper) Obj() *nodev1.RuntimeClass {
 	return &rc.RuntimeClass
 }
+
+// LimitRangeWrapper wraps a LimitRange with a single item.
+type LimitRangeWrapper struct{ corev1.LimitRange }
+
+// MakeLimitRange creates a wrapper for a LimitRange with a Container item.
+func MakeLimitRange(name, ns string) *LimitRangeWrapper {
+	return &LimitRangeWrapper{corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:                 corev1.LimitTypeContainer,
+					Max:                  corev1.ResourceList{},
+					Min:                  corev1.ResourceList{},
+					Default:              corev1.ResourceList{},
+					DefaultRequest:       corev1.ResourceList{},
+					MaxLimitRequestRatio: corev1.ResourceList{},
+				},
+			},
+		},
+	}}
+}
+
+// WithType sets the type of the item.
+func (lr *LimitRangeWrapper) WithType(t corev1.LimitType) *LimitRangeWrapper {
+	lr.Spec.Limits[0].Type = t
+	return lr
+}
+
+// Max sets the maximum of the resource.
+func (lr *LimitRangeWrapper) Max(r corev1.ResourceName, q string) *LimitRangeWrapper {
+	lr.Spec.Limits[0].Max[r] = resource.MustParse(q)
+	return lr
+}
+
+// Min sets the minimum of the resource.
+func (lr *LimitRangeWrapper) Min(r corev1.ResourceName, q string) *LimitRangeWrapper {
+	lr.Spec.Limits[0].Min[r] = resource.MustParse(q)
+	return lr
+}
+
+// Default sets the default limit of the resource.
+func (lr *LimitRangeWrapper) Default(r corev1.ResourceName, q string) *LimitRangeWrapper {
+	lr.Spec.Limits[0].Default[r] = resource.MustParse(q)
+	return lr
+}
+
+// DefaultRequest sets the default request of the resource.
+func (lr *LimitRangeWrapper) DefaultRequest(r corev1.ResourceName, q string) *LimitRangeWrapper {
+	lr.Spec.Limits[0].DefaultRequest[r] = resource.MustParse(q)
+	return lr
+}
+
+// Obj returns the inner LimitRange.
+func (lr *LimitRangeWrapper) Obj() *corev1.LimitRange {
+	return &lr.LimitRange
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
+)
+
+const (
+	tracerName  = "sigs.k8s.io/kueue"
+	serviceName = "kueue"
+
+	// annotationPrefix is the prefix of the annotations that hold the trace
+	// context of an object, like kueue.x-k8s.io/traceparent.
+	annotationPrefix = "kueue.x-k8s.io/"
+
+	// shutdownTimeout is how long the pending spans are exported for after
+	// the manager stops.
+	shutdownTimeout = 10 * time.Second
+)
+
+// Tracer returns the tracer of the Kueue components. Its spans are not
+// recorded until a Provider is set up.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// End records the error, if any, in the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject stores the trace context of the span in the context as annotations
+// of the object, so that the components that process the object later can
+// add their spans to the same trace. Nothing is stored if the tracing is
+// disabled.
+func Inject(ctx context.Context, obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, annotationCarrier(annotations))
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// Extract returns a copy of the context with the trace context stored in the
+// annotations of the object, if any. The spans started from the returned
+// context belong to the trace of the object.
+func Extract(ctx context.Context, obj metav1.Object) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, annotationCarrier(obj.GetAnnotations()))
+}
+
+// annotationCarrier stores the fields of a propagator as annotations.
+type annotationCarrier map[string]string
+
+var _ propagation.TextMapCarrier = annotationCarrier(nil)
+
+func (c annotationCarrier) Get(key string) string {
+	return c[annotationPrefix+key]
+}
+
+func (c annotationCarrier) Set(key, value string) {
+	c[annotationPrefix+key] = value
+}
+
+func (c annotationCarrier) Keys() []string {
+	var keys []string
+	for k := range c {
+		if strings.HasPrefix(k, annotationPrefix) {
+			keys = append(keys, strings.TrimPrefix(k, annotationPrefix))
+		}
+	}
+	return keys
+}
+
+// Provider exports the spans of the Kueue components. It flushes the
+// pending spans when the manager stops.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	out      io.Closer
+}
+
+var _ manager.Runnable = &Provider{}
+var _ manager.LeaderElectionRunnable = &Provider{}
+
+// NewProvider returns a Provider that exports the spans as configured and
+// sets it as the global provider, along with the W3C trace context
+// propagator.
+func NewProvider(cfg *configapi.Tracing) (*Provider, error) {
+	if cfg.Exporter != configapi.StdoutTracingExporter {
+		return nil, fmt.Errorf("unsupported exporter %q", cfg.Exporter)
+	}
+	p := &Provider{}
+	var w io.Writer = os.Stdout
+	if cfg.File != "" {
+		f, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("opening the traces file: %w", err)
+		}
+		w = f
+		p.out = f
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+	p.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler(cfg.SamplingRatePerMillion)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(p.provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return p, nil
+}
+
+// sampler samples the new traces at the given rate, and the rest of the
+// spans following their parent.
+func sampler(ratePerMillion *int32) sdktrace.Sampler {
+	if ratePerMillion == nil {
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(*ratePerMillion) / 1000000))
+}
+
+// Start waits until the context is done. Then, it exports the pending spans
+// for up to shutdownTimeout.
+func (p *Provider) Start(ctx context.Context) error {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := p.provider.Shutdown(shutdownCtx); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Exporting the pending spans")
+	}
+	if p.out != nil {
+		return p.out.Close()
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false, so that the spans of all the replicas
+// are exported.
+func (p *Provider) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(tracerName)
+	ctx, span := tracer.Start(context.Background(), "CreateWorkload")
+	span.End()
+
+	// Tracing disabled.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	wl := utiltesting.MakeWorkload("a", "default").Obj()
+	Inject(ctx, wl)
+	if len(wl.Annotations) != 0 {
+		t.Errorf("Got annotations %v with tracing disabled, want none", wl.Annotations)
+	}
+
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	Inject(ctx, wl)
+	if _, ok := wl.Annotations["kueue.x-k8s.io/traceparent"]; !ok {
+		t.Fatalf("Got annotations %v, want kueue.x-k8s.io/traceparent", wl.Annotations)
+	}
+
+	_, child := tracer.Start(Extract(context.Background(), wl), "Admit")
+	End(child, errors.New("conflict"))
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Got %d spans, want 2", len(spans))
+	}
+	parent, got := spans[0], spans[1]
+	if got.Parent().SpanID() != parent.SpanContext().SpanID() || got.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Span %s doesn't belong to the trace of the workload", got.Name())
+	}
+	if got.Status().Code != codes.Error || got.Status().Description != "conflict" {
+		t.Errorf("Got status %v, want the error", got.Status())
+	}
+}
+
+func TestExtractWithoutTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(tracerName)
+	ctx, span := tracer.Start(context.Background(), "SchedulingCycle")
+	defer span.End()
+
+	// The spans of the objects without trace context keep the parent in the
+	// context.
+	_, child := tracer.Start(Extract(ctx, utiltesting.MakeWorkload("a", "default").Obj()), "Admit")
+	child.End()
+	if got := recorder.Ended()[0]; got.Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("Span %s has parent %s, want %s", got.Name(), got.Parent().SpanID(), span.SpanContext().SpanID())
+	}
+}
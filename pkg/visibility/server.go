@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package visibility
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/scheduler"
+)
+
+const (
+	shutdownTimeout = 30 * time.Second
+	// maxRequestBytes limits the size of the workloads sent for evaluation.
+	maxRequestBytes = 3 << 20
+)
+
+// DryRunner evaluates the admission of workloads without admitting them.
+type DryRunner interface {
+	DryRun(ctx context.Context, wl *kueue.Workload) scheduler.DryRunResult
+}
+
+// Server serves read-only endpoints that help understand the admission
+// decisions:
+// - POST /dry-run takes a Workload and returns whether it would fit, as a
+// scheduler.DryRunResult.
+// - GET /snapshot returns the quota usage of the ClusterQueues and cohorts,
+// and the pending workloads with the reasons why they were not admitted, as
+// a SnapshotDump.
+type Server struct {
+	bindAddress string
+	dryRunner   DryRunner
+	cache       *cache.Cache
+	queues      *queue.Manager
+}
+
+var _ manager.Runnable = &Server{}
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// NewServer returns a Server listening in the given address.
+func NewServer(bindAddress string, dryRunner DryRunner, cCache *cache.Cache, queues *queue.Manager) *Server {
+	return &Server{
+		bindAddress: bindAddress,
+		dryRunner:   dryRunner,
+		cache:       cCache,
+		queues:      queues,
+	}
+}
+
+// Start serves the visibility endpoints until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("visibility")
+	srv := &http.Server{
+		Handler:           s.handler(log),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ln, err := net.Listen("tcp", s.bindAddress)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "Shutting down the visibility server")
+		}
+	}()
+	log.Info("Serving visibility endpoints", "address", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns true, since only the leader knows about the
+// workloads that the scheduler is admitting.
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+func (s *Server) handler(log logr.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dry-run", func(w http.ResponseWriter, r *http.Request) {
+		s.serveDryRun(log, w, r)
+	})
+	mux.HandleFunc("/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		s.serveSnapshot(log, w, r)
+	})
+	return mux
+}
+
+func (s *Server) serveDryRun(log logr.Logger, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	var wl kueue.Workload
+	if err := json.Unmarshal(body, &wl); err != nil {
+		http.Error(w, fmt.Sprintf("decoding the workload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if wl.Namespace == "" {
+		http.Error(w, "the workload must have a namespace", http.StatusBadRequest)
+		return
+	}
+	wl.Default()
+	if errs := kueue.ValidateWorkload(&wl); len(errs) > 0 {
+		http.Error(w, fmt.Sprintf("invalid workload: %v", errs.ToAggregate()), http.StatusBadRequest)
+		return
+	}
+	writeJSON(log, w, s.dryRunner.DryRun(r.Context(), &wl))
+}
+
+func (s *Server) serveSnapshot(log logr.Logger, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(log, w, newSnapshotDump(time.Now(), s.cache.Snapshot(), s.queues.PendingWorkloadsInfo()))
+}
+
+func writeJSON(log logr.Logger, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error(err, "Writing the response")
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package visibility
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/scheduler"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+// fakeDryRunner reports that the workloads fit in the ClusterQueue named
+// after their queue.
+type fakeDryRunner struct{}
+
+func (fakeDryRunner) DryRun(_ context.Context, wl *kueue.Workload) scheduler.DryRunResult {
+	return scheduler.DryRunResult{
+		ClusterQueue: wl.Spec.QueueName,
+		Fits:         true,
+		Admission:    utiltesting.MakeAdmission(wl.Spec.QueueName).Flavor(corev1.ResourceCPU, wl.Spec.PodSets[0].Name).Obj(),
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	wl := utiltesting.MakeWorkload("wl", "default").Queue("main").Request(corev1.ResourceCPU, "1").Obj()
+	wl.Spec.PodSets[0].Name = ""
+	validBody, err := json.Marshal(wl)
+	if err != nil {
+		t.Fatalf("Encoding workload: %v", err)
+	}
+	noNamespace := wl.DeepCopy()
+	noNamespace.Namespace = ""
+	noNamespaceBody, err := json.Marshal(noNamespace)
+	if err != nil {
+		t.Fatalf("Encoding workload: %v", err)
+	}
+	cases := map[string]struct {
+		method     string
+		body       string
+		wantStatus int
+		wantResult *scheduler.DryRunResult
+	}{
+		"valid workload, defaulted": {
+			method:     http.MethodPost,
+			body:       string(validBody),
+			wantStatus: http.StatusOK,
+			wantResult: &scheduler.DryRunResult{
+				ClusterQueue: "main",
+				Fits:         true,
+				Admission:    utiltesting.MakeAdmission("main").Flavor(corev1.ResourceCPU, kueue.DefaultPodSetName).Obj(),
+			},
+		},
+		"wrong method": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		"malformed body": {
+			method:     http.MethodPost,
+			body:       "{",
+			wantStatus: http.StatusBadRequest,
+		},
+		"no namespace": {
+			method:     http.MethodPost,
+			body:       string(noNamespaceBody),
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid workload": {
+			method:     http.MethodPost,
+			body:       `{"metadata":{"name":"wl","namespace":"default"},"spec":{"queueName":"main"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(NewServer("", fakeDryRunner{}, nil, nil).handler(logr.Discard()))
+			defer srv.Close()
+			req, err := http.NewRequest(tc.method, srv.URL+"/dry-run", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("Creating request: %v", err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("Sending request: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("Got status %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantResult == nil {
+				return
+			}
+			var got scheduler.DryRunResult
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decoding response: %v", err)
+			}
+			if diff := cmp.Diff(*tc.wantResult, got); diff != "" {
+				t.Errorf("Unexpected result (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	ctx := context.Background()
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cCache := cache.New(cl)
+	queues := queue.NewManager(cl, cCache)
+	cq := utiltesting.MakeClusterQueue("cq").Obj()
+	if err := cCache.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue to the cache: %v", err)
+	}
+	if err := queues.AddClusterQueue(ctx, cq); err != nil {
+		t.Fatalf("Failed adding ClusterQueue to the queues: %v", err)
+	}
+
+	cases := map[string]struct {
+		method     string
+		wantStatus int
+		wantCQs    []string
+	}{
+		"get": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantCQs:    []string{"cq"},
+		},
+		"wrong method": {
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(NewServer("", fakeDryRunner{}, cCache, queues).handler(logr.Discard()))
+			defer srv.Close()
+			req, err := http.NewRequest(tc.method, srv.URL+"/snapshot", nil)
+			if err != nil {
+				t.Fatalf("Creating request: %v", err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("Sending request: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("Got status %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantCQs == nil {
+				return
+			}
+			var got SnapshotDump
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decoding response: %v", err)
+			}
+			var gotCQs []string
+			for _, cqDump := range got.ClusterQueues {
+				gotCQs = append(gotCQs, cqDump.Name)
+			}
+			if diff := cmp.Diff(tc.wantCQs, gotCQs); diff != "" {
+				t.Errorf("Unexpected ClusterQueues (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
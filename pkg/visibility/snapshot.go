@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package visibility
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+// SnapshotDump is a point-in-time view of the quota usage and the pending
+// workloads, to diagnose why workloads are not admitted.
+type SnapshotDump struct {
+	// Time is when the dump was taken.
+	Time time.Time `json:"time"`
+	// ClusterQueues are sorted by name.
+	ClusterQueues []ClusterQueueDump `json:"clusterQueues"`
+	// Cohorts are sorted by name.
+	Cohorts []CohortDump `json:"cohorts,omitempty"`
+}
+
+// ClusterQueueDump is the state of a ClusterQueue.
+type ClusterQueueDump struct {
+	Name   string `json:"name"`
+	Cohort string `json:"cohort,omitempty"`
+	// Active is false when the ClusterQueue can't admit workloads, because it
+	// references missing ResourceFlavors or it's stopped. The quota and usage
+	// of inactive ClusterQueues are not reported.
+	Active bool `json:"active"`
+	// Resources are the quota and usage, by resource and flavor.
+	Resources []ResourceDump `json:"resources,omitempty"`
+	// AdmittedWorkloads is the number of admitted workloads that are not
+	// finished.
+	AdmittedWorkloads int `json:"admittedWorkloads"`
+	// PendingWorkloads are the workloads waiting for admission. The workloads
+	// that would be tried first go first, followed by the inadmissible ones.
+	PendingWorkloads []PendingWorkloadDump `json:"pendingWorkloads,omitempty"`
+}
+
+// ResourceDump is the quota and usage of a resource in a ClusterQueue.
+type ResourceDump struct {
+	Name    corev1.ResourceName `json:"name"`
+	Flavors []FlavorDump        `json:"flavors"`
+}
+
+// FlavorDump is the quota and usage of a resource flavor in a ClusterQueue.
+type FlavorDump struct {
+	Name string             `json:"name"`
+	Min  resource.Quantity  `json:"min"`
+	Max  *resource.Quantity `json:"max,omitempty"`
+	// Used includes the quota borrowed from the cohort.
+	Used resource.Quantity `json:"used"`
+}
+
+// CohortDump is the balance of a cohort.
+type CohortDump struct {
+	Name string `json:"name"`
+	// Members are the active ClusterQueues in the cohort, sorted by name.
+	Members   []string             `json:"members"`
+	Resources []CohortResourceDump `json:"resources,omitempty"`
+}
+
+// CohortResourceDump is the balance of a resource in a cohort.
+type CohortResourceDump struct {
+	Name    corev1.ResourceName `json:"name"`
+	Flavors []CohortFlavorDump  `json:"flavors"`
+}
+
+// CohortFlavorDump is the balance of a resource flavor in a cohort.
+type CohortFlavorDump struct {
+	Name string `json:"name"`
+	// Requestable is the sum of the min quota of the members.
+	Requestable resource.Quantity `json:"requestable"`
+	Used        resource.Quantity `json:"used"`
+	// Available is the quota that is still unused. It's negative if the
+	// members are using more than the requestable quota.
+	Available resource.Quantity `json:"available"`
+}
+
+// PendingWorkloadDump describes a workload waiting for admission.
+type PendingWorkloadDump struct {
+	// Key is the namespace/name of the workload.
+	Key   string `json:"key"`
+	Queue string `json:"queue"`
+	// Inadmissible indicates that the workload was tried and it's waiting
+	// for changes in the cluster to be tried again.
+	Inadmissible bool `json:"inadmissible,omitempty"`
+	// ShortResources are the resources that didn't fit in the quota the last
+	// time the workload was tried.
+	ShortResources []string `json:"shortResources,omitempty"`
+	// LastInadmissibleReason is the message of the Admitted condition, set
+	// the last time the workload couldn't be admitted.
+	LastInadmissibleReason string `json:"lastInadmissibleReason,omitempty"`
+}
+
+func newSnapshotDump(now time.Time, snap cache.Snapshot, pending map[string]queue.PendingWorkloads) SnapshotDump {
+	dump := SnapshotDump{
+		Time:          now,
+		ClusterQueues: make([]ClusterQueueDump, 0, len(snap.ClusterQueues)+snap.InactiveClusterQueueSets.Len()),
+	}
+	cohorts := make(map[string]*cache.Cohort)
+	members := make(map[string][]string)
+	for _, cq := range snap.ClusterQueues {
+		cqDump := ClusterQueueDump{
+			Name:              cq.Name,
+			Active:            true,
+			Resources:         resourcesDump(cq),
+			AdmittedWorkloads: len(cq.Workloads),
+			PendingWorkloads:  pendingDump(pending[cq.Name]),
+		}
+		if cq.Cohort != nil {
+			cqDump.Cohort = cq.Cohort.Name
+			cohorts[cq.Cohort.Name] = cq.Cohort
+			members[cq.Cohort.Name] = append(members[cq.Cohort.Name], cq.Name)
+		}
+		dump.ClusterQueues = append(dump.ClusterQueues, cqDump)
+	}
+	for name := range snap.InactiveClusterQueueSets {
+		dump.ClusterQueues = append(dump.ClusterQueues, ClusterQueueDump{
+			Name:             name,
+			PendingWorkloads: pendingDump(pending[name]),
+		})
+	}
+	sort.Slice(dump.ClusterQueues, func(i, j int) bool {
+		return dump.ClusterQueues[i].Name < dump.ClusterQueues[j].Name
+	})
+
+	for name, cohort := range cohorts {
+		sort.Strings(members[name])
+		dump.Cohorts = append(dump.Cohorts, CohortDump{
+			Name:      name,
+			Members:   members[name],
+			Resources: cohortResourcesDump(cohort),
+		})
+	}
+	sort.Slice(dump.Cohorts, func(i, j int) bool {
+		return dump.Cohorts[i].Name < dump.Cohorts[j].Name
+	})
+	return dump
+}
+
+func resourcesDump(cq *cache.ClusterQueue) []ResourceDump {
+	names := make([]string, 0, len(cq.RequestableResources))
+	for rName := range cq.RequestableResources {
+		names = append(names, string(rName))
+	}
+	sort.Strings(names)
+	resources := make([]ResourceDump, 0, len(names))
+	for _, name := range names {
+		rName := corev1.ResourceName(name)
+		flavors := make([]FlavorDump, 0, len(cq.RequestableResources[rName]))
+		for _, f := range cq.RequestableResources[rName] {
+			fDump := FlavorDump{
+				Name: f.Name,
+				Min:  workload.ResourceQuantity(rName, f.Min),
+				Used: workload.ResourceQuantity(rName, cq.UsedResources[rName][f.Name]),
+			}
+			if f.Max != nil {
+				max := workload.ResourceQuantity(rName, *f.Max)
+				fDump.Max = &max
+			}
+			flavors = append(flavors, fDump)
+		}
+		resources = append(resources, ResourceDump{Name: rName, Flavors: flavors})
+	}
+	return resources
+}
+
+func cohortResourcesDump(cohort *cache.Cohort) []CohortResourceDump {
+	names := make([]string, 0, len(cohort.RequestableResources))
+	for rName := range cohort.RequestableResources {
+		names = append(names, string(rName))
+	}
+	sort.Strings(names)
+	resources := make([]CohortResourceDump, 0, len(names))
+	for _, name := range names {
+		rName := corev1.ResourceName(name)
+		requestable := cohort.RequestableResources[rName]
+		flavorNames := sets.StringKeySet(requestable).List()
+		flavors := make([]CohortFlavorDump, 0, len(flavorNames))
+		for _, fName := range flavorNames {
+			used := cohort.UsedResources[rName][fName]
+			flavors = append(flavors, CohortFlavorDump{
+				Name:        fName,
+				Requestable: workload.ResourceQuantity(rName, requestable[fName]),
+				Used:        workload.ResourceQuantity(rName, used),
+				Available:   workload.ResourceQuantity(rName, requestable[fName]-used),
+			})
+		}
+		resources = append(resources, CohortResourceDump{Name: rName, Flavors: flavors})
+	}
+	return resources
+}
+
+func pendingDump(pending queue.PendingWorkloads) []PendingWorkloadDump {
+	var dump []PendingWorkloadDump
+	for _, info := range pending.Active {
+		dump = append(dump, pendingWorkloadDump(info, false))
+	}
+	for _, info := range pending.Inadmissible {
+		dump = append(dump, pendingWorkloadDump(info, true))
+	}
+	return dump
+}
+
+func pendingWorkloadDump(info *workload.Info, inadmissible bool) PendingWorkloadDump {
+	d := PendingWorkloadDump{
+		Key:          workload.Key(info.Obj),
+		Queue:        info.Obj.Spec.QueueName,
+		Inadmissible: inadmissible,
+	}
+	if info.ShortResources.Len() > 0 {
+		d.ShortResources = info.ShortResources.List()
+	}
+	if i := workload.FindConditionIndex(&info.Obj.Status, kueue.WorkloadAdmitted); i != -1 {
+		if c := info.Obj.Status.Conditions[i]; c.Status == corev1.ConditionFalse {
+			d.LastInadmissibleReason = c.Message
+		}
+	}
+	return d
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package visibility
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/cache"
+	"sigs.k8s.io/kueue/pkg/queue"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+	"sigs.k8s.io/kueue/pkg/workload"
+)
+
+func TestNewSnapshotDump(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kueue.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed adding kueue scheme: %v", err)
+	}
+	ctx := context.Background()
+	cCache := cache.New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	cCache.AddOrUpdateResourceFlavor(utiltesting.MakeResourceFlavor("on-demand").Obj())
+	clusterQueues := []*kueue.ClusterQueue{
+		utiltesting.MakeClusterQueue("a").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("on-demand", "5").Max("10").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("b").
+			Cohort("all").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("on-demand", "3").Obj()).Obj()).
+			Obj(),
+		utiltesting.MakeClusterQueue("missing-flavor").
+			Resource(utiltesting.MakeResource(corev1.ResourceCPU).
+				Flavor(utiltesting.MakeFlavor("spot", "3").Obj()).Obj()).
+			Obj(),
+	}
+	for _, cq := range clusterQueues {
+		if err := cCache.AddClusterQueue(ctx, cq); err != nil {
+			t.Fatalf("Failed adding ClusterQueue %s: %v", cq.Name, err)
+		}
+	}
+	admitted := utiltesting.MakeWorkload("admitted", "default").
+		Queue("main").
+		Request(corev1.ResourceCPU, "6").
+		Admit(utiltesting.MakeAdmission("a").Flavor(corev1.ResourceCPU, "on-demand").Obj()).
+		Obj()
+	cCache.AddOrUpdateWorkload(admitted)
+
+	inadmissible := workload.NewInfo(utiltesting.MakeWorkload("too-big", "default").
+		Queue("main").
+		Request(corev1.ResourceCPU, "8").
+		Condition(kueue.WorkloadCondition{
+			Type:    kueue.WorkloadAdmitted,
+			Status:  corev1.ConditionFalse,
+			Reason:  "Pending",
+			Message: "insufficient quota for cpu in flavor on-demand",
+		}).
+		Obj())
+	inadmissible.ShortResources = sets.NewString(string(corev1.ResourceCPU))
+	pending := map[string]queue.PendingWorkloads{
+		"a": {
+			Active: []*workload.Info{
+				workload.NewInfo(utiltesting.MakeWorkload("small", "default").Queue("main").Request(corev1.ResourceCPU, "1").Obj()),
+			},
+			Inadmissible: []*workload.Info{inadmissible},
+		},
+		"b": {},
+		"missing-flavor": {
+			Active: []*workload.Info{
+				workload.NewInfo(utiltesting.MakeWorkload("waiting", "other").Queue("spot").Obj()),
+			},
+		},
+	}
+	now := time.Now()
+	want := SnapshotDump{
+		Time: now,
+		ClusterQueues: []ClusterQueueDump{
+			{
+				Name:   "a",
+				Cohort: "all",
+				Active: true,
+				Resources: []ResourceDump{{
+					Name: corev1.ResourceCPU,
+					Flavors: []FlavorDump{{
+						Name: "on-demand",
+						Min:  resource.MustParse("5"),
+						Max:  quantity("10"),
+						Used: resource.MustParse("6"),
+					}},
+				}},
+				AdmittedWorkloads: 1,
+				PendingWorkloads: []PendingWorkloadDump{
+					{
+						Key:   "default/small",
+						Queue: "main",
+					},
+					{
+						Key:                    "default/too-big",
+						Queue:                  "main",
+						Inadmissible:           true,
+						ShortResources:         []string{"cpu"},
+						LastInadmissibleReason: "insufficient quota for cpu in flavor on-demand",
+					},
+				},
+			},
+			{
+				Name:   "b",
+				Cohort: "all",
+				Active: true,
+				Resources: []ResourceDump{{
+					Name: corev1.ResourceCPU,
+					Flavors: []FlavorDump{{
+						Name: "on-demand",
+						Min:  resource.MustParse("3"),
+						Used: resource.MustParse("0"),
+					}},
+				}},
+			},
+			{
+				Name: "missing-flavor",
+				PendingWorkloads: []PendingWorkloadDump{
+					{
+						Key:   "other/waiting",
+						Queue: "spot",
+					},
+				},
+			},
+		},
+		Cohorts: []CohortDump{{
+			Name:    "all",
+			Members: []string{"a", "b"},
+			Resources: []CohortResourceDump{{
+				Name: corev1.ResourceCPU,
+				Flavors: []CohortFlavorDump{{
+					Name:        "on-demand",
+					Requestable: resource.MustParse("8"),
+					Used:        resource.MustParse("6"),
+					Available:   resource.MustParse("2"),
+				}},
+			}},
+		}},
+	}
+	got := newSnapshotDump(now, cCache.Snapshot(), pending)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected dump (-want,+got):\n%s", diff)
+	}
+}
+
+func quantity(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
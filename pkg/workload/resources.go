@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+)
+
+// AdjustResources sets in the pod templates of the workload the resources
+// that the pods will request at runtime, so that they are accounted in the
+// quotas. It sets:
+//   - the overhead of the RuntimeClass of the pods.
+//   - the requests of the containers that only set limits, to the limits, as
+//     the API server does when creating the pods.
+//   - the default requests and limits of the LimitRanges in the namespace,
+//     as the LimitRanger admission plugin does.
+func AdjustResources(ctx context.Context, c client.Client, wl *kueue.Workload) {
+	handlePodOverhead(ctx, c, wl)
+	handleLimitsToRequests(wl)
+	handlePodLimitRange(ctx, c, wl)
+}
+
+// handlePodOverhead sets the overhead of the RuntimeClass on the pod
+// templates that don't have it, like the RuntimeClass admission controller
+// does for the pods.
+//
+// We do not verify Pod's RuntimeClass legality here as this will be performed in admission controller.
+// As a result, the pod's Overhead is not always correct. E.g. if we set a non-existent runtime class name to
+// `pod.Spec.RuntimeClassName` and we also set the `pod.Spec.Overhead`, in real world, the pod creation will be
+// rejected due to the mismatch with RuntimeClass. However, in the future we assume that they are correct.
+func handlePodOverhead(ctx context.Context, c client.Client, wl *kueue.Workload) {
+	log := ctrl.LoggerFrom(ctx)
+	for i := range wl.Spec.PodSets {
+		spec := &wl.Spec.PodSets[i].Spec
+		if spec.RuntimeClassName == nil || len(spec.Overhead) > 0 {
+			continue
+		}
+		var runtimeClass nodev1.RuntimeClass
+		if err := c.Get(ctx, types.NamespacedName{Name: *spec.RuntimeClassName}, &runtimeClass); err != nil {
+			log.Error(err, "Could not get RuntimeClass", "runtimeClass", *spec.RuntimeClassName)
+			continue
+		}
+		if runtimeClass.Overhead != nil {
+			spec.Overhead = runtimeClass.Overhead.PodFixed
+		}
+	}
+}
+
+// handleLimitsToRequests sets the requests of the containers that don't have
+// them to their limits.
+func handleLimitsToRequests(wl *kueue.Workload) {
+	for i := range wl.Spec.PodSets {
+		spec := &wl.Spec.PodSets[i].Spec
+		for j := range spec.InitContainers {
+			mergeResources(&spec.InitContainers[j].Resources.Requests, spec.InitContainers[j].Resources.Limits)
+		}
+		for j := range spec.Containers {
+			mergeResources(&spec.Containers[j].Resources.Requests, spec.Containers[j].Resources.Limits)
+		}
+	}
+}
+
+// handlePodLimitRange sets the default requests and limits of the Container
+// items of the LimitRanges in the namespace of the workload to the containers
+// that don't have them.
+func handlePodLimitRange(ctx context.Context, c client.Client, wl *kueue.Workload) {
+	var limitRanges corev1.LimitRangeList
+	if err := c.List(ctx, &limitRanges, client.InNamespace(wl.Namespace)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Could not list LimitRanges")
+		return
+	}
+	var defaultRequests, defaultLimits corev1.ResourceList
+	for _, lr := range limitRanges.Items {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			mergeResources(&defaultRequests, item.DefaultRequest)
+			mergeResources(&defaultLimits, item.Default)
+		}
+	}
+	if len(defaultRequests) == 0 && len(defaultLimits) == 0 {
+		return
+	}
+	for i := range wl.Spec.PodSets {
+		spec := &wl.Spec.PodSets[i].Spec
+		for j := range spec.InitContainers {
+			mergeResources(&spec.InitContainers[j].Resources.Requests, defaultRequests)
+			mergeResources(&spec.InitContainers[j].Resources.Limits, defaultLimits)
+		}
+		for j := range spec.Containers {
+			mergeResources(&spec.Containers[j].Resources.Requests, defaultRequests)
+			mergeResources(&spec.Containers[j].Resources.Limits, defaultLimits)
+		}
+	}
+}
+
+// ValidateLimitRange validates the resources of the containers and of the
+// pods of the workload against the minimums and maximums of the LimitRanges
+// in its namespace, like the LimitRanger admission plugin does when creating
+// the pods. A workload that doesn't pass the validation can't create its pods
+// once admitted.
+func ValidateLimitRange(ctx context.Context, c client.Client, wl *kueue.Workload) field.ErrorList {
+	var limitRanges corev1.LimitRangeList
+	if err := c.List(ctx, &limitRanges, client.InNamespace(wl.Namespace)); err != nil {
+		return field.ErrorList{field.InternalError(nil, fmt.Errorf("listing LimitRanges: %w", err))}
+	}
+	var allErrs field.ErrorList
+	podSetsPath := field.NewPath("spec", "podSets")
+	for _, lr := range limitRanges.Items {
+		for _, item := range lr.Spec.Limits {
+			for i := range wl.Spec.PodSets {
+				spec := &wl.Spec.PodSets[i].Spec
+				specPath := podSetsPath.Index(i).Child("spec")
+				switch item.Type {
+				case corev1.LimitTypeContainer:
+					for j := range spec.InitContainers {
+						res := &spec.InitContainers[j].Resources
+						allErrs = append(allErrs, validateLimitRangeItem(specPath.Child("initContainers").Index(j).Child("resources"), lr.Name, &item, res.Requests, res.Limits, true)...)
+					}
+					for j := range spec.Containers {
+						res := &spec.Containers[j].Resources
+						allErrs = append(allErrs, validateLimitRangeItem(specPath.Child("containers").Index(j).Child("resources"), lr.Name, &item, res.Requests, res.Limits, true)...)
+					}
+				case corev1.LimitTypePod:
+					requests, limits := podResourceLists(spec)
+					allErrs = append(allErrs, validateLimitRangeItem(specPath, lr.Name, &item, requests, limits, false)...)
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateLimitRangeItem validates the requests and limits against the
+// minimums and maximums of the item. If requireLimits is true, the resources
+// with a maximum must have a limit.
+func validateLimitRangeItem(path *field.Path, lrName string, item *corev1.LimitRangeItem, requests, limits corev1.ResourceList, requireLimits bool) field.ErrorList {
+	var allErrs field.ErrorList
+	for name, max := range item.Max {
+		if q, ok := requests[name]; ok && q.Cmp(max) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(string(name)), q.String(),
+				fmt.Sprintf("must be less than or equal to %s, the maximum of LimitRange %s", max.String(), lrName)))
+		}
+		if q, ok := limits[name]; ok && q.Cmp(max) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("limits").Key(string(name)), q.String(),
+				fmt.Sprintf("must be less than or equal to %s, the maximum of LimitRange %s", max.String(), lrName)))
+		} else if !ok && requireLimits {
+			allErrs = append(allErrs, field.Required(path.Child("limits").Key(string(name)),
+				fmt.Sprintf("LimitRange %s has a maximum of %s", lrName, max.String())))
+		}
+	}
+	for name, min := range item.Min {
+		if q, ok := requests[name]; !ok {
+			allErrs = append(allErrs, field.Required(path.Child("requests").Key(string(name)),
+				fmt.Sprintf("LimitRange %s has a minimum of %s", lrName, min.String())))
+		} else if q.Cmp(min) < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(string(name)), q.String(),
+				fmt.Sprintf("must be greater than or equal to %s, the minimum of LimitRange %s", min.String(), lrName)))
+		}
+		if q, ok := limits[name]; ok && q.Cmp(min) < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("limits").Key(string(name)), q.String(),
+				fmt.Sprintf("must be greater than or equal to %s, the minimum of LimitRange %s", min.String(), lrName)))
+		}
+	}
+	return allErrs
+}
+
+// podResourceLists returns the requests and limits of a pod: the sum of its
+// containers, or the maximum of its init containers if higher.
+func podResourceLists(spec *corev1.PodSpec) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for i := range spec.Containers {
+		addResources(requests, spec.Containers[i].Resources.Requests)
+		addResources(limits, spec.Containers[i].Resources.Limits)
+	}
+	for i := range spec.InitContainers {
+		maxResources(requests, spec.InitContainers[i].Resources.Requests)
+		maxResources(limits, spec.InitContainers[i].Resources.Limits)
+	}
+	return requests, limits
+}
+
+func addResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		v := dst[name]
+		v.Add(q)
+		dst[name] = v
+	}
+}
+
+func maxResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if v, ok := dst[name]; !ok || q.Cmp(v) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+// mergeResources sets the quantities of src that are missing in dst.
+func mergeResources(dst *corev1.ResourceList, src corev1.ResourceList) {
+	for name, q := range src {
+		if _, ok := (*dst)[name]; ok {
+			continue
+		}
+		if *dst == nil {
+			*dst = make(corev1.ResourceList, len(src))
+		}
+		(*dst)[name] = q.DeepCopy()
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
+)
+
+func TestAdjustResources(t *testing.T) {
+	overhead := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("250m"),
+		corev1.ResourceMemory: resource.MustParse("120Mi"),
+	}
+	cases := map[string]struct {
+		workload     *kueue.Workload
+		wantOverhead corev1.ResourceList
+	}{
+		"no RuntimeClass": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Obj(),
+		},
+		"RuntimeClass with overhead": {
+			workload:     utiltesting.MakeWorkload("wl", "ns").RuntimeClass("kata").Obj(),
+			wantOverhead: overhead,
+		},
+		"RuntimeClass without overhead": {
+			workload: utiltesting.MakeWorkload("wl", "ns").RuntimeClass("runc").Obj(),
+		},
+		"missing RuntimeClass": {
+			workload: utiltesting.MakeWorkload("wl", "ns").RuntimeClass("gvisor").Obj(),
+		},
+		"overhead already set": {
+			workload: func() *kueue.Workload {
+				wl := utiltesting.MakeWorkload("wl", "ns").RuntimeClass("kata").Obj()
+				wl.Spec.PodSets[0].Spec.Overhead = corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				}
+				return wl
+			}(),
+			wantOverhead: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := nodev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding node scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding core scheme: %v", err)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				utiltesting.MakeRuntimeClass("kata", "kata").PodOverhead(overhead).Obj(),
+				utiltesting.MakeRuntimeClass("runc", "runc").Obj(),
+			).Build()
+			AdjustResources(context.Background(), cl, tc.workload)
+			if diff := cmp.Diff(tc.wantOverhead, tc.workload.Spec.PodSets[0].Spec.Overhead); diff != "" {
+				t.Errorf("Unexpected overhead (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAdjustResourcesContainers(t *testing.T) {
+	cases := map[string]struct {
+		workload      *kueue.Workload
+		limitRanges   []corev1.LimitRange
+		wantResources corev1.ResourceRequirements
+	}{
+		"limits as requests": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceMemory, "1Gi").
+				Limit(corev1.ResourceCPU, "2").
+				Limit(corev1.ResourceMemory, "2Gi").
+				Obj(),
+			wantResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
+		"LimitRange defaults": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceMemory, "1Gi").
+				Obj(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("defaults", "ns").
+					DefaultRequest(corev1.ResourceCPU, "500m").
+					DefaultRequest(corev1.ResourceMemory, "512Mi").
+					Default(corev1.ResourceCPU, "1").
+					Obj(),
+				*utiltesting.MakeLimitRange("pod", "ns").
+					WithType(corev1.LimitTypePod).
+					DefaultRequest(corev1.ResourceEphemeralStorage, "1Gi").
+					Obj(),
+				*utiltesting.MakeLimitRange("other-namespace", "other").
+					DefaultRequest(corev1.ResourceEphemeralStorage, "1Gi").
+					Obj(),
+			},
+			wantResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+			},
+		},
+		"limits take precedence over LimitRange default requests": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Limit(corev1.ResourceCPU, "2").
+				Obj(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("defaults", "ns").
+					DefaultRequest(corev1.ResourceCPU, "500m").
+					Default(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+			wantResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding core scheme: %v", err)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithLists(&corev1.LimitRangeList{Items: tc.limitRanges}).Build()
+			AdjustResources(context.Background(), cl, tc.workload)
+			if diff := cmp.Diff(tc.wantResources, tc.workload.Spec.PodSets[0].Spec.Containers[0].Resources); diff != "" {
+				t.Errorf("Unexpected resources (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateLimitRange(t *testing.T) {
+	cases := map[string]struct {
+		workload    *kueue.Workload
+		limitRanges []corev1.LimitRange
+		wantErrs    []string
+	}{
+		"no LimitRanges": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj(),
+		},
+		"within the container limits": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "1").
+				Limit(corev1.ResourceCPU, "2").
+				Obj(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("lr", "ns").
+					Min(corev1.ResourceCPU, "500m").
+					Max(corev1.ResourceCPU, "2").
+					Obj(),
+			},
+		},
+		"outside the container limits": {
+			workload: utiltesting.MakeWorkload("wl", "ns").
+				Request(corev1.ResourceCPU, "100m").
+				Limit(corev1.ResourceCPU, "3").
+				Obj(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("lr", "ns").
+					Min(corev1.ResourceCPU, "500m").
+					Max(corev1.ResourceCPU, "2").
+					Min(corev1.ResourceMemory, "1Gi").
+					Max(corev1.ResourceMemory, "2Gi").
+					Obj(),
+			},
+			wantErrs: []string{
+				"spec.podSets[0].spec.containers[0].resources.limits[cpu]",
+				"spec.podSets[0].spec.containers[0].resources.limits[memory]",
+				"spec.podSets[0].spec.containers[0].resources.requests[cpu]",
+				"spec.podSets[0].spec.containers[0].resources.requests[memory]",
+			},
+		},
+		"outside the pod limits": {
+			workload: func() *kueue.Workload {
+				wl := utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj()
+				wl.Spec.PodSets[0].Spec.Containers = append(wl.Spec.PodSets[0].Spec.Containers, corev1.Container{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				})
+				return wl
+			}(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("lr", "ns").
+					WithType(corev1.LimitTypePod).
+					Max(corev1.ResourceCPU, "2").
+					Obj(),
+			},
+			wantErrs: []string{
+				"spec.podSets[0].spec.requests[cpu]",
+			},
+		},
+		"LimitRange in another namespace": {
+			workload: utiltesting.MakeWorkload("wl", "ns").Request(corev1.ResourceCPU, "2").Obj(),
+			limitRanges: []corev1.LimitRange{
+				*utiltesting.MakeLimitRange("lr", "other").
+					Max(corev1.ResourceCPU, "1").
+					Obj(),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed adding core scheme: %v", err)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithLists(&corev1.LimitRangeList{Items: tc.limitRanges}).Build()
+			errs := ValidateLimitRange(context.Background(), cl, tc.workload)
+			var gotErrs []string
+			for _, err := range errs {
+				gotErrs = append(gotErrs, err.Field)
+			}
+			sort.Strings(gotErrs)
+			if diff := cmp.Diff(tc.wantErrs, gotErrs); diff != "" {
+				t.Errorf("Unexpected errors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
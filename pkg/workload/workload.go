@@ -21,12 +21,17 @@ import (
 	"fmt"
 	"strings"
 
+	"gopkg.in/inf.v0"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
 )
 
 // Info holds a Workload object and some pre-processing.
@@ -36,6 +41,10 @@ type Info struct {
 	TotalRequests []PodSetResources
 	// Populated from queue.
 	ClusterQueue string
+	// ShortResources are the resources that didn't fit in the quota of the
+	// ClusterQueue the last time the workload was evaluated for admission.
+	// Empty when the workload wasn't admitted for other reasons.
+	ShortResources sets.String
 }
 
 type PodSetResources struct {
@@ -44,10 +53,41 @@ type PodSetResources struct {
 	Flavors  map[corev1.ResourceName]string
 }
 
-func NewInfo(w *kueue.Workload) *Info {
+type infoOptions struct {
+	excludedResourcePrefixes []string
+	transformations          map[corev1.ResourceName]*configapi.ResourceTransformation
+}
+
+// InfoOption configures how the Info of a workload is computed.
+type InfoOption func(*infoOptions)
+
+// WithExcludedResourcePrefixes sets the prefixes of the resource names that
+// are left out of the requests of the podsets.
+func WithExcludedResourcePrefixes(prefixes []string) InfoOption {
+	return func(o *infoOptions) {
+		o.excludedResourcePrefixes = prefixes
+	}
+}
+
+// WithResourceTransformations sets the transformations applied to the
+// requests of the podsets.
+func WithResourceTransformations(transformations []configapi.ResourceTransformation) InfoOption {
+	return func(o *infoOptions) {
+		o.transformations = make(map[corev1.ResourceName]*configapi.ResourceTransformation, len(transformations))
+		for i := range transformations {
+			o.transformations[transformations[i].Input] = &transformations[i]
+		}
+	}
+}
+
+func NewInfo(w *kueue.Workload, opts ...InfoOption) *Info {
+	var options infoOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &Info{
 		Obj:           w,
-		TotalRequests: totalRequests(&w.Spec),
+		TotalRequests: totalRequests(&w.Spec, &options),
 	}
 }
 
@@ -55,11 +95,45 @@ func (i *Info) Update(wl *kueue.Workload) {
 	i.Obj = wl
 }
 
+// PodsCount returns the total count of the pods of the podSets. For a
+// partially admitted workload, only the admitted pods are counted.
+func (i *Info) PodsCount() int32 {
+	admitted := admittedCounts(i.Obj.Spec.Admission)
+	var count int32
+	for _, ps := range i.Obj.Spec.PodSets {
+		if c, ok := admitted[ps.Name]; ok {
+			count += c
+		} else {
+			count += ps.Count
+		}
+	}
+	return count
+}
+
+// admittedCounts returns the admitted count of the podSets that were
+// partially admitted, by podSet name.
+func admittedCounts(admission *kueue.Admission) map[string]int32 {
+	if admission == nil {
+		return nil
+	}
+	var counts map[string]int32
+	for _, ps := range admission.PodSetFlavors {
+		if ps.Count == nil {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int32)
+		}
+		counts[ps.Name] = *ps.Count
+	}
+	return counts
+}
+
 func Key(w *kueue.Workload) string {
 	return fmt.Sprintf("%s/%s", w.Namespace, w.Name)
 }
 
-func totalRequests(spec *kueue.WorkloadSpec) []PodSetResources {
+func totalRequests(spec *kueue.WorkloadSpec, options *infoOptions) []PodSetResources {
 	if len(spec.PodSets) == 0 {
 		return nil
 	}
@@ -71,13 +145,20 @@ func totalRequests(spec *kueue.WorkloadSpec) []PodSetResources {
 			podSetFlavors[ps.Name] = ps.Flavors
 		}
 	}
+	admitted := admittedCounts(spec.Admission)
 
 	for _, ps := range spec.PodSets {
 		setRes := PodSetResources{
 			Name: ps.Name,
 		}
 		setRes.Requests = podRequests(&ps.Spec)
-		setRes.Requests.scale(int64(ps.Count))
+		count := ps.Count
+		if c, ok := admitted[ps.Name]; ok {
+			count = c
+		}
+		setRes.Requests.scale(int64(count))
+		setRes.Requests.transform(options.transformations)
+		setRes.Requests.dropWithPrefixes(options.excludedResourcePrefixes)
 		flavors := podSetFlavors[ps.Name]
 		if len(flavors) > 0 {
 			setRes.Flavors = make(map[corev1.ResourceName]string, len(flavors))
@@ -151,6 +232,42 @@ func (r Requests) setMax(o Requests) {
 	}
 }
 
+// transform adds the outputs of the transformations of the requested
+// resources. The outputs are computed for the total of the input, so that
+// fractional outputs are only rounded up once.
+func (r Requests) transform(transformations map[corev1.ResourceName]*configapi.ResourceTransformation) {
+	if len(transformations) == 0 {
+		return
+	}
+	outputs := Requests{}
+	for name, val := range r {
+		t, ok := transformations[name]
+		if !ok {
+			continue
+		}
+		input := ResourceQuantity(name, val)
+		for outName, perUnit := range t.Outputs {
+			total := new(inf.Dec).Mul(input.AsDec(), perUnit.AsDec())
+			outputs[outName] += ResourceValue(outName, *resource.NewDecimalQuantity(*total, perUnit.Format))
+		}
+		if t.Strategy != nil && *t.Strategy == configapi.Replace {
+			delete(r, name)
+		}
+	}
+	r.add(outputs)
+}
+
+func (r Requests) dropWithPrefixes(prefixes []string) {
+	for name := range r {
+		for _, p := range prefixes {
+			if strings.HasPrefix(string(name), p) {
+				delete(r, name)
+				break
+			}
+		}
+	}
+}
+
 func (r Requests) scale(f int64) {
 	for name := range r {
 		r[name] *= f
@@ -185,8 +302,19 @@ func UpdateStatus(ctx context.Context,
 	conditionType kueue.WorkloadConditionType,
 	conditionStatus corev1.ConditionStatus,
 	reason, message string) error {
-	conditionIndex := FindConditionIndex(&wl.Status, conditionType)
+	// Avoid modifying the object in the cache.
+	newWl := *wl
+	newWl.Status = *newWl.Status.DeepCopy()
+	setCondition(&newWl.Status, conditionType, conditionStatus, reason, message)
+	return c.Status().Update(ctx, &newWl)
+}
 
+// setCondition adds or replaces the condition of the given type in the
+// status.
+func setCondition(status *kueue.WorkloadStatus,
+	conditionType kueue.WorkloadConditionType,
+	conditionStatus corev1.ConditionStatus,
+	reason, message string) {
 	now := metav1.Now()
 	condition := kueue.WorkloadCondition{
 		Type:               conditionType,
@@ -196,17 +324,11 @@ func UpdateStatus(ctx context.Context,
 		Reason:             reason,
 		Message:            message,
 	}
-	// Avoid modifying the object in the cache.
-	newWl := *wl
-	newWl.Status = *newWl.Status.DeepCopy()
-
-	if conditionIndex == -1 {
-		newWl.Status.Conditions = append(newWl.Status.Conditions, condition)
+	if i := FindConditionIndex(status, conditionType); i != -1 {
+		status.Conditions[i] = condition
 	} else {
-		newWl.Status.Conditions[conditionIndex] = condition
+		status.Conditions = append(status.Conditions, condition)
 	}
-
-	return c.Status().Update(ctx, &newWl)
 }
 
 func UpdateStatusIfChanged(ctx context.Context,
@@ -229,6 +351,114 @@ func UpdateStatusIfChanged(ctx context.Context,
 	return UpdateStatus(ctx, c, wl, conditionType, conditionStatus, reason, message)
 }
 
+// Reasons for evicting an admitted workload.
+const (
+	EvictedByDeactivation        = "InactiveWorkload"
+	EvictedByClusterQueueStopped = "ClusterQueueStopped"
+	EvictedByQueueStopped        = "QueueStopped"
+	EvictedByPreemption          = "Preempted"
+	EvictedByPodsReadyTimeout    = "PodsReadyTimeout"
+	EvictedByPodsNotCreated      = "PodsNotCreated"
+)
+
+var evictionReasons = sets.NewString(
+	EvictedByDeactivation,
+	EvictedByClusterQueueStopped,
+	EvictedByQueueStopped,
+	EvictedByPreemption,
+	EvictedByPodsReadyTimeout,
+	EvictedByPodsNotCreated,
+)
+
+// Reasons for preempting an admitted workload.
+const (
+	// PreemptedByPriority means that the workload was preempted to admit a
+	// workload with higher priority in the same ClusterQueue.
+	PreemptedByPriority = "Priority"
+	// PreemptedByReclamation means that the workload was borrowing quota that
+	// its owner ClusterQueue in the cohort reclaimed.
+	PreemptedByReclamation = "Reclamation"
+)
+
+// IsActive returns whether the workload can be admitted. Workloads are
+// active unless .spec.active is set to false.
+func IsActive(w *kueue.Workload) bool {
+	return w.Spec.Active == nil || *w.Spec.Active
+}
+
+// Evict clears the admission of the workload, so that the integration stops
+// its pods, and sets the Admitted condition to false with the given reason.
+// The PodsCreated and PodsReady conditions are set to false as well, as the
+// pods have to be created and become ready again once the workload is
+// admitted again.
+func Evict(ctx context.Context, c client.Client, recorder record.EventRecorder, wl *kueue.Workload, reason, message string) error {
+	newWl := wl.DeepCopy()
+	newWl.Spec.Admission = nil
+	if err := c.Update(ctx, newWl); err != nil {
+		return err
+	}
+	metrics.EvictedWorkload(reason)
+	RecordEvent(recorder, newWl, corev1.EventTypeNormal, "Evicted", message)
+	setEvictedConditions(&newWl.Status, reason, message)
+	return c.Status().Update(ctx, newWl)
+}
+
+// Preempt evicts the workload to free quota for other workloads and records
+// the reason of the preemption. The message should tell which workload
+// preempted this one.
+func Preempt(ctx context.Context, c client.Client, recorder record.EventRecorder, wl *kueue.Workload, reason, message string) error {
+	newWl := wl.DeepCopy()
+	newWl.Spec.Admission = nil
+	if err := c.Update(ctx, newWl); err != nil {
+		return err
+	}
+	metrics.EvictedWorkload(EvictedByPreemption)
+	metrics.PreemptedWorkload(reason)
+	RecordEvent(recorder, newWl, corev1.EventTypeNormal, "Preempted", fmt.Sprintf("%s (reason: %s)", message, reason))
+	setEvictedConditions(&newWl.Status, EvictedByPreemption, message)
+	return c.Status().Update(ctx, newWl)
+}
+
+// setEvictedConditions sets the Admitted condition to false with the reason
+// of the eviction, and resets the conditions of the pods that were stopped.
+func setEvictedConditions(status *kueue.WorkloadStatus, reason, message string) {
+	for _, t := range []kueue.WorkloadConditionType{kueue.WorkloadPodsCreated, kueue.WorkloadPodsReady} {
+		if i := FindConditionIndex(status, t); i != -1 && status.Conditions[i].Status == corev1.ConditionTrue {
+			setCondition(status, t, corev1.ConditionFalse, reason, message)
+		}
+	}
+	setCondition(status, kueue.WorkloadAdmitted, corev1.ConditionFalse, reason, message)
+}
+
+// RecordEvent records an event for the workload and for the object that
+// controls it, such as a Job, so that users find it when describing either.
+func RecordEvent(recorder record.EventRecorder, wl *kueue.Workload, eventType, reason, message string) {
+	recorder.Event(wl, eventType, reason, message)
+	owner := metav1.GetControllerOf(wl)
+	if owner == nil {
+		return
+	}
+	ownerObj := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: owner.APIVersion, Kind: owner.Kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      owner.Name,
+			Namespace: wl.Namespace,
+			UID:       owner.UID,
+		},
+	}
+	recorder.Event(ownerObj, eventType, reason, message)
+}
+
+// IsEvicted returns whether the admission of the workload was cleared and the
+// scheduler didn't consider the workload for admission again yet.
+func IsEvicted(w *kueue.Workload) bool {
+	if w.Spec.Admission != nil {
+		return false
+	}
+	i := FindConditionIndex(&w.Status, kueue.WorkloadAdmitted)
+	return i != -1 && w.Status.Conditions[i].Status == corev1.ConditionFalse && evictionReasons.Has(w.Status.Conditions[i].Reason)
+}
+
 func InCondition(w *kueue.Workload, condition kueue.WorkloadConditionType) bool {
 	i := FindConditionIndex(&w.Status, condition)
 	return i != -1 && w.Status.Conditions[i].Status == corev1.ConditionTrue
@@ -22,13 +22,19 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	configapi "sigs.k8s.io/kueue/apis/config/v1alpha1"
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	"sigs.k8s.io/kueue/pkg/metrics"
+	"sigs.k8s.io/kueue/pkg/util/pointer"
 	utiltesting "sigs.k8s.io/kueue/pkg/util/testing"
 )
 
@@ -172,30 +178,186 @@ func TestNewInfo(t *testing.T) {
 			},
 		},
 	}
+	retain := configapi.Retain
+	replace := configapi.Replace
+	cases := map[string]struct {
+		opts         []InfoOption
+		wantRequests []PodSetResources
+	}{
+		"default": {
+			wantRequests: []PodSetResources{
+				{
+					Name: "driver",
+					Requests: Requests{
+						corev1.ResourceCPU:    10,
+						corev1.ResourceMemory: 512 * 1024,
+					},
+					Flavors: map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "on-demand",
+					},
+				},
+				{
+					Name: "workers",
+					Requests: Requests{
+						corev1.ResourceCPU:    15,
+						corev1.ResourceMemory: 3 * 1024 * 1024,
+						"ex.com/gpu":          3,
+					},
+				},
+			},
+		},
+		"excluded resource prefixes": {
+			opts: []InfoOption{WithExcludedResourcePrefixes([]string{"ex.com/", "memory"})},
+			wantRequests: []PodSetResources{
+				{
+					Name: "driver",
+					Requests: Requests{
+						corev1.ResourceCPU: 10,
+					},
+					Flavors: map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "on-demand",
+					},
+				},
+				{
+					Name: "workers",
+					Requests: Requests{
+						corev1.ResourceCPU: 15,
+					},
+				},
+			},
+		},
+		"resource transformations": {
+			opts: []InfoOption{WithResourceTransformations([]configapi.ResourceTransformation{
+				{
+					Input:    "ex.com/gpu",
+					Strategy: &replace,
+					Outputs: corev1.ResourceList{
+						"ex.com/credits":   resource.MustParse("500m"),
+						corev1.ResourceCPU: resource.MustParse("1500m"),
+					},
+				},
+				{
+					Input:    corev1.ResourceCPU,
+					Strategy: &retain,
+					Outputs: corev1.ResourceList{
+						"ex.com/cpu-credits": resource.MustParse("1"),
+					},
+				},
+			})},
+			wantRequests: []PodSetResources{
+				{
+					Name: "driver",
+					Requests: Requests{
+						corev1.ResourceCPU:    10,
+						corev1.ResourceMemory: 512 * 1024,
+						"ex.com/cpu-credits":  1,
+					},
+					Flavors: map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "on-demand",
+					},
+				},
+				{
+					Name: "workers",
+					Requests: Requests{
+						corev1.ResourceCPU:    15 + 4500,
+						corev1.ResourceMemory: 3 * 1024 * 1024,
+						"ex.com/credits":      2,
+						"ex.com/cpu-credits":  1,
+					},
+				},
+			},
+		},
+		"resource transformations and excluded resource prefixes": {
+			opts: []InfoOption{
+				WithResourceTransformations([]configapi.ResourceTransformation{
+					{
+						Input:    "ex.com/gpu",
+						Strategy: &retain,
+						Outputs: corev1.ResourceList{
+							"credits.com/gpu": resource.MustParse("2"),
+						},
+					},
+				}),
+				WithExcludedResourcePrefixes([]string{"ex.com/"}),
+			},
+			wantRequests: []PodSetResources{
+				{
+					Name: "driver",
+					Requests: Requests{
+						corev1.ResourceCPU:    10,
+						corev1.ResourceMemory: 512 * 1024,
+					},
+					Flavors: map[corev1.ResourceName]string{
+						corev1.ResourceCPU: "on-demand",
+					},
+				},
+				{
+					Name: "workers",
+					Requests: Requests{
+						corev1.ResourceCPU:    15,
+						corev1.ResourceMemory: 3 * 1024 * 1024,
+						"credits.com/gpu":     6,
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			info := NewInfo(wl, tc.opts...)
+			if diff := cmp.Diff(tc.wantRequests, info.TotalRequests); diff != "" {
+				t.Errorf("NewInfo returned unexpected total requests (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewInfoPartialAdmission(t *testing.T) {
+	wl := &kueue.Workload{
+		Spec: kueue.WorkloadSpec{
+			PodSets: []kueue.PodSet{
+				{
+					Name: "main",
+					Spec: corev1.PodSpec{
+						Containers: containersForRequests(
+							map[corev1.ResourceName]string{
+								corev1.ResourceCPU: "1",
+							}),
+					},
+					Count: 5,
+				},
+			},
+			Admission: &kueue.Admission{
+				PodSetFlavors: []kueue.PodSetFlavors{
+					{
+						Name: "main",
+						Flavors: map[corev1.ResourceName]string{
+							corev1.ResourceCPU: "on-demand",
+						},
+						Count: pointer.Int32(3),
+					},
+				},
+			},
+		},
+	}
 	info := NewInfo(wl)
 	wantRequests := []PodSetResources{
 		{
-			Name: "driver",
+			Name: "main",
 			Requests: Requests{
-				corev1.ResourceCPU:    10,
-				corev1.ResourceMemory: 512 * 1024,
+				corev1.ResourceCPU: 3000,
 			},
 			Flavors: map[corev1.ResourceName]string{
 				corev1.ResourceCPU: "on-demand",
 			},
 		},
-		{
-			Name: "workers",
-			Requests: Requests{
-				corev1.ResourceCPU:    15,
-				corev1.ResourceMemory: 3 * 1024 * 1024,
-				"ex.com/gpu":          3,
-			},
-		},
 	}
-	if diff := cmp.Diff(info.TotalRequests, wantRequests); diff != "" {
+	if diff := cmp.Diff(wantRequests, info.TotalRequests); diff != "" {
 		t.Errorf("NewInfo returned unexpected total requests (-want,+got):\n%s", diff)
 	}
+	if got := info.PodsCount(); got != 3 {
+		t.Errorf("PodsCount() = %d, want 3", got)
+	}
 }
 
 var ignoreConditionTimestamps = cmpopts.IgnoreFields(kueue.WorkloadCondition{}, "LastProbeTime", "LastTransitionTime")
@@ -312,3 +474,98 @@ func containersForRequests(requests ...map[corev1.ResourceName]string) []corev1.
 	}
 	return containers
 }
+
+func TestEvict(t *testing.T) {
+	cases := map[string]struct {
+		preemptReason string
+		evictReason   string
+		wantReason    string
+		wantEvents    []string
+	}{
+		"evicted": {
+			evictReason: EvictedByDeactivation,
+			wantReason:  EvictedByDeactivation,
+			wantEvents: []string{
+				"Normal Evicted evicted",
+				"Normal Evicted evicted",
+			},
+		},
+		"preempted": {
+			preemptReason: PreemptedByReclamation,
+			wantReason:    EvictedByPreemption,
+			wantEvents: []string{
+				"Normal Preempted preempted (reason: Reclamation)",
+				"Normal Preempted preempted (reason: Reclamation)",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := kueue.AddToScheme(scheme); err != nil {
+				t.Fatalf("Failed to add kueue scheme: %v", err)
+			}
+			wl := utiltesting.MakeWorkload("foo", "bar").
+				Admit(utiltesting.MakeAdmission("cq").Obj()).
+				Condition(kueue.WorkloadCondition{Type: kueue.WorkloadPodsCreated, Status: corev1.ConditionTrue}).
+				Condition(kueue.WorkloadCondition{Type: kueue.WorkloadPodsReady, Status: corev1.ConditionTrue}).
+				Obj()
+			wl.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Name:       "foo",
+				UID:        "foo",
+				Controller: pointer.Bool(true),
+			}}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(wl).Build()
+			recorder := record.NewFakeRecorder(10)
+			ctx := context.Background()
+			evictedBefore := testutil.ToFloat64(metrics.EvictedWorkloadsTotal.WithLabelValues(tc.wantReason))
+			var err error
+			if tc.preemptReason != "" {
+				preemptedBefore := testutil.ToFloat64(metrics.PreemptedWorkloadsTotal.WithLabelValues(tc.preemptReason))
+				err = Preempt(ctx, cl, recorder, wl, tc.preemptReason, "preempted")
+				if got := testutil.ToFloat64(metrics.PreemptedWorkloadsTotal.WithLabelValues(tc.preemptReason)) - preemptedBefore; got != 1 {
+					t.Errorf("Preempted workloads metric increased by %v, want 1", got)
+				}
+			} else {
+				err = Evict(ctx, cl, recorder, wl, tc.evictReason, "evicted")
+			}
+			if err != nil {
+				t.Fatalf("Failed evicting workload: %v", err)
+			}
+			if got := testutil.ToFloat64(metrics.EvictedWorkloadsTotal.WithLabelValues(tc.wantReason)) - evictedBefore; got != 1 {
+				t.Errorf("Evicted workloads metric increased by %v, want 1", got)
+			}
+
+			var updatedWl kueue.Workload
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(wl), &updatedWl); err != nil {
+				t.Fatalf("Failed obtaining updated object: %v", err)
+			}
+			if updatedWl.Spec.Admission != nil {
+				t.Errorf("Workload still has admission %v", updatedWl.Spec.Admission)
+			}
+			i := FindConditionIndex(&updatedWl.Status, kueue.WorkloadAdmitted)
+			if i == -1 || updatedWl.Status.Conditions[i].Status != corev1.ConditionFalse || updatedWl.Status.Conditions[i].Reason != tc.wantReason {
+				t.Errorf("Unexpected conditions %v, want Admitted=False with reason %s", updatedWl.Status.Conditions, tc.wantReason)
+			}
+			if !IsEvicted(&updatedWl) {
+				t.Error("IsEvicted returned false for the evicted workload")
+			}
+			if InCondition(&updatedWl, kueue.WorkloadPodsCreated) {
+				t.Error("The evicted workload kept the PodsCreated condition")
+			}
+			if InCondition(&updatedWl, kueue.WorkloadPodsReady) {
+				t.Error("The evicted workload kept the PodsReady condition")
+			}
+			close(recorder.Events)
+			var gotEvents []string
+			for e := range recorder.Events {
+				gotEvents = append(gotEvents, e)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("Unexpected events for the workload and its owner (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package core
 
 import (
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -41,6 +42,11 @@ const (
 	flavorModelB   = "model-b"
 )
 
+var (
+	ignoreCqConditions        = cmpopts.IgnoreFields(kueue.ClusterQueueStatus{}, "Conditions")
+	ignoreConditionTimestamps = cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "ObservedGeneration")
+)
+
 var _ = ginkgo.Describe("ClusterQueue controller", func() {
 	var (
 		ns                 *corev1.Namespace
@@ -115,7 +121,7 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.ClusterQueueStatus{
 			PendingWorkloads: 5,
 			UsedResources:    emptyUsedResources,
-		}))
+		}, ignoreCqConditions))
 
 		ginkgo.By("Admitting workloads")
 		admissions := []*kueue.Admission{
@@ -165,7 +171,7 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 					},
 				},
 			},
-		}))
+		}, ignoreCqConditions))
 
 		ginkgo.By("Finishing workloads")
 		for _, w := range workloads {
@@ -185,6 +191,60 @@ var _ = ginkgo.Describe("ClusterQueue controller", func() {
 			return updatedCq.Status
 		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.ClusterQueueStatus{
 			UsedResources: emptyUsedResources,
-		}))
+		}, ignoreCqConditions))
+	})
+
+	ginkgo.It("Should evict the admitted workloads when stopped with HoldAndDrain", func() {
+		ginkgo.By("Creating an admitted workload")
+		wl := testing.MakeWorkload("one", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "2").
+			Admit(testing.MakeAdmission(clusterQueue.Name).Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()).Obj()
+		gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
+
+		ginkgo.By("Stopping the clusterQueue")
+		gomega.Eventually(func() error {
+			var updatedCQ kueue.ClusterQueue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterQueue), &updatedCQ)).To(gomega.Succeed())
+			policy := kueue.HoldAndDrain
+			updatedCQ.Spec.StopPolicy = &policy
+			return k8sClient.Update(ctx, &updatedCQ)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+
+		gomega.Eventually(func() *kueue.Admission {
+			var updatedWl kueue.Workload
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), &updatedWl)).To(gomega.Succeed())
+			return updatedWl.Spec.Admission
+		}, framework.Timeout, framework.Interval).Should(gomega.BeNil())
+		gomega.Eventually(func() kueue.ClusterQueueStatus {
+			var updatedCQ kueue.ClusterQueue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterQueue), &updatedCQ)).To(gomega.Succeed())
+			return updatedCQ.Status
+		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.ClusterQueueStatus{
+			PendingWorkloads: 1,
+			UsedResources:    emptyUsedResources,
+			Conditions: []metav1.Condition{{
+				Type:    kueue.ClusterQueueActive,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Stopped",
+				Message: "Can't admit new workloads and admitted workloads are evicted; the stopPolicy is HoldAndDrain",
+			}},
+		}, ignoreConditionTimestamps))
+
+		ginkgo.By("Resuming the clusterQueue")
+		gomega.Eventually(func() error {
+			var updatedCQ kueue.ClusterQueue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterQueue), &updatedCQ)).To(gomega.Succeed())
+			updatedCQ.Spec.StopPolicy = nil
+			return k8sClient.Update(ctx, &updatedCQ)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		gomega.Eventually(func() []metav1.Condition {
+			var updatedCQ kueue.ClusterQueue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterQueue), &updatedCQ)).To(gomega.Succeed())
+			return updatedCQ.Status.Conditions
+		}, framework.Timeout, framework.Interval).Should(testing.Equal([]metav1.Condition{{
+			Type:    kueue.ClusterQueueActive,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Ready",
+			Message: "Can admit new workloads",
+		}}, ignoreConditionTimestamps))
 	})
 })
@@ -17,6 +17,7 @@ limitations under the License.
 package core
 
 import (
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +31,8 @@ import (
 
 // +kubebuilder:docs-gen:collapse=Imports
 
+var ignoreQueueConditions = cmpopts.IgnoreFields(kueue.QueueStatus{}, "Conditions")
+
 var _ = ginkgo.Describe("Queue controller", func() {
 	var (
 		ns           *corev1.Namespace
@@ -81,8 +84,8 @@ var _ = ginkgo.Describe("Queue controller", func() {
 			var updatedQueue kueue.Queue
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
 			return updatedQueue.Status
-		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{PendingWorkloads: 3}))
-		framework.ExpectPendingWorkloadsMetric(queue, 3)
+		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{PendingWorkloads: 3}, ignoreQueueConditions))
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 3)
 
 		ginkgo.By("Admitting workloads")
 		for _, w := range workloads {
@@ -98,8 +101,8 @@ var _ = ginkgo.Describe("Queue controller", func() {
 			var updatedQueue kueue.Queue
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
 			return updatedQueue.Status
-		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{PendingWorkloads: 0}))
-		framework.ExpectPendingWorkloadsMetric(queue, 0)
+		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{PendingWorkloads: 0}, ignoreQueueConditions))
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 0)
 
 		ginkgo.By("Finishing workloads")
 		for _, w := range workloads {
@@ -117,6 +120,45 @@ var _ = ginkgo.Describe("Queue controller", func() {
 			var updatedQueue kueue.Queue
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
 			return updatedQueue.Status
-		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{}))
+		}, framework.Timeout, framework.Interval).Should(testing.Equal(kueue.QueueStatus{}, ignoreQueueConditions))
+	})
+
+	ginkgo.It("Should report the queue is inactive while stopped", func() {
+		ginkgo.By("Stopping the queue")
+		gomega.Eventually(func() error {
+			var updatedQueue kueue.Queue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
+			policy := kueue.Hold
+			updatedQueue.Spec.StopPolicy = &policy
+			return k8sClient.Update(ctx, &updatedQueue)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		gomega.Eventually(func() []metav1.Condition {
+			var updatedQueue kueue.Queue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
+			return updatedQueue.Status.Conditions
+		}, framework.Timeout, framework.Interval).Should(testing.Equal([]metav1.Condition{{
+			Type:    kueue.QueueActive,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Stopped",
+			Message: "Can't submit new workloads; the stopPolicy is Hold",
+		}}, ignoreConditionTimestamps))
+
+		ginkgo.By("Resuming the queue")
+		gomega.Eventually(func() error {
+			var updatedQueue kueue.Queue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
+			updatedQueue.Spec.StopPolicy = nil
+			return k8sClient.Update(ctx, &updatedQueue)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		gomega.Eventually(func() []metav1.Condition {
+			var updatedQueue kueue.Queue
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(queue), &updatedQueue)).To(gomega.Succeed())
+			return updatedQueue.Status.Conditions
+		}, framework.Timeout, framework.Interval).Should(testing.Equal([]metav1.Condition{{
+			Type:    kueue.QueueActive,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Ready",
+			Message: "Can submit new workloads to the clusterQueue",
+		}}, ignoreConditionTimestamps))
 	})
 })
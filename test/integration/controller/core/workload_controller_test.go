@@ -141,6 +141,30 @@ var _ = ginkgo.Describe("Workload controller", func() {
 				return workload.InCondition(&updatedQueueWorkload, kueue.WorkloadAdmitted)
 			}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 		})
+
+		ginkgo.It("Should evict the workload when it's deactivated", func() {
+			ginkgo.By("Create admitted workload")
+			wl = testing.MakeWorkload("one", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "1").
+				Admit(testing.MakeAdmission(clusterQueue.Name).Flavor(corev1.ResourceCPU, flavorOnDemand).Obj()).Obj()
+			gomega.Expect(k8sClient.Create(ctx, wl)).To(gomega.Succeed())
+
+			ginkgo.By("Deactivate workload")
+			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), &updatedQueueWorkload)).To(gomega.Succeed())
+			updatedQueueWorkload.Spec.Active = pointer.Bool(false)
+			gomega.Expect(k8sClient.Update(ctx, &updatedQueueWorkload)).To(gomega.Succeed())
+			gomega.Eventually(func() *kueue.Admission {
+				gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), &updatedQueueWorkload)).To(gomega.Succeed())
+				return updatedQueueWorkload.Spec.Admission
+			}, framework.Timeout, framework.Interval).Should(gomega.BeNil())
+			gomega.Eventually(func() string {
+				gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), &updatedQueueWorkload)).To(gomega.Succeed())
+				i := workload.FindConditionIndex(&updatedQueueWorkload.Status, kueue.WorkloadAdmitted)
+				if i == -1 {
+					return ""
+				}
+				return updatedQueueWorkload.Status.Conditions[i].Reason
+			}, framework.Timeout, framework.Interval).Should(gomega.BeElementOf(workload.EvictedByDeactivation, "Inactive"))
+		})
 	})
 
 	ginkgo.When("Workload with RuntimeClass defined", func() {
@@ -186,7 +210,7 @@ var _ = ginkgo.Describe("Workload controller", func() {
 						},
 					},
 				},
-			}))
+			}, ignoreCqConditions))
 		})
 	})
 
@@ -230,7 +254,7 @@ var _ = ginkgo.Describe("Workload controller", func() {
 						},
 					},
 				},
-			}))
+			}, ignoreCqConditions))
 		})
 	})
 })
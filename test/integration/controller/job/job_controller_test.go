@@ -30,12 +30,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kueue "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
 	"sigs.k8s.io/kueue/pkg/constants"
 	"sigs.k8s.io/kueue/pkg/controller/workload/job"
 	workloadjob "sigs.k8s.io/kueue/pkg/controller/workload/job"
+	"sigs.k8s.io/kueue/pkg/features"
 	"sigs.k8s.io/kueue/pkg/util/testing"
 	"sigs.k8s.io/kueue/pkg/workload"
 	"sigs.k8s.io/kueue/test/integration/framework"
@@ -138,7 +140,13 @@ var _ = ginkgo.Describe("Job controller", func() {
 		ginkgo.By("checking the job is unsuspended when workload is assigned")
 		onDemandFlavor := testing.MakeResourceFlavor("on-demand").Label(labelKey, "on-demand").Obj()
 		gomega.Expect(k8sClient.Create(ctx, onDemandFlavor)).Should(gomega.Succeed())
-		spotFlavor := testing.MakeResourceFlavor("spot").Label(labelKey, "spot").Obj()
+		spotToleration := corev1.Toleration{
+			Key:      "spot",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "true",
+			Effect:   corev1.TaintEffectNoSchedule,
+		}
+		spotFlavor := testing.MakeResourceFlavor("spot").Label(labelKey, "spot").Toleration(spotToleration).Obj()
 		gomega.Expect(k8sClient.Create(ctx, spotFlavor)).Should(gomega.Succeed())
 		clusterQueue := testing.MakeClusterQueue("cluster-queue").
 			Resource(testing.MakeResource(corev1.ResourceCPU).
@@ -167,11 +175,20 @@ var _ = ginkgo.Describe("Job controller", func() {
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 		gomega.Expect(len(createdJob.Spec.Template.Spec.NodeSelector)).Should(gomega.Equal(1))
 		gomega.Expect(createdJob.Spec.Template.Spec.NodeSelector[labelKey]).Should(gomega.Equal(onDemandFlavor.Name))
+		gomega.Expect(createdJob.Annotations).Should(gomega.HaveKeyWithValue(constants.OriginalNodeSelectorsAnnotation, "null"))
+		gomega.Eventually(func() map[string]string {
+			if err := k8sClient.Get(ctx, lookupKey, createdJob); err != nil {
+				return nil
+			}
+			return createdJob.Annotations
+		}, framework.Timeout, framework.Interval).Should(gomega.HaveKeyWithValue(constants.WorkloadStatusAnnotation, "Admitted"))
 		gomega.Consistently(func() bool {
 			if err := k8sClient.Get(ctx, lookupKey, createdWorkload); err != nil {
 				return false
 			}
-			return len(createdWorkload.Status.Conditions) == 0
+			return len(createdWorkload.Status.Conditions) == 1 &&
+				createdWorkload.Status.Conditions[0].Type == kueue.WorkloadPodsCreated &&
+				createdWorkload.Status.Conditions[0].Status == corev1.ConditionFalse
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.BeTrue())
 
 		ginkgo.By("checking the job gets suspended when parallelism changes and the added node selectors are removed")
@@ -185,6 +202,7 @@ var _ = ginkgo.Describe("Job controller", func() {
 			return createdJob.Spec.Suspend != nil && *createdJob.Spec.Suspend &&
 				len(createdJob.Spec.Template.Spec.NodeSelector) == 0
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+		gomega.Expect(createdJob.Annotations).ShouldNot(gomega.HaveKey(constants.OriginalNodeSelectorsAnnotation))
 		gomega.Eventually(func() bool {
 			ok, _ := testing.CheckLatestEvent(ctx, k8sClient, "DeletedWorkload", corev1.EventTypeNormal, fmt.Sprintf("Deleted not matching Workload: %v", jobKey))
 			return ok
@@ -199,7 +217,7 @@ var _ = ginkgo.Describe("Job controller", func() {
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 		gomega.Expect(createdWorkload.Spec.Admission).Should(gomega.BeNil())
 
-		ginkgo.By("checking the job is unsuspended and selectors added when workload is assigned again")
+		ginkgo.By("checking the job is unsuspended and selectors and tolerations added when workload is assigned again")
 		createdWorkload.Spec.Admission = &kueue.Admission{
 			ClusterQueue: kueue.ClusterQueueReference(clusterQueue.Name),
 			PodSetFlavors: []kueue.PodSetFlavors{{
@@ -217,13 +235,26 @@ var _ = ginkgo.Describe("Job controller", func() {
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 		gomega.Expect(len(createdJob.Spec.Template.Spec.NodeSelector)).Should(gomega.Equal(1))
 		gomega.Expect(createdJob.Spec.Template.Spec.NodeSelector[labelKey]).Should(gomega.Equal(spotFlavor.Name))
+		gomega.Expect(createdJob.Spec.Template.Spec.Tolerations).Should(gomega.ConsistOf(spotToleration))
 		gomega.Consistently(func() bool {
 			if err := k8sClient.Get(ctx, lookupKey, createdWorkload); err != nil {
 				return false
 			}
-			return len(createdWorkload.Status.Conditions) == 0
+			return len(createdWorkload.Status.Conditions) == 1 &&
+				createdWorkload.Status.Conditions[0].Type == kueue.WorkloadPodsCreated &&
+				createdWorkload.Status.Conditions[0].Status == corev1.ConditionFalse
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.BeTrue())
 
+		ginkgo.By("checking the workload has the PodsReady condition when the job pods are ready")
+		createdJob.Status.Ready = pointer.Int32(newParallelism)
+		gomega.Expect(k8sClient.Status().Update(ctx, createdJob)).Should(gomega.Succeed())
+		gomega.Eventually(func() bool {
+			if err := k8sClient.Get(ctx, lookupKey, createdWorkload); err != nil {
+				return false
+			}
+			return workload.InCondition(createdWorkload, kueue.WorkloadPodsReady)
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+
 		ginkgo.By("checking the workload is finished when job is completed")
 		createdJob.Status.Conditions = append(createdJob.Status.Conditions,
 			batchv1.JobCondition{
@@ -234,14 +265,65 @@ var _ = ginkgo.Describe("Job controller", func() {
 			})
 		gomega.Expect(k8sClient.Status().Update(ctx, createdJob)).Should(gomega.Succeed())
 		gomega.Eventually(func() bool {
-			err := k8sClient.Get(ctx, lookupKey, createdWorkload)
-			if err != nil || len(createdWorkload.Status.Conditions) == 0 {
+			if err := k8sClient.Get(ctx, lookupKey, createdWorkload); err != nil {
 				return false
 			}
+			return workload.InCondition(createdWorkload, kueue.WorkloadFinished)
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 
-			return createdWorkload.Status.Conditions[0].Type == kueue.WorkloadFinished &&
-				createdWorkload.Status.Conditions[0].Status == corev1.ConditionTrue
+		ginkgo.By("checking the job mirrors the status of the finished workload")
+		gomega.Eventually(func() map[string]string {
+			if err := k8sClient.Get(ctx, lookupKey, createdJob); err != nil {
+				return nil
+			}
+			return createdJob.Annotations
+		}, framework.Timeout, framework.Interval).Should(gomega.HaveKeyWithValue(constants.WorkloadStatusAnnotation, "Finished"))
+		gomega.Expect(createdJob.Annotations).Should(gomega.HaveKeyWithValue(constants.WorkloadStatusReasonAnnotation, "JobFinished"))
+	})
+})
+
+var _ = ginkgo.Describe("Job controller scaling down running jobs", func() {
+	ginkgo.BeforeEach(func() {
+		fwk = &framework.Framework{
+			ManagerSetup: managerSetup(),
+			CRDPath:      crdPath,
+		}
+		ctx, cfg, k8sClient = fwk.Setup()
+	})
+	ginkgo.AfterEach(func() {
+		fwk.Teardown()
+	})
+	ginkgo.It("Should update the workload count without suspending the job", func() {
+		ginkgo.By("admitting the workload of the job")
+		flavor := testing.MakeResourceFlavor("on-demand").Label(labelKey, "on-demand").Obj()
+		gomega.Expect(k8sClient.Create(ctx, flavor)).Should(gomega.Succeed())
+		job := testing.MakeJob(jobName, jobNamespace).Queue("test-queue").Obj()
+		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
+		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+		createdWorkload := &kueue.Workload{}
+		gomega.Eventually(func() error {
+			return k8sClient.Get(ctx, lookupKey, createdWorkload)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		createdWorkload.Spec.Admission = testing.MakeAdmission("cluster-queue").Flavor(corev1.ResourceCPU, flavor.Name).Obj()
+		gomega.Expect(k8sClient.Update(ctx, createdWorkload)).Should(gomega.Succeed())
+		createdJob := &batchv1.Job{}
+		gomega.Eventually(func() bool {
+			if err := k8sClient.Get(ctx, lookupKey, createdJob); err != nil {
+				return false
+			}
+			return !*createdJob.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+
+		ginkgo.By("decreasing the parallelism of the running job")
+		createdJob.Spec.Parallelism = pointer.Int32(parallelism - 1)
+		gomega.Expect(k8sClient.Update(ctx, createdJob)).Should(gomega.Succeed())
+		gomega.Eventually(func() int32 {
+			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdWorkload)).Should(gomega.Succeed())
+			return createdWorkload.Spec.PodSets[0].Count
+		}, framework.Timeout, framework.Interval).Should(gomega.Equal(int32(parallelism - 1)))
+		gomega.Expect(createdWorkload.Spec.Admission).ShouldNot(gomega.BeNil())
+		gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob)).Should(gomega.Succeed())
+		gomega.Expect(*createdJob.Spec.Suspend).Should(gomega.BeFalse())
 	})
 })
 
@@ -278,3 +360,155 @@ var _ = ginkgo.Describe("Job controller for workloads with no queue set", func()
 		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
 	})
 })
+
+var _ = ginkgo.Describe("Job controller for deleted jobs", func() {
+	ginkgo.BeforeEach(func() {
+		fwk = &framework.Framework{
+			ManagerSetup: managerSetup(),
+			CRDPath:      crdPath,
+		}
+		ctx, cfg, k8sClient = fwk.Setup()
+	})
+	ginkgo.AfterEach(func() {
+		fwk.Teardown()
+	})
+	ginkgo.It("Should delete the workload of a deleted job", func() {
+		ginkgo.By("creating the job")
+		job := testing.MakeJob(jobName, jobNamespace).Queue("test-queue").Obj()
+		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
+		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+		createdWorkload := &kueue.Workload{}
+		gomega.Eventually(func() error {
+			return k8sClient.Get(ctx, lookupKey, createdWorkload)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+
+		ginkgo.By("deleting the job, there is no garbage collector in the test environment")
+		gomega.Expect(k8sClient.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))).Should(gomega.Succeed())
+		gomega.Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, lookupKey, createdWorkload))
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+	})
+})
+
+var _ = ginkgo.Describe("Job controller for jobs with flavor requirements", func() {
+	ginkgo.BeforeEach(func() {
+		fwk = &framework.Framework{
+			ManagerSetup: managerSetup(),
+			CRDPath:      crdPath,
+		}
+		ctx, cfg, k8sClient = fwk.Setup()
+	})
+	ginkgo.AfterEach(func() {
+		fwk.Teardown()
+	})
+	ginkgo.It("Should set the flavor requirements of the workload from the job annotations", func() {
+		ginkgo.By("creating the job")
+		job := testing.MakeJob(jobName, jobNamespace).
+			Queue("test-queue").
+			RequiredFlavors("on-demand, spot").
+			FlavorSelector("hardware=gpu").
+			Obj()
+		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
+		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+		createdWorkload := &kueue.Workload{}
+		gomega.Eventually(func() error {
+			return k8sClient.Get(ctx, lookupKey, createdWorkload)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		gomega.Expect(createdWorkload.Spec.PodSets[0].RequiredFlavors).Should(gomega.Equal([]string{"on-demand", "spot"}))
+		gomega.Expect(createdWorkload.Spec.PodSets[0].FlavorSelector).Should(gomega.Equal(&metav1.LabelSelector{
+			MatchLabels: map[string]string{"hardware": "gpu"},
+		}))
+
+		ginkgo.By("changing the required flavors of the suspended job")
+		createdJob := &batchv1.Job{}
+		gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob)).Should(gomega.Succeed())
+		createdJob.Annotations[constants.RequiredFlavorsAnnotation] = "spot"
+		gomega.Expect(k8sClient.Update(ctx, createdJob)).Should(gomega.Succeed())
+		gomega.Eventually(func() []string {
+			if err := k8sClient.Get(ctx, lookupKey, createdWorkload); err != nil {
+				return nil
+			}
+			return createdWorkload.Spec.PodSets[0].RequiredFlavors
+		}, framework.Timeout, framework.Interval).Should(gomega.Equal([]string{"spot"}))
+	})
+})
+
+var _ = ginkgo.Describe("Job controller with workload slices", func() {
+	ginkgo.BeforeEach(func() {
+		gomega.Expect(features.DefaultMutableFeatureGate.SetFromMap(map[string]bool{
+			string(features.WorkloadSlices): true,
+		})).To(gomega.Succeed())
+		fwk = &framework.Framework{
+			ManagerSetup: managerSetup(),
+			CRDPath:      crdPath,
+		}
+		ctx, cfg, k8sClient = fwk.Setup()
+	})
+	ginkgo.AfterEach(func() {
+		fwk.Teardown()
+		gomega.Expect(features.DefaultMutableFeatureGate.SetFromMap(map[string]bool{
+			string(features.WorkloadSlices): false,
+		})).To(gomega.Succeed())
+	})
+	ginkgo.It("Should scale up a running job through a workload slice", func() {
+		ginkgo.By("admitting the workload of the job")
+		flavor := testing.MakeResourceFlavor("on-demand").Label(labelKey, "on-demand").Obj()
+		gomega.Expect(k8sClient.Create(ctx, flavor)).Should(gomega.Succeed())
+		job := testing.MakeJob(jobName, jobNamespace).Queue("test-queue").Obj()
+		gomega.Expect(k8sClient.Create(ctx, job)).Should(gomega.Succeed())
+		lookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+		createdWorkload := &kueue.Workload{}
+		gomega.Eventually(func() error {
+			return k8sClient.Get(ctx, lookupKey, createdWorkload)
+		}, framework.Timeout, framework.Interval).Should(gomega.Succeed())
+		admission := testing.MakeAdmission("cluster-queue").Flavor(corev1.ResourceCPU, flavor.Name).Obj()
+		createdWorkload.Spec.Admission = admission
+		gomega.Expect(k8sClient.Update(ctx, createdWorkload)).Should(gomega.Succeed())
+		createdJob := &batchv1.Job{}
+		gomega.Eventually(func() bool {
+			if err := k8sClient.Get(ctx, lookupKey, createdJob); err != nil {
+				return false
+			}
+			return !*createdJob.Spec.Suspend
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+
+		ginkgo.By("increasing the parallelism of the running job")
+		createdJob.Spec.Parallelism = pointer.Int32(parallelism + 2)
+		gomega.Expect(k8sClient.Update(ctx, createdJob)).Should(gomega.Succeed())
+		var slice *kueue.Workload
+		gomega.Eventually(func() bool {
+			var workloads kueue.WorkloadList
+			if err := k8sClient.List(ctx, &workloads, client.InNamespace(jobNamespace)); err != nil {
+				return false
+			}
+			for i := range workloads.Items {
+				if workloads.Items[i].Annotations[constants.WorkloadSliceOfAnnotation] == createdWorkload.Name {
+					slice = &workloads.Items[i]
+					return true
+				}
+			}
+			return false
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+		gomega.Expect(slice.Spec.PodSets[0].Count).Should(gomega.Equal(int32(2)))
+		gomega.Expect(slice.Spec.PodSets[0].Spec.NodeSelector).Should(gomega.HaveKeyWithValue(labelKey, flavor.Name))
+		gomega.Eventually(func() int32 {
+			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob)).Should(gomega.Succeed())
+			return *createdJob.Spec.Parallelism
+		}, framework.Timeout, framework.Interval).Should(gomega.Equal(int32(parallelism)))
+		gomega.Expect(*createdJob.Spec.Suspend).Should(gomega.BeFalse())
+
+		ginkgo.By("admitting the workload slice")
+		slice.Spec.Admission = admission
+		gomega.Expect(k8sClient.Update(ctx, slice)).Should(gomega.Succeed())
+		gomega.Eventually(func() int32 {
+			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob)).Should(gomega.Succeed())
+			return *createdJob.Spec.Parallelism
+		}, framework.Timeout, framework.Interval).Should(gomega.Equal(int32(parallelism + 2)))
+		gomega.Expect(*createdJob.Spec.Suspend).Should(gomega.BeFalse())
+		gomega.Expect(k8sClient.Get(ctx, lookupKey, createdWorkload)).Should(gomega.Succeed())
+		gomega.Expect(createdWorkload.Spec.PodSets[0].Count).Should(gomega.Equal(int32(parallelism + 2)))
+		gomega.Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(slice), &kueue.Workload{}))
+		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
+	})
+})
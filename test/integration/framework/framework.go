@@ -248,8 +248,32 @@ func ExpectWorkloadsToBeFrozen(ctx context.Context, k8sClient client.Client, cq
 	}, Timeout, Interval).Should(gomega.Equal(len(wls)), "Not enough workloads are frozen")
 }
 
-func ExpectPendingWorkloadsMetric(q *kueue.Queue, v int) {
-	metric := metrics.PendingWorkloads.WithLabelValues(string(q.Spec.ClusterQueue), queue.Key(q))
+func ExpectQueuePendingWorkloadsMetric(q *kueue.Queue, v int) {
+	metric := metrics.QueuePendingWorkloads.WithLabelValues(string(q.Spec.ClusterQueue), queue.Key(q))
+	gomega.EventuallyWithOffset(1, func() int {
+		v, err := testutil.GetGaugeMetricValue(metric)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		return int(v)
+	}, Timeout, Interval).Should(gomega.Equal(v))
+}
+
+func ExpectPendingWorkloadsMetric(cq *kueue.ClusterQueue, active, inadmissible int) {
+	activeMetric := metrics.PendingWorkloads.WithLabelValues(cq.Name, metrics.PendingStatusActive)
+	gomega.EventuallyWithOffset(1, func() int {
+		v, err := testutil.GetGaugeMetricValue(activeMetric)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		return int(v)
+	}, Timeout, Interval).Should(gomega.Equal(active))
+	inadmissibleMetric := metrics.PendingWorkloads.WithLabelValues(cq.Name, metrics.PendingStatusInadmissible)
+	gomega.EventuallyWithOffset(1, func() int {
+		v, err := testutil.GetGaugeMetricValue(inadmissibleMetric)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		return int(v)
+	}, Timeout, Interval).Should(gomega.Equal(inadmissible))
+}
+
+func ExpectAdmittedActiveWorkloadsMetric(cq *kueue.ClusterQueue, v int) {
+	metric := metrics.AdmittedActiveWorkloads.WithLabelValues(cq.Name)
 	gomega.EventuallyWithOffset(1, func() int {
 		v, err := testutil.GetGaugeMetricValue(metric)
 		gomega.Expect(err).ToNot(gomega.HaveOccurred())
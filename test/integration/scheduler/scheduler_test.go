@@ -124,7 +124,9 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdProdJob1.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdProdJob1.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 0)
+		framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 
 		ginkgo.By("checking a second no-fit prod job does not start")
 		prodJob2 := testing.MakeJob("prod-job2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
@@ -135,7 +137,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, lookupKey2, createdProdJob2)).Should(gomega.Succeed())
 			return createdProdJob2.Spec.Suspend
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal(pointer.Bool(true)))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 1)
 
 		ginkgo.By("checking a dev job starts")
 		devJob := testing.MakeJob("dev-job", ns.Name).Queue(devQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
@@ -147,7 +150,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdDevJob.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdDevJob.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(spotUntaintedFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(devQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(devQueue, 0)
 
 		ginkgo.By("checking the second prod job starts when the first finishes")
 		createdProdJob1.Status.Conditions = append(createdProdJob1.Status.Conditions,
@@ -163,7 +166,9 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdProdJob2.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdProdJob2.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectPendingWorkloadsMetric(prodClusterQ, 0, 0)
+		framework.ExpectAdmittedActiveWorkloadsMetric(prodClusterQ, 1)
 	})
 
 	ginkgo.It("Should schedule workloads on tolerated flavors", func() {
@@ -177,13 +182,13 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl1), wlCopy)).To(gomega.Succeed())
 			return wlCopy.Spec.Admission
 		}, framework.Timeout, framework.Interval).Should(testing.Equal(expectAdmission))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 
 		ginkgo.By("checking a second workload without toleration doesn't start")
 		wl2 := testing.MakeWorkload("on-demand-wl2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "5").Obj()
 		gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 		framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl2)
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 
 		ginkgo.By("checking a third workload with toleration starts")
 		wl3 := testing.MakeWorkload("on-demand-wl3", ns.Name).Queue(prodQueue.Name).Toleration(spotToleration).Request(corev1.ResourceCPU, "5").Obj()
@@ -194,7 +199,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl3), wlCopy)).To(gomega.Succeed())
 			return wlCopy.Spec.Admission
 		}, framework.Timeout, framework.Interval).Should(testing.Equal(expectAdmission))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 	})
 
 	ginkgo.It("Should schedule jobs using borrowed ClusterQueue", func() {
@@ -208,7 +213,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob)).Should(gomega.Succeed())
 			return createdJob.Spec.Suspend
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal(pointer.Bool(true)))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 
 		ginkgo.By("checking the job starts when a fallback ClusterQueue gets added")
 		fallbackClusterQueue := testing.MakeClusterQueue("fallback-cq").
@@ -227,7 +232,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 	})
 
 	ginkgo.It("Should schedule jobs with affinity to specific flavor", func() {
@@ -241,7 +246,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob1.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob1.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(spotUntaintedFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(devQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(devQueue, 0)
 
 		ginkgo.By("checking a second job with affinity to on-demand")
 		job2 := testing.MakeJob("affinity-job", ns.Name).Queue(devQueue.Name).
@@ -256,7 +261,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		}, framework.Timeout, framework.Interval).Should(gomega.BeTrue())
 		gomega.Expect(len(createdJob2.Spec.Template.Spec.NodeSelector)).Should(gomega.Equal(2))
 		gomega.Expect(createdJob2.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(devQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(devQueue, 0)
 	})
 
 	ginkgo.It("Should schedule jobs from the selected namespaces", func() {
@@ -289,7 +294,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, createdJob)).Should(gomega.Succeed())
 			return createdJob.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(true)), "Job should be suspended")
-		framework.ExpectPendingWorkloadsMetric(queue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 1)
 
 		ginkgo.By("checking the job starts after updating namespace labels to match QC selector")
 		ns.Labels = map[string]string{"dep": "eng"}
@@ -298,7 +303,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, createdJob)).Should(gomega.Succeed())
 			return createdJob.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)), "Job should be unsuspended")
-		framework.ExpectPendingWorkloadsMetric(queue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 0)
 	})
 
 	ginkgo.It("Should schedule jobs according to their priorities", func() {
@@ -351,7 +356,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob2.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 
-		framework.ExpectPendingWorkloadsMetric(queue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 1)
 	})
 
 	ginkgo.It("Should re-enqueue by the delete event of workload belonging to the same ClusterQueue", func() {
@@ -365,7 +370,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob1.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob1.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 
 		job2 := testing.MakeJob("on-demand-job2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "4").Obj()
 		gomega.Expect(k8sClient.Create(ctx, job2)).Should(gomega.Succeed())
@@ -375,7 +380,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob2)).Should(gomega.Succeed())
 			return createdJob2.Spec.Suspend
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal(pointer.Bool(true)))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 
 		job3 := testing.MakeJob("on-demand-job3", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "1").Obj()
 		gomega.Expect(k8sClient.Create(ctx, job3)).Should(gomega.Succeed())
@@ -386,23 +391,19 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob3.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob3.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 
 		ginkgo.By("deleting job1")
 		gomega.Expect(k8sClient.Delete(ctx, job1, client.PropagationPolicy(metav1.DeletePropagationBackground))).Should(gomega.Succeed())
-		// Simulated deletion of workload by ownerReference of job1.
-		gomega.Expect(k8sClient.Delete(ctx,
-			&kueue.Workload{ObjectMeta: metav1.ObjectMeta{
-				Name:      job1.Name,
-				Namespace: job1.Namespace,
-			}})).Should(gomega.Succeed())
+		// The job controller deletes the workload of job1, as there is no
+		// garbage collector in the test environment.
 		gomega.Eventually(func() *bool {
 			lookupKey := types.NamespacedName{Name: job2.Name, Namespace: job2.Namespace}
 			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob2)).Should(gomega.Succeed())
 			return createdJob2.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob2.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 	})
 
 	ginkgo.It("Should re-enqueue by the delete event of workload belonging to the same Cohort", func() {
@@ -432,7 +433,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob1.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob1.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(queue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 0)
 
 		job2 := testing.MakeJob("on-demand-job2",
 			ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "8").Obj()
@@ -443,7 +444,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob2)).Should(gomega.Succeed())
 			return createdJob2.Spec.Suspend
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal(pointer.Bool(true)))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 1)
 
 		job3 := testing.MakeJob("on-demand-job3", ns.Name).Queue(queue.Name).Request(corev1.ResourceCPU, "2").Obj()
 		gomega.Expect(k8sClient.Create(ctx, job3)).Should(gomega.Succeed())
@@ -454,16 +455,12 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob3.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob3.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(queue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(queue, 0)
 
 		ginkgo.By("deleting job1")
 		gomega.Expect(k8sClient.Delete(ctx, job1, client.PropagationPolicy(metav1.DeletePropagationBackground))).Should(gomega.Succeed())
-		// Simulated deletion of workload by ownerReference of job1.
-		gomega.Expect(k8sClient.Delete(ctx,
-			&kueue.Workload{ObjectMeta: metav1.ObjectMeta{
-				Name:      job1.Name,
-				Namespace: job1.Namespace,
-			}})).Should(gomega.Succeed())
+		// The job controller deletes the workload of job1, as there is no
+		// garbage collector in the test environment.
 
 		gomega.Eventually(func() *bool {
 			lookupKey := types.NamespacedName{Name: job2.Name, Namespace: job2.Namespace}
@@ -471,7 +468,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob2.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob2.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 	})
 
 	ginkgo.It("Should re-enqueue by the update event of ClusterQueue", func() {
@@ -500,7 +497,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob1.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob1.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 
 		job2 := testing.MakeJob("on-demand-job2", ns.Name).Queue(devBEQueue.Name).Request(corev1.ResourceCPU, "6").Obj()
 		gomega.Expect(k8sClient.Create(ctx, job2)).Should(gomega.Succeed())
@@ -510,7 +507,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			gomega.Expect(k8sClient.Get(ctx, lookupKey, createdJob2)).Should(gomega.Succeed())
 			return createdJob2.Spec.Suspend
 		}, framework.ConsistentDuration, framework.Interval).Should(gomega.Equal(pointer.Bool(true)))
-		framework.ExpectPendingWorkloadsMetric(devBEQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(devBEQueue, 1)
 
 		job3 := testing.MakeJob("on-demand-job3", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2").Obj()
 		gomega.Expect(k8sClient.Create(ctx, job3)).Should(gomega.Succeed())
@@ -521,7 +518,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob3.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob3.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 
 		ginkgo.By("updating ClusterQueue")
 		devCq := &kueue.ClusterQueue{}
@@ -537,7 +534,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 			return createdJob2.Spec.Suspend
 		}, framework.Timeout, framework.Interval).Should(gomega.Equal(pointer.Bool(false)))
 		gomega.Expect(createdJob2.Spec.Template.Spec.NodeSelector[instanceKey]).Should(gomega.Equal(onDemandFlavor.Name))
-		framework.ExpectPendingWorkloadsMetric(devBEQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(devBEQueue, 0)
 	})
 
 	ginkgo.It("Should admit two small workloads after a big one finishes", func() {
@@ -546,7 +543,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		gomega.Expect(k8sClient.Create(ctx, bigWl)).Should(gomega.Succeed())
 
 		framework.ExpectWorkloadsToBeAdmitted(ctx, k8sClient, prodClusterQ.Name, bigWl)
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 
 		smallWl1 := testing.MakeWorkload("small-wl-1", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
 		smallWl2 := testing.MakeWorkload("small-wl-2", ns.Name).Queue(prodQueue.Name).Request(corev1.ResourceCPU, "2.5").Obj()
@@ -555,7 +552,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		gomega.Expect(k8sClient.Create(ctx, smallWl2)).Should(gomega.Succeed())
 
 		framework.ExpectWorkloadsToBePending(ctx, k8sClient, smallWl1, smallWl2)
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 2)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 2)
 
 		ginkgo.By("Marking the big workload as finished")
 		framework.UpdateWorkloadStatus(ctx, k8sClient, bigWl, func(wl *kueue.Workload) {
@@ -566,7 +563,7 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		})
 
 		framework.ExpectWorkloadsToBeAdmitted(ctx, k8sClient, prodClusterQ.Name, smallWl1, smallWl2)
-		framework.ExpectPendingWorkloadsMetric(prodQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodQueue, 0)
 	})
 
 	ginkgo.It("Should schedule workloads borrowing quota from ClusterQueues in the same Cohort", func() {
@@ -599,8 +596,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 		gomega.Expect(k8sClient.Create(ctx, wl1)).Should(gomega.Succeed())
 		gomega.Expect(k8sClient.Create(ctx, wl2)).Should(gomega.Succeed())
 		framework.ExpectWorkloadsToBePending(ctx, k8sClient, wl1, wl2)
-		framework.ExpectPendingWorkloadsMetric(prodBEQueue, 1)
-		framework.ExpectPendingWorkloadsMetric(devBEQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(prodBEQueue, 1)
+		framework.ExpectQueuePendingWorkloadsMetric(devBEQueue, 1)
 
 		// Make sure workloads are in the same scheduling cycle.
 		testBEClusterQ := testing.MakeClusterQueue("test-be-cq").
@@ -619,8 +616,8 @@ var _ = ginkgo.Describe("Scheduler", func() {
 
 		framework.ExpectWorkloadsToBeAdmitted(ctx, k8sClient, prodBEClusterQ.Name, wl1)
 		framework.ExpectWorkloadsToBeAdmitted(ctx, k8sClient, devBEClusterQ.Name, wl2)
-		framework.ExpectPendingWorkloadsMetric(prodBEQueue, 0)
-		framework.ExpectPendingWorkloadsMetric(devBEQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(prodBEQueue, 0)
+		framework.ExpectQueuePendingWorkloadsMetric(devBEQueue, 0)
 	})
 
 	ginkgo.It("Should schedule workloads by their priority strictly in StrictFIFO", func() {